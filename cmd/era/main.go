@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/internal/era"
 	"github.com/Ezkerrox/bsc/internal/ethapi"
@@ -268,9 +269,10 @@ func checkAccumulator(e *era.Era) error {
 	//   4) the starting total difficulty value is correct
 	//   5) the accumulator is correct by recomputing it locally, which verifies
 	//      the blocks are all correct (via hash)
+	//   6) the blob sidecars, if any, match the blob transactions of the block
 	//
-	// The attributes 1), 2), and 3) are checked for each block. 4) and 5) require
-	// accumulation across the entire set and are verified at the end.
+	// The attributes 1), 2), 3) and 6) are checked for each block. 4) and 5)
+	// require accumulation across the entire set and are verified at the end.
 	for it.Next() {
 		// 1) next() walks the block index, so we're able to implicitly verify it.
 		if it.Error() != nil {
@@ -290,6 +292,16 @@ func checkAccumulator(e *era.Era) error {
 		if rr != block.ReceiptHash() {
 			return fmt.Errorf("receipt root in block %d mismatch: want %s, got %s", block.NumberU64(), block.ReceiptHash(), rr)
 		}
+		// 6) verify the blob sidecars against the blob transactions.
+		sidecars, err := it.BlobSidecars()
+		if err != nil {
+			return fmt.Errorf("error reading blob sidecars %d: %w", it.Number(), err)
+		}
+		if sidecars != nil {
+			if err := core.VerifyBlobSidecars(block, sidecars); err != nil {
+				return fmt.Errorf("blob sidecars in block %d invalid: %w", block.NumberU64(), err)
+			}
+		}
 		hashes = append(hashes, block.Hash())
 		td.Add(td, block.Difficulty())
 		tds = append(tds, new(big.Int).Set(td))
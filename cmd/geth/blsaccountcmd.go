@@ -27,8 +27,11 @@ import (
 
 	"github.com/Ezkerrox/bsc/cmd/utils"
 	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/vote"
 	"github.com/Ezkerrox/bsc/crypto"
 	"github.com/Ezkerrox/bsc/internal/flags"
+	"github.com/Ezkerrox/bsc/node"
 	"github.com/Ezkerrox/bsc/signer/core"
 )
 
@@ -213,6 +216,64 @@ Generate ownership proof for the selected BLS account from the BLS wallet. The p
 					},
 				},
 			},
+			{
+				Name:      "journal",
+				Usage:     "Manage the vote journal",
+				ArgsUsage: "",
+				Category:  "BLS ACCOUNT COMMANDS",
+				Description: `
+
+Export or import the vote history kept in the vote journal, in the EIP-3076
+slashing protection interchange format. When failing over a validator to a
+backup machine, export the journal of the previous machine and import it into
+the backup one before it starts voting, the backup machine then refuses to vote
+at or below the imported votes.
+
+The node must be stopped while its vote journal is exported or imported.`,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "Export the vote journal into an interchange file",
+						Action:    blsJournalExport,
+						ArgsUsage: "<interchange file>",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+						},
+						Description: `
+	geth bls journal export <interchange file>
+
+Export all the votes recorded in the vote journal into the interchange file.`,
+					},
+					{
+						Name:      "import",
+						Usage:     "Import an interchange file into the vote journal",
+						Action:    blsJournalImport,
+						ArgsUsage: "<interchange file>",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+							utils.BLSPasswordFileFlag,
+							utils.BLSWalletDirFlag,
+							utils.BLSRemoteSignerFlag,
+							utils.BLSRemoteSignerPubKeyFlag,
+							utils.BLSRemoteSignerCACertFlag,
+							utils.BLSRemoteSignerClientCertFlag,
+							utils.BLSRemoteSignerClientKeyFlag,
+						},
+						Description: `
+	geth bls journal import <interchange file>
+
+Import the votes of the interchange file into the vote journal, the genesis of
+the interchange file must match the local chain, which must therefore be
+initialized first. The interchange file must only contain the votes of the BLS
+key the node votes with, which is read from the local wallet or the remote
+signer as configured.`,
+					},
+				},
+			},
 		},
 	}
 )
@@ -665,3 +726,102 @@ func blsAccountGenerateProof(ctx *cli.Context) error {
 
 	return nil
 }
+
+// openVoteJournal opens the vote journal of the node and reads the genesis hash
+// of its chain, which identifies the chain in the interchange file. The node must
+// be closed by the caller.
+func openVoteJournal(ctx *cli.Context) (*node.Node, *vote.VoteJournal, common.Hash) {
+	stack, cfg := makeConfigNode(ctx)
+
+	db := utils.MakeChainDatabase(ctx, stack, true, false)
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	db.Close()
+
+	journal, err := vote.NewVoteJournal(stack.ResolvePath(cfg.Node.VoteJournalDir))
+	if err != nil {
+		utils.Fatalf("Open vote journal failed: %v.", err)
+	}
+	return stack, journal, genesisHash
+}
+
+// voteSignerConfig returns the config of the vote signer of the node, from the
+// same flags as the vote manager.
+func voteSignerConfig(stack *node.Node) *vote.SignerConfig {
+	conf := stack.Config()
+	signerConfig := &vote.SignerConfig{
+		PasswordPath: stack.ResolvePath(conf.BLSPasswordFile),
+		WalletPath:   stack.ResolvePath(conf.BLSWalletDir),
+	}
+	if conf.BLSRemoteSigner != "" {
+		resolvePath := func(path string) string {
+			if path == "" {
+				return ""
+			}
+			return stack.ResolvePath(path)
+		}
+		signerConfig.Remote = &vote.RemoteSignerConfig{
+			URL:        conf.BLSRemoteSigner,
+			PublicKey:  conf.BLSRemoteSignerPubKey,
+			CACert:     resolvePath(conf.BLSRemoteSignerCACert),
+			ClientCert: resolvePath(conf.BLSRemoteSignerClientCert),
+			ClientKey:  resolvePath(conf.BLSRemoteSignerClientKey),
+		}
+	}
+	return signerConfig
+}
+
+// blsJournalExport exports the vote journal into an interchange file.
+func blsJournalExport(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("The interchange file must be given as the only argument.")
+	}
+	stack, journal, genesisHash := openVoteJournal(ctx)
+	defer stack.Close()
+
+	interchange, err := journal.ExportInterchange(genesisHash)
+	if err != nil {
+		utils.Fatalf("Export vote journal failed: %v.", err)
+	}
+	data, err := json.MarshalIndent(interchange, "", "  ")
+	if err != nil {
+		utils.Fatalf("Marshal interchange failed: %v.", err)
+	}
+	if err := os.WriteFile(ctx.Args().First(), data, 0600); err != nil {
+		utils.Fatalf("Write interchange file failed: %v.", err)
+	}
+	count := 0
+	for _, data := range interchange.Data {
+		count += len(data.SignedAttestations)
+	}
+	fmt.Printf("Exported %d votes of %d BLS keys.\n", count, len(interchange.Data))
+	return nil
+}
+
+// blsJournalImport imports an interchange file into the vote journal.
+func blsJournalImport(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("The interchange file must be given as the only argument.")
+	}
+	data, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Read interchange file failed: %v.", err)
+	}
+	var interchange vote.Interchange
+	if err := json.Unmarshal(data, &interchange); err != nil {
+		utils.Fatalf("Unmarshal interchange file failed: %v.", err)
+	}
+	stack, journal, genesisHash := openVoteJournal(ctx)
+	defer stack.Close()
+
+	// Only the vote history of the local BLS key is imported.
+	signer, err := vote.NewSigner(voteSignerConfig(stack), nil)
+	if err != nil {
+		utils.Fatalf("Open vote signer failed: %v.", err)
+	}
+	count, err := journal.ImportInterchange(&interchange, genesisHash, signer.PublicKey())
+	if err != nil {
+		utils.Fatalf("Import vote journal failed: %v.", err)
+	}
+	fmt.Printf("Imported %d votes.\n", count)
+	return nil
+}
@@ -137,8 +137,12 @@ a data corruption.`,
 			utils.ForceFlag,
 			utils.AncientFlag,
 		},
-		Usage:       "Convert Hash-Base to Path-Base trie node.",
-		Description: `This command iterates the entire trie node database and convert the hash-base node to path-base node.`,
+		Usage: "Convert Hash-Base to Path-Base trie node.",
+		Description: `This command iterates the entire trie node database and convert the hash-base node to path-base node.
+The progress is persisted, an interrupted conversion resumes where it stopped unless --force is given. The
+conversion can also run in the background of a live node, see debug_startStateMigration. The path-based state
+stays at the converted block then, the node rewinds the chain to it at the next restart and syncs the blocks
+imported since again.`,
 	}
 	dbTrieGetCmd = &cli.Command{
 		Action:    dbTrieGet,
@@ -1300,45 +1304,49 @@ func hbss2pbss(ctx *cli.Context) error {
 		log.Info("hbss2pbss triedb", "scheme", triedb.Scheme())
 		defer triedb.Close()
 
-		headerHash := rawdb.ReadHeadHeaderHash(db)
-		blockNumber := rawdb.ReadHeaderNumber(db, headerHash)
-		if blockNumber == nil {
-			log.Error("read header number failed.")
-			return fmt.Errorf("read header number failed")
+		// Resume the conversion in progress, unless forced to start over
+		h2p, err := trie.ResumeHbss2Pbss(triedb, jobnum)
+		if err != nil {
+			return err
 		}
+		if h2p != nil && !force {
+			log.Info("Resuming hbss2pbss conversion", "root", h2p.Root(), "number", h2p.Number())
+		} else {
+			headerHash := rawdb.ReadHeadHeaderHash(db)
+			blockNumber := rawdb.ReadHeaderNumber(db, headerHash)
+			if blockNumber == nil {
+				log.Error("read header number failed.")
+				return fmt.Errorf("read header number failed")
+			}
 
-		log.Info("hbss2pbss converting", "HeaderHash: ", headerHash.String(), ", blockNumber: ", *blockNumber)
+			log.Info("hbss2pbss converting", "HeaderHash: ", headerHash.String(), ", blockNumber: ", *blockNumber)
 
-		var headerBlockHash common.Hash
-		var trieRootHash common.Hash
+			var headerBlockHash common.Hash
+			var trieRootHash common.Hash
 
-		if *blockNumber != math.MaxUint64 {
-			headerBlockHash = rawdb.ReadCanonicalHash(db, *blockNumber)
-			if headerBlockHash == (common.Hash{}) {
-				return errors.New("ReadHeadBlockHash empty hash")
+			if *blockNumber != math.MaxUint64 {
+				headerBlockHash = rawdb.ReadCanonicalHash(db, *blockNumber)
+				if headerBlockHash == (common.Hash{}) {
+					return errors.New("ReadHeadBlockHash empty hash")
+				}
+				blockHeader := rawdb.ReadHeader(db, headerBlockHash, *blockNumber)
+				trieRootHash = blockHeader.Root
+				fmt.Println("Canonical Hash: ", headerBlockHash.String(), ", TrieRootHash: ", trieRootHash.String())
+			}
+			if (trieRootHash == common.Hash{}) {
+				log.Error("Empty root hash")
+				return errors.New("Empty root hash.")
 			}
-			blockHeader := rawdb.ReadHeader(db, headerBlockHash, *blockNumber)
-			trieRootHash = blockHeader.Root
-			fmt.Println("Canonical Hash: ", headerBlockHash.String(), ", TrieRootHash: ", trieRootHash.String())
-		}
-		if (trieRootHash == common.Hash{}) {
-			log.Error("Empty root hash")
-			return errors.New("Empty root hash.")
-		}
 
-		id := trie.StateTrieID(trieRootHash)
-		theTrie, err := trie.New(id, triedb)
-		if err != nil {
-			log.Error("fail to new trie tree", "err", err, "rootHash", err, trieRootHash.String())
-			return err
+			h2p, err = trie.NewHbss2Pbss(triedb, trieRootHash, *blockNumber, jobnum)
+			if err != nil {
+				log.Error("fail to new hash2pbss", "err", err, "rootHash", trieRootHash.String())
+				return err
+			}
 		}
-
-		h2p, err := trie.NewHbss2Pbss(theTrie, triedb, trieRootHash, *blockNumber, jobnum)
-		if err != nil {
-			log.Error("fail to new hash2pbss", "err", err, "rootHash", err, trieRootHash.String())
+		if err := h2p.Run(nil); err != nil {
 			return err
 		}
-		h2p.Run()
 	} else {
 		log.Info("Convert hbss to pbss success. Nothing to do.")
 	}
@@ -1368,9 +1376,9 @@ func hbss2pbss(ctx *cli.Context) error {
 	}
 	// prune hbss trie node
 	if stateDiskDb != nil {
-		err = rawdb.PruneHashTrieNodeInDataBase(stateDiskDb)
+		err = rawdb.PruneHashTrieNodeInDataBase(stateDiskDb, nil)
 	} else {
-		err = rawdb.PruneHashTrieNodeInDataBase(db)
+		err = rawdb.PruneHashTrieNodeInDataBase(db, nil)
 	}
 	if err != nil {
 		log.Error("Prune Hash trie node in database failed", "error", err)
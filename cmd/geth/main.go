@@ -95,6 +95,8 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolOverflowPoolSlotsFlag,
+		utils.TxPoolOverflowPoolPolicyFlag,
+		utils.TxPoolHistoryFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.TxPoolReannounceTimeFlag,
 		utils.BlobPoolDataDirFlag,
@@ -109,6 +111,9 @@ var (
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.StateHistoryFlag,
+		utils.HistoricalStateFlag,
+		utils.OnlinePruningBloomSizeFlag,
+		utils.OnlinePruningRateFlag,
 		utils.PathDBSyncFlag,
 		utils.JournalFileFlag,
 		utils.LightServeFlag,       // deprecated
@@ -133,6 +138,8 @@ var (
 		utils.CachePreimagesFlag,
 		utils.MultiDataBaseFlag,
 		utils.PersistDiffFlag,
+		utils.BlockProfilesFlag,
+		utils.SlowTxThresholdFlag,
 		utils.DiffBlockFlag,
 		utils.PruneAncientDataFlag,
 		utils.CacheLogSizeFlag,
@@ -180,15 +187,24 @@ var (
 		utils.BlockAmountReserved,
 		utils.CheckSnapshotWithMPT,
 		utils.EnableDoubleSignMonitorFlag,
+		utils.DoubleSignEvidenceSubmitKeyFlag,
 		utils.VotingEnabledFlag,
 		utils.DisableVoteAttestationFlag,
 		utils.EnableMaliciousVoteMonitorFlag,
 		utils.BLSPasswordFileFlag,
 		utils.BLSWalletDirFlag,
+		utils.BLSRemoteSignerFlag,
+		utils.BLSRemoteSignerPubKeyFlag,
+		utils.BLSRemoteSignerCACertFlag,
+		utils.BLSRemoteSignerClientCertFlag,
+		utils.BLSRemoteSignerClientKeyFlag,
 		utils.VoteJournalDirFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
 		utils.BlobExtraReserveFlag,
+		utils.BlobArchiveFlag,
+		utils.BlobArchiveRegionFlag,
+		utils.BlobArchiveCredentialsFlag,
 		// utils.BeaconApiFlag,
 		// utils.BeaconApiHeaderFlag,
 		// utils.BeaconThresholdFlag,
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/ethclient"
+	"github.com/Ezkerrox/bsc/internal/ethapi"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+var errNoProfit = errors.New("no profit in pending transactions")
+
+// bidSender sends the signed bids, either to the validator through a sentry or
+// to the in-process mock sentry.
+type bidSender interface {
+	SendBid(ctx context.Context, args types.BidArgs) (common.Hash, error)
+}
+
+// builder watches the mempool of the node, and bids for the next block with the
+// pending transactions paying the highest tips.
+type builder struct {
+	node    *ethclient.Client
+	sender  bidSender
+	key     *ecdsa.PrivateKey
+	address common.Address
+	maxTxs  int
+	feeRate uint64 // The builder fee, 100 means 1% of the gas fee
+}
+
+func newBuilder(node *ethclient.Client, sender bidSender, key *ecdsa.PrivateKey, maxTxs int, feeRate uint64) *builder {
+	return &builder{
+		node:    node,
+		sender:  sender,
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		maxTxs:  maxTxs,
+		feeRate: feeRate,
+	}
+}
+
+// loop bids once for each new head of the node, until the context is cancelled.
+func (b *builder) loop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last common.Hash
+	for {
+		select {
+		case <-ticker.C:
+			head, err := b.node.HeaderByNumber(ctx, nil)
+			if err != nil {
+				log.Warn("Failed to retrieve head", "err", err)
+				continue
+			}
+			if head.Hash() == last {
+				continue
+			}
+			last = head.Hash()
+
+			hash, err := b.bid(ctx, head)
+			switch {
+			case errors.Is(err, errNoProfit):
+				log.Debug("Skipped bid", "number", head.Number.Uint64()+1, "err", err)
+			case err != nil:
+				log.Warn("Failed to bid", "number", head.Number.Uint64()+1, "err", err)
+			default:
+				log.Info("Sent bid", "number", head.Number.Uint64()+1, "hash", hash)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// bid simulates the pending transactions on top of the head, and bids their
+// gas fee for the next block.
+func (b *builder) bid(ctx context.Context, head *types.Header) (common.Hash, error) {
+	txs, err := b.pendingTxs(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(txs) == 0 {
+		return common.Hash{}, errNoProfit
+	}
+	encoded := make([]hexutil.Bytes, 0, len(txs))
+	for _, tx := range txs {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return common.Hash{}, err
+		}
+		encoded = append(encoded, data)
+	}
+	state := rpc.BlockNumberOrHashWithHash(head.Hash(), false)
+	var result ethapi.CallBundleResult
+	if err := b.node.Client().CallContext(ctx, &result, "eth_callBundle", ethapi.CallBundleArgs{
+		Txs:                    encoded,
+		StateBlockNumberOrHash: &state,
+	}); err != nil {
+		return common.Hash{}, err
+	}
+	args, err := newBidArgs(b.key, head, encoded, uint64(result.TotalGasUsed), result.CoinbaseDiff.ToInt(), b.feeRate)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return b.sender.SendBid(ctx, *args)
+}
+
+// pendingTxs returns the executable transactions of the mempool, in nonce order
+// per account, the accounts paying the highest tips first.
+func (b *builder) pendingTxs(ctx context.Context) ([]*types.Transaction, error) {
+	var content map[string]map[string]map[string]*types.Transaction
+	if err := b.node.Client().CallContext(ctx, &content, "txpool_content"); err != nil {
+		return nil, err
+	}
+	accounts := make([][]*types.Transaction, 0, len(content["pending"]))
+	for _, pending := range content["pending"] {
+		txs := make([]*types.Transaction, 0, len(pending))
+		for _, tx := range pending {
+			// Blob transactions are not allowed in the bids
+			if tx.Type() != types.BlobTxType {
+				txs = append(txs, tx)
+			}
+		}
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+		if len(txs) > 0 {
+			accounts = append(accounts, txs)
+		}
+	}
+	return orderByTip(accounts, b.maxTxs), nil
+}
+
+// orderByTip merges the nonce-ordered transactions of the accounts, picking the
+// transaction with the highest tip among the next ones of each account.
+func orderByTip(accounts [][]*types.Transaction, limit int) []*types.Transaction {
+	var txs []*types.Transaction
+	for len(txs) < limit {
+		best := -1
+		for i, account := range accounts {
+			if len(account) == 0 {
+				continue
+			}
+			if best < 0 || account[0].GasTipCapCmp(accounts[best][0]) > 0 {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		txs = append(txs, accounts[best][0])
+		accounts[best] = accounts[best][1:]
+	}
+	return txs
+}
+
+// newBidArgs assembles the bid for the block following the head, signed by the
+// builder.
+func newBidArgs(key *ecdsa.PrivateKey, head *types.Header, txs []hexutil.Bytes, gasUsed uint64, gasFee *big.Int, feeRate uint64) (*types.BidArgs, error) {
+	if gasFee == nil || gasFee.Sign() <= 0 || gasUsed == 0 {
+		return nil, errNoProfit
+	}
+	builderFee := new(big.Int).Mul(gasFee, new(big.Int).SetUint64(feeRate))
+	builderFee.Div(builderFee, big.NewInt(10000))
+
+	rawBid := &types.RawBid{
+		BlockNumber:  head.Number.Uint64() + 1,
+		ParentHash:   head.Hash(),
+		Txs:          txs,
+		UnRevertible: []common.Hash{},
+		GasUsed:      gasUsed,
+		GasFee:       gasFee,
+		BuilderFee:   builderFee,
+	}
+	signature, err := crypto.Sign(rawBid.Hash().Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	return &types.BidArgs{RawBid: rawBid, Signature: signature}, nil
+}
+
+// logIssue logs the issues the validator reports about the bids.
+func logIssue(issue *types.BidIssue) {
+	log.Warn("Bid issue reported", "validator", issue.Validator, "builder", issue.Builder,
+		"bid", issue.BidHash, "message", issue.Message)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/params"
+)
+
+func TestNewBidArgs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	head := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2)}
+
+	if _, err := newBidArgs(key, head, []hexutil.Bytes{{0x1}}, 21000, big.NewInt(0), 0); err != errNoProfit {
+		t.Fatalf("expected no profit error, got %v", err)
+	}
+	args, err := newBidArgs(key, head, []hexutil.Bytes{{0x1}}, 21000, big.NewInt(1_000_000), 100)
+	if err != nil {
+		t.Fatalf("failed to create bid: %v", err)
+	}
+	if args.RawBid.BlockNumber != 11 || args.RawBid.ParentHash != head.Hash() {
+		t.Fatalf("bid block mismatch: have %d %x", args.RawBid.BlockNumber, args.RawBid.ParentHash)
+	}
+	if args.RawBid.BuilderFee.Cmp(big.NewInt(10_000)) != 0 {
+		t.Fatalf("builder fee mismatch: have %v, want %v", args.RawBid.BuilderFee, 10_000)
+	}
+	builder, err := args.EcrecoverSender()
+	if err != nil {
+		t.Fatalf("failed to recover builder: %v", err)
+	}
+	if builder != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("builder mismatch: have %x, want %x", builder, crypto.PubkeyToAddress(key.PublicKey))
+	}
+}
+
+func TestOrderByTip(t *testing.T) {
+	newTx := func(nonce uint64, tip int64) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(tip)})
+	}
+	accounts := [][]*types.Transaction{
+		{newTx(0, 1), newTx(1, 10)},
+		{newTx(0, 5), newTx(1, 2)},
+	}
+	txs := orderByTip(accounts, 3)
+	want := []int64{5, 2, 1}
+	if len(txs) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), len(want))
+	}
+	for i, tx := range txs {
+		if tx.GasPrice().Int64() != want[i] {
+			t.Fatalf("transaction %d tip mismatch: have %d, want %d", i, tx.GasPrice().Int64(), want[i])
+		}
+	}
+}
+
+func TestNewPayBidTx(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		chainID = big.NewInt(714)
+		builder = common.Address{0x1}
+	)
+	tx, err := newPayBidTx(key, chainID, 3, big.NewInt(params.GWei), builder, big.NewInt(10_000))
+	if err != nil {
+		t.Fatalf("failed to create pay bid tx: %v", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("sender mismatch: have %x, want %x", from, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	if *tx.To() != builder || tx.Value().Cmp(big.NewInt(10_000)) != 0 || tx.Gas() != params.TxGas || tx.Nonce() != 3 {
+		t.Fatalf("pay bid tx mismatch: to %x, value %v, gas %d, nonce %d", tx.To(), tx.Value(), tx.Gas(), tx.Nonce())
+	}
+}
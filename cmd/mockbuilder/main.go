@@ -0,0 +1,227 @@
+// mockbuilder is a mock MEV builder and sentry for testing the bid path of a
+// validator locally, e.g. on a dev chain.
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/ethclient"
+	"github.com/Ezkerrox/bsc/internal/flags"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+var (
+	app *cli.App
+
+	nodeFlag = &cli.StringFlag{
+		Name:  "node",
+		Usage: "rpc endpoint of the validator, http,https,ws,wss,ipc are supported",
+	}
+	builderKeyFlag = &cli.StringFlag{
+		Name:  "builder.key",
+		Usage: "raw private key of the builder in hex format without 0x prefix",
+	}
+	sentryKeyFlag = &cli.StringFlag{
+		Name:  "sentry.key",
+		Usage: "raw private key of the account paying the builder fees in hex format without 0x prefix",
+	}
+	sentryFlag = &cli.StringFlag{
+		Name:  "sentry",
+		Usage: "rpc endpoint of the sentry to send the bids to, the sentry runs in process if empty",
+	}
+	listenFlag = &cli.StringFlag{
+		Name:  "listen",
+		Usage: "listening address of the mev_sendBid and mev_reportIssue endpoints",
+		Value: "127.0.0.1:8555",
+	}
+	maxTxsFlag = &cli.IntFlag{
+		Name:  "maxtxs",
+		Usage: "maximum number of transactions in a bid",
+		Value: 100,
+	}
+	builderFeeFlag = &cli.Uint64Flag{
+		Name:  "builderfee",
+		Usage: "builder fee claimed in the bids, 100 means 1% of the gas fee; must not exceed the validator commission",
+		Value: 0,
+	}
+	intervalFlag = &cli.DurationFlag{
+		Name:  "interval",
+		Usage: "interval the head of the validator is polled at",
+		Value: 100 * time.Millisecond,
+	}
+)
+
+func init() {
+	app = flags.NewApp("a mock MEV builder and sentry for local testing")
+	app.Name = "mockbuilder"
+	app.Flags = []cli.Flag{
+		nodeFlag,
+		builderKeyFlag,
+		sentryKeyFlag,
+		sentryFlag,
+		listenFlag,
+		maxTxsFlag,
+		builderFeeFlag,
+		intervalFlag,
+	}
+	app.Action = runBuilder
+	app.Commands = []*cli.Command{
+		{
+			Name:   "sentry",
+			Usage:  "Run the mock sentry only, relaying the bids of the builders to the validator",
+			Flags:  []cli.Flag{nodeFlag, sentryKeyFlag, listenFlag},
+			Action: runSentry,
+		},
+	}
+}
+
+// runBuilder runs the mock builder, along with the mock sentry unless a sentry
+// endpoint is given.
+func runBuilder(c *cli.Context) error {
+	node, err := dialNode(c)
+	if err != nil {
+		return err
+	}
+	defer node.Close()
+
+	builderKey, err := parseKey(c, builderKeyFlag)
+	if err != nil {
+		return err
+	}
+	var (
+		api    = new(mevAPI)
+		sender bidSender
+	)
+	if url := c.String(sentryFlag.Name); url != "" {
+		remote, err := ethclient.Dial(url)
+		if err != nil {
+			return fmt.Errorf("failed to connect to sentry %s: %v", url, err)
+		}
+		defer remote.Close()
+		sender = remote
+	} else {
+		if api.sentry, err = startSentry(c, node); err != nil {
+			return err
+		}
+		sender = api.sentry
+	}
+	stop, err := serve(c.String(listenFlag.Name), api)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	b := newBuilder(node, sender, builderKey, c.Int(maxTxsFlag.Name), c.Uint64(builderFeeFlag.Name))
+	log.Info("Mock builder started", "builder", b.address, "node", c.String(nodeFlag.Name))
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	b.loop(ctx, c.Duration(intervalFlag.Name))
+	return nil
+}
+
+// runSentry runs the mock sentry only.
+func runSentry(c *cli.Context) error {
+	node, err := dialNode(c)
+	if err != nil {
+		return err
+	}
+	defer node.Close()
+
+	s, err := startSentry(c, node)
+	if err != nil {
+		return err
+	}
+	stop, err := serve(c.String(listenFlag.Name), &mevAPI{sentry: s})
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	<-ctx.Done()
+	return nil
+}
+
+func dialNode(c *cli.Context) (*ethclient.Client, error) {
+	url := c.String(nodeFlag.Name)
+	if url == "" {
+		return nil, fmt.Errorf("no node specified (--%s)", nodeFlag.Name)
+	}
+	node, err := ethclient.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to node %s: %v", url, err)
+	}
+	return node, nil
+}
+
+func parseKey(c *cli.Context, flag *cli.StringFlag) (*ecdsa.PrivateKey, error) {
+	raw := c.String(flag.Name)
+	if raw == "" {
+		return nil, fmt.Errorf("no key specified (--%s)", flag.Name)
+	}
+	key, err := crypto.HexToECDSA(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key (--%s): %v", flag.Name, err)
+	}
+	return key, nil
+}
+
+func startSentry(c *cli.Context, node *ethclient.Client) (*sentry, error) {
+	key, err := parseKey(c, sentryKeyFlag)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := node.ChainID(c.Context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve chain id: %v", err)
+	}
+	log.Info("Mock sentry started", "payer", crypto.PubkeyToAddress(key.PublicKey), "chainId", chainID)
+	return newSentry(node, key, chainID), nil
+}
+
+// serve exposes the mev namespace on the listening address over HTTP.
+func serve(addr string, api *mevAPI) (func(), error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("mev", api); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	httpSrv := &http.Server{Handler: srv, ReadHeaderTimeout: 5 * time.Second}
+	go httpSrv.Serve(listener)
+	log.Info("Mock MEV endpoint opened", "url", "http://"+listener.Addr().String())
+
+	return func() {
+		httpSrv.Close()
+		srv.Stop()
+	}, nil
+}
+
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+func main() {
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelInfo, true)))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/ethclient"
+	"github.com/Ezkerrox/bsc/params"
+)
+
+// sentry relays the bids of the builders to the validator, appending the
+// transaction paying the builder fee, as the MEV sentry in front of a validator
+// does.
+type sentry struct {
+	node    *ethclient.Client
+	key     *ecdsa.PrivateKey // The key of the account paying the builder fees
+	chainID *big.Int
+
+	mu sync.Mutex // Serialises the nonces of the pay bid transactions
+}
+
+func newSentry(node *ethclient.Client, key *ecdsa.PrivateKey, chainID *big.Int) *sentry {
+	return &sentry{
+		node:    node,
+		key:     key,
+		chainID: chainID,
+	}
+}
+
+// SendBid appends the pay bid transaction to the bid and forwards it to the
+// validator.
+func (s *sentry) SendBid(ctx context.Context, args types.BidArgs) (common.Hash, error) {
+	if args.RawBid == nil {
+		return common.Hash{}, errors.New("rawBid should not be nil")
+	}
+	builder, err := args.EcrecoverSender()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	payBidTx, err := s.payBidTx(ctx, builder, args.RawBid.BuilderFee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if args.PayBidTx, err = payBidTx.MarshalBinary(); err != nil {
+		return common.Hash{}, err
+	}
+	args.PayBidTxGasUsed = params.TxGas
+	return s.node.SendBid(ctx, args)
+}
+
+// payBidTx creates the transfer of the builder fee to the builder.
+func (s *sentry) payBidTx(ctx context.Context, builder common.Address, fee *big.Int) (*types.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nonce, err := s.node.PendingNonceAt(ctx, crypto.PubkeyToAddress(s.key.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := s.node.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newPayBidTx(s.key, s.chainID, nonce, gasPrice, builder, fee)
+}
+
+// newPayBidTx signs the transfer of the builder fee to the builder.
+func newPayBidTx(key *ecdsa.PrivateKey, chainID *big.Int, nonce uint64, gasPrice *big.Int, builder common.Address, fee *big.Int) (*types.Transaction, error) {
+	if fee == nil {
+		fee = new(big.Int)
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      params.TxGas,
+		To:       &builder,
+		Value:    fee,
+	})
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+// mevAPI serves the calls of the builders and the validator to the mock builder
+// and sentry.
+type mevAPI struct {
+	sentry *sentry // nil if the sentry doesn't run in process
+}
+
+// SendBid relays the bid of a builder to the validator.
+func (api *mevAPI) SendBid(ctx context.Context, args types.BidArgs) (common.Hash, error) {
+	if api.sentry == nil {
+		return common.Hash{}, errors.New("sentry is not running")
+	}
+	return api.sentry.SendBid(ctx, args)
+}
+
+// ReportIssue receives the issues the validator found in the bids.
+func (api *mevAPI) ReportIssue(_ context.Context, issue *types.BidIssue) error {
+	logIssue(issue)
+	return nil
+}
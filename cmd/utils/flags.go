@@ -386,6 +386,23 @@ var (
 		Value:    ethconfig.Defaults.StateHistory,
 		Category: flags.StateCategory,
 	}
+	HistoricalStateFlag = &cli.BoolFlag{
+		Name:     "history.state.query",
+		Usage:    "Serve the states of the blocks covered by the state history in path scheme, reads get slower the older the block",
+		Category: flags.StateCategory,
+	}
+	OnlinePruningBloomSizeFlag = &cli.Uint64Flag{
+		Name:     "state.pruning.bloomsize",
+		Usage:    "Megabytes of memory allocated to bloom-filter for online state pruning (hash scheme)",
+		Value:    ethconfig.Defaults.OnlinePruningBloomSize,
+		Category: flags.StateCategory,
+	}
+	OnlinePruningRateFlag = &cli.Uint64Flag{
+		Name:     "state.pruning.rate",
+		Usage:    "Maximum number of trie nodes deleted per second by online state pruning (0 = unlimited)",
+		Value:    ethconfig.Defaults.OnlinePruningRate,
+		Category: flags.StateCategory,
+	}
 	TransactionHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.transactions",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
@@ -509,6 +526,17 @@ var (
 		Value:    ethconfig.Defaults.TxPool.OverflowPoolSlots,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolOverflowPoolPolicyFlag = &cli.StringFlag{
+		Name:     "txpool.overflowpoolpolicy",
+		Usage:    "Order the overflow pool transactions are re-injected into the pool in (age, price, fairness)",
+		Value:    string(ethconfig.Defaults.TxPool.OverflowPoolPolicy),
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolHistoryFlag = &cli.BoolFlag{
+		Name:     "txpool.history",
+		Usage:    "Record the lifecycle events of all the transactions, not only the ones watched over RPC",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolLifetimeFlag = &cli.DurationFlag{
 		Name:     "txpool.lifetime",
 		Usage:    "Maximum amount of time non-executable transaction are queued",
@@ -586,6 +614,16 @@ var (
 		Usage:    "Enable persistence of the diff layer",
 		Category: flags.FastNodeCategory,
 	}
+	BlockProfilesFlag = &cli.IntFlag{
+		Name:     "profile.blocks",
+		Usage:    "Number of recent block imports whose stage timings are retained for debug_getBlockProfile (0 = disabled)",
+		Category: flags.PerfCategory,
+	}
+	SlowTxThresholdFlag = &cli.DurationFlag{
+		Name:     "slowtx.threshold",
+		Usage:    "Execution time per million gas above which an imported transaction is traced and reported as slow (0 = disabled)",
+		Category: flags.PerfCategory,
+	}
 	DiffBlockFlag = &cli.Uint64Flag{
 		Name:     "diffblock",
 		Usage:    "The number of blocks should be persisted in db (default = 86400)",
@@ -1203,6 +1241,12 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.MinerCategory,
 	}
 
+	DoubleSignEvidenceSubmitKeyFlag = &cli.StringFlag{
+		Name:     "monitor.doublesign.submitkey",
+		Usage:    "Private key file used to submit the double sign evidences to the SlashIndicator contract automatically",
+		Category: flags.MinerCategory,
+	}
+
 	VotingEnabledFlag = &cli.BoolFlag{
 		Name:     "vote",
 		Usage:    "Enable voting when mining",
@@ -1233,6 +1277,36 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.AccountCategory,
 	}
 
+	BLSRemoteSignerFlag = &cli.StringFlag{
+		Name:     "blsremotesigner",
+		Usage:    "URL of a Web3Signer compatible signing service keeping the BLS key, used instead of the local BLS wallet",
+		Category: flags.AccountCategory,
+	}
+
+	BLSRemoteSignerPubKeyFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.pubkey",
+		Usage:    "BLS public key to sign votes with in the remote signing service (default = the first key of the service)",
+		Category: flags.AccountCategory,
+	}
+
+	BLSRemoteSignerCACertFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.cacert",
+		Usage:    "CA certificate file to verify the remote signing service with",
+		Category: flags.AccountCategory,
+	}
+
+	BLSRemoteSignerClientCertFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.clientcert",
+		Usage:    "Client certificate file for mutual TLS with the remote signing service",
+		Category: flags.AccountCategory,
+	}
+
+	BLSRemoteSignerClientKeyFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.clientkey",
+		Usage:    "Client key file for mutual TLS with the remote signing service",
+		Category: flags.AccountCategory,
+	}
+
 	VoteJournalDirFlag = &flags.DirectoryFlag{
 		Name:     "vote-journal-path",
 		Usage:    "Path for the voteJournal dir in fast finality feature (default = inside the datadir)",
@@ -1246,6 +1320,22 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    params.DefaultExtraReserveForBlobRequests,
 		Category: flags.MiscCategory,
 	}
+	BlobArchiveFlag = &cli.StringFlag{
+		Name:     "blob.archive",
+		Usage:    "Archive the blob sidecars before pruning them, to a directory or an S3-compatible bucket URL (e.g. https://s3.us-east-1.amazonaws.com/bucket)",
+		Category: flags.MiscCategory,
+	}
+	BlobArchiveRegionFlag = &cli.StringFlag{
+		Name:     "blob.archive.region",
+		Usage:    "Region of the S3-compatible blob archive bucket",
+		Value:    "us-east-1",
+		Category: flags.MiscCategory,
+	}
+	BlobArchiveCredentialsFlag = &cli.StringFlag{
+		Name:     "blob.archive.credentials",
+		Usage:    "File holding the access key and the secret key of the S3-compatible blob archive bucket on two lines (default: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables, anonymous if unset)",
+		Category: flags.MiscCategory,
+	}
 
 	// Fake beacon
 	FakeBeaconEnabledFlag = &cli.BoolFlag{
@@ -1557,6 +1647,9 @@ func setMonitors(ctx *cli.Context, cfg *node.Config) {
 	if ctx.Bool(EnableDoubleSignMonitorFlag.Name) {
 		cfg.EnableDoubleSignMonitor = true
 	}
+	if ctx.IsSet(DoubleSignEvidenceSubmitKeyFlag.Name) {
+		cfg.DoubleSignEvidenceSubmitKey = ctx.String(DoubleSignEvidenceSubmitKeyFlag.Name)
+	}
 	if ctx.Bool(EnableMaliciousVoteMonitorFlag.Name) {
 		cfg.EnableMaliciousVoteMonitor = true
 	}
@@ -1763,6 +1856,21 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BLSPasswordFileFlag.Name) {
 		cfg.BLSPasswordFile = ctx.String(BLSPasswordFileFlag.Name)
 	}
+	if ctx.IsSet(BLSRemoteSignerFlag.Name) {
+		cfg.BLSRemoteSigner = ctx.String(BLSRemoteSignerFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerPubKeyFlag.Name) {
+		cfg.BLSRemoteSignerPubKey = ctx.String(BLSRemoteSignerPubKeyFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerCACertFlag.Name) {
+		cfg.BLSRemoteSignerCACert = ctx.String(BLSRemoteSignerCACertFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerClientCertFlag.Name) {
+		cfg.BLSRemoteSignerClientCert = ctx.String(BLSRemoteSignerClientCertFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerClientKeyFlag.Name) {
+		cfg.BLSRemoteSignerClientKey = ctx.String(BLSRemoteSignerClientKeyFlag.Name)
+	}
 	if ctx.IsSet(DBEngineFlag.Name) {
 		dbEngine := ctx.String(DBEngineFlag.Name)
 		if dbEngine != "leveldb" && dbEngine != "pebble" {
@@ -1883,6 +1991,12 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolOverflowPoolSlotsFlag.Name) {
 		cfg.OverflowPoolSlots = ctx.Uint64(TxPoolOverflowPoolSlotsFlag.Name)
 	}
+	if ctx.IsSet(TxPoolOverflowPoolPolicyFlag.Name) {
+		cfg.OverflowPoolPolicy = legacypool.OverflowPolicy(ctx.String(TxPoolOverflowPoolPolicyFlag.Name))
+	}
+	if ctx.IsSet(TxPoolHistoryFlag.Name) {
+		cfg.History = ctx.Bool(TxPoolHistoryFlag.Name)
+	}
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
@@ -2106,6 +2220,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateHistoryFlag.Name) {
 		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
+	if ctx.IsSet(HistoricalStateFlag.Name) {
+		cfg.HistoricalState = ctx.Bool(HistoricalStateFlag.Name)
+	}
+	if ctx.IsSet(OnlinePruningBloomSizeFlag.Name) {
+		cfg.OnlinePruningBloomSize = ctx.Uint64(OnlinePruningBloomSizeFlag.Name)
+	}
+	if ctx.IsSet(OnlinePruningRateFlag.Name) {
+		cfg.OnlinePruningRate = ctx.Uint64(OnlinePruningRateFlag.Name)
+	}
 	scheme, err := ParseCLIAndConfigStateScheme(ctx.String(StateSchemeFlag.Name), cfg.StateScheme)
 	if err != nil {
 		Fatalf("%v", err)
@@ -2337,6 +2460,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		}
 		cfg.BlobExtraReserve = extraReserve
 	}
+	if ctx.IsSet(BlobArchiveFlag.Name) {
+		cfg.BlobArchive = ctx.String(BlobArchiveFlag.Name)
+		cfg.BlobArchiveRegion = ctx.String(BlobArchiveRegionFlag.Name)
+		cfg.BlobArchiveCredentials = ctx.String(BlobArchiveCredentialsFlag.Name)
+	}
 	// VM tracing config.
 	if ctx.IsSet(VMTraceFlag.Name) {
 		if name := ctx.String(VMTraceFlag.Name); name != "" {
@@ -2344,6 +2472,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.VMTraceJsonConfig = ctx.String(VMTraceJsonConfigFlag.Name)
 		}
 	}
+	if ctx.IsSet(BlockProfilesFlag.Name) {
+		cfg.BlockProfiles = ctx.Int(BlockProfilesFlag.Name)
+	}
+	if ctx.IsSet(SlowTxThresholdFlag.Name) {
+		cfg.SlowTxThreshold = ctx.Duration(SlowTxThresholdFlag.Name)
+	}
 }
 
 // SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
@@ -2430,17 +2564,18 @@ func EnableNodeInfo(poolConfig *legacypool.Config, nodeInfo *p2p.NodeInfo) Setup
 	return func() {
 		// register node info into metrics
 		metrics.GetOrRegisterLabel("node-info", nil).Mark(map[string]interface{}{
-			"Enode":             nodeInfo.Enode,
-			"ENR":               nodeInfo.ENR,
-			"ID":                nodeInfo.ID,
-			"PriceLimit":        poolConfig.PriceLimit,
-			"PriceBump":         poolConfig.PriceBump,
-			"AccountSlots":      poolConfig.AccountSlots,
-			"GlobalSlots":       poolConfig.GlobalSlots,
-			"AccountQueue":      poolConfig.AccountQueue,
-			"GlobalQueue":       poolConfig.GlobalQueue,
-			"OverflowPoolSlots": poolConfig.OverflowPoolSlots,
-			"Lifetime":          poolConfig.Lifetime,
+			"Enode":              nodeInfo.Enode,
+			"ENR":                nodeInfo.ENR,
+			"ID":                 nodeInfo.ID,
+			"PriceLimit":         poolConfig.PriceLimit,
+			"PriceBump":          poolConfig.PriceBump,
+			"AccountSlots":       poolConfig.AccountSlots,
+			"GlobalSlots":        poolConfig.GlobalSlots,
+			"AccountQueue":       poolConfig.AccountQueue,
+			"GlobalQueue":        poolConfig.GlobalQueue,
+			"OverflowPoolSlots":  poolConfig.OverflowPoolSlots,
+			"OverflowPoolPolicy": poolConfig.OverflowPoolPolicy,
+			"Lifetime":           poolConfig.Lifetime,
 		})
 	}
 }
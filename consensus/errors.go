@@ -38,4 +38,8 @@ var (
 	// ErrInvalidTerminalBlock is returned if a block is invalid wrt. the terminal
 	// total difficulty.
 	ErrInvalidTerminalBlock = errors.New("invalid terminal block")
+
+	// ErrUnknownVoteAddress is returned if the address a vote is signed with isn't
+	// the one of a validator of the target block.
+	ErrUnknownVoteAddress = errors.New("vote verification failed")
 )
@@ -17,12 +17,23 @@
 package parlia
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/willf/bitset"
+
 	"github.com/Ezkerrox/bsc/common"
+	cmath "github.com/Ezkerrox/bsc/common/math"
 	"github.com/Ezkerrox/bsc/consensus"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/rpc"
 )
 
+// maxValidatorPerformanceRange is the maximum number of blocks that a single
+// GetValidatorPerformance call is allowed to scan.
+const maxValidatorPerformanceRange = 10000
+
 // API is a user facing RPC API to allow query snapshot and validators
 type API struct {
 	chain  consensus.ChainHeaderReader
@@ -114,6 +125,224 @@ func (api *API) GetFinalizedNumber(number *rpc.BlockNumber) (uint64, error) {
 	return snap.Attestation.SourceNumber, nil
 }
 
+// ValidatorPerformance is the block production and voting record of a single
+// validator within a range of blocks.
+type ValidatorPerformance struct {
+	InTurnBlocks    uint64 `json:"in_turn_blocks"`     // Blocks proposed while being the in-turn validator
+	OutOfTurnBlocks uint64 `json:"out_of_turn_blocks"` // Blocks proposed while not being the in-turn validator
+	MissedSlots     uint64 `json:"missed_slots"`       // In-turn slots that were filled by another validator
+	AvgBackOffTime  uint64 `json:"avg_back_off_time"`  // Average backoff delay of out-of-turn blocks in milliseconds
+	Attestations    uint64 `json:"attestations"`       // Vote attestations the validator's vote was aggregated into
+
+	totalBackOffTime uint64
+}
+
+// ValidatorPerformanceResult is the result of GetValidatorPerformance.
+type ValidatorPerformanceResult struct {
+	From       uint64                                   `json:"from"`
+	To         uint64                                   `json:"to"`
+	Validators map[common.Address]*ValidatorPerformance `json:"validators"`
+}
+
+// get returns the performance record of the given validator, creating it if needed.
+func (r *ValidatorPerformanceResult) get(val common.Address) *ValidatorPerformance {
+	perf, ok := r.Validators[val]
+	if !ok {
+		perf = &ValidatorPerformance{}
+		r.Validators[val] = perf
+	}
+	return perf
+}
+
+// recordBlock accounts a block proposed by signer, where inturn is the validator
+// which had the priority to propose it.
+func (r *ValidatorPerformanceResult) recordBlock(signer, inturn common.Address, inturnRecentlySigned bool, backOffTime uint64) {
+	perf := r.get(signer)
+	if signer == inturn {
+		perf.InTurnBlocks++
+		return
+	}
+	perf.OutOfTurnBlocks++
+	perf.totalBackOffTime += backOffTime
+	perf.AvgBackOffTime = perf.totalBackOffTime / perf.OutOfTurnBlocks
+
+	// A validator which signed recently is not allowed to seal, so the slot
+	// is not considered as missed by it.
+	if !inturnRecentlySigned {
+		r.get(inturn).MissedSlots++
+	}
+}
+
+// GetValidatorPerformance walks the headers in [from, to] and reports, for every
+// validator, the in-turn and out-of-turn blocks it proposed, the in-turn slots it
+// missed, the average backoff delay of its out-of-turn blocks and the number of
+// vote attestations it contributed to.
+func (api *API) GetValidatorPerformance(from, to rpc.BlockNumber) (*ValidatorPerformanceResult, error) {
+	fromHeader, toHeader := api.getHeader(&from), api.getHeader(&to)
+	if fromHeader == nil || toHeader == nil {
+		return nil, errUnknownBlock
+	}
+	start, end := fromHeader.Number.Uint64(), toHeader.Number.Uint64()
+	if start > end {
+		return nil, fmt.Errorf("invalid block range, from %d is larger than to %d", start, end)
+	}
+	if end-start >= maxValidatorPerformanceRange {
+		return nil, fmt.Errorf("block range too large, at most %d blocks are allowed", maxValidatorPerformanceRange)
+	}
+	if start == 0 {
+		start = 1 // the genesis block has no proposer
+	}
+
+	result := &ValidatorPerformanceResult{
+		From:       start,
+		To:         end,
+		Validators: make(map[common.Address]*ValidatorPerformance),
+	}
+	header := toHeader
+	for number := end; number >= start && number > 0; number-- {
+		parent := api.chain.GetHeader(header.ParentHash, number-1)
+		if parent == nil {
+			return nil, fmt.Errorf("header is nil at height %d", number-1)
+		}
+		snap, err := api.parlia.snapshot(api.chain, parent.Number.Uint64(), parent.Hash(), nil)
+		if err != nil {
+			return nil, err
+		}
+		inturn := snap.inturnValidator()
+		result.recordBlock(header.Coinbase, inturn, snap.SignRecently(inturn), api.parlia.backOffTime(snap, parent, header, header.Coinbase))
+
+		if err := api.recordAttestation(result, header, snap.EpochLength); err != nil {
+			return nil, err
+		}
+		header = parent
+	}
+	return result, nil
+}
+
+// recordAttestation accounts the validators which voted for the attestation
+// carried by the given header, if any.
+func (api *API) recordAttestation(result *ValidatorPerformanceResult, header *types.Header, epochLength uint64) error {
+	attestation, err := getVoteAttestationFromHeader(header, api.chain.Config(), epochLength)
+	if err != nil || attestation == nil || attestation.Data == nil {
+		return err
+	}
+	justifiedBlock := api.chain.GetHeaderByHash(attestation.Data.TargetHash)
+	if justifiedBlock == nil || justifiedBlock.Number.Uint64() == 0 {
+		return nil
+	}
+	snap, err := api.parlia.snapshot(api.chain, justifiedBlock.Number.Uint64()-1, justifiedBlock.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	signed, _, err := attestationVoters(snap, attestation)
+	if err != nil {
+		return fmt.Errorf("invalid attestation in block %d: %w", header.Number.Uint64(), err)
+	}
+	for _, val := range signed {
+		result.get(val).Attestations++
+	}
+	return nil
+}
+
+// AttestationResult is the decoded vote attestation carried by a header.
+type AttestationResult struct {
+	Number         uint64           `json:"number"`          // Block carrying the attestation
+	Hash           common.Hash      `json:"hash"`            // Block carrying the attestation
+	SourceNumber   uint64           `json:"source_number"`   // Latest justified block when voting
+	SourceHash     common.Hash      `json:"source_hash"`     // Latest justified block when voting
+	TargetNumber   uint64           `json:"target_number"`   // Block voted for
+	TargetHash     common.Hash      `json:"target_hash"`     // Block voted for
+	Signed         []common.Address `json:"signed"`          // Validators whose vote was aggregated
+	Missing        []common.Address `json:"missing"`         // Validators whose vote was not aggregated
+	Quorum         bool             `json:"quorum"`          // Whether at least 2/3 of the validators voted
+	SignatureValid bool             `json:"signature_valid"` // Whether the aggregated BLS signature verifies
+}
+
+// GetAttestation decodes the vote attestation carried by the given block and
+// resolves its voters through the snapshot of the target block's parent. It
+// returns nil if the block carries no attestation.
+func (api *API) GetAttestation(blockNrOrHash rpc.BlockNumberOrHash) (*AttestationResult, error) {
+	var header *types.Header
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		header = api.chain.GetHeaderByHash(hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		header = api.getHeader(&number)
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	epochLength, err := api.parlia.epochLength(api.chain, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	attestation, err := getVoteAttestationFromHeader(header, api.chain.Config(), epochLength)
+	if err != nil || attestation == nil || attestation.Data == nil {
+		return nil, err
+	}
+	target := api.chain.GetHeaderByHash(attestation.Data.TargetHash)
+	if target == nil || target.Number.Uint64() == 0 {
+		return nil, fmt.Errorf("unknown attestation target block %d, hash: %s", attestation.Data.TargetNumber, attestation.Data.TargetHash)
+	}
+	snap, err := api.parlia.snapshot(api.chain, target.Number.Uint64()-1, target.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	signed, missing, err := attestationVoters(snap, attestation)
+	if err != nil {
+		return nil, err
+	}
+	return &AttestationResult{
+		Number:         header.Number.Uint64(),
+		Hash:           header.Hash(),
+		SourceNumber:   attestation.Data.SourceNumber,
+		SourceHash:     attestation.Data.SourceHash,
+		TargetNumber:   attestation.Data.TargetNumber,
+		TargetHash:     attestation.Data.TargetHash,
+		Signed:         signed,
+		Missing:        missing,
+		Quorum:         len(signed) >= cmath.CeilDiv(len(snap.Validators)*2, 3),
+		SignatureValid: verifyAttestationSignature(snap, signed, attestation),
+	}, nil
+}
+
+// attestationVoters splits the validators of the snapshot into the ones marked
+// in the vote address set of the attestation and the missing ones.
+func attestationVoters(snap *Snapshot, attestation *types.VoteAttestation) (signed, missing []common.Address, err error) {
+	validators := snap.validators()
+	validatorsBitSet := bitset.From([]uint64{uint64(attestation.VoteAddressSet)})
+	if validatorsBitSet.Count() > uint(len(validators)) {
+		return nil, nil, errors.New("invalid attestation, vote number larger than validators number")
+	}
+	signed = make([]common.Address, 0, validatorsBitSet.Count())
+	missing = make([]common.Address, 0, len(validators)-int(validatorsBitSet.Count()))
+	for index, val := range validators {
+		if validatorsBitSet.Test(uint(index)) {
+			signed = append(signed, val)
+		} else {
+			missing = append(missing, val)
+		}
+	}
+	return signed, missing, nil
+}
+
+// verifyAttestationSignature reports whether the aggregated signature of the
+// attestation verifies against the vote addresses of the signed validators.
+func verifyAttestationSignature(snap *Snapshot, signed []common.Address, attestation *types.VoteAttestation) bool {
+	voteAddrs := make([]bls.PublicKey, 0, len(signed))
+	for _, val := range signed {
+		voteAddr, err := bls.PublicKeyFromBytes(snap.Validators[val].VoteAddress[:])
+		if err != nil {
+			return false
+		}
+		voteAddrs = append(voteAddrs, voteAddr)
+	}
+	aggSig, err := bls.SignatureFromBytes(attestation.AggSignature[:])
+	if err != nil {
+		return false
+	}
+	return aggSig.FastAggregateVerify(voteAddrs, attestation.Data.Hash())
+}
+
 func (api *API) getHeader(number *rpc.BlockNumber) (header *types.Header) {
 	currentHeader := api.chain.CurrentHeader()
 
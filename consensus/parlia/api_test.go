@@ -0,0 +1,136 @@
+package parlia
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/params"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+func TestValidatorPerformanceRecordBlock(t *testing.T) {
+	var (
+		valA = common.HexToAddress("0x1")
+		valB = common.HexToAddress("0x2")
+		valC = common.HexToAddress("0x3")
+	)
+	result := &ValidatorPerformanceResult{Validators: make(map[common.Address]*ValidatorPerformance)}
+
+	result.recordBlock(valA, valA, false, 0)
+	result.recordBlock(valB, valA, false, 2000)
+	result.recordBlock(valB, valC, false, 3000)
+	result.recordBlock(valC, valA, true, 1000)
+
+	assert.Equal(t, uint64(1), result.Validators[valA].InTurnBlocks)
+	assert.Equal(t, uint64(1), result.Validators[valA].MissedSlots)
+
+	assert.Equal(t, uint64(0), result.Validators[valB].InTurnBlocks)
+	assert.Equal(t, uint64(2), result.Validators[valB].OutOfTurnBlocks)
+	assert.Equal(t, uint64(2500), result.Validators[valB].AvgBackOffTime)
+
+	// valA signed recently, so it was not allowed to seal the slot taken by valC
+	assert.Equal(t, uint64(1), result.Validators[valC].OutOfTurnBlocks)
+	assert.Equal(t, uint64(1), result.Validators[valC].MissedSlots)
+	assert.Equal(t, uint64(1000), result.Validators[valC].AvgBackOffTime)
+}
+
+func TestGetValidatorPerformanceRPC(t *testing.T) {
+	// Seal a chain with the first validator down, so that its turns are taken
+	// by the others after a backoff and it's missing from the attestations
+	s := newSimulator(t, simConfig(new(uint64), nil, nil), 5, 5)
+	s.down(0)
+	s.run(30)
+
+	engine := New(s.config, rawdb.NewMemoryDatabase(), nil, s.chain.GenesisHeader().Hash())
+	server := rpc.NewServer()
+	for _, api := range engine.APIs(s.chain) {
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatalf("failed to register %s API: %v", api.Namespace, err)
+		}
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var result ValidatorPerformanceResult
+	if err := client.Call(&result, "parlia_getValidatorPerformance", "earliest", "latest"); err != nil {
+		t.Fatalf("failed to get validator performance: %v", err)
+	}
+	assert.Equal(t, uint64(1), result.From)
+	assert.Equal(t, uint64(30), result.To)
+
+	var inTurn, outOfTurn uint64
+	for _, perf := range result.Validators {
+		inTurn += perf.InTurnBlocks
+		outOfTurn += perf.OutOfTurnBlocks
+	}
+	assert.Equal(t, s.report.InTurn, inTurn)
+	assert.Equal(t, s.report.OutOfTurn, outOfTurn)
+
+	down := result.Validators[s.pool[0].address]
+	if assert.NotNil(t, down) {
+		assert.Zero(t, down.InTurnBlocks+down.OutOfTurnBlocks)
+		assert.NotZero(t, down.MissedSlots)
+		assert.Equal(t, s.report.Slashes[s.pool[0].address], down.MissedSlots)
+		assert.Zero(t, down.Attestations)
+	}
+	for _, val := range s.pool[1:] {
+		perf := result.Validators[val.address]
+		if assert.NotNil(t, perf) {
+			assert.Zero(t, perf.MissedSlots)
+			assert.NotZero(t, perf.Attestations)
+			if perf.OutOfTurnBlocks > 0 {
+				assert.NotZero(t, perf.AvgBackOffTime)
+			}
+		}
+	}
+
+	// Invalid ranges are rejected
+	err := client.Call(&result, "parlia_getValidatorPerformance", "latest", "earliest")
+	assert.ErrorContains(t, err, "invalid block range")
+	err = client.Call(&result, "parlia_getValidatorPerformance", "earliest", "0x100")
+	assert.ErrorContains(t, err, errUnknownBlock.Error())
+}
+
+func TestAttestationVoters(t *testing.T) {
+	var (
+		validators = []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")}
+		voteAddrs  = make([]types.BLSPublicKey, len(validators))
+		keys       = make([]bls.SecretKey, len(validators))
+	)
+	for i := range validators {
+		keys[i], _ = bls.RandKey()
+		copy(voteAddrs[i][:], keys[i].PublicKey().Marshal())
+	}
+	snap := newSnapshot(params.ParliaTestChainConfig.Parlia, nil, 0, common.Hash{}, validators, voteAddrs, nil)
+
+	attestation := &types.VoteAttestation{
+		VoteAddressSet: 0b101,
+		Data:           &types.VoteData{TargetNumber: 1, TargetHash: common.Hash{0x1}},
+	}
+	signed, missing, err := attestationVoters(snap, attestation)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{validators[0], validators[2]}, signed)
+	assert.Equal(t, []common.Address{validators[1]}, missing)
+
+	// Aggregate the signatures of the voters, then of the wrong validators
+	aggregate := func(idx ...int) {
+		sigs := make([]bls.Signature, 0, len(idx))
+		for _, i := range idx {
+			sigs = append(sigs, keys[i].Sign(attestation.Data.Hash().Bytes()))
+		}
+		copy(attestation.AggSignature[:], bls.AggregateSignatures(sigs).Marshal())
+	}
+	aggregate(0, 2)
+	assert.True(t, verifyAttestationSignature(snap, signed, attestation))
+	aggregate(0, 1)
+	assert.False(t, verifyAttestationSignature(snap, signed, attestation))
+
+	attestation.VoteAddressSet = 0b1111
+	_, _, err = attestationVoters(snap, attestation)
+	assert.Error(t, err)
+}
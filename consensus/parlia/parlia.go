@@ -94,6 +94,7 @@ var (
 	verifyVoteAttestationErrorCounter = metrics.NewRegisteredCounter("parlia/verifyVoteAttestation/error", nil)
 	updateAttestationErrorCounter     = metrics.NewRegisteredCounter("parlia/updateAttestation/error", nil)
 	validVotesfromSelfCounter         = metrics.NewRegisteredCounter("parlia/VerifyVote/self", nil)
+	verifiedVotesCounter              = metrics.NewRegisteredCounterVec("parlia/VerifyVote", nil, "validator")
 	doubleSignCounter                 = metrics.NewRegisteredCounter("parlia/doublesign", nil)
 	intentionalDelayMiningCounter     = metrics.NewRegisteredCounter("parlia/intentionalDelayMining", nil)
 
@@ -355,6 +356,11 @@ func (p *Parlia) ConsensusAddress() common.Address {
 	return p.val
 }
 
+// SlashABI returns the ABI of the SlashIndicator system contract.
+func (p *Parlia) SlashABI() abi.ABI {
+	return p.slashABI
+}
+
 // VerifyHeader checks whether a header conforms to the consensus rules.
 func (p *Parlia) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
 	return p.verifyHeader(chain, header, nil)
@@ -1627,12 +1633,12 @@ func (p *Parlia) VerifyVote(chain consensus.ChainHeaderReader, vote *types.VoteE
 			if addr == p.val {
 				validVotesfromSelfCounter.Inc(1)
 			}
-			metrics.GetOrRegisterCounter(fmt.Sprintf("parlia/VerifyVote/%s", addr.String()), nil).Inc(1)
+			verifiedVotesCounter.With(addr.String()).Inc(1)
 			return nil
 		}
 	}
 
-	return errors.New("vote verification failed")
+	return consensus.ErrUnknownVoteAddress
 }
 
 // Authorize injects a private key into the consensus engine to mint new blocks
@@ -2316,6 +2322,11 @@ func (p *Parlia) epochLength(chain consensus.ChainHeaderReader, header *types.He
 	return snap.EpochLength, nil
 }
 
+// EpochLength returns the number of blocks in one epoch for the given header
+func (p *Parlia) EpochLength(chain consensus.ChainHeaderReader, header *types.Header) (uint64, error) {
+	return p.epochLength(chain, header, nil)
+}
+
 // BlockInterval returns the block interval in milliseconds for the given header
 func (p *Parlia) BlockInterval(chain consensus.ChainHeaderReader, header *types.Header) (uint64, error) {
 	if header == nil {
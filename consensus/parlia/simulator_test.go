@@ -0,0 +1,580 @@
+package parlia
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/Ezkerrox/bsc/common"
+	cmath "github.com/Ezkerrox/bsc/common/math"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/params"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+const (
+	simMisdemeanorThreshold = 50  // Slashes after which a validator's rewards are forfeited
+	simFelonyThreshold      = 150 // Slashes after which a validator is jailed
+)
+
+// simChain is a minimal in-memory consensus.ChainHeaderReader over the headers
+// generated by the simulator.
+type simChain struct {
+	config   *params.ChainConfig
+	headers  map[common.Hash]*types.Header
+	canon    []*types.Header
+	totalDif map[common.Hash]*big.Int
+}
+
+func newSimChain(config *params.ChainConfig, genesis *types.Header) *simChain {
+	c := &simChain{
+		config:   config,
+		headers:  make(map[common.Hash]*types.Header),
+		totalDif: make(map[common.Hash]*big.Int),
+	}
+	c.insert(genesis)
+	return c
+}
+
+func (c *simChain) insert(header *types.Header) {
+	td := new(big.Int).Set(header.Difficulty)
+	if parent, ok := c.totalDif[header.ParentHash]; ok && header.Number.Sign() > 0 {
+		td.Add(td, parent)
+	}
+	c.headers[header.Hash()] = header
+	c.totalDif[header.Hash()] = td
+	c.canon = append(c.canon, header)
+}
+
+func (c *simChain) Config() *params.ChainConfig  { return c.config }
+func (c *simChain) GenesisHeader() *types.Header { return c.canon[0] }
+func (c *simChain) CurrentHeader() *types.Header { return c.canon[len(c.canon)-1] }
+func (c *simChain) ChasingHead() *types.Header   { return c.CurrentHeader() }
+
+func (c *simChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (c *simChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.canon)) {
+		return nil
+	}
+	return c.canon[number]
+}
+
+func (c *simChain) GetHeaderByHash(hash common.Hash) *types.Header { return c.headers[hash] }
+func (c *simChain) GetTd(hash common.Hash, number uint64) *big.Int { return c.totalDif[hash] }
+func (c *simChain) GetHighestVerifiedHeader() *types.Header        { return c.CurrentHeader() }
+func (c *simChain) GetVerifiedBlockByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+// simValidator is a validator run in process by the simulator.
+type simValidator struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	vote    types.BLSPublicKey
+	online  bool
+}
+
+// simReport summarizes the outcome of a simulation.
+type simReport struct {
+	Blocks         uint64
+	InTurn         uint64 // Blocks sealed by the in-turn validator
+	OutOfTurn      uint64 // Blocks sealed after a backoff
+	TotalBackOff   uint64 // Sum of the backoff delays in milliseconds
+	MaxBackOff     uint64 // Longest backoff delay in milliseconds
+	Attested       uint64 // Blocks carrying a vote attestation
+	Finalized      uint64 // Latest finalized block number
+	MaxFinalityLag uint64 // Longest distance between a block and the finalized block, once finality started
+	Slashes        map[common.Address]uint64
+
+	Validators    int // Size of the current validator set
+	TurnLength    uint8
+	EpochLength   uint64
+	BlockInterval uint64
+}
+
+// slashedOver returns the validators slashed at least threshold times, e.g.
+// simMisdemeanorThreshold or simFelonyThreshold.
+func (r *simReport) slashedOver(threshold uint64) []common.Address {
+	var vals []common.Address
+	for val, count := range r.Slashes {
+		if count >= threshold {
+			vals = append(vals, val)
+		}
+	}
+	sort.Sort(validatorsAscending(vals))
+	return vals
+}
+
+func (r *simReport) String() string {
+	return fmt.Sprintf("blocks=%d inturn=%d outofturn=%d backoff(total=%dms max=%dms) attested=%d finalized=%d maxlag=%d slashed=%d validators=%d turnLength=%d epoch=%d interval=%dms",
+		r.Blocks, r.InTurn, r.OutOfTurn, r.TotalBackOff, r.MaxBackOff, r.Attested, r.Finalized, r.MaxFinalityLag,
+		len(r.Slashes), r.Validators, r.TurnLength, r.EpochLength, r.BlockInterval)
+}
+
+// simulator generates a Parlia chain sealed by in-process validators, in the
+// spirit of core.GenerateChain, and runs every header through the consensus
+// snapshot. Validator joins and leaves, downtime and turn length changes are
+// scripted per block number, fork activations through the chain config.
+type simulator struct {
+	t      *testing.T
+	config *params.ChainConfig
+	engine *Parlia
+	chain  *simChain
+	snap   *Snapshot
+
+	pool       []*simValidator // All the validators, in or out of the set
+	byAddress  map[common.Address]*simValidator
+	next       map[common.Address]bool // Validator set announced at the next epoch block
+	turnLength uint8                   // Turn length announced at the next epoch block after Bohr
+	script     map[uint64][]func(s *simulator)
+
+	report simReport
+}
+
+// newSimulator creates a simulator with a pool of n validators, the first
+// active of them forming the genesis validator set.
+func newSimulator(t *testing.T, config *params.ChainConfig, n, active int) *simulator {
+	sigCache, _ := lru.NewARC(inMemorySignatures)
+	s := &simulator{
+		t:          t,
+		config:     config,
+		engine:     &Parlia{chainConfig: config},
+		byAddress:  make(map[common.Address]*simValidator),
+		next:       make(map[common.Address]bool),
+		turnLength: defaultTurnLength,
+		script:     make(map[uint64][]func(s *simulator)),
+		report:     simReport{Slashes: make(map[common.Address]uint64)},
+	}
+	for i := 0; i < n; i++ {
+		key, _ := crypto.GenerateKey()
+		val := &simValidator{key: key, address: crypto.PubkeyToAddress(key.PublicKey), online: true}
+		rand.Read(val.vote[:])
+		s.pool = append(s.pool, val)
+		s.byAddress[val.address] = val
+	}
+	var (
+		validators []common.Address
+		voteAddrs  []types.BLSPublicKey
+	)
+	for _, val := range s.pool[:active] {
+		validators = append(validators, val.address)
+		voteAddrs = append(voteAddrs, val.vote)
+		s.next[val.address] = true
+	}
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: new(big.Int).Set(diffInTurn),
+		GasLimit:   params.GenesisGasLimit,
+		UncleHash:  types.EmptyUncleHash,
+	}
+	// The validator set of the genesis block is read again at the first switch
+	genesis.Extra = append(s.validatorBytes(genesis), make([]byte, extraSeal)...)
+	s.chain = newSimChain(config, genesis)
+	if !config.IsLuban(common.Big0) {
+		voteAddrs = nil
+	}
+	s.snap = newSnapshot(config.Parlia, sigCache, 0, genesis.Hash(), validators, voteAddrs, nil)
+	return s
+}
+
+// at schedules an action before the block with the given number is sealed.
+func (s *simulator) at(number uint64, action func(s *simulator)) {
+	s.script[number] = append(s.script[number], action)
+}
+
+func (s *simulator) join(idx int)  { s.next[s.pool[idx].address] = true }
+func (s *simulator) leave(idx int) { delete(s.next, s.pool[idx].address) }
+func (s *simulator) down(idx int)  { s.pool[idx].online = false }
+func (s *simulator) up(idx int)    { s.pool[idx].online = true }
+
+func (s *simulator) setTurnLength(turnLength uint8) { s.turnLength = turnLength }
+
+// run seals the given number of blocks on top of the current head.
+func (s *simulator) run(blocks uint64) {
+	s.t.Helper()
+	for i := uint64(0); i < blocks; i++ {
+		number := s.snap.Number + 1
+		for _, action := range s.script[number] {
+			action(s)
+		}
+		if err := s.seal(); err != nil {
+			s.t.Fatalf("block %d: %v", number, err)
+		}
+	}
+	s.report.Validators = len(s.snap.Validators)
+	s.report.TurnLength = s.snap.TurnLength
+	s.report.EpochLength = s.snap.EpochLength
+	s.report.BlockInterval = s.snap.BlockInterval
+}
+
+// seal picks the validator allowed to seal the next block first, assembles the
+// header the way Prepare and Seal do, and applies it to the snapshot.
+func (s *simulator) seal() error {
+	parent := s.chain.CurrentHeader()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       (parent.MilliTimestamp() + s.snap.BlockInterval) / 1000,
+	}
+	producer, delay := s.producer(parent, header)
+	if producer == nil {
+		return fmt.Errorf("chain halted, no validator can seal")
+	}
+	inturn := s.snap.inturn(producer.address)
+	if inturn {
+		header.Difficulty = new(big.Int).Set(diffInTurn)
+	} else {
+		header.Difficulty = new(big.Int).Set(diffNoTurn)
+	}
+	header.Coinbase = producer.address
+
+	blockTime := parent.MilliTimestamp() + s.snap.BlockInterval + delay
+	header.Time = blockTime / 1000
+	if s.config.IsLorentz(header.Number, header.Time) {
+		header.SetMilliseconds(blockTime % 1000)
+	}
+	extra, err := s.extra(parent, header)
+	if err != nil {
+		return err
+	}
+	header.Extra = extra
+	sig, err := crypto.Sign(types.SealHash(header, s.config.ChainID).Bytes(), producer.key)
+	if err != nil {
+		return err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+
+	// Record the slashing the system contract would apply in Finalize
+	if !inturn {
+		spoiledVal := s.snap.inturnValidator()
+		signedRecently := false
+		if s.config.IsPlato(header.Number) {
+			signedRecently = s.snap.SignRecently(spoiledVal)
+		} else {
+			for _, recent := range s.snap.Recents {
+				if recent == spoiledVal {
+					signedRecently = true
+					break
+				}
+			}
+		}
+		if !signedRecently {
+			s.report.Slashes[spoiledVal]++
+		}
+	}
+	snap, err := s.snap.apply([]*types.Header{header}, s.chain, nil, s.config)
+	if err != nil {
+		return err
+	}
+	s.snap = snap
+	s.chain.insert(header)
+
+	s.report.Blocks++
+	if inturn {
+		s.report.InTurn++
+	} else {
+		s.report.OutOfTurn++
+		s.report.TotalBackOff += delay
+		s.report.MaxBackOff = max(s.report.MaxBackOff, delay)
+	}
+	if finalized := snap.getFinalizedNumber(); finalized > 0 {
+		s.report.Finalized = finalized
+		s.report.MaxFinalityLag = max(s.report.MaxFinalityLag, snap.Number-finalized)
+	}
+	return nil
+}
+
+// producer returns the online validator whose backoff expires first, and its
+// backoff in milliseconds, or nil if every validator is offline or has signed
+// recently.
+func (s *simulator) producer(parent, header *types.Header) (*simValidator, uint64) {
+	if val := s.byAddress[s.snap.inturnValidator()]; val.online && !s.snap.SignRecently(val.address) {
+		return val, 0
+	}
+	var (
+		best  *simValidator
+		delay uint64
+	)
+	for _, addr := range s.snap.validators() {
+		val := s.byAddress[addr]
+		if !val.online || s.snap.SignRecently(addr) {
+			continue
+		}
+		backOff := s.engine.backOffTime(s.snap, parent, header, addr)
+		if best == nil || backOff < delay {
+			best, delay = val, backOff
+		}
+	}
+	return best, delay
+}
+
+// extra assembles the extra data of the header: vanity, the validator set and
+// turn length at epoch blocks, the vote attestation and room for the seal.
+func (s *simulator) extra(parent, header *types.Header) ([]byte, error) {
+	extra := make([]byte, extraVanity)
+	if header.Number.Uint64()%s.snap.EpochLength == 0 {
+		extra = s.validatorBytes(header)
+	}
+	if attestation := s.attestation(parent, header); attestation != nil {
+		buf, err := rlp.EncodeToBytes(attestation)
+		if err != nil {
+			return nil, err
+		}
+		extra = append(extra, buf...)
+		s.report.Attested++
+	}
+	return append(extra, make([]byte, extraSeal)...), nil
+}
+
+// validatorBytes returns the vanity followed by the validator set announced at
+// the epoch block, and its turn length after Bohr.
+func (s *simulator) validatorBytes(header *types.Header) []byte {
+	validators := make([]common.Address, 0, len(s.next))
+	for addr := range s.next {
+		validators = append(validators, addr)
+	}
+	sort.Sort(validatorsAscending(validators))
+
+	extra := make([]byte, extraVanity)
+	if s.config.IsLuban(header.Number) {
+		extra = append(extra, byte(len(validators)))
+	}
+	for _, addr := range validators {
+		extra = append(extra, addr.Bytes()...)
+		if s.config.IsLuban(header.Number) {
+			extra = append(extra, s.byAddress[addr].vote[:]...)
+		}
+	}
+	if s.config.IsBohr(header.Number, header.Time) {
+		extra = append(extra, s.turnLength)
+	}
+	return extra
+}
+
+// attestation aggregates the votes of the online validators for the parent
+// block, if they reach the quorum. The aggregated signature is left empty as
+// the snapshot doesn't verify it.
+func (s *simulator) attestation(parent, header *types.Header) *types.VoteAttestation {
+	if !s.config.IsLuban(header.Number) || parent.Number.Sign() == 0 {
+		return nil
+	}
+	attestation := &types.VoteAttestation{
+		Data: &types.VoteData{
+			SourceNumber: 0,
+			SourceHash:   s.chain.GenesisHeader().Hash(),
+			TargetNumber: parent.Number.Uint64(),
+			TargetHash:   parent.Hash(),
+		},
+	}
+	if s.snap.Attestation != nil {
+		attestation.Data.SourceNumber = s.snap.Attestation.TargetNumber
+		attestation.Data.SourceHash = s.snap.Attestation.TargetHash
+	}
+	votes := 0
+	for idx, addr := range s.snap.validators() {
+		if s.byAddress[addr].online {
+			attestation.VoteAddressSet |= 1 << idx
+			votes++
+		}
+	}
+	if votes < cmath.CeilDiv(len(s.snap.Validators)*2, 3) {
+		return nil
+	}
+	return attestation
+}
+
+// simConfig returns a Parlia chain config with every block based fork active
+// from genesis and the Bohr, Lorentz and Maxwell forks at the given times, nil
+// leaving a fork inactive.
+func simConfig(bohr, lorentz, maxwell *uint64) *params.ChainConfig {
+	config := *params.ParliaTestChainConfig
+	config.BohrTime = bohr
+	config.LorentzTime = lorentz
+	config.MaxwellTime = maxwell
+	return &config
+}
+
+func TestSimulateHealthyValidators(t *testing.T) {
+	s := newSimulator(t, simConfig(new(uint64), nil, nil), 21, 21)
+	s.run(3 * defaultEpochLength)
+	t.Log(s.report.String())
+
+	if s.report.OutOfTurn != 0 || len(s.report.Slashes) != 0 {
+		t.Fatalf("unexpected out of turn blocks: %d, slashed: %d", s.report.OutOfTurn, len(s.report.Slashes))
+	}
+	if s.report.Finalized != s.report.Blocks-2 || s.report.MaxFinalityLag != 2 {
+		t.Fatalf("finality mismatch: finalized %d, max lag %d", s.report.Finalized, s.report.MaxFinalityLag)
+	}
+}
+
+func TestSimulateDowntime(t *testing.T) {
+	tests := []struct {
+		down      int
+		finalized bool
+	}{
+		{down: 7, finalized: true},  // 14 of 21 validators still reach the quorum
+		{down: 8, finalized: false}, // 13 of 21 don't
+	}
+	for _, tt := range tests {
+		s := newSimulator(t, simConfig(new(uint64), nil, nil), 21, 21)
+		s.run(10)
+		for i := 0; i < tt.down; i++ {
+			s.down(i)
+		}
+		stalled := s.report.Finalized
+		s.run(2 * defaultEpochLength)
+		t.Logf("down %d: %s", tt.down, s.report.String())
+
+		if finalizing := s.report.Finalized > stalled; finalizing != tt.finalized {
+			t.Fatalf("down %d: finality mismatch: have %v, want %v", tt.down, finalizing, tt.finalized)
+		}
+		if s.report.OutOfTurn == 0 || s.report.MaxBackOff == 0 {
+			t.Fatalf("down %d: no backoff while validators are down", tt.down)
+		}
+		// Every down validator misses its turns, the online ones never do
+		for i, val := range s.pool {
+			if slashed := s.report.Slashes[val.address] > 0; slashed != (i < tt.down) {
+				t.Fatalf("down %d: validator %d slashed %d times", tt.down, i, s.report.Slashes[val.address])
+			}
+		}
+		if !tt.finalized {
+			// Bringing the validators back resumes finality
+			for i := 0; i < tt.down; i++ {
+				s.up(i)
+			}
+			s.run(10)
+			if s.report.Finalized != s.report.Blocks-2 {
+				t.Fatalf("down %d: finality not resumed, finalized %d of %d", tt.down, s.report.Finalized, s.report.Blocks)
+			}
+		}
+	}
+}
+
+func TestSimulateSlashingThresholds(t *testing.T) {
+	s := newSimulator(t, simConfig(new(uint64), nil, nil), 3, 3)
+	s.down(0)
+	s.run(3*simMisdemeanorThreshold + 3)
+
+	if vals := s.report.slashedOver(simMisdemeanorThreshold); len(vals) != 1 || vals[0] != s.pool[0].address {
+		t.Fatalf("misdemeanor mismatch: have %v, want %v", vals, s.pool[0].address)
+	}
+	if vals := s.report.slashedOver(simFelonyThreshold); len(vals) != 0 {
+		t.Fatalf("unexpected felonies: %v", vals)
+	}
+}
+
+func TestSimulateValidatorSetChange(t *testing.T) {
+	s := newSimulator(t, simConfig(new(uint64), nil, nil), 7, 5)
+	// Validators 5 and 6 join and validator 0 leaves at the first epoch
+	s.at(defaultEpochLength-10, func(s *simulator) {
+		s.join(5)
+		s.join(6)
+		s.leave(0)
+	})
+	s.run(defaultEpochLength)
+	if len(s.snap.Validators) != 5 {
+		t.Fatalf("validator set switched before the epoch: %d", len(s.snap.Validators))
+	}
+	// The new set takes effect once the epoch block is out of the miner history
+	switchBlock := defaultEpochLength + s.snap.minerHistoryCheckLen()
+	s.run(switchBlock - s.snap.Number)
+	if len(s.snap.Validators) != 6 {
+		t.Fatalf("validator set size mismatch: have %d, want %d", len(s.snap.Validators), 6)
+	}
+	if _, ok := s.snap.Validators[s.pool[0].address]; ok {
+		t.Fatalf("left validator still in the set")
+	}
+	for i, addr := range s.snap.validators() {
+		if s.snap.Validators[addr].Index != i+1 || s.snap.Validators[addr].VoteAddress != s.byAddress[addr].vote {
+			t.Fatalf("validator %x info mismatch: %+v", addr, s.snap.Validators[addr])
+		}
+	}
+	// The left validator can't seal anymore, the new ones do
+	sealers := make(map[common.Address]bool)
+	s.run(2 * 6)
+	for _, header := range s.chain.canon[switchBlock+1:] {
+		sealers[header.Coinbase] = true
+	}
+	if sealers[s.pool[0].address] || !sealers[s.pool[5].address] || !sealers[s.pool[6].address] {
+		t.Fatalf("sealers mismatch after the switch: %v", sealers)
+	}
+	if s.report.OutOfTurn != 0 || s.report.Finalized != s.report.Blocks-2 {
+		t.Fatalf("unhealthy switch: %s", s.report.String())
+	}
+}
+
+func TestSimulateTurnLength(t *testing.T) {
+	s := newSimulator(t, simConfig(new(uint64), nil, nil), 5, 5)
+	s.at(defaultEpochLength, func(s *simulator) { s.setTurnLength(4) })
+	s.run(defaultEpochLength + s.snap.minerHistoryCheckLen())
+	if s.snap.TurnLength != 4 {
+		t.Fatalf("turn length mismatch: have %d, want %d", s.snap.TurnLength, 4)
+	}
+	start := s.snap.Number
+	s.run(5 * 4 * 2)
+	t.Log(s.report.String())
+
+	// Every validator seals turnLength blocks in a row
+	for number := start + 2; number <= s.snap.Number; number++ {
+		if number/4 != (number-1)/4 {
+			continue
+		}
+		if prev, header := s.chain.canon[number-1], s.chain.canon[number]; prev.Coinbase != header.Coinbase {
+			t.Fatalf("turn broken at block %d: %x then %x", number, prev.Coinbase, header.Coinbase)
+		}
+	}
+	if s.report.OutOfTurn != 0 || len(s.report.Slashes) != 0 {
+		t.Fatalf("unhealthy turn length switch: %s", s.report.String())
+	}
+}
+
+func TestSimulateForkActivations(t *testing.T) {
+	var (
+		lorentz = uint64(300)  // Block 100 at a 3s interval
+		maxwell = uint64(1200) // Block 700 at a 1.5s interval
+	)
+	s := newSimulator(t, simConfig(new(uint64), &lorentz, &maxwell), 21, 21)
+	s.run(99)
+	if s.snap.BlockInterval != defaultBlockInterval || s.snap.EpochLength != defaultEpochLength {
+		t.Fatalf("fork activated early: interval %d, epoch %d", s.snap.BlockInterval, s.snap.EpochLength)
+	}
+	s.run(1)
+	if s.snap.BlockInterval != lorentzBlockInterval {
+		t.Fatalf("block interval mismatch at Lorentz: have %d, want %d", s.snap.BlockInterval, lorentzBlockInterval)
+	}
+	s.run(lorentzEpochLength - 1 - s.snap.Number)
+	if s.snap.EpochLength != lorentzEpochLength {
+		t.Fatalf("epoch length mismatch at Lorentz: have %d, want %d", s.snap.EpochLength, lorentzEpochLength)
+	}
+	s.run(700 - s.snap.Number)
+	if s.snap.BlockInterval != maxwellBlockInterval {
+		t.Fatalf("block interval mismatch at Maxwell: have %d, want %d", s.snap.BlockInterval, maxwellBlockInterval)
+	}
+	s.run(maxwellEpochLength + lorentzEpochLength - s.snap.Number)
+	t.Log(s.report.String())
+
+	if s.report.EpochLength != maxwellEpochLength {
+		t.Fatalf("epoch length mismatch at Maxwell: have %d, want %d", s.report.EpochLength, maxwellEpochLength)
+	}
+	if s.report.OutOfTurn != 0 || s.report.Finalized != s.report.Blocks-2 {
+		t.Fatalf("unhealthy fork activations: %s", s.report.String())
+	}
+	// The block times follow the interval of each fork
+	if header := s.chain.canon[700]; header.MilliTimestamp() != maxwell*1000 {
+		t.Fatalf("Maxwell block time mismatch: have %d, want %d", header.MilliTimestamp(), maxwell*1000)
+	}
+}
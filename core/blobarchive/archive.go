@@ -0,0 +1,249 @@
+// Package blobarchive keeps the blob sidecars pruned from the ancient store in
+// an external object store.
+package blobarchive
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/metrics"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+const (
+	// archiveQueue is the number of blocks whose sidecars can wait for their
+	// upload, the freezer retries queuing the others later.
+	archiveQueue = 64
+
+	// archiveAttempts is the number of uploads of the sidecars of a block before
+	// they are set aside, to be retried after archiveRetryLater.
+	archiveAttempts = 5
+)
+
+var (
+	archivedBlocksMeter = metrics.NewRegisteredMeter("blobarchive/archived/blocks", nil)
+	failedBlocksMeter   = metrics.NewRegisteredMeter("blobarchive/failed/blocks", nil)
+	fetchedBlocksMeter  = metrics.NewRegisteredMeter("blobarchive/fetched/blocks", nil)
+)
+
+var (
+	archiveRetryDelay = time.Second // Delay before the second upload, doubled at each attempt
+	archiveRetryLater = time.Minute // Delay before retrying the uploads which failed every attempt
+
+	errArchiveBusy = errors.New("blob archive queue is full")
+)
+
+// archiveTask is the sidecars of a block waiting for their upload.
+type archiveTask struct {
+	number   uint64
+	hash     common.Hash
+	sidecars []byte
+	txs      []common.Hash
+}
+
+// Archive stores the blob sidecars of the blocks in a backend, under the block
+// hash, and indexes them by transaction hash:
+//
+//	blocks/<block hash> = rlp(sidecars)
+//	txs/<tx hash>       = block hash
+//
+// The block objects are written before the transaction ones, a transaction is
+// only indexed once the sidecars of its block are retrievable.
+//
+// The sidecars are uploaded in the background, so a slow backend never stalls
+// the freezer. The failed uploads are retried a few times, then set aside and
+// retried later, the freezer keeps their blocks meanwhile.
+type Archive struct {
+	backend Backend
+
+	queue   chan *archiveTask
+	pending map[uint64]*archiveTask // Queued, uploading or failed blocks
+	failed  []*archiveTask          // Blocks set aside after failing every attempt
+	lock    sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns an archive storing the blob sidecars in the backend.
+func New(backend Backend) *Archive {
+	a := &Archive{
+		backend: backend,
+		queue:   make(chan *archiveTask, archiveQueue),
+		pending: make(map[uint64]*archiveTask),
+		quit:    make(chan struct{}),
+	}
+	a.wg.Add(1)
+	go a.loop()
+	return a
+}
+
+// Close stops the uploads, the blocks not archived yet are queued again by the
+// freezer after a restart.
+func (a *Archive) Close() {
+	close(a.quit)
+	a.wg.Wait()
+}
+
+func blockKey(hash common.Hash) string {
+	return "blocks/" + hash.Hex()
+}
+
+func txKey(hash common.Hash) string {
+	return "txs/" + hash.Hex()
+}
+
+// ArchiveBlobSidecars implements ethdb.BlobArchiver, queuing the RLP-encoded
+// blob sidecars of a block for their upload.
+func (a *Archive) ArchiveBlobSidecars(number uint64, hash common.Hash, sidecars []byte) error {
+	var decoded types.BlobSidecars
+	if err := rlp.DecodeBytes(sidecars, &decoded); err != nil {
+		return fmt.Errorf("invalid blob sidecars of block %d: %w", number, err)
+	}
+	task := &archiveTask{number: number, hash: hash, sidecars: sidecars}
+	for _, sidecar := range decoded {
+		task.txs = append(task.txs, sidecar.TxHash)
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, ok := a.pending[number]; ok {
+		return nil
+	}
+	select {
+	case a.queue <- task:
+		a.pending[number] = task
+		return nil
+	default:
+		return errArchiveBusy
+	}
+}
+
+// ArchivedBlobs implements ethdb.BlobArchiver, returning the number of the first
+// queued block whose sidecars aren't archived yet.
+func (a *Archive) ArchivedBlobs() uint64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	first := uint64(math.MaxUint64)
+	for number := range a.pending {
+		first = min(first, number)
+	}
+	return first
+}
+
+// loop uploads the queued sidecars, and periodically the ones set aside.
+func (a *Archive) loop() {
+	defer a.wg.Done()
+
+	retry := time.NewTicker(archiveRetryLater)
+	defer retry.Stop()
+
+	for {
+		select {
+		case task := <-a.queue:
+			a.upload(task)
+
+		case <-retry.C:
+			a.lock.Lock()
+			failed := a.failed
+			a.failed = nil
+			a.lock.Unlock()
+
+			for _, task := range failed {
+				a.upload(task)
+			}
+
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// upload archives the sidecars of the block, trying a few times before setting
+// them aside.
+func (a *Archive) upload(task *archiveTask) {
+	delay := archiveRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := a.archive(task)
+		if err == nil {
+			a.lock.Lock()
+			delete(a.pending, task.number)
+			a.lock.Unlock()
+			return
+		}
+		if attempt == archiveAttempts {
+			log.Warn("Failed to archive blob sidecars, retrying later", "number", task.number, "hash", task.hash, "err", err)
+			failedBlocksMeter.Mark(1)
+
+			a.lock.Lock()
+			a.failed = append(a.failed, task)
+			a.lock.Unlock()
+			return
+		}
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// archive stores the sidecars of the block and indexes its transactions.
+func (a *Archive) archive(task *archiveTask) error {
+	if err := a.backend.Put(blockKey(task.hash), task.sidecars); err != nil {
+		return err
+	}
+	for _, tx := range task.txs {
+		if err := a.backend.Put(txKey(tx), task.hash.Bytes()); err != nil {
+			return err
+		}
+	}
+	archivedBlocksMeter.Mark(1)
+	log.Trace("Archived blob sidecars", "number", task.number, "hash", task.hash, "sidecars", len(task.txs))
+	return nil
+}
+
+// BlobSidecars returns the archived blob sidecars of the block, ErrNotFound is
+// returned if they weren't archived.
+func (a *Archive) BlobSidecars(hash common.Hash) (types.BlobSidecars, error) {
+	data, err := a.backend.Get(blockKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	var sidecars types.BlobSidecars
+	if err := rlp.DecodeBytes(data, &sidecars); err != nil {
+		return nil, fmt.Errorf("invalid archived blob sidecars of block %x: %w", hash, err)
+	}
+	fetchedBlocksMeter.Mark(1)
+	return sidecars, nil
+}
+
+// BlobSidecarByTxHash returns the archived blob sidecar of the transaction,
+// ErrNotFound is returned if it wasn't archived.
+func (a *Archive) BlobSidecarByTxHash(hash common.Hash) (*types.BlobSidecar, error) {
+	data, err := a.backend.Get(txKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != common.HashLength {
+		return nil, fmt.Errorf("invalid archived block hash of transaction %x", hash)
+	}
+	sidecars, err := a.BlobSidecars(common.BytesToHash(data))
+	if err != nil {
+		return nil, err
+	}
+	for _, sidecar := range sidecars {
+		if sidecar.TxHash == hash {
+			return sidecar, nil
+		}
+	}
+	return nil, errors.New("transaction missing from the archived blob sidecars")
+}
@@ -0,0 +1,243 @@
+package blobarchive
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto/kzg4844"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+// s3StandIn is a minimal S3-compatible object store, serving the objects of a
+// single bucket from memory.
+type s3StandIn struct {
+	bucket    string
+	accessKey string
+	objects   map[string][]byte
+	lock      sync.Mutex
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+s.bucket+"/")
+	if !ok {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	if s.accessKey != "" {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/") || !strings.Contains(auth, "Signature=") ||
+			r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+			http.Error(w, "AccessDenied", http.StatusForbidden)
+			return
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[key] = data
+	case http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestBackends(t *testing.T) {
+	stand := &s3StandIn{bucket: "blobs", accessKey: "key", objects: make(map[string][]byte)}
+	server := httptest.NewServer(stand)
+	defer server.Close()
+
+	dir, err := NewBackend(t.TempDir(), "", "", "")
+	if err != nil {
+		t.Fatalf("failed to create dir backend: %v", err)
+	}
+	s3, err := NewBackend(server.URL+"/blobs", "", "key", "secret")
+	if err != nil {
+		t.Fatalf("failed to create s3 backend: %v", err)
+	}
+	denied, err := NewBackend(server.URL+"/blobs", "", "", "")
+	if err != nil {
+		t.Fatalf("failed to create s3 backend: %v", err)
+	}
+	for name, backend := range map[string]Backend{"dir": dir, "s3": s3} {
+		if _, err := backend.Get("blocks/a"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: missing object error mismatch: have %v, want %v", name, err, ErrNotFound)
+		}
+		if err := backend.Put("blocks/a", []byte{1}); err != nil {
+			t.Fatalf("%s: failed to put object: %v", name, err)
+		}
+		if err := backend.Put("blocks/a", []byte{2}); err != nil {
+			t.Fatalf("%s: failed to replace object: %v", name, err)
+		}
+		if data, err := backend.Get("blocks/a"); err != nil || string(data) != "\x02" {
+			t.Errorf("%s: object mismatch: have %x/%v, want 02", name, data, err)
+		}
+	}
+	if err := denied.Put("blocks/b", []byte{1}); err == nil {
+		t.Error("unsigned request accepted")
+	}
+	if _, err := NewBackend(server.URL, "", "", ""); err == nil {
+		t.Error("bucketless URL accepted")
+	}
+}
+
+func TestArchive(t *testing.T) {
+	backend, err := NewDirBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	var (
+		archive  = New(backend)
+		hash     = common.Hash{0x01}
+		sidecars = types.BlobSidecars{
+			{
+				BlobTxSidecar: types.BlobTxSidecar{
+					Blobs:       []kzg4844.Blob{{0x01}},
+					Commitments: []kzg4844.Commitment{{0x01}},
+					Proofs:      []kzg4844.Proof{{0x01}},
+				},
+				BlockNumber: big.NewInt(1),
+				BlockHash:   hash,
+				TxIndex:     0,
+				TxHash:      common.Hash{0xaa},
+			},
+			{
+				BlobTxSidecar: types.BlobTxSidecar{
+					Blobs:       []kzg4844.Blob{{0x02}},
+					Commitments: []kzg4844.Commitment{{0x02}},
+					Proofs:      []kzg4844.Proof{{0x02}},
+				},
+				BlockNumber: big.NewInt(1),
+				BlockHash:   hash,
+				TxIndex:     2,
+				TxHash:      common.Hash{0xbb},
+			},
+		}
+	)
+	defer archive.Close()
+
+	if _, err := archive.BlobSidecars(hash); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing block error mismatch: have %v, want %v", err, ErrNotFound)
+	}
+	data, _ := rlp.EncodeToBytes(sidecars)
+	if err := archive.ArchiveBlobSidecars(1, hash, data); err != nil {
+		t.Fatalf("failed to archive sidecars: %v", err)
+	}
+	if err := archive.ArchiveBlobSidecars(2, common.Hash{0x02}, []byte{0x01}); err == nil {
+		t.Fatal("invalid sidecars archived")
+	}
+	waitArchived(t, archive)
+	have, err := archive.BlobSidecars(hash)
+	if err != nil {
+		t.Fatalf("failed to retrieve sidecars: %v", err)
+	}
+	if len(have) != len(sidecars) {
+		t.Fatalf("sidecar count mismatch: have %d, want %d", len(have), len(sidecars))
+	}
+	for i, sidecar := range sidecars {
+		if have[i].TxHash != sidecar.TxHash || have[i].TxIndex != sidecar.TxIndex || have[i].Blobs[0] != sidecar.Blobs[0] {
+			t.Errorf("sidecar %d mismatch", i)
+		}
+		byTx, err := archive.BlobSidecarByTxHash(sidecar.TxHash)
+		if err != nil {
+			t.Fatalf("failed to retrieve sidecar %d by transaction: %v", i, err)
+		}
+		if byTx.TxIndex != sidecar.TxIndex || byTx.BlockHash != hash {
+			t.Errorf("sidecar %d by transaction mismatch", i)
+		}
+	}
+	if _, err := archive.BlobSidecarByTxHash(common.Hash{0xcc}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing transaction error mismatch: have %v, want %v", err, ErrNotFound)
+	}
+}
+
+// waitArchived waits for the queued sidecars to be uploaded.
+func waitArchived(t *testing.T, archive *Archive) {
+	t.Helper()
+	for start := time.Now(); archive.ArchivedBlobs() != math.MaxUint64; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("sidecars not archived, first pending block %d", archive.ArchivedBlobs())
+		}
+	}
+}
+
+// flakyBackend is a backend failing the given number of writes.
+type flakyBackend struct {
+	Backend
+	failures atomic.Int32
+}
+
+func (b *flakyBackend) Put(key string, value []byte) error {
+	if b.failures.Add(-1) >= 0 {
+		return errors.New("unavailable")
+	}
+	return b.Backend.Put(key, value)
+}
+
+func TestArchiveRetry(t *testing.T) {
+	defer func(delay, later time.Duration) {
+		archiveRetryDelay, archiveRetryLater = delay, later
+	}(archiveRetryDelay, archiveRetryLater)
+	archiveRetryDelay, archiveRetryLater = time.Millisecond, 50*time.Millisecond
+
+	dir, err := NewDirBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	// Fail every attempt of the first round, the upload succeeds later
+	backend := &flakyBackend{Backend: dir}
+	backend.failures.Store(archiveAttempts)
+
+	archive := New(backend)
+	defer archive.Close()
+
+	data, _ := rlp.EncodeToBytes(types.BlobSidecars{{TxHash: common.Hash{0xaa}, BlockNumber: big.NewInt(5)}})
+	if err := archive.ArchiveBlobSidecars(5, common.Hash{0x05}, data); err != nil {
+		t.Fatalf("failed to queue sidecars: %v", err)
+	}
+	if first := archive.ArchivedBlobs(); first != 5 {
+		t.Fatalf("first pending block mismatch: have %d, want 5", first)
+	}
+	waitArchived(t, archive)
+	if _, err := archive.BlobSidecarByTxHash(common.Hash{0xaa}); err != nil {
+		t.Fatalf("failed to retrieve retried sidecar: %v", err)
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "envkey")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+	if access, secret, err := LoadCredentials(""); err != nil || access != "envkey" || secret != "envsecret" {
+		t.Errorf("environment credentials mismatch: have %s/%s/%v", access, secret, err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials")
+	os.WriteFile(path, []byte("filekey\nfilesecret\n"), 0600)
+	if access, secret, err := LoadCredentials(path); err != nil || access != "filekey" || secret != "filesecret" {
+		t.Errorf("file credentials mismatch: have %s/%s/%v", access, secret, err)
+	}
+	os.WriteFile(path, []byte("filekey\n"), 0600)
+	if _, _, err := LoadCredentials(path); err == nil {
+		t.Error("incomplete credentials accepted")
+	}
+}
@@ -0,0 +1,224 @@
+package blobarchive
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by the backends for keys without object.
+var ErrNotFound = errors.New("not found")
+
+// Backend is an object store the archived blob sidecars are written to. The
+// keys are slash separated paths.
+type Backend interface {
+	// Put stores the object under the key, replacing any previous one.
+	Put(key string, value []byte) error
+
+	// Get retrieves the object stored under the key, ErrNotFound is returned if
+	// there is none.
+	Get(key string) ([]byte, error)
+}
+
+// NewBackend returns the backend of the given location, an S3-compatible bucket
+// if it is an http(s) URL, a local directory otherwise.
+func NewBackend(location, region, accessKey, secretKey string) (Backend, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return NewS3Backend(location, region, accessKey, secretKey)
+	}
+	return NewDirBackend(location)
+}
+
+// LoadCredentials returns the access key and the secret key of an S3-compatible
+// bucket, read from the first two lines of the given file, or from the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables if no file
+// is given. Empty keys make the requests anonymous.
+func LoadCredentials(path string) (accessKey string, secretKey string, err error) {
+	if path == "" {
+		return os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(string(data), "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[0]) == "" || strings.TrimSpace(lines[1]) == "" {
+		return "", "", fmt.Errorf("missing access key or secret key in %s", path)
+	}
+	return strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1]), nil
+}
+
+// DirBackend is a backend storing the objects as files of a local directory.
+type DirBackend struct {
+	dir string
+}
+
+// NewDirBackend returns a backend storing the objects in dir, which is created
+// if it doesn't exist.
+func NewDirBackend(dir string) (*DirBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DirBackend{dir: dir}, nil
+}
+
+// Put implements Backend, the object is written to a temporary file renamed in
+// place, so a crash never leaves a partial object behind.
+func (b *DirBackend) Put(key string, value []byte) error {
+	path := filepath.Join(b.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, value, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get implements Backend.
+func (b *DirBackend) Get(key string) ([]byte, error) {
+	value, err := os.ReadFile(filepath.Join(b.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// S3Backend is a backend storing the objects in a bucket of an S3-compatible
+// object store, addressed path-style. The requests are signed with AWS
+// signature version 4 if credentials are given, anonymous otherwise.
+type S3Backend struct {
+	endpoint  *url.URL // Bucket URL, with an optional key prefix
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3Backend returns a backend storing the objects in the bucket at the given
+// URL, e.g. https://s3.us-east-1.amazonaws.com/bucket/prefix.
+func NewS3Backend(endpoint, region, accessKey, secretKey string) (*S3Backend, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("missing bucket in %s", endpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Backend{
+		endpoint:  u,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// Put implements Backend.
+func (b *S3Backend) Put(key string, value []byte) error {
+	res, err := b.do(http.MethodPut, key, value)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return b.error(res)
+	}
+	return nil
+}
+
+// Get implements Backend.
+func (b *S3Backend) Get(key string) ([]byte, error) {
+	res, err := b.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(res.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, b.error(res)
+	}
+}
+
+// do sends a request on the object stored under the key.
+func (b *S3Backend) do(method, key string, body []byte) (*http.Response, error) {
+	u := *b.endpoint
+	u.Path += "/" + key
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if b.accessKey != "" {
+		b.sign(req, body, time.Now().UTC())
+	}
+	return b.client.Do(req)
+}
+
+// error returns the error reported by a failed request.
+func (b *S3Backend) error(res *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return fmt.Errorf("%s %s: %s %s", res.Request.Method, res.Request.URL, res.Status, bytes.TrimSpace(msg))
+}
+
+// sign adds the AWS signature version 4 headers to the request. The keys are
+// hex and slash characters only, the path needs no further escaping.
+func (b *S3Backend) sign(req *http.Request, body []byte, now time.Time) {
+	var (
+		date        = now.Format("20060102")
+		timestamp   = now.Format("20060102T150405Z")
+		payloadHash = sha256.Sum256(body)
+		payload     = hex.EncodeToString(payloadHash[:])
+		scope       = date + "/" + b.region + "/s3/aws4_request"
+		signed      = "host;x-amz-content-sha256;x-amz-date"
+	)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+	req.Header.Set("X-Amz-Date", timestamp)
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payload,
+		"x-amz-date:" + timestamp,
+		"",
+		signed,
+		payload,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + timestamp + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + b.secretKey)
+	for _, part := range []string{date, b.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", b.accessKey, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+)
+
+// TxProfile is the execution time of a transaction during a block import.
+type TxProfile struct {
+	Hash     common.Hash
+	GasUsed  uint64
+	Duration time.Duration
+}
+
+// BlockProfile is the time spent in each stage of a block import. The state
+// read, root and commit breakdowns are only measured if the expensive metrics
+// are enabled.
+type BlockProfile struct {
+	Number  uint64
+	Hash    common.Hash
+	GasUsed uint64
+	Time    time.Time // Time the import started at
+
+	Verify           time.Duration // Waiting for the header verification, and validating the body
+	DataAvailability time.Duration // Checking the blob sidecars availability, for the whole batch
+	Prefetch         time.Duration // Prefetching the state, concurrently with the execution
+	Execution        time.Duration // Executing the transactions, state reads included
+	StateRead        time.Duration // Reading the accounts and storage slots
+	Validation       time.Duration // Validating the state, state root included
+	StateRoot        time.Duration // Updating and hashing the tries
+	Write            time.Duration // Writing the block and committing the state
+	TrieCommit       time.Duration // Committing the account and storage tries
+	SnapshotCommit   time.Duration // Writing the snapshot layer
+	TrieDBCommit     time.Duration // Writing the trie database layer
+	Total            time.Duration
+
+	Transactions []TxProfile // System transactions excluded
+}
+
+// blockProfiler retains the profiles of the recently imported blocks in a ring
+// buffer.
+type blockProfiler struct {
+	profiles []*BlockProfile
+	next     int
+	lock     sync.RWMutex
+}
+
+func newBlockProfiler(size int) *blockProfiler {
+	return &blockProfiler{
+		profiles: make([]*BlockProfile, size),
+	}
+}
+
+// add retains the profile, overwriting the oldest one if the buffer is full.
+func (p *blockProfiler) add(profile *BlockProfile) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.profiles[p.next] = profile
+	p.next = (p.next + 1) % len(p.profiles)
+}
+
+// setPrefetch sets the prefetching time of the profile, which is measured
+// concurrently with the import.
+func (p *blockProfiler) setPrefetch(profile *BlockProfile, elapsed time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	profile.Prefetch = elapsed
+}
+
+// get returns a copy of the latest profile of a block with the given number,
+// preferring the one with the given hash.
+func (p *blockProfiler) get(number uint64, hash common.Hash) *BlockProfile {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	var found *BlockProfile
+	for i := 1; i <= len(p.profiles); i++ {
+		profile := p.profiles[(p.next-i+len(p.profiles))%len(p.profiles)]
+		if profile == nil {
+			break
+		}
+		if profile.Number != number {
+			continue
+		}
+		if profile.Hash == hash {
+			found = profile
+			break
+		}
+		if found == nil {
+			found = profile
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	cpy := *found
+	return &cpy
+}
+
+// EnableBlockProfiler retains the import profiles of the given number of recent
+// blocks.
+func EnableBlockProfiler(size int) BlockChainOption {
+	return func(bc *BlockChain) (*BlockChain, error) {
+		if size > 0 {
+			bc.blockProfiler = newBlockProfiler(size)
+		}
+		return bc, nil
+	}
+}
+
+// GetBlockProfile returns the import profile of the canonical block with the
+// given number, or of the latest imported one with that number if the canonical
+// one wasn't profiled. Nil is returned if the profiler is disabled or the block
+// was not profiled.
+func (bc *BlockChain) GetBlockProfile(number uint64) *BlockProfile {
+	if bc.blockProfiler == nil {
+		return nil
+	}
+	return bc.blockProfiler.get(number, bc.GetCanonicalHash(number))
+}
+
+// BlockProfilerEnabled reports whether the import profiles are retained.
+func (bc *BlockChain) BlockProfilerEnabled() bool {
+	return bc.blockProfiler != nil
+}
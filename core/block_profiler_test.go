@@ -0,0 +1,70 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/consensus/ethash"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/core/vm"
+	"github.com/Ezkerrox/bsc/params"
+)
+
+func TestBlockProfiler(t *testing.T) {
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		gspec  = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{testAddr: {Balance: big.NewInt(100000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, gen *BlockGen) {
+		for j := 0; j < i; j++ {
+			tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+				Nonce:    gen.TxNonce(testAddr),
+				To:       &common.Address{0x01},
+				Gas:      params.TxGas,
+				GasPrice: gen.header.BaseFee,
+			})
+			gen.AddTx(tx)
+		}
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, EnableBlockProfiler(2))
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Only the profiles of the two last blocks are retained
+	for _, block := range blocks[:2] {
+		if profile := chain.GetBlockProfile(block.NumberU64()); profile != nil {
+			t.Errorf("block #%d: unexpected profile", block.NumberU64())
+		}
+	}
+	for _, block := range blocks[2:] {
+		profile := chain.GetBlockProfile(block.NumberU64())
+		if profile == nil {
+			t.Fatalf("block #%d: missing profile", block.NumberU64())
+		}
+		if profile.Hash != block.Hash() || profile.GasUsed != block.GasUsed() {
+			t.Errorf("block #%d: profile mismatch: have %x/%d, want %x/%d", block.NumberU64(), profile.Hash, profile.GasUsed, block.Hash(), block.GasUsed())
+		}
+		if profile.Total < profile.Execution+profile.Validation+profile.Write {
+			t.Errorf("block #%d: total %v shorter than the stages", block.NumberU64(), profile.Total)
+		}
+		if len(profile.Transactions) != len(block.Transactions()) {
+			t.Fatalf("block #%d: transaction count mismatch: have %d, want %d", block.NumberU64(), len(profile.Transactions), len(block.Transactions()))
+		}
+		for i, tx := range profile.Transactions {
+			if tx.Hash != block.Transactions()[i].Hash() || tx.GasUsed != params.TxGas {
+				t.Errorf("block #%d: transaction %d mismatch: have %x/%d", block.NumberU64(), i, tx.Hash, tx.GasUsed)
+			}
+		}
+	}
+}
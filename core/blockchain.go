@@ -35,6 +35,7 @@ import (
 	"github.com/Ezkerrox/bsc/common/prque"
 	"github.com/Ezkerrox/bsc/consensus"
 	"github.com/Ezkerrox/bsc/consensus/misc/eip4844"
+	"github.com/Ezkerrox/bsc/core/blobarchive"
 	"github.com/Ezkerrox/bsc/core/monitor"
 	"github.com/Ezkerrox/bsc/core/rawdb"
 	"github.com/Ezkerrox/bsc/core/state"
@@ -250,9 +251,64 @@ type BlockStats struct {
 
 	SendVoteTime         atomic.Int64
 	FirstRecvVoteTime    atomic.Int64
+	FirstRecvVoteFrom    atomic.Value
 	RecvMajorityVoteTime atomic.Int64
 }
 
+// BlockTimeline is the propagation timeline of a block as seen by the local node.
+// The times are unix milliseconds, zero for the stages the block didn't go
+// through, and the peers are the remote addresses the block or vote came from.
+type BlockTimeline struct {
+	Hash      common.Hash    `json:"hash"`
+	Number    uint64         `json:"number"`
+	Coinbase  common.Address `json:"coinbase"`
+	BlockTime int64          `json:"blockTime"`
+
+	RecvNewBlockHashTime int64  `json:"recvNewBlockHashTime"`
+	RecvNewBlockHashFrom string `json:"recvNewBlockHashFrom,omitempty"`
+	RecvNewBlockTime     int64  `json:"recvNewBlockTime"`
+	RecvNewBlockFrom     string `json:"recvNewBlockFrom,omitempty"`
+	StartMiningTime      int64  `json:"startMiningTime"`
+	SendBlockTime        int64  `json:"sendBlockTime"`
+	StartImportBlockTime int64  `json:"startImportBlockTime"`
+	ImportedBlockTime    int64  `json:"importedBlockTime"`
+
+	SendVoteTime         int64  `json:"sendVoteTime"`
+	FirstRecvVoteTime    int64  `json:"firstRecvVoteTime"`
+	FirstRecvVoteFrom    string `json:"firstRecvVoteFrom,omitempty"`
+	RecvMajorityVoteTime int64  `json:"recvMajorityVoteTime"`
+}
+
+// Timeline snapshots the stats of the block, the header being nil if the block
+// is only announced yet.
+func (s *BlockStats) Timeline(hash common.Hash, header *types.Header) *BlockTimeline {
+	from := func(v *atomic.Value) string {
+		addr, _ := v.Load().(string)
+		return addr
+	}
+	timeline := &BlockTimeline{
+		Hash:                 hash,
+		RecvNewBlockHashTime: s.RecvNewBlockHashTime.Load(),
+		RecvNewBlockHashFrom: from(&s.RecvNewBlockHashFrom),
+		RecvNewBlockTime:     s.RecvNewBlockTime.Load(),
+		RecvNewBlockFrom:     from(&s.RecvNewBlockFrom),
+		StartMiningTime:      s.StartMiningTime.Load(),
+		SendBlockTime:        s.SendBlockTime.Load(),
+		StartImportBlockTime: s.StartImportBlockTime.Load(),
+		ImportedBlockTime:    s.ImportedBlockTime.Load(),
+		SendVoteTime:         s.SendVoteTime.Load(),
+		FirstRecvVoteTime:    s.FirstRecvVoteTime.Load(),
+		FirstRecvVoteFrom:    from(&s.FirstRecvVoteFrom),
+		RecvMajorityVoteTime: s.RecvMajorityVoteTime.Load(),
+	}
+	if header != nil {
+		timeline.Number = header.Number.Uint64()
+		timeline.Coinbase = header.Coinbase
+		timeline.BlockTime = int64(header.MilliTimestamp())
+	}
+	return timeline
+}
+
 // BlockChain represents the canonical chain given a database with a genesis
 // block. The Blockchain manages chain imports, reverts, chain reorganisations.
 //
@@ -291,6 +347,7 @@ type BlockChain struct {
 	blockProcFeed            event.Feed
 	finalizedHeaderFeed      event.Feed
 	highestVerifiedBlockFeed event.Feed
+	blockTimelineFeed        event.Feed
 	scope                    event.SubscriptionScope
 	genesisBlock             *types.Block
 
@@ -314,6 +371,7 @@ type BlockChain struct {
 	txLookupLock  sync.RWMutex
 	txLookupCache *lru.Cache[common.Hash, txLookup]
 	sidecarsCache *lru.Cache[common.Hash, types.BlobSidecars]
+	blobArchive   *blobarchive.Archive // Archive of the pruned blob sidecars, nil if disabled
 
 	// future blocks are blocks added for later processing
 	futureBlocks *lru.Cache[common.Hash, *types.Block]
@@ -340,6 +398,8 @@ type BlockChain struct {
 
 	// monitor
 	doubleSignMonitor *monitor.DoubleSignMonitor
+	blockProfiler     *blockProfiler  // Import profiles of the recent blocks, nil if disabled
+	slowTxDetector    *slowTxDetector // Detector reporting the slow transactions, nil if disabled
 	logger            *tracing.Hooks
 }
 
@@ -1988,6 +2048,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 		}
 		if sealedBlockSender != nil {
 			bc.chainHeadFeed.Send(ChainHeadEvent{Header: block.Header()})
+			bc.sendBlockTimeline(block.Header())
 			if finalizedHeader != nil {
 				bc.finalizedHeaderFeed.Send(FinalizedHeaderEvent{finalizedHeader})
 			}
@@ -2081,6 +2142,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 	defer func() {
 		if lastCanon != nil && bc.CurrentBlock().Hash() == lastCanon.Hash() {
 			bc.chainHeadFeed.Send(ChainHeadEvent{Header: lastCanon.Header()})
+			bc.sendBlockTimeline(lastCanon.Header())
 			if posa, ok := bc.Engine().(consensus.PoSA); ok {
 				if finalizedHeader := posa.GetFinalizedHeader(bc, lastCanon.Header()); finalizedHeader != nil {
 					bc.finalizedHeaderFeed.Send(FinalizedHeaderEvent{finalizedHeader})
@@ -2090,10 +2152,13 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 	}()
 
 	// check block data available first
+	var daTime time.Duration
 	if bc.chainConfig.Parlia != nil {
+		daStart := time.Now()
 		if index, err := CheckDataAvailableInBatch(bc, chain); err != nil {
 			return nil, index, err
 		}
+		daTime = time.Since(daStart)
 	}
 
 	// Start the parallel header verifier
@@ -2265,6 +2330,17 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 		}
 		bc.updateHighestVerifiedHeader(block.Header())
 
+		var profile *BlockProfile
+		if bc.blockProfiler != nil {
+			profile = &BlockProfile{
+				Number:           block.NumberU64(),
+				Hash:             block.Hash(),
+				Time:             start,
+				Verify:           it.verifyTime,
+				DataAvailability: daTime,
+			}
+		}
+
 		// If we are past Byzantium, enable prefetching to pull in trie node paths
 		// while processing transactions. Before Byzantium the prefetcher is mostly
 		// useless due to the intermediate root hashing after each transaction.
@@ -2290,7 +2366,15 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 			// Disable tracing for prefetcher executions.
 			vmCfg := bc.vmConfig
 			vmCfg.Tracer = nil
-			go bc.prefetcher.Prefetch(block.Transactions(), block.Header(), block.GasLimit(), throwaway, &vmCfg, interruptCh)
+			if profile != nil {
+				go func(profile *BlockProfile) {
+					pstart := time.Now()
+					bc.prefetcher.Prefetch(block.Transactions(), block.Header(), block.GasLimit(), throwaway, &vmCfg, interruptCh)
+					bc.blockProfiler.setPrefetch(profile, time.Since(pstart))
+				}(profile)
+			} else {
+				go bc.prefetcher.Prefetch(block.Transactions(), block.Header(), block.GasLimit(), throwaway, &vmCfg, interruptCh)
+			}
 
 			// 2.do trie prefetch for MPT trie node cache
 			// it is for the big state trie tree, prefetch based on transaction's From/To address.
@@ -2299,7 +2383,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 		}
 
 		// The traced section of block import.
-		res, err := bc.processBlock(block, statedb, start, setHead, interruptCh)
+		res, err := bc.processBlock(block, statedb, start, setHead, interruptCh, profile)
 		if err != nil {
 			return nil, it.index, err
 		}
@@ -2394,8 +2478,9 @@ type blockProcessingResult struct {
 }
 
 // processBlock executes and validates the given block. If there was no error
-// it writes the block and associated state to database.
-func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, start time.Time, setHead bool, interruptCh chan struct{}) (_ *blockProcessingResult, blockEndErr error) {
+// it writes the block and associated state to database, and retains the
+// timings of the stages in the profile if it's not nil.
+func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, start time.Time, setHead bool, interruptCh chan struct{}, profile *BlockProfile) (_ *blockProcessingResult, blockEndErr error) {
 	statedb.SetExpectedStateRoot(block.Root())
 
 	if bc.logger != nil && bc.logger.OnBlockStart != nil {
@@ -2510,6 +2595,30 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 	blockInsertTxSizeGauge.Update(int64(len(block.Transactions())))
 	blockInsertGasUsedGauge.Update(int64(block.GasUsed()))
 
+	if profile != nil {
+		profile.GasUsed = res.GasUsed
+		profile.Execution = ptime
+		profile.StateRead = statedb.AccountReads + statedb.StorageReads
+		profile.Validation = vtime
+		profile.StateRoot = triehash + trieUpdate
+		profile.Write = time.Since(wstart)
+		profile.TrieCommit = max(statedb.AccountCommits, statedb.StorageCommits)
+		profile.SnapshotCommit = statedb.SnapshotCommits
+		profile.TrieDBCommit = statedb.TrieDBCommits
+		profile.Total = time.Since(start)
+		profile.Transactions = make([]TxProfile, 0, len(res.TxTimes))
+		for i, elapsed := range res.TxTimes {
+			profile.Transactions = append(profile.Transactions, TxProfile{
+				Hash:     res.Receipts[i].TxHash,
+				GasUsed:  res.Receipts[i].GasUsed,
+				Duration: elapsed,
+			})
+		}
+		bc.blockProfiler.add(profile)
+	}
+	if bc.slowTxDetector != nil {
+		bc.slowTxDetector.check(bc, block, res)
+	}
 	return &blockProcessingResult{usedGas: res.GasUsed, procTime: proctime, status: status}, nil
 }
 
@@ -3219,10 +3328,24 @@ func EnableBlockValidator(chainConfig *params.ChainConfig, mode VerifyMode, peer
 }
 
 func EnableDoubleSignChecker(bc *BlockChain) (*BlockChain, error) {
-	bc.doubleSignMonitor = monitor.NewDoubleSignMonitor()
+	bc.doubleSignMonitor = monitor.NewDoubleSignMonitor(bc.db)
 	return bc, nil
 }
 
+// DoubleSignMonitor returns the double sign monitor of the chain, nil if it is not enabled.
+func (bc *BlockChain) DoubleSignMonitor() *monitor.DoubleSignMonitor {
+	return bc.doubleSignMonitor
+}
+
+// EnableBlobArchive makes the chain serve the blob sidecars pruned from the
+// database from the archive, through the explicit archive lookups only.
+func EnableBlobArchive(archive *blobarchive.Archive) BlockChainOption {
+	return func(bc *BlockChain) (*BlockChain, error) {
+		bc.blobArchive = archive
+		return bc, nil
+	}
+}
+
 func (bc *BlockChain) GetVerifyResult(blockNumber uint64, blockHash common.Hash, diffHash common.Hash) *VerifyResult {
 	var res VerifyResult
 	res.BlockNumber = blockNumber
@@ -3357,3 +3480,21 @@ func (bc *BlockChain) GetBlockStats(hash common.Hash) *BlockStats {
 	bc.blockStatsCache.Add(hash, n)
 	return n
 }
+
+// PeekBlockStats retrieves the stats of a block without tracking it, nil if the
+// block isn't tracked.
+func (bc *BlockChain) PeekBlockStats(hash common.Hash) *BlockStats {
+	stats, _ := bc.blockStatsCache.Peek(hash)
+	return stats
+}
+
+// sendBlockTimeline posts the timeline of the parent of the new head, whose
+// votes have been collected by the time its child is imported.
+func (bc *BlockChain) sendBlockTimeline(head *types.Header) {
+	if head.Number.Sign() == 0 {
+		return
+	}
+	if timeline := bc.GetBlockTimeline(head.ParentHash); timeline != nil {
+		bc.blockTimelineFeed.Send(BlockTimelineEvent{Timeline: timeline})
+	}
+}
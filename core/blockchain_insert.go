@@ -105,6 +105,8 @@ type insertIterator struct {
 
 	index     int       // Current offset of the iterator
 	validator Validator // Validator to run if verification succeeds
+
+	verifyTime time.Duration // Time spent verifying the current block
 }
 
 // newInsertIterator creates a new iterator based on the given blocks, which are
@@ -128,6 +130,9 @@ func (it *insertIterator) next() (*types.Block, error) {
 		return nil, nil
 	}
 	// Advance the iterator and wait for verification result if not yet done
+	start := time.Now()
+	defer func() { it.verifyTime = time.Since(start) }()
+
 	it.index++
 	if len(it.errors) <= it.index {
 		it.errors = append(it.errors, <-it.results)
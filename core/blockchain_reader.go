@@ -24,10 +24,12 @@ import (
 
 	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/consensus"
+	"github.com/Ezkerrox/bsc/core/blobarchive"
 	"github.com/Ezkerrox/bsc/core/rawdb"
 	"github.com/Ezkerrox/bsc/core/state"
 	"github.com/Ezkerrox/bsc/core/state/snapshot"
 	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/ethdb"
 	"github.com/Ezkerrox/bsc/event"
 	"github.com/Ezkerrox/bsc/params"
 	"github.com/Ezkerrox/bsc/rlp"
@@ -277,6 +279,44 @@ func (bc *BlockChain) GetSidecarsByHash(hash common.Hash) types.BlobSidecars {
 	return sidecars
 }
 
+// GetArchivedSidecars retrieves the sidecars of a block pruned from the database
+// from the blob archive. Only the blocks carrying blobs are looked up. It sends
+// a request to the archive, so it must not be reachable by the peers.
+func (bc *BlockChain) GetArchivedSidecars(hash common.Hash) types.BlobSidecars {
+	if bc.blobArchive == nil {
+		return nil
+	}
+	header := bc.GetHeaderByHash(hash)
+	if header == nil || header.BlobGasUsed == nil || *header.BlobGasUsed == 0 {
+		return nil
+	}
+	sidecars, err := bc.blobArchive.BlobSidecars(hash)
+	if err != nil {
+		if !errors.Is(err, blobarchive.ErrNotFound) {
+			log.Warn("Failed to retrieve archived blob sidecars", "number", header.Number, "hash", hash, "err", err)
+		}
+		return nil
+	}
+	return sidecars
+}
+
+// GetArchivedSidecarByTxHash retrieves the sidecar of a blob transaction from
+// the blob archive, regardless of the transaction being indexed. Nil is returned
+// if the archive is disabled or lacks the transaction.
+func (bc *BlockChain) GetArchivedSidecarByTxHash(hash common.Hash) *types.BlobSidecar {
+	if bc.blobArchive == nil {
+		return nil
+	}
+	sidecar, err := bc.blobArchive.BlobSidecarByTxHash(hash)
+	if err != nil {
+		if !errors.Is(err, blobarchive.ErrNotFound) {
+			log.Warn("Failed to retrieve archived blob sidecar", "hash", hash, "err", err)
+		}
+		return nil
+	}
+	return sidecar
+}
+
 // GetUnclesInChain retrieves all the uncles from a given block backwards until
 // a specific distance is reached.
 func (bc *BlockChain) GetUnclesInChain(block *types.Block, length int) []*types.Header {
@@ -432,6 +472,13 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return stateDb, err
 }
 
+// HistoricState returns the historical state of the given root, reconstructed
+// from the state histories of the path-based trie database. The live states are
+// not served, use StateAt instead.
+func (bc *BlockChain) HistoricState(root common.Hash) (*state.StateDB, error) {
+	return state.New(root, state.NewHistoricDatabase(bc.triedb.Disk(), bc.triedb))
+}
+
 // Config retrieves the chain's fork configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -481,6 +528,11 @@ func (bc *BlockChain) TxIndexProgress() (TxIndexProgress, error) {
 	return bc.txIndexer.txIndexProgress()
 }
 
+// DB retrieves the key-value database the chain is stored in.
+func (bc *BlockChain) DB() ethdb.Database {
+	return bc.db
+}
+
 // TrieDB retrieves the low level trie database used for data storage.
 func (bc *BlockChain) TrieDB() *triedb.Database {
 	return bc.triedb
@@ -532,6 +584,25 @@ func (bc *BlockChain) SubscribeFinalizedHeaderEvent(ch chan<- FinalizedHeaderEve
 	return bc.scope.Track(bc.finalizedHeaderFeed.Subscribe(ch))
 }
 
+// SubscribeBlockTimelineEvent registers a subscription of BlockTimelineEvent.
+func (bc *BlockChain) SubscribeBlockTimelineEvent(ch chan<- BlockTimelineEvent) event.Subscription {
+	return bc.scope.Track(bc.blockTimelineFeed.Subscribe(ch))
+}
+
+// GetBlockTimeline retrieves the propagation timeline of a recent block, nil if
+// the block is neither known nor tracked anymore.
+func (bc *BlockChain) GetBlockTimeline(hash common.Hash) *BlockTimeline {
+	header := bc.GetHeaderByHash(hash)
+	stats := bc.PeekBlockStats(hash)
+	if stats == nil {
+		if header == nil {
+			return nil
+		}
+		stats = new(BlockStats)
+	}
+	return stats.Timeline(hash, header)
+}
+
 // AncientTail retrieves the tail the ancients blocks
 func (bc *BlockChain) AncientTail() (uint64, error) {
 	tail, err := bc.db.BlockStore().Tail()
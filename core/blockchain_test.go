@@ -4484,3 +4484,43 @@ func TestEIP7702(t *testing.T) {
 		t.Fatalf("addr2 storage wrong: expected %d, got %d", fortyTwo, actual)
 	}
 }
+
+func TestBlockTimeline(t *testing.T) {
+	genDb, genesis, chain, err := newCanonical(ethash.NewFaker(), 0, true, rawdb.HashScheme)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	timelines := make(chan BlockTimelineEvent, 1)
+	sub := chain.SubscribeBlockTimelineEvent(timelines)
+	defer sub.Unsubscribe()
+
+	blocks := makeBlockChain(chain.chainConfig, chain.GetBlockByHash(genesis.ToBlock().Hash()), 2, ethash.NewFaker(), genDb, canonicalSeed)
+	chain.GetBlockStats(blocks[0].Hash()).RecvNewBlockFrom.Store("127.0.0.1:30311")
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// The timeline of the parent of the new head is posted
+	select {
+	case ev := <-timelines:
+		if ev.Timeline.Hash != blocks[0].Hash() || ev.Timeline.Number != 1 {
+			t.Fatalf("timeline block mismatch: have %d %x, want %d %x", ev.Timeline.Number, ev.Timeline.Hash, 1, blocks[0].Hash())
+		}
+		if ev.Timeline.RecvNewBlockFrom != "127.0.0.1:30311" {
+			t.Fatalf("timeline peer mismatch: have %q", ev.Timeline.RecvNewBlockFrom)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no block timeline posted")
+	}
+	timeline := chain.GetBlockTimeline(blocks[1].Hash())
+	if timeline == nil || timeline.Number != 2 || timeline.Coinbase != blocks[1].Coinbase() {
+		t.Fatalf("timeline mismatch: %+v", timeline)
+	}
+	if timeline.StartImportBlockTime == 0 || timeline.ImportedBlockTime < timeline.StartImportBlockTime {
+		t.Fatalf("import times mismatch: start %d, imported %d", timeline.StartImportBlockTime, timeline.ImportedBlockTime)
+	}
+	if chain.GetBlockTimeline(common.Hash{0x1}) != nil {
+		t.Fatal("timeline of unknown block")
+	}
+}
@@ -83,6 +83,23 @@ func IsDataAvailable(chain consensus.ChainHeaderReader, block *types.Block) (err
 		block.CleanSidecars()
 	}
 	sidecars := block.Sidecars()
+
+	// check blob amount
+	blobCnt := 0
+	for _, s := range sidecars {
+		blobCnt += len(s.Blobs)
+	}
+	maxBlobPerBlock := eip4844.MaxBlobsPerBlock(chain.Config(), block.Time())
+	if blobCnt > maxBlobPerBlock {
+		return fmt.Errorf("too many blobs in block: have %d, permitted %d", blobCnt, maxBlobPerBlock)
+	}
+	return VerifyBlobSidecars(block, sidecars)
+}
+
+// VerifyBlobSidecars checks that the sidecars belong to the block, one per blob
+// transaction in order, and that their blobs match the versioned hashes of the
+// transactions.
+func VerifyBlobSidecars(block *types.Block, sidecars types.BlobSidecars) error {
 	for _, s := range sidecars {
 		if err := s.SanityCheck(block.Number(), block.Hash()); err != nil {
 			return err
@@ -102,16 +119,6 @@ func IsDataAvailable(chain consensus.ChainHeaderReader, block *types.Block) (err
 		return fmt.Errorf("blob info mismatch: sidecars %d, versionedHashes:%d", len(sidecars), len(blobTxs))
 	}
 
-	// check blob amount
-	blobCnt := 0
-	for _, s := range sidecars {
-		blobCnt += len(s.Blobs)
-	}
-	maxBlobPerBlock := eip4844.MaxBlobsPerBlock(chain.Config(), block.Time())
-	if blobCnt > maxBlobPerBlock {
-		return fmt.Errorf("too many blobs in block: have %d, permitted %d", blobCnt, maxBlobPerBlock)
-	}
-
 	// check blob and versioned hash
 	for i, tx := range blobTxs {
 		// check sidecar tx related
@@ -41,6 +41,10 @@ type NewVoteEvent struct{ Vote *types.VoteEnvelope }
 // FinalizedHeaderEvent is posted when a finalized header is reached.
 type FinalizedHeaderEvent struct{ Header *types.Header }
 
+// BlockTimelineEvent is posted with the propagation timeline of a block once
+// its child becomes the chain head.
+type BlockTimelineEvent struct{ Timeline *BlockTimeline }
+
 type ChainEvent struct {
 	Header *types.Header
 }
@@ -144,3 +144,13 @@ func Transfer(db vm.StateDB, sender, recipient common.Address, amount *uint256.I
 	db.SubBalance(sender, amount, tracing.BalanceChangeTransfer)
 	db.AddBalance(recipient, amount, tracing.BalanceChangeTransfer)
 }
+
+// RewardBalance returns the total balance of the accounts receiving the block
+// rewards: the system address for parlia, the coinbase for the tips paid directly.
+func RewardBalance(db vm.StateDB, config *params.ChainConfig, coinbase common.Address) *uint256.Int {
+	balance := new(uint256.Int).Set(db.GetBalance(coinbase))
+	if config.Parlia != nil && coinbase != consensus.SystemAddress {
+		balance.Add(balance, db.GetBalance(consensus.SystemAddress))
+	}
+	return balance
+}
@@ -2,28 +2,152 @@ package monitor
 
 import (
 	"bytes"
+	"sync"
+	"sync/atomic"
 
+	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/common/hexutil"
 	"github.com/Ezkerrox/bsc/common/prque"
+	"github.com/Ezkerrox/bsc/core/rawdb"
 	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/ethdb"
 	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/metrics"
 	"github.com/Ezkerrox/bsc/rlp"
 )
 
 const (
 	MaxCacheHeader = 100
+
+	// evidenceQueueSize is the number of found evidences waiting for the
+	// submission, the ones beyond are submitted after the next restart.
+	evidenceQueueSize = 16
+
+	// maxEvidenceAge is the number of blocks an evidence is kept and submitted
+	// for, the slash transaction of an older evidence is likely to revert.
+	maxEvidenceAge = 256
+)
+
+var (
+	doubleSignEvidenceCounter       = metrics.NewRegisteredCounter("monitor/doubleSign/evidence", nil)
+	doubleSignSubmitCounter         = metrics.NewRegisteredCounter("monitor/doubleSign/submit", nil)
+	doubleSignSubmitFailureCounter  = metrics.NewRegisteredCounter("monitor/doubleSign/submitFailure", nil)
+	doubleSignPersistFailureCounter = metrics.NewRegisteredCounter("monitor/doubleSign/persistFailure", nil)
+	doubleSignStaleCounter          = metrics.NewRegisteredCounter("monitor/doubleSign/stale", nil)
 )
 
-func NewDoubleSignMonitor() *DoubleSignMonitor {
+// DoubleSignEvidence is a pair of different headers sealed by the same validator
+// at the same height, which is enough to get the validator slashed.
+type DoubleSignEvidence struct {
+	Number   uint64         `json:"number"`
+	Signer   common.Address `json:"signer"`
+	Header1  *types.Header  `json:"header1"`
+	Header2  *types.Header  `json:"header2"`
+	SubmitTx common.Hash    `json:"submitTx"` // Hash of the slash transaction, empty if not submitted
+}
+
+func NewDoubleSignMonitor(db ethdb.KeyValueStore) *DoubleSignMonitor {
 	return &DoubleSignMonitor{
 		headerNumbers: prque.New[int64, *types.Header](nil),
 		headers:       make(map[uint64]*types.Header, MaxCacheHeader),
+		db:            db,
+		queue:         make(chan *DoubleSignEvidence, evidenceQueueSize),
+		quit:          make(chan struct{}),
 	}
 }
 
 type DoubleSignMonitor struct {
 	headerNumbers *prque.Prque[int64, *types.Header]
 	headers       map[uint64]*types.Header
+
+	db        ethdb.KeyValueStore               // Database to persist the found evidences, nil to disable
+	submitter atomic.Pointer[EvidenceSubmitter] // Submitter to slash the double signer automatically, nil to disable
+
+	queue     chan *DoubleSignEvidence // Found evidences waiting for the submission
+	quit      chan struct{}            // Channel to terminate the submission
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// SetEvidenceSubmitter enables the automatic submission of the found evidences
+// to the SlashIndicator system contract, it takes effect once started.
+func (m *DoubleSignMonitor) SetEvidenceSubmitter(submitter *EvidenceSubmitter) {
+	m.submitter.Store(submitter)
+}
+
+// Start launches the submission of the found evidences if a submitter is set.
+// The persisted evidences which weren't submitted yet, because the submission
+// failed or the node stopped before it, are submitted again first, unless they
+// are older than maxEvidenceAge blocks. It must be called once the backend of
+// the submitter is able to serve it.
+func (m *DoubleSignMonitor) Start() {
+	submitter := m.submitter.Load()
+	if submitter == nil {
+		return
+	}
+	m.startOnce.Do(func() {
+		m.wg.Add(1)
+		go m.loop(submitter)
+	})
+}
+
+// Stop terminates the submission, the evidences left are submitted after the
+// next restart.
+func (m *DoubleSignMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.quit)
+	})
+	m.wg.Wait()
+}
+
+// loop submits the evidences one by one, the persisted ones first and then the
+// ones found afterwards. The stale evidences are dropped instead.
+func (m *DoubleSignMonitor) loop(submitter *EvidenceSubmitter) {
+	defer m.wg.Done()
+
+	if head, err := submitter.HeadNumber(); err != nil {
+		log.Error("get head for double sign evidences error", "err", err)
+	} else {
+		m.prune(head)
+	}
+	evidences, err := m.Evidences()
+	if err != nil {
+		log.Error("load double sign evidences error", "err", err)
+	}
+	for _, evidence := range evidences {
+		select {
+		case <-m.quit:
+			return
+		default:
+		}
+		m.submit(submitter, evidence)
+	}
+	for {
+		select {
+		case evidence := <-m.queue:
+			m.submit(submitter, evidence)
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// Evidences returns all the double sign evidences persisted in the database.
+func (m *DoubleSignMonitor) Evidences() ([]*DoubleSignEvidence, error) {
+	if m.db == nil {
+		return nil, nil
+	}
+	blobs := rawdb.ReadAllDoubleSignEvidenceRLP(m.db)
+	evidences := make([]*DoubleSignEvidence, 0, len(blobs))
+	for _, blob := range blobs {
+		evidence := new(DoubleSignEvidence)
+		if err := rlp.DecodeBytes(blob, evidence); err != nil {
+			return nil, err
+		}
+		evidences = append(evidences, evidence)
+	}
+	return evidences, nil
 }
 
 func (m *DoubleSignMonitor) isDoubleSignHeaders(h1, h2 *types.Header) (bool, error) {
@@ -98,5 +222,96 @@ func (m *DoubleSignMonitor) Verify(h *types.Header) {
 		log.Warn("double sign header content",
 			"header1", hexutil.Encode(h1Bytes),
 			"header2", hexutil.Encode(h2Bytes))
+
+		doubleSignEvidenceCounter.Inc(1)
+		evidence := &DoubleSignEvidence{
+			Number:  h.Number.Uint64(),
+			Signer:  h.Coinbase,
+			Header1: h,
+			Header2: h2,
+		}
+		if m.db != nil && rawdb.ReadDoubleSignEvidenceRLP(m.db, evidence.Number, evidence.Signer) != nil {
+			log.Debug("double sign evidence already recorded", "number", evidence.Number, "signer", evidence.Signer)
+			return
+		}
+		m.prune(evidence.Number)
+		m.persist(evidence)
+		if m.submitter.Load() != nil {
+			select {
+			case m.queue <- evidence:
+			default:
+				log.Warn("double sign evidence submission is busy, deferred to the restart", "number", evidence.Number, "signer", evidence.Signer)
+			}
+		}
+	}
+}
+
+// persist stores the evidence into the database so that it survives restarts.
+func (m *DoubleSignMonitor) persist(evidence *DoubleSignEvidence) {
+	if m.db == nil {
+		return
+	}
+	blob, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		doubleSignPersistFailureCounter.Inc(1)
+		log.Error("encode double sign evidence error", "err", err, "number", evidence.Number)
+		return
+	}
+	rawdb.WriteDoubleSignEvidenceRLP(m.db, evidence.Number, evidence.Signer, blob)
+}
+
+// prune deletes the persisted evidences older than maxEvidenceAge blocks before
+// the given head, submitted or not.
+func (m *DoubleSignMonitor) prune(head uint64) {
+	if m.db == nil || head <= maxEvidenceAge {
+		return
+	}
+	evidences, err := m.Evidences()
+	if err != nil {
+		log.Error("load double sign evidences error", "err", err)
+		return
+	}
+	for _, evidence := range evidences {
+		if evidence.Number+maxEvidenceAge < head {
+			rawdb.DeleteDoubleSignEvidence(m.db, evidence.Number, evidence.Signer)
+		}
+	}
+}
+
+// submit sends the evidence to the SlashIndicator contract and records the hash
+// of the slash transaction in the persisted evidence, which is kept until it's
+// pruned. The evidence is skipped if it was submitted already, and dropped if
+// it's older than maxEvidenceAge blocks.
+func (m *DoubleSignMonitor) submit(submitter *EvidenceSubmitter, evidence *DoubleSignEvidence) {
+	if evidence.SubmitTx != (common.Hash{}) {
+		return
+	}
+	if head, err := submitter.HeadNumber(); err == nil && evidence.Number+maxEvidenceAge < head {
+		doubleSignStaleCounter.Inc(1)
+		log.Warn("drop stale double sign evidence", "number", evidence.Number, "signer", evidence.Signer, "head", head)
+		if m.db != nil {
+			rawdb.DeleteDoubleSignEvidence(m.db, evidence.Number, evidence.Signer)
+		}
+		return
+	}
+	if m.db != nil {
+		if blob := rawdb.ReadDoubleSignEvidenceRLP(m.db, evidence.Number, evidence.Signer); blob != nil {
+			persisted := new(DoubleSignEvidence)
+			if err := rlp.DecodeBytes(blob, persisted); err == nil && persisted.SubmitTx != (common.Hash{}) {
+				return
+			}
+		}
 	}
+	tx, err := submitter.SubmitDoubleSignEvidence(evidence)
+	if err != nil {
+		doubleSignSubmitFailureCounter.Inc(1)
+		log.Error("submit double sign evidence error", "err", err, "number", evidence.Number, "signer", evidence.Signer)
+		return
+	}
+	doubleSignSubmitCounter.Inc(1)
+	log.Info("submitted double sign evidence", "number", evidence.Number, "signer", evidence.Signer, "tx", tx.Hash())
+
+	cpy := *evidence
+	cpy.SubmitTx = tx.Hash()
+	m.persist(&cpy)
 }
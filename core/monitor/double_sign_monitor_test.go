@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	ethereum "github.com/Ezkerrox/bsc"
+	"github.com/Ezkerrox/bsc/accounts/abi"
+	"github.com/Ezkerrox/bsc/accounts/abi/bind"
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto"
+)
+
+func TestDoubleSignMonitorPersistEvidence(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	miner := common.HexToAddress("0x1")
+	parent := common.HexToHash("0x2")
+	h1 := &types.Header{Number: big.NewInt(10), ParentHash: parent, Coinbase: miner, Difficulty: big.NewInt(2), Extra: []byte{1}}
+	h2 := &types.Header{Number: big.NewInt(10), ParentHash: parent, Coinbase: miner, Difficulty: big.NewInt(2), Extra: []byte{2}}
+	h3 := &types.Header{Number: big.NewInt(11), ParentHash: h1.Hash(), Coinbase: miner, Difficulty: big.NewInt(2)}
+
+	m := NewDoubleSignMonitor(db)
+	m.Verify(h1)
+	m.Verify(h3)
+	evidences, err := m.Evidences()
+	assert.NoError(t, err)
+	assert.Empty(t, evidences)
+
+	m.Verify(h2)
+	m.Verify(h2)
+
+	// Evidences should survive a restart of the monitor
+	evidences, err = NewDoubleSignMonitor(db).Evidences()
+	assert.NoError(t, err)
+	assert.Len(t, evidences, 1)
+	assert.Equal(t, uint64(10), evidences[0].Number)
+	assert.Equal(t, miner, evidences[0].Signer)
+	assert.Equal(t, h2.Hash(), evidences[0].Header1.Hash())
+	assert.Equal(t, h1.Hash(), evidences[0].Header2.Hash())
+	assert.Equal(t, common.Hash{}, evidences[0].SubmitTx)
+}
+
+// slashBackend is a contract backend recording the sent slash transactions.
+type slashBackend struct {
+	bind.ContractBackend
+
+	lock sync.Mutex
+	sent []*types.Transaction
+	head uint64
+}
+
+func (b *slashBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(max(b.head, 1))}, nil
+}
+
+func (b *slashBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *slashBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (b *slashBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (b *slashBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return uint64(len(b.sent)), nil
+}
+
+func (b *slashBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *slashBackend) count() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.sent)
+}
+
+func TestDoubleSignMonitorSubmitEvidence(t *testing.T) {
+	slashABI, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"submitDoubleSignEvidence","inputs":[{"name":"header1","type":"bytes"},{"name":"header2","type":"bytes"}],"outputs":[]}]`))
+	assert.NoError(t, err)
+	key, _ := crypto.GenerateKey()
+	backend := new(slashBackend)
+	submitter, err := NewEvidenceSubmitter(key, big.NewInt(1), slashABI, backend)
+	assert.NoError(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	parent := common.HexToHash("0x2")
+	header := func(miner common.Address, extra byte) *types.Header {
+		return &types.Header{Number: big.NewInt(10), ParentHash: parent, Coinbase: miner, Difficulty: big.NewInt(2), Extra: []byte{extra}}
+	}
+	// An evidence found before the restart, not submitted yet
+	m := NewDoubleSignMonitor(db)
+	m.Verify(header(common.HexToAddress("0x1"), 1))
+	m.Verify(header(common.HexToAddress("0x1"), 2))
+
+	// Nothing is submitted until started, the evidences found meanwhile are
+	// submitted once only, along with the persisted ones.
+	m = NewDoubleSignMonitor(db)
+	m.SetEvidenceSubmitter(submitter)
+	m.Verify(header(common.HexToAddress("0x3"), 1))
+	m.Verify(header(common.HexToAddress("0x3"), 2))
+	assert.Equal(t, 0, backend.count())
+
+	m.Start()
+	assert.Eventually(t, func() bool {
+		evidences, _ := m.Evidences()
+		for _, evidence := range evidences {
+			if evidence.SubmitTx == (common.Hash{}) {
+				return false
+			}
+		}
+		return len(evidences) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	m.Stop()
+	assert.Equal(t, 2, backend.count())
+
+	// Submitted evidences are not submitted again after the restart
+	m = NewDoubleSignMonitor(db)
+	m.SetEvidenceSubmitter(submitter)
+	m.Start()
+	m.Stop()
+	assert.Equal(t, 2, backend.count())
+}
+
+func TestDoubleSignMonitorStaleEvidence(t *testing.T) {
+	slashABI, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"submitDoubleSignEvidence","inputs":[{"name":"header1","type":"bytes"},{"name":"header2","type":"bytes"}],"outputs":[]}]`))
+	assert.NoError(t, err)
+	key, _ := crypto.GenerateKey()
+	backend := &slashBackend{head: 10 + maxEvidenceAge + 1}
+	submitter, err := NewEvidenceSubmitter(key, big.NewInt(1), slashABI, backend)
+	assert.NoError(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	header := func(number int64, extra byte) *types.Header {
+		return &types.Header{Number: big.NewInt(number), ParentHash: common.Hash{byte(number)}, Coinbase: common.HexToAddress("0x1"), Difficulty: big.NewInt(2), Extra: []byte{extra}}
+	}
+	m := NewDoubleSignMonitor(db)
+	m.Verify(header(10, 1))
+	m.Verify(header(10, 2))
+	m.Verify(header(20, 1))
+	m.Verify(header(20, 2))
+
+	// The evidence past the age limit is dropped instead of being submitted
+	m = NewDoubleSignMonitor(db)
+	m.SetEvidenceSubmitter(submitter)
+	m.Start()
+	assert.Eventually(t, func() bool {
+		evidences, _ := m.Evidences()
+		return len(evidences) == 1 && evidences[0].Number == 20 && evidences[0].SubmitTx != (common.Hash{})
+	}, 5*time.Second, 10*time.Millisecond)
+	m.Stop()
+	assert.Equal(t, 1, backend.count())
+
+	// The evidences are pruned once past the age limit, even if submitted
+	m = NewDoubleSignMonitor(db)
+	m.Verify(header(20+maxEvidenceAge+1, 1))
+	m.Verify(header(20+maxEvidenceAge+1, 2))
+	evidences, err := m.Evidences()
+	assert.NoError(t, err)
+	assert.Len(t, evidences, 1)
+	assert.Equal(t, uint64(20+maxEvidenceAge+1), evidences[0].Number)
+}
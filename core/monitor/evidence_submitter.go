@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/Ezkerrox/bsc/accounts/abi"
+	"github.com/Ezkerrox/bsc/accounts/abi/bind"
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/systemcontracts"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+// EvidenceSubmitter signs and sends the slash transactions carrying the evidences
+// to the SlashIndicator system contract, in the same way as the
+// maliciousvote-submit tool does for malicious votes.
+type EvidenceSubmitter struct {
+	key      *ecdsa.PrivateKey
+	chainID  *big.Int
+	backend  bind.ContractBackend
+	contract *bind.BoundContract
+}
+
+// NewEvidenceSubmitter creates a submitter sending transactions signed by key
+// through the given contract backend, slashABI is the ABI of the SlashIndicator
+// system contract.
+func NewEvidenceSubmitter(key *ecdsa.PrivateKey, chainID *big.Int, slashABI abi.ABI, backend bind.ContractBackend) (*EvidenceSubmitter, error) {
+	if key == nil || chainID == nil {
+		return nil, errors.New("evidence submitter requires a key and a chain id")
+	}
+	if _, ok := slashABI.Methods["submitDoubleSignEvidence"]; !ok {
+		return nil, errors.New("SlashIndicator ABI misses submitDoubleSignEvidence")
+	}
+	return &EvidenceSubmitter{
+		key:      key,
+		chainID:  chainID,
+		backend:  backend,
+		contract: bind.NewBoundContract(common.HexToAddress(systemcontracts.SlashContract), slashABI, backend, backend, backend),
+	}, nil
+}
+
+// SubmitDoubleSignEvidence submits the two RLP encoded headers of the evidence
+// to SlashIndicator.submitDoubleSignEvidence.
+func (s *EvidenceSubmitter) SubmitDoubleSignEvidence(evidence *DoubleSignEvidence) (*types.Transaction, error) {
+	header1, err := rlp.EncodeToBytes(evidence.Header1)
+	if err != nil {
+		return nil, err
+	}
+	header2, err := rlp.EncodeToBytes(evidence.Header2)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(s.key, s.chainID)
+	if err != nil {
+		return nil, err
+	}
+	return s.contract.Transact(opts, "submitDoubleSignEvidence", header1, header2)
+}
+
+// HeadNumber returns the number of the current head block of the backend.
+func (s *EvidenceSubmitter) HeadNumber() (uint64, error) {
+	header, err := s.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
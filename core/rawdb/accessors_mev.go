@@ -0,0 +1,73 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+// WriteBidRecordRLP stores the RLP encoded record of a builder bid for the given
+// block height.
+func WriteBidRecordRLP(db ethdb.KeyValueWriter, number uint64, hash common.Hash, record rlp.RawValue) {
+	if err := db.Put(bidHistoryKey(number, hash), record); err != nil {
+		log.Crit("Failed to store bid record", "err", err)
+	}
+}
+
+// ReadBidRecordsRLP retrieves the stored bid records of the blocks in the given
+// range, both included, in their raw RLP database encoding. Only the latest limit
+// records are returned, ordered by block height.
+func ReadBidRecordsRLP(db ethdb.Iteratee, from uint64, to uint64, limit int) []rlp.RawValue {
+	var records []rlp.RawValue
+	if limit <= 0 {
+		return records
+	}
+	it := db.NewIterator(BidHistoryPrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(BidHistoryPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(BidHistoryPrefix):]) > to {
+			break
+		}
+		records = append(records, common.CopyBytes(it.Value()))
+		// Drop the oldest records in chunks to keep the memory bounded
+		if len(records) >= 2*limit {
+			records = append(records[:0], records[len(records)-limit:]...)
+		}
+	}
+	if len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return records
+}
+
+// DeleteBidRecords removes all the bid records of the blocks in the given range,
+// from included and to excluded.
+func DeleteBidRecords(db ethdb.KeyValueStore, from uint64, to uint64) {
+	it := db.NewIterator(BidHistoryPrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(BidHistoryPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(BidHistoryPrefix):]) >= to {
+			break
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			log.Crit("Failed to delete bid record", "err", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete bid records", "err", err)
+	}
+}
@@ -0,0 +1,49 @@
+package rawdb
+
+import (
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+// ReadDoubleSignEvidenceRLP retrieves the double sign evidence of a validator at
+// the given block height in its raw RLP database encoding.
+func ReadDoubleSignEvidenceRLP(db ethdb.KeyValueReader, number uint64, signer common.Address) rlp.RawValue {
+	data, _ := db.Get(doubleSignEvidenceKey(number, signer))
+	return data
+}
+
+// WriteDoubleSignEvidenceRLP stores the RLP encoded double sign evidence of a
+// validator at the given block height.
+func WriteDoubleSignEvidenceRLP(db ethdb.KeyValueWriter, number uint64, signer common.Address, evidence rlp.RawValue) {
+	if err := db.Put(doubleSignEvidenceKey(number, signer), evidence); err != nil {
+		log.Crit("Failed to store double sign evidence", "err", err)
+	}
+}
+
+// DeleteDoubleSignEvidence removes the double sign evidence of a validator at
+// the given block height.
+func DeleteDoubleSignEvidence(db ethdb.KeyValueWriter, number uint64, signer common.Address) {
+	if err := db.Delete(doubleSignEvidenceKey(number, signer)); err != nil {
+		log.Crit("Failed to delete double sign evidence", "err", err)
+	}
+}
+
+// ReadAllDoubleSignEvidenceRLP retrieves all the stored double sign evidences in
+// their raw RLP database encoding, in ascending block number order.
+func ReadAllDoubleSignEvidenceRLP(db ethdb.Iteratee) []rlp.RawValue {
+	var (
+		evidences []rlp.RawValue
+		it        = db.NewIterator(DoubleSignEvidencePrefix, nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(DoubleSignEvidencePrefix)+8+common.AddressLength {
+			continue
+		}
+		evidences = append(evidences, common.CopyBytes(it.Value()))
+	}
+	return evidences
+}
@@ -160,6 +160,79 @@ func DeleteTrieJournal(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadOnlinePruningStatus retrieves the serialized progress of the online state
+// pruning.
+func ReadOnlinePruningStatus(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(onlinePruningKey)
+	return data
+}
+
+// WriteOnlinePruningStatus stores the serialized progress of the online state
+// pruning.
+func WriteOnlinePruningStatus(db ethdb.KeyValueWriter, status []byte) {
+	if err := db.Put(onlinePruningKey, status); err != nil {
+		log.Crit("Failed to store online pruning status", "err", err)
+	}
+}
+
+// DeleteOnlinePruningStatus deletes the serialized progress of the online state
+// pruning.
+func DeleteOnlinePruningStatus(db ethdb.KeyValueWriter) {
+	if err := db.Delete(onlinePruningKey); err != nil {
+		log.Crit("Failed to remove online pruning status", "err", err)
+	}
+}
+
+// ReadHbss2PbssStatus retrieves the serialized progress of the conversion of
+// the hash-based state into the path-based scheme.
+func ReadHbss2PbssStatus(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(hbss2pbssKey)
+	return data
+}
+
+// WriteHbss2PbssStatus stores the serialized progress of the conversion of the
+// hash-based state into the path-based scheme.
+func WriteHbss2PbssStatus(db ethdb.KeyValueWriter, status []byte) {
+	if err := db.Put(hbss2pbssKey, status); err != nil {
+		log.Crit("Failed to store hbss2pbss status", "err", err)
+	}
+}
+
+// DeleteHbss2PbssStatus deletes the serialized progress of the conversion of
+// the hash-based state into the path-based scheme.
+func DeleteHbss2PbssStatus(db ethdb.KeyValueWriter) {
+	if err := db.Delete(hbss2pbssKey); err != nil {
+		log.Crit("Failed to remove hbss2pbss status", "err", err)
+	}
+}
+
+// ReadHashTriePruning retrieves the key of the last hash-based trie node removed
+// after the conversion into the path-based scheme, and whether the removal is
+// pending at all.
+func ReadHashTriePruning(db ethdb.KeyValueReader) ([]byte, bool) {
+	if ok, _ := db.Has(hashTriePruningKey); !ok {
+		return nil, false
+	}
+	data, _ := db.Get(hashTriePruningKey)
+	return data, true
+}
+
+// WriteHashTriePruning stores the key of the last hash-based trie node removed
+// after the conversion into the path-based scheme, an empty one marks the
+// removal as pending.
+func WriteHashTriePruning(db ethdb.KeyValueWriter, last []byte) {
+	if err := db.Put(hashTriePruningKey, last); err != nil {
+		log.Crit("Failed to store hash trie pruning progress", "err", err)
+	}
+}
+
+// DeleteHashTriePruning deletes the hash-based trie node removal progress.
+func DeleteHashTriePruning(db ethdb.KeyValueWriter) {
+	if err := db.Delete(hashTriePruningKey); err != nil {
+		log.Crit("Failed to remove hash trie pruning progress", "err", err)
+	}
+}
+
 // ReadStateHistoryMeta retrieves the metadata corresponding to the specified
 // state history. Compute the position of state history in freezer by minus
 // one since the id of first state history starts from one(zero for initial
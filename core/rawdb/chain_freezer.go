@@ -17,9 +17,11 @@
 package rawdb
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -62,6 +64,8 @@ type chainFreezer struct {
 	freezeEnv    atomic.Value
 	waitEnvTimes int
 
+	blobArchiveNext uint64 // Number of the first block whose blob sidecars aren't queued for archiving
+
 	multiDatabase bool
 }
 
@@ -412,6 +416,16 @@ func (f *chainFreezer) tryPruneBlobAncientTable(env *ethdb.FreezerEnv, num uint6
 	}
 	expectTail := num - reserveThreshold
 	start := time.Now()
+	if env != nil && env.BlobArchiver != nil {
+		archived, err := f.archiveBlobs(env.BlobArchiver, expectTail)
+		if err != nil {
+			log.Error("Cannot archive blobs, skip pruning", "block", num, "expectTail", expectTail, "err", err)
+			return
+		}
+		// Only prune the blobs uploaded already, the others are kept until
+		// the archiver catches up.
+		expectTail = min(expectTail, archived)
+	}
 	if _, err := f.TruncateTableTail(ChainFreezerBlobSidecarTable, expectTail); err != nil {
 		log.Error("Cannot prune blob ancient", "block", num, "expectTail", expectTail, "err", err)
 		return
@@ -419,6 +433,43 @@ func (f *chainFreezer) tryPruneBlobAncientTable(env *ethdb.FreezerEnv, num uint6
 	log.Debug("Chain freezer prune useless blobs, now ancient data is", "from", expectTail, "to", num, "cost", common.PrettyDuration(time.Since(start)))
 }
 
+// archiveBlobs queues the blob sidecars of the ancient blocks below the given
+// tail which haven't been pruned yet to the archiver, returning the number of
+// the first block whose sidecars aren't archived yet.
+func (f *chainFreezer) archiveBlobs(archiver ethdb.BlobArchiver, tail uint64) (uint64, error) {
+	// The blob table tail isn't tracked, search for the first retained item.
+	first := uint64(sort.Search(int(tail), func(i int) bool {
+		ok, _ := f.HasAncient(ChainFreezerBlobSidecarTable, uint64(i))
+		return ok
+	}))
+	next := max(first, f.blobArchiveNext)
+	for ; next < tail; next++ {
+		data, err := f.Ancient(ChainFreezerBlobSidecarTable, next)
+		if err != nil {
+			return 0, err
+		}
+		// Skip the blocks without blob transactions, stored as empty lists.
+		if len(data) == 0 || bytes.Equal(data, rlp.EmptyList) {
+			continue
+		}
+		hash, err := f.Ancient(ChainFreezerHashTable, next)
+		if err != nil {
+			return 0, err
+		}
+		// The archiver uploads in the background, the blocks it can't take
+		// yet are queued at the next attempt.
+		if err := archiver.ArchiveBlobSidecars(next, common.BytesToHash(hash), data); err != nil {
+			log.Debug("Deferred blob archiving", "number", next, "err", err)
+			break
+		}
+	}
+	if next > f.blobArchiveNext {
+		log.Debug("Queued blobs for archiving", "from", max(first, f.blobArchiveNext), "to", next)
+		f.blobArchiveNext = next
+	}
+	return min(next, archiver.ArchivedBlobs()), nil
+}
+
 func getBlobExtraReserveFromEnv(env *ethdb.FreezerEnv) uint64 {
 	if env == nil {
 		return params.DefaultExtraReserveForBlobRequests
@@ -718,25 +718,55 @@ func AncientInspect(db ethdb.Database) error {
 	return nil
 }
 
-func PruneHashTrieNodeInDataBase(db ethdb.Database) error {
-	it := db.NewIterator([]byte{}, []byte{})
+// PruneHashTrieNodeInDataBase deletes the hash-based trie nodes left in the
+// database by the conversion into the path-based scheme. The progress is stored
+// along with the deletions, an interrupted removal resumes after the last node
+// deleted. The removal stops once quit is closed, nil is returned then too.
+func PruneHashTrieNodeInDataBase(db ethdb.Database, quit <-chan struct{}) error {
+	start, _ := ReadHashTriePruning(db)
+	it := db.NewIterator(nil, start)
 	defer it.Release()
 
-	total_num := 0
+	var (
+		batch  = db.NewBatch()
+		total  int
+		logged = time.Now()
+	)
 	for it.Next() {
-		var key = it.Key()
-		switch {
-		case IsLegacyTrieNode(key, it.Value()):
-			db.Delete(key)
-			total_num++
-			if total_num%100000 == 0 {
-				log.Info("Pruning hash-base state trie nodes", "Complete progress: ", total_num)
-			}
-		default:
+		key := it.Key()
+		if !IsLegacyTrieNode(key, it.Value()) {
 			continue
 		}
+		batch.Delete(key)
+		total++
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			WriteHashTriePruning(batch, key)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			select {
+			case <-quit:
+				log.Info("Paused pruning hash-base state trie nodes", "deleted", total)
+				return nil
+			default:
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Pruning hash-base state trie nodes", "deleted", total, "at", common.Bytes2Hex(key))
+				logged = time.Now()
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	DeleteHashTriePruning(batch)
+	if err := batch.Write(); err != nil {
+		return err
 	}
-	log.Info("Pruning hash-base state trie nodes", "Complete progress", total_num)
+	log.Info("Pruned hash-base state trie nodes", "deleted", total)
 	return nil
 }
 
@@ -768,7 +798,8 @@ func DataTypeByKey(key []byte) DataType {
 		return BlockDataType
 	default:
 		for _, meta := range [][]byte{
-			fastTrieProgressKey, persistentStateIDKey, trieJournalKey, snapSyncStatusFlagKey} {
+			fastTrieProgressKey, persistentStateIDKey, trieJournalKey, snapSyncStatusFlagKey, onlinePruningKey,
+			hbss2pbssKey, hashTriePruningKey} {
 			if bytes.Equal(key, meta) {
 				return StateDataType
 			}
@@ -823,6 +854,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		bloomBits       stat
 		cliqueSnaps     stat
 		parliaSnaps     stat
+		doubleSigns     stat
+		bidHistory      stat
 
 		// Verkle statistics
 		verkleTries        stat
@@ -891,6 +924,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			cliqueSnaps.Add(size)
 		case bytes.HasPrefix(key, ParliaSnapshotPrefix) && len(key) == 7+common.HashLength:
 			parliaSnaps.Add(size)
+		case bytes.HasPrefix(key, DoubleSignEvidencePrefix) && len(key) == len(DoubleSignEvidencePrefix)+8+common.AddressLength:
+			doubleSigns.Add(size)
+		case bytes.HasPrefix(key, BidHistoryPrefix) && len(key) == len(BidHistoryPrefix)+8+common.HashLength:
+			bidHistory.Add(size)
 		case bytes.HasPrefix(key, ChtTablePrefix) ||
 			bytes.HasPrefix(key, ChtIndexTablePrefix) ||
 			bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
@@ -925,6 +962,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
 				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
+				onlinePruningKey, hbss2pbssKey, hashTriePruningKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -966,7 +1004,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			default:
 				var accounted bool
 				for _, meta := range [][]byte{
-					fastTrieProgressKey, persistentStateIDKey, trieJournalKey, snapSyncStatusFlagKey} {
+					fastTrieProgressKey, persistentStateIDKey, trieJournalKey, snapSyncStatusFlagKey, onlinePruningKey,
+					hbss2pbssKey, hashTriePruningKey} {
 					if bytes.Equal(key, meta) {
 						metadata.Add(size)
 						accounted = true
@@ -1057,6 +1096,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
 		{"Key-Value store", "Parlia snapshots", parliaSnaps.Size(), parliaSnaps.Count()},
+		{"Key-Value store", "Double sign evidences", doubleSigns.Size(), doubleSigns.Count()},
+		{"Key-Value store", "Bid history", bidHistory.Size(), bidHistory.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
 		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
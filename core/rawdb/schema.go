@@ -76,6 +76,17 @@ var (
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
+	// onlinePruningKey tracks the online state pruning progress across restarts.
+	onlinePruningKey = []byte("OnlinePruning")
+
+	// hbss2pbssKey tracks the conversion progress of the hash-based state into
+	// the path-based scheme across restarts.
+	hbss2pbssKey = []byte("Hbss2Pbss")
+
+	// hashTriePruningKey tracks the removal progress of the hash-based trie nodes
+	// left after the conversion into the path-based scheme.
+	hashTriePruningKey = []byte("HashTriePruning")
+
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
@@ -161,6 +172,9 @@ var (
 
 	BlockBlobSidecarsPrefix = []byte("blobs")
 
+	DoubleSignEvidencePrefix = []byte("doublesign-") // DoubleSignEvidencePrefix + num (uint64 big endian) + signer -> double sign evidence
+	BidHistoryPrefix         = []byte("bidhistory-") // BidHistoryPrefix + num (uint64 big endian) + bid hash -> bid record
+
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 )
@@ -220,6 +234,16 @@ func blockBlobSidecarsKey(number uint64, hash common.Hash) []byte {
 	return append(append(BlockBlobSidecarsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// doubleSignEvidenceKey = DoubleSignEvidencePrefix + num (uint64 big endian) + signer
+func doubleSignEvidenceKey(number uint64, signer common.Address) []byte {
+	return append(append(DoubleSignEvidencePrefix, encodeBlockNumber(number)...), signer.Bytes()...)
+}
+
+// bidHistoryKey = BidHistoryPrefix + num (uint64 big endian) + bid hash
+func bidHistoryKey(number uint64, hash common.Hash) []byte {
+	return append(append(BidHistoryPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // diffLayerKey = diffLayerKeyPrefix + hash
 func diffLayerKey(hash common.Hash) []byte {
 	return append(diffLayerPrefix, hash.Bytes()...)
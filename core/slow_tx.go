@@ -0,0 +1,324 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/consensus"
+	"github.com/Ezkerrox/bsc/consensus/misc"
+	"github.com/Ezkerrox/bsc/core/systemcontracts"
+	"github.com/Ezkerrox/bsc/core/tracing"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/core/vm"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/metrics"
+)
+
+const (
+	// slowTxMinGas is the gas a transaction must use to be checked, the fixed
+	// overhead of the smaller ones skews their time per gas.
+	slowTxMinGas = 100000
+
+	// slowTxReports is the number of slow transaction reports retained.
+	slowTxReports = 128
+)
+
+var (
+	slowTxMeter           = metrics.NewRegisteredMeter("chain/slowtx/detected", nil)
+	slowTxTimePerMgasHist = metrics.NewRegisteredHistogram("chain/slowtx/timepermgas", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// OpcodeStat is the number of executions of an opcode in a transaction, and the
+// gas they were charged, the gas forwarded by the calls included.
+type OpcodeStat struct {
+	Count uint64
+	Gas   uint64
+}
+
+// SlowTxReport is a transaction whose execution time per gas exceeded the
+// threshold of the slow transaction detector.
+type SlowTxReport struct {
+	Hash        common.Hash
+	From        common.Address
+	To          *common.Address
+	BlockNumber uint64
+	BlockHash   common.Hash
+	GasUsed     uint64
+	Duration    time.Duration // Execution time, measured without tracing
+	Time        time.Time     // Time the block import detected it at
+
+	Contract  common.Address            // Contract whose code executed the most opcodes
+	Contracts map[common.Address]uint64 // Number of opcodes executed per contract code
+	Opcodes   map[vm.OpCode]OpcodeStat
+}
+
+// slowTxFrame is a call being executed, accumulating the opcodes executed by
+// the code of the callee.
+type slowTxFrame struct {
+	code  common.Address
+	steps uint64
+}
+
+// slowTxDetector checks the execution time of the imported transactions, and
+// retains the opcode histogram of the ones whose time per million gas exceeds
+// the threshold. The import isn't traced, the execution times are the ones
+// measured by the processor, only the blocks with slow transactions are
+// executed again with the opcode tracer.
+type slowTxDetector struct {
+	threshold time.Duration // Execution time per million gas above which a transaction is slow
+
+	reports []*SlowTxReport
+	next    int
+	lock    sync.RWMutex
+}
+
+func newSlowTxDetector(threshold time.Duration) *slowTxDetector {
+	return &slowTxDetector{
+		threshold: threshold,
+		reports:   make([]*SlowTxReport, slowTxReports),
+	}
+}
+
+// slowTxTracer builds the opcode histograms of the slow transactions of a block
+// executed again, the other transactions of the block are ignored.
+type slowTxTracer struct {
+	reports map[common.Hash]*SlowTxReport
+	traced  int
+
+	active    *SlowTxReport // Report of the transaction being executed, nil if not slow
+	frames    []slowTxFrame
+	contracts map[common.Address]uint64
+	counts    [256]uint64
+	gas       [256]uint64
+}
+
+func (t *slowTxTracer) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: t.onTxStart,
+		OnTxEnd:   t.onTxEnd,
+		OnEnter:   t.onEnter,
+		OnExit:    t.onExit,
+		OnOpcode:  t.onOpcode,
+	}
+}
+
+func (t *slowTxTracer) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.active = t.reports[tx.Hash()]
+	if t.active == nil {
+		return
+	}
+	t.frames = t.frames[:0]
+	t.contracts = make(map[common.Address]uint64)
+	clear(t.counts[:])
+	clear(t.gas[:])
+}
+
+func (t *slowTxTracer) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.active != nil {
+		t.frames = append(t.frames, slowTxFrame{code: to})
+	}
+}
+
+func (t *slowTxTracer) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.active == nil || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if frame.steps > 0 {
+		t.contracts[frame.code] += frame.steps
+	}
+}
+
+func (t *slowTxTracer) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.active == nil {
+		return
+	}
+	t.counts[op]++
+	t.gas[op] += cost
+	if len(t.frames) > 0 {
+		t.frames[len(t.frames)-1].steps++
+	}
+}
+
+func (t *slowTxTracer) onTxEnd(receipt *types.Receipt, err error) {
+	report := t.active
+	if report == nil {
+		return
+	}
+	for len(t.frames) > 0 {
+		t.onExit(0, nil, 0, nil, false)
+	}
+	report.Contracts = t.contracts
+	report.Opcodes = make(map[vm.OpCode]OpcodeStat)
+	for code, steps := range t.contracts {
+		if steps > report.Contracts[report.Contract] {
+			report.Contract = code
+		}
+	}
+	for op, count := range t.counts {
+		if count > 0 {
+			report.Opcodes[vm.OpCode(op)] = OpcodeStat{Count: count, Gas: t.gas[op]}
+		}
+	}
+	t.active = nil
+	t.traced++
+}
+
+// check reports the transactions of the block whose execution time per million
+// gas exceeded the threshold, with their opcode histograms.
+func (d *slowTxDetector) check(bc *BlockChain, block *types.Block, res *ProcessResult) {
+	var (
+		signer  = types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+		now     = time.Now()
+		reports = make(map[common.Hash]*SlowTxReport)
+	)
+	for i, elapsed := range res.TxTimes {
+		receipt := res.Receipts[i]
+		if receipt.GasUsed < slowTxMinGas {
+			continue
+		}
+		slowTxTimePerMgasHist.Update(elapsed.Microseconds() * 1000000 / int64(receipt.GasUsed))
+
+		if elapsed*1000000 <= d.threshold*time.Duration(receipt.GasUsed) {
+			continue
+		}
+		tx := block.Transaction(receipt.TxHash)
+		if tx == nil {
+			continue
+		}
+		from, _ := types.Sender(signer, tx)
+		reports[receipt.TxHash] = &SlowTxReport{
+			Hash:        receipt.TxHash,
+			From:        from,
+			To:          tx.To(),
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			GasUsed:     receipt.GasUsed,
+			Duration:    elapsed,
+			Time:        now,
+		}
+	}
+	if len(reports) == 0 {
+		return
+	}
+	if err := bc.traceSlowTxs(block, reports); err != nil {
+		log.Debug("Failed to trace slow transactions", "number", block.Number(), "hash", block.Hash(), "err", err)
+	}
+	for _, report := range reports {
+		d.lock.Lock()
+		d.reports[d.next] = report
+		d.next = (d.next + 1) % len(d.reports)
+		d.lock.Unlock()
+
+		var top vm.OpCode
+		for op, stat := range report.Opcodes {
+			if stat.Count > report.Opcodes[top].Count {
+				top = op
+			}
+		}
+		slowTxMeter.Mark(1)
+		log.Warn("Slow transaction", "number", report.BlockNumber, "hash", report.Hash, "contract", report.Contract,
+			"gas", report.GasUsed, "elapsed", common.PrettyDuration(report.Duration),
+			"mgasps", float64(report.GasUsed)*1000/float64(report.Duration), "opcode", top)
+	}
+}
+
+// traceSlowTxs executes the block again on top of its parent state, up to the
+// last slow transaction, and fills the opcode histograms of the reports.
+func (bc *BlockChain) traceSlowTxs(block *types.Block, reports map[common.Hash]*SlowTxReport) error {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return errors.New("parent block not found")
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return err
+	}
+	if bc.chainConfig.DAOForkSupport && bc.chainConfig.DAOForkBlock != nil && bc.chainConfig.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	systemcontracts.TryUpdateBuildInSystemContract(bc.chainConfig, block.Number(), parent.Time, block.Time(), statedb, true)
+
+	var (
+		tracer  = &slowTxTracer{reports: reports}
+		header  = block.Header()
+		signer  = types.MakeSigner(bc.chainConfig, header.Number, header.Time)
+		evm     = vm.NewEVM(NewEVMBlockContext(header, bc, nil), statedb, bc.chainConfig, vm.Config{Tracer: tracer.hooks()})
+		gp      = new(GasPool).AddGas(block.GasLimit())
+		usedGas uint64
+	)
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		ProcessBeaconBlockRoot(*beaconRoot, evm)
+	}
+	if bc.chainConfig.IsPrague(block.Number(), block.Time()) || bc.chainConfig.IsVerkle(block.Number(), block.Time()) {
+		ProcessParentBlockHash(block.ParentHash(), evm)
+	}
+	posa, isPoSA := bc.engine.(consensus.PoSA)
+	for i, tx := range block.Transactions() {
+		if tracer.traced == len(reports) {
+			break
+		}
+		if isPoSA {
+			if isSystemTx, err := posa.IsSystemTransaction(tx, header); err != nil {
+				return err
+			} else if isSystemTx {
+				continue
+			}
+		}
+		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return err
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+		if _, err := ApplyTransactionWithEVM(msg, gp, statedb, block.Number(), block.Hash(), tx, &usedGas, evm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns the retained reports, oldest first.
+func (d *slowTxDetector) get() []*SlowTxReport {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	reports := make([]*SlowTxReport, 0, len(d.reports))
+	for i := 0; i < len(d.reports); i++ {
+		if report := d.reports[(d.next+i)%len(d.reports)]; report != nil {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// EnableSlowTxDetector reports the imported transactions whose execution time
+// per million gas exceeds the threshold, with the opcodes they executed. The
+// blocks with slow transactions are executed again to trace them.
+func EnableSlowTxDetector(threshold time.Duration) BlockChainOption {
+	return func(bc *BlockChain) (*BlockChain, error) {
+		if threshold > 0 {
+			bc.slowTxDetector = newSlowTxDetector(threshold)
+		}
+		return bc, nil
+	}
+}
+
+// GetSlowTransactions returns the recently imported transactions whose execution
+// time per million gas exceeded the threshold, oldest first. Nil is returned if
+// the detector is disabled.
+func (bc *BlockChain) GetSlowTransactions() []*SlowTxReport {
+	if bc.slowTxDetector == nil {
+		return nil
+	}
+	return bc.slowTxDetector.get()
+}
+
+// SlowTxDetectorEnabled reports whether the imported transactions are checked
+// by the slow transaction detector.
+func (bc *BlockChain) SlowTxDetectorEnabled() bool {
+	return bc.slowTxDetector != nil
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/consensus/ethash"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/core/vm"
+	"github.com/Ezkerrox/bsc/params"
+)
+
+func TestSlowTxDetector(t *testing.T) {
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		loop   = common.Address{0xaa}
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				testAddr: {Balance: big.NewInt(100000000000000000)},
+				// JUMPDEST, PUSH1 0, JUMP: loops until out of gas
+				loop: {Code: common.FromHex("0x5b600056"), Balance: common.Big0},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(i int, gen *BlockGen) {
+		// A transfer, too small to be checked
+		gen.AddTx(types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    gen.TxNonce(testAddr),
+			To:       &common.Address{0x01},
+			Gas:      params.TxGas,
+			GasPrice: gen.header.BaseFee,
+		}))
+		gen.AddTx(types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    gen.TxNonce(testAddr),
+			To:       &loop,
+			Gas:      200000,
+			GasPrice: gen.header.BaseFee,
+		}))
+	})
+	// Any execution time exceeds the threshold
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, EnableSlowTxDetector(1))
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	reports := chain.GetSlowTransactions()
+	if len(reports) != 1 {
+		t.Fatalf("report count mismatch: have %d, want 1", len(reports))
+	}
+	report := reports[0]
+	if want := blocks[0].Transactions()[1].Hash(); report.Hash != want {
+		t.Errorf("hash mismatch: have %x, want %x", report.Hash, want)
+	}
+	if report.BlockNumber != 1 || report.BlockHash != blocks[0].Hash() {
+		t.Errorf("block mismatch: have #%d %x, want #1 %x", report.BlockNumber, report.BlockHash, blocks[0].Hash())
+	}
+	if report.From != testAddr || report.GasUsed != 200000 {
+		t.Errorf("transaction mismatch: have %x/%d, want %x/%d", report.From, report.GasUsed, testAddr, 200000)
+	}
+	if report.Contract != loop {
+		t.Errorf("contract mismatch: have %x, want %x", report.Contract, loop)
+	}
+	jumps := report.Opcodes[vm.JUMP]
+	if jumps.Count == 0 || jumps.Gas > jumps.Count*8 {
+		t.Errorf("unexpected JUMP stats: %+v", jumps)
+	}
+	for _, op := range []vm.OpCode{vm.JUMPDEST, vm.PUSH1} {
+		if count := report.Opcodes[op].Count; count < jumps.Count || count > jumps.Count+1 {
+			t.Errorf("%v count mismatch: have %d, want %d", op, count, jumps.Count)
+		}
+	}
+	if steps := report.Contracts[loop]; steps != jumps.Count+report.Opcodes[vm.JUMPDEST].Count+report.Opcodes[vm.PUSH1].Count {
+		t.Errorf("contract steps mismatch: have %d", steps)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/lru"
+	"github.com/Ezkerrox/bsc/core/state/snapshot"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/rlp"
+	"github.com/Ezkerrox/bsc/trie"
+	"github.com/Ezkerrox/bsc/trie/utils"
+	"github.com/Ezkerrox/bsc/triedb"
+	"github.com/Ezkerrox/bsc/triedb/pathdb"
+)
+
+// historicReader wraps a historical state reader of the path-based trie
+// database, implementing the StateReader interface.
+type historicReader struct {
+	reader *pathdb.HistoricalStateReader
+}
+
+// Account implements StateReader, retrieving the account specified by the address.
+//
+// The returned account might be nil if it's not existent.
+func (r *historicReader) Account(addr common.Address) (*types.StateAccount, error) {
+	return r.reader.Account(addr)
+}
+
+// Storage implements StateReader, retrieving the storage slot specified by the
+// address and slot key.
+//
+// The returned storage slot might be empty if it's not existent.
+func (r *historicReader) Storage(addr common.Address, key common.Hash) (common.Hash, error) {
+	blob, err := r.reader.Storage(addr, key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(blob) == 0 {
+		return common.Hash{}, nil
+	}
+	_, content, _, err := rlp.Split(blob)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var slot common.Hash
+	slot.SetBytes(content)
+	return slot, nil
+}
+
+// HistoricDB is an implementation of Database interface, providing read access
+// to the historical states retained by the state histories of the path-based
+// trie database. The states are reconstructed from the histories rather than
+// tries, so the tries are not available and the states can't be committed.
+type HistoricDB struct {
+	disk          ethdb.KeyValueStore
+	triedb        *triedb.Database
+	codeCache     *lru.SizeConstrainedCache[common.Hash, []byte]
+	codeSizeCache *lru.Cache[common.Hash, int]
+	pointCache    *utils.PointCache
+}
+
+// NewHistoricDatabase creates a historic state database.
+func NewHistoricDatabase(disk ethdb.KeyValueStore, triedb *triedb.Database) *HistoricDB {
+	return &HistoricDB{
+		disk:          disk,
+		triedb:        triedb,
+		codeCache:     lru.NewSizeConstrainedCache[common.Hash, []byte](codeCacheSize),
+		codeSizeCache: lru.NewCache[common.Hash, int](codeSizeCacheSize),
+		pointCache:    utils.NewPointCache(pointCacheSize),
+	}
+}
+
+// Reader implements Database interface, returning a reader of the specific state.
+func (db *HistoricDB) Reader(stateRoot common.Hash) (Reader, error) {
+	hr, err := db.triedb.HistoricReader(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(newCachingCodeReader(db.disk, db.codeCache, db.codeSizeCache), &historicReader{reader: hr}), nil
+}
+
+// OpenTrie implements Database interface. The tries of historical states are
+// not available, an empty trie is returned so the state is accessed through the
+// reader only.
+func (db *HistoricDB) OpenTrie(root common.Hash) (Trie, error) {
+	return trie.NewEmptyTrie(), nil
+}
+
+// OpenStorageTrie implements Database interface, returning an empty trie as
+// OpenTrie does.
+func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	return trie.NewEmptyTrie(), nil
+}
+
+// PointCache returns the cache holding points used in verkle tree key computation.
+func (db *HistoricDB) PointCache() *utils.PointCache {
+	return db.pointCache
+}
+
+// TrieDB returns the underlying trie database for managing trie nodes.
+func (db *HistoricDB) TrieDB() *triedb.Database {
+	return db.triedb
+}
+
+// NoTries returns true, the tries of historical states are not available.
+func (db *HistoricDB) NoTries() bool {
+	return true
+}
+
+// Snapshot returns nil, the state snapshot only covers the live states.
+func (db *HistoricDB) Snapshot() *snapshot.Tree {
+	return nil
+}
@@ -0,0 +1,529 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rlp"
+	"github.com/Ezkerrox/bsc/trie"
+	"github.com/Ezkerrox/bsc/triedb"
+)
+
+const (
+	// sweepBatchSize is the maximum number of trie nodes deleted at once.
+	sweepBatchSize = 4096
+
+	// sweepCheckpoint is the number of database entries scanned after which
+	// the sweeping progress is persisted, even if nothing was deleted.
+	sweepCheckpoint = 100000
+)
+
+var (
+	errPruningRunning    = errors.New("pruning is already running")
+	errPruningNotRunning = errors.New("pruning is not running")
+	errPruningAborted    = errors.New("pruning aborted")
+	errPruningClosed     = errors.New("pruning is closed")
+	errTargetReorged     = errors.New("pruning target is no longer canonical")
+)
+
+// OnlineConfig includes the configurations for online pruning.
+type OnlineConfig struct {
+	BloomSize uint64 // The Megabytes of memory allocated to bloom-filter
+	Rate      uint64 // The maximum number of trie nodes deleted per second, 0 for unlimited
+}
+
+// onlineStatus is the persisted progress of an online pruning round.
+type onlineStatus struct {
+	Cursor []byte // Key of the last swept database entry
+	Paused bool   // Whether the round was paused by the user
+	Nodes  uint64 // Number of trie nodes deleted in the round
+	Size   uint64 // Storage size of trie nodes deleted in the round
+}
+
+// OnlinePruner deletes the stale trie nodes of the hash-based scheme in the
+// background, while the node keeps running. A pruning round goes through:
+//
+//   - marking: the head state is flushed to disk and becomes the target, all
+//     trie nodes of the target and the genesis are recorded in a bloom filter.
+//     From the moment the marking starts, all nodes persisted by the trie
+//     database are recorded too, the states after the target are built upon
+//     these two sets.
+//   - waiting: the chain advances past the in-memory tries of the target, so
+//     that no live state derived from an earlier one is left.
+//   - sweeping: the database is iterated and the trie nodes not recorded are
+//     deleted in rate limited batches, along with the iteration cursor.
+//
+// If the target block is reorged out of the canonical chain while waiting or
+// sweeping, the head state is marked again and the sweeping goes on from the
+// cursor with the new filter.
+//
+// The states before the target are no longer available after the round. The
+// contract codes are left untouched.
+//
+// The bloom filter lives in memory only. A round interrupted by a shutdown
+// resumes the sweeping at the persisted cursor, after marking the new head
+// state again.
+type OnlinePruner struct {
+	config  OnlineConfig
+	chain   *core.BlockChain
+	db      ethdb.Database // The chain database
+	pruneDB ethdb.Database // The database of the trie nodes
+
+	status     *onlineStatus // The progress of the current round, nil if none
+	target     uint64        // The block number of the marked state
+	targetHash common.Hash   // The block hash of the marked state
+	marked     bool          // Whether the bloom filter holds the complete target
+	closed     bool          // Whether the pruner is closed, refusing new rounds
+
+	bloom *stateBloom // The recorded trie nodes, nil if not recording
+	lock  sync.Mutex  // Lock serializing the recording and deletion of trie nodes
+
+	quit    chan struct{} // Channel to abort the running round
+	done    chan struct{} // Channel closed when the running round exits
+	runLock sync.Mutex    // Lock protecting the round lifecycle
+}
+
+// NewOnlinePruner creates the online pruner of the given hash-based chain,
+// loading the progress of an unfinished round.
+func NewOnlinePruner(chain *core.BlockChain, db ethdb.Database, config OnlineConfig) (*OnlinePruner, error) {
+	if chain.TrieDB().Scheme() != rawdb.HashScheme {
+		return nil, errors.New("online pruning is only supported in hash-based scheme")
+	}
+	if config.BloomSize < 256 {
+		log.Warn("Sanitizing bloomfilter size", "provided(MB)", config.BloomSize, "updated(MB)", 256)
+		config.BloomSize = 256
+	}
+	p := &OnlinePruner{
+		config:  config,
+		chain:   chain,
+		db:      db,
+		pruneDB: db,
+	}
+	if db.StateStore() != nil {
+		p.pruneDB = db.StateStore()
+	}
+	if blob := rawdb.ReadOnlinePruningStatus(p.pruneDB); len(blob) > 0 {
+		status := new(onlineStatus)
+		if err := rlp.DecodeBytes(blob, status); err != nil {
+			return nil, fmt.Errorf("invalid online pruning status: %v", err)
+		}
+		p.status = status
+	}
+	return p, nil
+}
+
+// Interrupted reports whether a round was interrupted by a shutdown and should
+// be resumed.
+func (p *OnlinePruner) Interrupted() bool {
+	p.runLock.Lock()
+	defer p.runLock.Unlock()
+
+	return p.status != nil && !p.status.Paused
+}
+
+// Start starts a new pruning round, or resumes the current one.
+func (p *OnlinePruner) Start() error {
+	p.runLock.Lock()
+	defer p.runLock.Unlock()
+
+	if p.closed {
+		return errPruningClosed
+	}
+	if p.running() {
+		return errPruningRunning
+	}
+	if p.status == nil {
+		p.status = new(onlineStatus)
+		log.Info("Started online state pruning")
+	} else {
+		log.Info("Resumed online state pruning", "nodes", p.status.Nodes, "size", common.StorageSize(p.status.Size))
+	}
+	p.status.Paused = false
+	p.writeStatus()
+
+	p.quit, p.done = make(chan struct{}), make(chan struct{})
+	go p.run(p.quit, p.done)
+	return nil
+}
+
+// Stop pauses the running round. The trie nodes persisted meanwhile keep being
+// recorded, so that the round is resumed without marking again.
+func (p *OnlinePruner) Stop() error {
+	p.runLock.Lock()
+	defer p.runLock.Unlock()
+
+	if !p.running() {
+		return errPruningNotRunning
+	}
+	close(p.quit)
+	<-p.done
+
+	p.status.Paused = true
+	p.writeStatus()
+	if !p.marked {
+		p.release()
+	}
+	log.Info("Paused online state pruning", "nodes", p.status.Nodes, "size", common.StorageSize(p.status.Size))
+	return nil
+}
+
+// Close terminates the running round without pausing it, it's resumed after the
+// restart.
+func (p *OnlinePruner) Close() {
+	p.runLock.Lock()
+	defer p.runLock.Unlock()
+
+	if p.running() {
+		close(p.quit)
+		<-p.done
+	}
+	p.release()
+	p.closed = true
+}
+
+// Running reports whether a round is running.
+func (p *OnlinePruner) Running() bool {
+	p.runLock.Lock()
+	defer p.runLock.Unlock()
+
+	return p.running()
+}
+
+// running reports whether a round is running. The run lock is assumed to be held.
+func (p *OnlinePruner) running() bool {
+	if p.done == nil {
+		return false
+	}
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// writeStatus persists the progress of the current round.
+func (p *OnlinePruner) writeStatus() {
+	blob, err := rlp.EncodeToBytes(p.status)
+	if err != nil {
+		log.Crit("Failed to encode online pruning status", "err", err)
+	}
+	rawdb.WriteOnlinePruningStatus(p.pruneDB, blob)
+}
+
+// record adds a persisted trie node into the bloom filter, it's installed as
+// the flush hook of the trie database.
+func (p *OnlinePruner) record(hash common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.bloom != nil {
+		p.bloom.Put(hash.Bytes(), nil)
+	}
+}
+
+// release stops recording the persisted trie nodes and drops the bloom filter.
+func (p *OnlinePruner) release() {
+	p.chain.TrieDB().SetFlushHook(nil)
+
+	p.lock.Lock()
+	p.bloom = nil
+	p.lock.Unlock()
+
+	p.marked = false
+}
+
+// run executes the remaining stages of the current round.
+func (p *OnlinePruner) run(quit chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	var (
+		err   error
+		start = time.Now()
+	)
+	for {
+		err = p.mark(quit)
+		if err == nil {
+			err = p.wait(quit)
+		}
+		if err == nil {
+			err = p.sweep(quit)
+		}
+		if !errors.Is(err, errTargetReorged) {
+			break
+		}
+		log.Warn("Online pruning target reorged, marking again", "number", p.target, "hash", p.targetHash)
+		p.release()
+	}
+	switch {
+	case errors.Is(err, errPruningAborted):
+		return
+	case err != nil:
+		log.Error("Online state pruning failed", "err", err)
+		p.release()
+		return
+	}
+	p.release()
+	rawdb.DeleteOnlinePruningStatus(p.pruneDB)
+	log.Info("Online state pruning finished", "nodes", p.status.Nodes, "size", common.StorageSize(p.status.Size), "elapsed", common.PrettyDuration(time.Since(start)))
+	p.status = nil
+}
+
+// mark records the trie nodes of the head state and the genesis into a new
+// bloom filter, unless the filter of the round is still complete.
+func (p *OnlinePruner) mark(quit chan struct{}) error {
+	if p.marked {
+		return nil
+	}
+	bloom, err := newStateBloomWithSize(p.config.BloomSize)
+	if err != nil {
+		return err
+	}
+	p.lock.Lock()
+	p.bloom = bloom
+	p.lock.Unlock()
+
+	// Start recording before flushing the target, every node persisted
+	// from now on is kept.
+	if err := p.chain.TrieDB().SetFlushHook(p.record); err != nil {
+		return err
+	}
+	head := p.chain.CurrentBlock()
+	if err := p.chain.TrieDB().Commit(head.Root, false); err != nil {
+		return err
+	}
+	if !rawdb.HasLegacyTrieNode(p.pruneDB, head.Root) {
+		return fmt.Errorf("head state %x is not available", head.Root)
+	}
+	log.Info("Marking state for online pruning", "number", head.Number, "root", head.Root)
+
+	start := time.Now()
+	nodes, err := markState(p.db, head.Root, bloom, quit)
+	if err != nil {
+		return err
+	}
+	if err := extractGenesis(p.db, bloom); err != nil {
+		return err
+	}
+	p.target, p.targetHash, p.marked = head.Number.Uint64(), head.Hash(), true
+	log.Info("Marked state for online pruning", "number", head.Number, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// markState records all trie nodes of the given state into the bloom filter.
+func markState(db ethdb.Database, root common.Hash, bloom *stateBloom, quit chan struct{}) (int, error) {
+	var (
+		nodes  int
+		start  = time.Now()
+		logged = time.Now()
+		tdb    = triedb.NewDatabase(db, triedb.HashDefaults)
+	)
+	mark := func(it trie.NodeIterator) error {
+		// Embedded nodes don't have hash.
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			bloom.Put(hash.Bytes(), nil)
+			nodes++
+		}
+		select {
+		case <-quit:
+			return errPruningAborted
+		default:
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Marking state for online pruning", "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		return nil
+	}
+	t, err := trie.NewStateTrie(trie.StateTrieID(root), tdb)
+	if err != nil {
+		return 0, err
+	}
+	accIter, err := t.NodeIterator(nil)
+	if err != nil {
+		return 0, err
+	}
+	for accIter.Next(true) {
+		if err := mark(accIter); err != nil {
+			return 0, err
+		}
+		if !accIter.Leaf() {
+			continue
+		}
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(accIter.LeafBlob(), &acc); err != nil {
+			return 0, err
+		}
+		if acc.Root == types.EmptyRootHash {
+			continue
+		}
+		id := trie.StorageTrieID(root, common.BytesToHash(accIter.LeafKey()), acc.Root)
+		storageTrie, err := trie.NewStateTrie(id, tdb)
+		if err != nil {
+			return 0, err
+		}
+		storageIter, err := storageTrie.NodeIterator(nil)
+		if err != nil {
+			return 0, err
+		}
+		for storageIter.Next(true) {
+			if err := mark(storageIter); err != nil {
+				return 0, err
+			}
+		}
+		if storageIter.Error() != nil {
+			return 0, storageIter.Error()
+		}
+	}
+	return nodes, accIter.Error()
+}
+
+// reorged reports whether the target block is no longer canonical, the states
+// built upon the new canonical block might not be recorded then.
+func (p *OnlinePruner) reorged() bool {
+	return rawdb.ReadCanonicalHash(p.db, p.target) != p.targetHash
+}
+
+// wait blocks until the chain advances past the in-memory tries of the target.
+func (p *OnlinePruner) wait(quit chan struct{}) error {
+	for {
+		if p.reorged() {
+			return errTargetReorged
+		}
+		if p.chain.CurrentBlock().Number.Uint64() >= p.target+p.chain.TriesInMemory() {
+			return nil
+		}
+		select {
+		case <-time.After(3 * time.Second):
+		case <-quit:
+			return errPruningAborted
+		}
+	}
+}
+
+// sweepEntry is a trie node candidate for deletion.
+type sweepEntry struct {
+	key  []byte
+	size int
+}
+
+// sweep iterates the database from the persisted cursor, deleting the trie
+// nodes not recorded in the bloom filter.
+func (p *OnlinePruner) sweep(quit chan struct{}) error {
+	var (
+		pending []sweepEntry
+		scanned int
+		deleted uint64
+		start   = time.Now()
+		logged  = time.Now()
+		iter    = p.pruneDB.NewIterator(nil, p.status.Cursor)
+	)
+	defer func() { iter.Release() }()
+
+	log.Info("Sweeping stale state for online pruning", "target", p.target)
+	for iter.Next() {
+		select {
+		case <-quit:
+			return errPruningAborted
+		default:
+		}
+		key, value := iter.Key(), iter.Value()
+		scanned++
+		if rawdb.IsLegacyTrieNode(key, value) && !p.bloom.Contain(key) {
+			pending = append(pending, sweepEntry{key: common.CopyBytes(key), size: len(key) + len(value)})
+		}
+		if len(pending) < sweepBatchSize && scanned < sweepCheckpoint {
+			continue
+		}
+		if p.reorged() {
+			return errTargetReorged
+		}
+		cursor := common.CopyBytes(key)
+		n, err := p.delete(pending, cursor)
+		if err != nil {
+			return err
+		}
+		deleted += uint64(n)
+		pending, scanned = pending[:0], 0
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Sweeping stale state for online pruning", "nodes", p.status.Nodes, "size", common.StorageSize(p.status.Size),
+				"cursor", fmt.Sprintf("%#x", cursor), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		// Throttle the deletion to the configured rate
+		if p.config.Rate > 0 {
+			expect := time.Duration(float64(deleted) / float64(p.config.Rate) * float64(time.Second))
+			if wait := expect - time.Since(start); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-quit:
+					return errPruningAborted
+				}
+			}
+		}
+		// Recreate the iterator after every batch commit in order
+		// to allow the underlying compactor to delete the entries.
+		iter.Release()
+		iter = p.pruneDB.NewIterator(nil, cursor)
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if p.reorged() {
+		return errTargetReorged
+	}
+	_, err := p.delete(pending, p.status.Cursor)
+	return err
+}
+
+// delete removes the given trie nodes, except those recorded meanwhile, and
+// persists the sweeping cursor atomically. The lock is held across the write
+// so that a node recorded concurrently is persisted after its deletion.
+func (p *OnlinePruner) delete(entries []sweepEntry, cursor []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	batch := p.pruneDB.NewBatch()
+	var deleted int
+	for _, entry := range entries {
+		if p.bloom.Contain(entry.key) {
+			continue
+		}
+		batch.Delete(entry.key)
+		p.status.Nodes++
+		p.status.Size += uint64(entry.size)
+		deleted++
+	}
+	p.status.Cursor = cursor
+	blob, err := rlp.EncodeToBytes(p.status)
+	if err != nil {
+		return 0, err
+	}
+	rawdb.WriteOnlinePruningStatus(batch, blob)
+	return deleted, batch.Write()
+}
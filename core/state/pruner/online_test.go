@@ -0,0 +1,217 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/consensus/ethash"
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/core/vm"
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/params"
+)
+
+// newPruningChain creates a hash-based chain persisting the state of every block,
+// and returns it along with the blocks yet to be imported.
+func newPruningChain(t *testing.T, imported, pending int) (*core.BlockChain, ethdb.Database, []*types.Block) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), imported+pending, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	config := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	config.TrieDirtyDisabled = true
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks[:imported]); err != nil {
+		t.Fatalf("Failed to import blocks: %v", err)
+	}
+	return chain, db, blocks[imported:]
+}
+
+// checkState traverses the given state, failing on the first missing trie node.
+func checkState(db ethdb.Database, root common.Hash) error {
+	bloom, err := newStateBloomWithSize(1)
+	if err != nil {
+		return err
+	}
+	_, err = markState(db, root, bloom, make(chan struct{}))
+	return err
+}
+
+func waitPruning(t *testing.T, p *OnlinePruner) {
+	p.runLock.Lock()
+	done := p.done
+	p.runLock.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("Pruning didn't finish")
+	}
+}
+
+func TestOnlinePruning(t *testing.T) {
+	chain, db, pending := newPruningChain(t, 32, int(core.DefaultCacheConfigWithScheme(rawdb.HashScheme).TriesInMemory)+8)
+	defer chain.Stop()
+
+	target := chain.CurrentBlock()
+	p, err := NewOnlinePruner(chain, db, OnlineConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	if err := p.Start(); err != nil {
+		t.Fatalf("Failed to start pruning: %v", err)
+	}
+	if err := p.Start(); err != errPruningRunning {
+		t.Fatalf("Unexpected error starting twice: have %v, want %v", err, errPruningRunning)
+	}
+	// The sweeping waits for the chain to advance past the target
+	if _, err := chain.InsertChain(pending); err != nil {
+		t.Fatalf("Failed to import blocks: %v", err)
+	}
+	waitPruning(t, p)
+
+	if p.status != nil || len(rawdb.ReadOnlinePruningStatus(db)) != 0 {
+		t.Fatal("Pruning status not cleaned up")
+	}
+	// The states before the target must be gone, except the genesis
+	for n := uint64(1); n < target.Number.Uint64(); n++ {
+		if rawdb.HasLegacyTrieNode(db, chain.GetBlockByNumber(n).Root()) {
+			t.Fatalf("Stale state of block %d not pruned", n)
+		}
+	}
+	if err := checkState(db, chain.Genesis().Root()); err != nil {
+		t.Fatalf("Genesis state is incomplete: %v", err)
+	}
+	// The target and all states after it must be complete
+	for n := target.Number.Uint64(); n <= chain.CurrentBlock().Number.Uint64(); n++ {
+		root := chain.GetBlockByNumber(n).Root()
+		if err := checkState(db, root); err != nil {
+			t.Fatalf("State of block %d is incomplete: %v", n, err)
+		}
+	}
+}
+
+func TestOnlinePruningResume(t *testing.T) {
+	chain, db, pending := newPruningChain(t, 32, int(core.DefaultCacheConfigWithScheme(rawdb.HashScheme).TriesInMemory)+8)
+	defer chain.Stop()
+
+	p, err := NewOnlinePruner(chain, db, OnlineConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	if err := p.Stop(); err != errPruningNotRunning {
+		t.Fatalf("Unexpected error stopping idle pruner: have %v, want %v", err, errPruningNotRunning)
+	}
+	if err := p.Start(); err != nil {
+		t.Fatalf("Failed to start pruning: %v", err)
+	}
+	// A paused round is not resumed after a restart
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Failed to stop pruning: %v", err)
+	}
+	p.Close()
+	if p, err = NewOnlinePruner(chain, db, OnlineConfig{}); err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	if p.Interrupted() {
+		t.Fatal("Paused pruning reported as interrupted")
+	}
+	// A round terminated by the shutdown is resumed after the restart
+	if err := p.Start(); err != nil {
+		t.Fatalf("Failed to resume pruning: %v", err)
+	}
+	p.Close()
+	if p, err = NewOnlinePruner(chain, db, OnlineConfig{}); err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	if !p.Interrupted() {
+		t.Fatal("Terminated pruning not reported as interrupted")
+	}
+	if err := p.Start(); err != nil {
+		t.Fatalf("Failed to resume pruning: %v", err)
+	}
+	if _, err := chain.InsertChain(pending); err != nil {
+		t.Fatalf("Failed to import blocks: %v", err)
+	}
+	waitPruning(t, p)
+
+	if len(rawdb.ReadOnlinePruningStatus(db)) != 0 {
+		t.Fatal("Pruning status not cleaned up")
+	}
+	if err := checkState(db, chain.CurrentBlock().Root); err != nil {
+		t.Fatalf("Head state is incomplete: %v", err)
+	}
+}
+
+func TestOnlinePruningReorg(t *testing.T) {
+	chain, db, _ := newPruningChain(t, 32, 0)
+	defer chain.Stop()
+
+	p, err := NewOnlinePruner(chain, db, OnlineConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	defer p.release()
+
+	quit := make(chan struct{})
+	if err := p.mark(quit); err != nil {
+		t.Fatalf("Failed to mark state: %v", err)
+	}
+	if p.reorged() {
+		t.Fatal("Canonical target reported as reorged")
+	}
+	// Reorg the target out with a longer fork from its parent
+	parent := chain.GetBlockByNumber(p.target - 1)
+	fork, _ := core.GenerateChain(chain.Config(), parent, ethash.NewFaker(), db, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("Failed to import fork: %v", err)
+	}
+	if err := p.wait(quit); err != errTargetReorged {
+		t.Fatalf("Unexpected waiting error: have %v, want %v", err, errTargetReorged)
+	}
+	// The new head is marked afterwards
+	p.release()
+	if err := p.mark(quit); err != nil {
+		t.Fatalf("Failed to mark state: %v", err)
+	}
+	if head := chain.CurrentBlock(); p.reorged() || p.targetHash != head.Hash() {
+		t.Fatalf("Target mismatch: have %x, want %x", p.targetHash, head.Hash())
+	}
+}
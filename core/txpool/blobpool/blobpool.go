@@ -326,6 +326,8 @@ type BlobPool struct {
 	// overridden for testing purposes.
 	txValidationFn txpool.ValidationFunction
 
+	policy *txpool.Policy // Admission rules shared with the other subpools, nil to admit all
+
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
 }
 
@@ -347,6 +349,12 @@ func New(config Config, chain BlockChain) *BlobPool {
 	}
 }
 
+// SetPolicy sets the admission rules applied to the new transactions. It must
+// be called before the pool is initialized.
+func (p *BlobPool) SetPolicy(policy *txpool.Policy) {
+	p.policy = policy
+}
+
 // Filter returns whether the given transaction can be consumed by the blob pool.
 func (p *BlobPool) Filter(tx *types.Transaction) bool {
 	return tx.Type() == types.BlobTxType
@@ -1341,6 +1349,19 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 	// If the address is not yet known, request exclusivity to track the account
 	// only by this subpool until all transactions are evicted
 	from, _ := types.Sender(p.signer, tx) // already validated above
+	reservation, err := p.policy.Reserve(from, tx, nil)
+	if err != nil {
+		log.Trace("Transaction rejected by policy", "hash", tx.Hash(), "err", err)
+		return err
+	}
+	defer func() {
+		// Spend the rate limit of the sender only if the transaction is pooled
+		if err != nil {
+			reservation.Cancel()
+		} else {
+			reservation.Commit()
+		}
+	}()
 	if _, ok := p.index[from]; !ok {
 		if err := p.reserve(from, true); err != nil {
 			addNonExclusiveMeter.Mark(1)
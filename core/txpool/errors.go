@@ -59,4 +59,16 @@ var (
 
 	// ErrInBlackList is returned if the transaction send by banned address
 	ErrInBlackList = errors.New("sender or to in black list")
+
+	// ErrPolicyDenied is returned if the sender or the destination of the
+	// transaction is in the deny list of the admission policy.
+	ErrPolicyDenied = errors.New("denied by txpool policy")
+
+	// ErrPolicyRateLimited is returned if the sender exceeded the transaction
+	// rate allowed by the admission policy.
+	ErrPolicyRateLimited = errors.New("sender rate limit exceeded")
+
+	// ErrPolicyQuotaExceeded is returned if the destination of the transaction
+	// already has as many pooled transactions as the admission policy allows.
+	ErrPolicyQuotaExceeded = errors.New("destination quota exceeded")
 )
@@ -0,0 +1,289 @@
+package txpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/lru"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/event"
+	"github.com/Ezkerrox/bsc/metrics"
+)
+
+const (
+	// historyTxs is the number of transactions whose history is retained, the
+	// least recently active ones are forgotten first.
+	historyTxs = 32768
+
+	// historyEvents is the number of events retained per transaction, the
+	// oldest ones after the first are forgotten first.
+	historyEvents = 16
+
+	// historyQueue is the number of events waiting to be dispatched to the
+	// subscribers, the new ones are dropped when full.
+	historyQueue = 4096
+)
+
+var historyDroppedMeter = metrics.NewRegisteredMeter("txpool/history/dropped", nil)
+
+// TxEventKind is the kind of a transaction lifecycle event.
+type TxEventKind string
+
+const (
+	TxEventReceived TxEventKind = "received" // Received from a peer
+	TxEventRejected TxEventKind = "rejected" // Refused by the pool
+	TxEventQueued   TxEventKind = "queued"   // Added to the non-executable queue
+	TxEventPending  TxEventKind = "pending"  // Added to the executable set
+	TxEventOverflow TxEventKind = "overflow" // Moved to the overflow pool
+	TxEventReplaced TxEventKind = "replaced" // Replaced by a transaction with the same nonce
+	TxEventDropped  TxEventKind = "dropped"  // Evicted from the pool
+	TxEventSkipped  TxEventKind = "skipped"  // Left out of a block by the local miner
+	TxEventIncluded TxEventKind = "included" // Included in a block
+)
+
+// TxEvent is a step in the lifecycle of a transaction.
+type TxEvent struct {
+	Hash        common.Hash
+	Kind        TxEventKind
+	Time        time.Time
+	Peer        string      // Peer the transaction was received from
+	Replacement common.Hash // Transaction replacing this one
+	Reason      string      // Why the transaction was rejected, dropped or skipped
+	BlockNumber uint64      // Block the transaction was included in
+	BlockHash   common.Hash
+}
+
+// History records the lifecycle events of the recently seen transactions, from
+// their reception to their inclusion or eviction. A nil history records nothing.
+//
+// Only the watched transactions are tracked, or all of them if enabled. The
+// events are dispatched to the subscribers in the background, so that a slow
+// subscriber never stalls the pool.
+type History struct {
+	txs     lru.BasicLRU[common.Hash, []*TxEvent]
+	watched map[common.Hash]int // Number of watchers of the transactions
+	all     atomic.Bool         // Whether all the transactions are tracked
+	lock    sync.Mutex
+
+	feed   event.Feed
+	queue  chan TxEvent  // Events waiting to be dispatched to the subscribers
+	closed chan struct{} // Channel closed to stop the dispatching
+	once   sync.Once
+}
+
+// NewHistory creates a history retaining the events of the given number of
+// transactions.
+func NewHistory(size int) *History {
+	h := &History{
+		txs:     lru.NewBasicLRU[common.Hash, []*TxEvent](size),
+		watched: make(map[common.Hash]int),
+		queue:   make(chan TxEvent, historyQueue),
+		closed:  make(chan struct{}),
+	}
+	go h.loop()
+	return h
+}
+
+// TrackAll sets whether all the transactions are tracked, or only the watched
+// ones.
+func (h *History) TrackAll(all bool) {
+	if h == nil {
+		return
+	}
+	h.all.Store(all)
+}
+
+// TracksAll reports whether all the transactions are tracked.
+func (h *History) TracksAll() bool {
+	if h == nil {
+		return false
+	}
+	return h.all.Load()
+}
+
+// Watch starts tracking the given transactions, until unwatched.
+func (h *History) Watch(hashes []common.Hash) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, hash := range hashes {
+		h.watched[hash]++
+	}
+}
+
+// Unwatch stops tracking the given transactions, unless watched elsewhere or
+// all the transactions are tracked. Their recorded events are retained.
+func (h *History) Unwatch(hashes []common.Hash) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, hash := range hashes {
+		if h.watched[hash] <= 1 {
+			delete(h.watched, hash)
+		} else {
+			h.watched[hash]--
+		}
+	}
+}
+
+// Close stops dispatching the events to the subscribers.
+func (h *History) Close() {
+	if h == nil {
+		return
+	}
+	h.once.Do(func() { close(h.closed) })
+}
+
+// loop dispatches the recorded events to the subscribers.
+func (h *History) loop() {
+	for {
+		select {
+		case event := <-h.queue:
+			h.feed.Send(event)
+		case <-h.closed:
+			return
+		}
+	}
+}
+
+// Get returns the recorded events of the transaction, oldest first.
+func (h *History) Get(hash common.Hash) []TxEvent {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	events, _ := h.txs.Peek(hash)
+	list := make([]TxEvent, 0, len(events))
+	for _, event := range events {
+		list = append(list, *event)
+	}
+	return list
+}
+
+// SubscribeEvents subscribes to the events recorded for any transaction. The
+// subscription to a nil history never delivers any event.
+func (h *History) SubscribeEvents(ch chan<- TxEvent) event.Subscription {
+	if h == nil {
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			<-quit
+			return nil
+		})
+	}
+	return h.feed.Subscribe(ch)
+}
+
+// Received records the transactions received from a peer. Only the first peer
+// delivering a transaction is recorded.
+func (h *History) Received(peer string, txs []*types.Transaction) {
+	if h == nil {
+		return
+	}
+	for _, tx := range txs {
+		h.record(&TxEvent{Hash: tx.Hash(), Kind: TxEventReceived, Peer: peer}, true, true)
+	}
+}
+
+// Rejected records a transaction refused by the pool.
+func (h *History) Rejected(hash common.Hash, err error) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventRejected, Reason: err.Error()}, true, false)
+}
+
+// Queued records a transaction added to the non-executable queue.
+func (h *History) Queued(hash common.Hash) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventQueued}, true, false)
+}
+
+// Pending records a transaction added to the executable set.
+func (h *History) Pending(hash common.Hash) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventPending}, true, false)
+}
+
+// Overflow records a transaction moved to the overflow pool.
+func (h *History) Overflow(hash common.Hash) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventOverflow}, false, false)
+}
+
+// Replaced records a transaction replaced by another with the same nonce.
+func (h *History) Replaced(hash common.Hash, replacement common.Hash) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventReplaced, Replacement: replacement}, false, false)
+}
+
+// Dropped records a transaction evicted from the pool.
+func (h *History) Dropped(hash common.Hash, reason string) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventDropped, Reason: reason}, false, false)
+}
+
+// Skipped records a transaction the miner failed to include in a block.
+func (h *History) Skipped(hash common.Hash, err error) {
+	h.record(&TxEvent{Hash: hash, Kind: TxEventSkipped, Reason: err.Error()}, false, false)
+}
+
+// Empty reports whether no transaction is tracked or watched.
+func (h *History) Empty() bool {
+	if h == nil {
+		return true
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.txs.Len() == 0 && len(h.watched) == 0
+}
+
+// Included records the inclusion of the tracked transactions of the block.
+func (h *History) Included(block *types.Block) {
+	if h == nil {
+		return
+	}
+	for _, tx := range block.Transactions() {
+		h.record(&TxEvent{Hash: tx.Hash(), Kind: TxEventIncluded, BlockNumber: block.NumberU64(), BlockHash: block.Hash()}, false, false)
+	}
+}
+
+// record appends the event to the history of its transaction. Untracked
+// transactions start being tracked on any event if they are watched, or if track
+// is set and all the transactions are tracked. The event is dropped if it repeats
+// the last one, or if first is set and the transaction is already tracked.
+func (h *History) record(event *TxEvent, track bool, first bool) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	events, ok := h.txs.Get(event.Hash)
+	if !ok {
+		_, watched := h.watched[event.Hash]
+		track = watched || (track && h.all.Load())
+	}
+	switch {
+	case !ok && !track, ok && first:
+		h.lock.Unlock()
+		return
+	case ok:
+		last := events[len(events)-1]
+		if last.Kind == event.Kind && last.Reason == event.Reason && last.BlockHash == event.BlockHash {
+			h.lock.Unlock()
+			return
+		}
+	}
+	event.Time = time.Now()
+	if len(events) >= historyEvents {
+		events = append(events[:1], events[2:]...)
+	}
+	h.txs.Add(event.Hash, append(events, event))
+	h.lock.Unlock()
+
+	select {
+	case h.queue <- *event:
+	default:
+		historyDroppedMeter.Mark(1)
+	}
+}
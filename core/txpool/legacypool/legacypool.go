@@ -125,6 +125,12 @@ var (
 	slotsGauge        = metrics.NewRegisteredGauge("txpool/slots", nil)
 	OverflowPoolGauge = metrics.NewRegisteredGauge("txpool/overflowpool", nil)
 
+	// Metrics for the overflow pool
+	overflowAddedMeter      = metrics.NewRegisteredMeter("txpool/overflowpool/added", nil)
+	overflowEvictedMeter    = metrics.NewRegisteredMeter("txpool/overflowpool/evicted", nil)    // Dropped to make room for newer ones
+	overflowReinjectedMeter = metrics.NewRegisteredMeter("txpool/overflowpool/reinjected", nil) // Moved back into the main pool
+	overflowRejectedMeter   = metrics.NewRegisteredMeter("txpool/overflowpool/rejected", nil)   // Refused by the main pool on re-injection
+
 	reheapTimer = metrics.NewRegisteredTimer("txpool/reheap", nil)
 )
 
@@ -154,14 +160,19 @@ type Config struct {
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
-	AccountSlots      uint64 // Number of executable transaction slots guaranteed per account
-	GlobalSlots       uint64 // Maximum number of executable transaction slots for all accounts
-	AccountQueue      uint64 // Maximum number of non-executable transaction slots permitted per account
-	GlobalQueue       uint64 // Maximum number of non-executable transaction slots for all accounts
-	OverflowPoolSlots uint64 // Maximum number of transaction slots in overflow pool
+	AccountSlots       uint64         // Number of executable transaction slots guaranteed per account
+	GlobalSlots        uint64         // Maximum number of executable transaction slots for all accounts
+	AccountQueue       uint64         // Maximum number of non-executable transaction slots permitted per account
+	GlobalQueue        uint64         // Maximum number of non-executable transaction slots for all accounts
+	OverflowPoolSlots  uint64         // Maximum number of transaction slots in overflow pool
+	OverflowPoolPolicy OverflowPolicy // Order the overflow pool transactions are re-injected in (age, price or fairness)
 
 	Lifetime       time.Duration // Maximum amount of time non-executable transaction are queued
 	ReannounceTime time.Duration // Duration for announcing local pending transactions again
+
+	Policy txpool.PolicyConfig // Sender and destination admission rules, replaceable at runtime
+
+	History bool // Whether to record the lifecycle events of all the transactions, only the watched ones otherwise
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -172,11 +183,12 @@ var DefaultConfig = Config{
 	PriceLimit: 1,
 	PriceBump:  10,
 
-	AccountSlots:      200,
-	GlobalSlots:       8000,
-	AccountQueue:      200,
-	GlobalQueue:       4000,
-	OverflowPoolSlots: 0,
+	AccountSlots:       200,
+	GlobalSlots:        8000,
+	AccountQueue:       200,
+	GlobalQueue:        4000,
+	OverflowPoolSlots:  0,
+	OverflowPoolPolicy: OverflowPolicyAge,
 
 	Lifetime:       10 * time.Minute,
 	ReannounceTime: 10 * 365 * 24 * time.Hour,
@@ -210,6 +222,12 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool global queue", "provided", conf.GlobalQueue, "updated", DefaultConfig.GlobalQueue)
 		conf.GlobalQueue = DefaultConfig.GlobalQueue
 	}
+	if !conf.OverflowPoolPolicy.valid() {
+		if conf.OverflowPoolPolicy != "" {
+			log.Warn("Sanitizing invalid txpool overflow pool policy", "provided", conf.OverflowPoolPolicy, "updated", DefaultConfig.OverflowPoolPolicy)
+		}
+		conf.OverflowPoolPolicy = DefaultConfig.OverflowPoolPolicy
+	}
 	if conf.Lifetime < 1 {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
@@ -266,6 +284,8 @@ type LegacyPool struct {
 	priced  *pricedList                  // All transactions sorted by price
 
 	localBufferPool *TxOverflowPool // Local buffer transactions
+	policy          *txpool.Policy  // Admission rules applied to the new transactions
+	history         *txpool.History // Lifecycle events of the transactions, nil if not recorded
 
 	reqResetCh      chan *txpoolResetRequest
 	reqPromoteCh    chan *accountSet
@@ -305,6 +325,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		reorgShutdownCh: make(chan struct{}),
 		initDoneCh:      make(chan struct{}),
 		localBufferPool: NewTxOverflowPoolHeap(config.OverflowPoolSlots),
+		policy:          txpool.NewPolicy(config.Policy),
 	}
 	pool.priced = newPricedList(pool.all)
 
@@ -400,6 +421,7 @@ func (pool *LegacyPool) loop() {
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
+						pool.history.Dropped(tx.Hash(), "lifetime exceeded")
 						pool.removeTx(tx.Hash(), true, true)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
@@ -482,6 +504,7 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		// pool.priced is sorted by GasFeeCap, so we have to iterate through pool.all instead
 		drop := pool.all.TxsBelowTip(tip)
 		for _, tx := range drop {
+			pool.history.Dropped(tx.Hash(), "tip below minimum")
 			pool.removeTx(tx.Hash(), false, true)
 		}
 		pool.priced.Removed(len(drop))
@@ -518,6 +541,67 @@ func (pool *LegacyPool) statsOverflowPool() uint64 {
 	return pool.localBufferPool.Size()
 }
 
+// Policy returns the admission rules applied to the new transactions, which
+// can be replaced at runtime.
+func (pool *LegacyPool) Policy() *txpool.Policy {
+	return pool.policy
+}
+
+// SetHistory sets the history recording the lifecycle events of the pooled
+// transactions. It must be called before the pool is initialized.
+func (pool *LegacyPool) SetHistory(history *txpool.History) {
+	pool.history = history
+}
+
+// OverflowContent retrieves the transactions buffered in the overflow pool,
+// grouped by account and sorted by nonce. Only the most recently buffered
+// transaction is kept for each account and nonce, as in the pool content.
+func (pool *LegacyPool) OverflowContent() map[common.Address][]*OverflowTx {
+	return pool.overflowContent(func(common.Address) bool { return true })
+}
+
+// OverflowContentFrom retrieves the transactions of the given account buffered
+// in the overflow pool, sorted by nonce.
+func (pool *LegacyPool) OverflowContentFrom(addr common.Address) []*OverflowTx {
+	return pool.overflowContent(func(from common.Address) bool { return from == addr })[addr]
+}
+
+// overflowContent groups the buffered transactions of the matching accounts by
+// account and nonce.
+func (pool *LegacyPool) overflowContent(match func(common.Address) bool) map[common.Address][]*OverflowTx {
+	nonces := make(map[common.Address]map[uint64]*OverflowTx)
+	for _, otx := range pool.localBufferPool.Transactions() {
+		from, _ := types.Sender(pool.signer, otx.Tx)
+		if !match(from) {
+			continue
+		}
+		if nonces[from] == nil {
+			nonces[from] = make(map[uint64]*OverflowTx)
+		}
+		// The transactions are listed oldest first, a later one with the same
+		// nonce replaces the previous one.
+		nonces[from][otx.Tx.Nonce()] = otx
+	}
+	content := make(map[common.Address][]*OverflowTx, len(nonces))
+	for from, txs := range nonces {
+		list := make([]*OverflowTx, 0, len(txs))
+		for _, otx := range txs {
+			list = append(list, otx)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Tx.Nonce() < list[j].Tx.Nonce() })
+		content[from] = list
+	}
+	return content
+}
+
+// OverflowStats retrieves the content of the overflow pool and the counters of
+// the transactions which moved through it.
+func (pool *LegacyPool) OverflowStats() OverflowPoolStats {
+	stats := pool.localBufferPool.Stats()
+	stats.Policy = pool.config.OverflowPoolPolicy
+	return stats
+}
+
 // stats retrieves the current pool stats, namely the number of pending and the
 // number of queued (non-executable) transactions.
 func (pool *LegacyPool) stats() (int, int) {
@@ -861,10 +945,12 @@ func (pool *LegacyPool) add(tx *types.Transaction) (replaced bool, err error) {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.history.Replaced(old.Hash(), hash)
 		}
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
 		pool.queueTxEvent(tx)
+		pool.history.Pending(hash)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// Successful promotion, bump the heartbeat
@@ -887,8 +973,10 @@ func (pool *LegacyPool) addToOverflowPool(drop types.Transactions) {
 		if added {
 			from, _ := types.Sender(pool.signer, tx)
 			log.Debug("Added to OverflowPool", "transaction", tx.Hash().String(), "from", from.String())
+			pool.history.Overflow(tx.Hash())
 		} else {
 			log.Debug("Failed to add transaction to OverflowPool", "transaction", tx.Hash().String())
+			pool.history.Dropped(tx.Hash(), "underpriced")
 		}
 	}
 }
@@ -937,6 +1025,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, addAl
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.history.Replaced(old.Hash(), hash)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -954,6 +1043,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, addAl
 	if _, exist := pool.beats[from]; !exist {
 		pool.beats[from] = time.Now()
 	}
+	pool.history.Queued(hash)
 	return old != nil, nil
 }
 
@@ -974,6 +1064,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.history.Dropped(hash, "replacement underpriced")
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -981,12 +1072,14 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.history.Replaced(old.Hash(), hash)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
 	}
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
 	pool.pendingNonces.set(addr, tx.Nonce()+1)
+	pool.history.Pending(hash)
 
 	// Successful promotion, bump the heartbeat
 	pool.beats[addr] = time.Now()
@@ -1023,10 +1116,17 @@ func (pool *LegacyPool) addRemoteSync(tx *types.Transaction) error {
 // If sync is set, the method will block until all internal maintenance related
 // to the add is finished. Only use this during tests for determinism!
 func (pool *LegacyPool) Add(txs []*types.Transaction, sync bool) []error {
+	return pool.addTxs(txs, sync, true)
+}
+
+// addTxs enqueues a batch of transactions into the pool if they are valid,
+// applying the admission policy to them if requested.
+func (pool *LegacyPool) addTxs(txs []*types.Transaction, sync bool, policy bool) []error {
 	// Filter out known ones without obtaining the pool lock or recovering signatures
 	var (
-		errs = make([]error, len(txs))
-		news = make([]*types.Transaction, 0, len(txs))
+		errs         = make([]error, len(txs))
+		news         = make([]*types.Transaction, 0, len(txs))
+		reservations = make([]*txpool.PolicyReservation, 0, len(txs))
 	)
 	for i, tx := range txs {
 		// If the transaction is known, pre-set the error slot
@@ -1042,10 +1142,26 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, sync bool) []error {
 			errs[i] = err
 			log.Trace("Discarding invalid transaction", "hash", tx.Hash(), "err", err)
 			invalidTxMeter.Mark(1)
+			pool.history.Rejected(tx.Hash(), err)
 			continue
 		}
+		// Apply the admission policy, the sender is cached by now. The rate limit
+		// and the quota are held per transaction, so that a batch can't exceed them.
+		var reservation *txpool.PolicyReservation
+		if policy {
+			from, _ := types.Sender(pool.signer, tx)
+			res, err := pool.policy.Reserve(from, tx, pool.all.destinationCount)
+			if err != nil {
+				errs[i] = err
+				log.Trace("Discarding transaction rejected by policy", "hash", tx.Hash(), "err", err)
+				pool.history.Rejected(tx.Hash(), err)
+				continue
+			}
+			reservation = res
+		}
 		// Accumulate all unknown transactions for deeper processing
 		news = append(news, tx)
+		reservations = append(reservations, reservation)
 	}
 	if len(news) == 0 {
 		return errs
@@ -1056,6 +1172,14 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, sync bool) []error {
 	newErrs, dirtyAddrs := pool.addTxsLocked(news)
 	pool.mu.Unlock()
 
+	// Spend the rate limit of the senders of the pooled transactions only
+	for i, reservation := range reservations {
+		if newErrs[i] == nil {
+			reservation.Commit()
+		} else {
+			reservation.Cancel()
+		}
+	}
 	var nilSlot = 0
 	for _, err := range newErrs {
 		for errs[nilSlot] != nil {
@@ -1083,6 +1207,9 @@ func (pool *LegacyPool) addTxsLocked(txs []*types.Transaction) ([]error, *accoun
 		if err == nil && !replaced {
 			dirty.addTx(tx)
 		}
+		if err != nil && !errors.Is(err, txpool.ErrAlreadyKnown) {
+			pool.history.Rejected(tx.Hash(), err)
+		}
 	}
 	validTxMeter.Mark(int64(len(dirty.accounts)))
 	return errs, dirty
@@ -1391,6 +1518,8 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 // reset retrieves the current state of the blockchain and ensures the content
 // of the transaction pool is valid with regard to the chain state.
 func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
+	pool.recordIncluded(oldHead, newHead)
+
 	// If we're reorging an old state, reinject all dropped transactions
 	var reinject types.Transactions
 
@@ -1488,6 +1617,22 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	pool.addTxsLocked(reinject)
 }
 
+// recordIncluded records the inclusion of the tracked transactions in the blocks
+// on top of the old head, up to the new one.
+func (pool *LegacyPool) recordIncluded(oldHead, newHead *types.Header) {
+	if pool.history.Empty() || oldHead == nil || newHead == nil {
+		return
+	}
+	var (
+		number = oldHead.Number.Uint64()
+		block  = pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64())
+	)
+	for depth := 0; block != nil && block.NumberU64() > number && depth < 64; depth++ {
+		pool.history.Included(block)
+		block = pool.chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	}
+}
+
 // promoteExecutables moves transactions that have become processable from the
 // future queue to the set of pending transactions. During this process, all
 // invalidated transactions (low nonce, low balance) are deleted.
@@ -1512,6 +1657,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
 		for _, tx := range drops {
 			pool.all.Remove(tx.Hash())
+			pool.history.Dropped(tx.Hash(), "unpayable")
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
@@ -1532,6 +1678,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range caps {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.history.Dropped(hash, "account queue limit exceeded")
 			log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 		}
 		queuedRateLimitMeter.Mark(int64(len(caps)))
@@ -1594,6 +1741,7 @@ func (pool *LegacyPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
+						pool.history.Dropped(hash, "pending limit exceeded")
 
 						// Update the account nonce to the dropped transaction
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
@@ -1619,6 +1767,7 @@ func (pool *LegacyPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					pool.all.Remove(hash)
+					pool.history.Dropped(hash, "pending limit exceeded")
 
 					// Update the account nonce to the dropped transaction
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1660,6 +1809,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
+				pool.history.Dropped(tx.Hash(), "queue limit exceeded")
 				pool.removeTx(tx.Hash(), true, true)
 			}
 			drop -= size
@@ -1669,6 +1819,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+			pool.history.Dropped(txs[i].Hash(), "queue limit exceeded")
 			pool.removeTx(txs[i].Hash(), true, true)
 			drop--
 			queuedRateLimitMeter.Mark(1)
@@ -1701,6 +1852,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.history.Dropped(hash, "unpayable")
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
@@ -1820,6 +1972,7 @@ type lookup struct {
 	txs   map[common.Hash]*types.Transaction
 
 	auths map[common.Address][]common.Hash // All accounts with a pooled authorization
+	dests map[common.Address]int           // Number of pooled transactions per destination
 }
 
 // newLookup returns a new lookup structure.
@@ -1827,6 +1980,7 @@ func newLookup() *lookup {
 	return &lookup{
 		txs:   make(map[common.Hash]*types.Transaction),
 		auths: make(map[common.Address][]common.Hash),
+		dests: make(map[common.Address]int),
 	}
 }
 
@@ -1878,6 +2032,9 @@ func (t *lookup) Add(tx *types.Transaction) {
 
 	t.txs[tx.Hash()] = tx
 	t.addAuthorities(tx)
+	if to := tx.To(); to != nil {
+		t.dests[*to]++
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1894,6 +2051,11 @@ func (t *lookup) Remove(hash common.Hash) {
 	t.slots -= numSlots(tx)
 	slotsGauge.Update(int64(t.slots))
 
+	if to := tx.To(); to != nil {
+		if t.dests[*to]--; t.dests[*to] <= 0 {
+			delete(t.dests, *to)
+		}
+	}
 	delete(t.txs, hash)
 }
 
@@ -1947,6 +2109,14 @@ func (t *lookup) removeAuthorities(tx *types.Transaction) {
 	}
 }
 
+// destinationCount returns the number of pooled transactions sent to the
+// specified address.
+func (t *lookup) destinationCount(addr common.Address) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.dests[addr]
+}
+
 // delegationTxsCount returns the number of pending authorizations for the specified address.
 func (t *lookup) delegationTxsCount(addr common.Address) int {
 	t.lock.RLock()
@@ -1976,12 +2146,27 @@ func (pool *LegacyPool) transferTransactions() {
 	extraSlots := maxMainPoolSize - currentMainPoolSize
 	extraTransactions := (extraSlots + 3) / 4 // Since a transaction can take up to 4 slots
 	log.Debug("Will attempt to transfer from OverflowPool to MainPool", "transactions", extraTransactions)
-	txs := pool.localBufferPool.Flush(extraTransactions)
+	txs := pool.localBufferPool.FlushBy(extraTransactions, pool.config.OverflowPoolPolicy, func(tx *types.Transaction) common.Address {
+		from, _ := types.Sender(pool.signer, tx)
+		return from
+	})
 	if len(txs) == 0 {
 		return
 	}
 
-	pool.Add(txs, false)
+	// The transactions were admitted by the policy when first added. The ones
+	// already known to the main pool are neither reinjected nor rejected.
+	var reinjected, rejected int
+	for _, err := range pool.addTxs(txs, false, false) {
+		switch {
+		case err == nil:
+			reinjected++
+		case errors.Is(err, txpool.ErrAlreadyKnown):
+		default:
+			rejected++
+		}
+	}
+	pool.localBufferPool.markReinjected(reinjected, rejected)
 }
 
 func (pool *LegacyPool) PrintTxStats() {
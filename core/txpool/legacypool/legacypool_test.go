@@ -21,6 +21,7 @@ import (
 	crand "crypto/rand"
 	"errors"
 	"fmt"
+	"maps"
 	"math/big"
 	"math/rand"
 	"slices"
@@ -257,6 +258,16 @@ func validatePoolInternals(pool *LegacyPool) error {
 			}
 		}
 	}
+	// Ensure the pooled transactions per destination are tracked
+	dests := make(map[common.Address]int)
+	for _, tx := range pool.all.txs {
+		if to := tx.To(); to != nil {
+			dests[*to]++
+		}
+	}
+	if !maps.Equal(dests, pool.all.dests) {
+		return fmt.Errorf("destination count mismatch: have %v, want %v", pool.all.dests, dests)
+	}
 	return nil
 }
 
@@ -2094,6 +2105,360 @@ func TestTransferTransactions(t *testing.T) {
 	assert.Equal(t, 1, pending, "pending transactions mismatched")
 	assert.Equal(t, 0, queue, "queued transactions mismatched")
 	assert.Equal(t, uint64(1), pool.statsOverflowPool(), "OverflowPool size unexpected")
+
+	from3, _ := types.Sender(pool.signer, tx3)
+	content := pool.OverflowContent()
+	assert.Equal(t, 1, len(content), "OverflowPool accounts mismatched")
+	assert.Equal(t, tx3.Hash(), content[from3][0].Tx.Hash(), "OverflowPool content mismatched")
+	assert.Equal(t, 1, len(pool.OverflowContentFrom(from3)), "OverflowPool account content mismatched")
+	assert.Equal(t, 0, len(pool.OverflowContentFrom(from)), "OverflowPool account content mismatched")
+
+	stats := pool.OverflowStats()
+	assert.Equal(t, uint64(2), stats.Added, "OverflowPool added transactions mismatched") // tx2 didn't fit
+	assert.Equal(t, uint64(0), stats.Evicted, "OverflowPool evicted transactions mismatched")
+	assert.Equal(t, uint64(1), stats.Reinjected, "OverflowPool reinjected transactions mismatched")
+	assert.Equal(t, OverflowPolicyAge, stats.Policy, "OverflowPool policy mismatched")
+}
+
+// Tests that the overflow pool content is grouped by account and nonce, listing
+// only the most recently buffered transaction of a nonce.
+func TestOverflowContent(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+	pool.localBufferPool = NewTxOverflowPoolHeap(10)
+
+	var (
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		tx0      = dynamicFeeTx(0, 100000, big.NewInt(3), big.NewInt(2), key)
+		tx0Bump  = dynamicFeeTx(0, 100000, big.NewInt(5), big.NewInt(4), key)
+		tx1      = dynamicFeeTx(1, 100000, big.NewInt(3), big.NewInt(2), key)
+		other, _ = crypto.GenerateKey()
+		otherTx  = dynamicFeeTx(0, 100000, big.NewInt(3), big.NewInt(2), other)
+	)
+	for _, tx := range []*types.Transaction{tx1, tx0, tx0Bump, otherTx} {
+		pool.localBufferPool.Add(tx)
+		time.Sleep(time.Millisecond)
+	}
+	content := pool.OverflowContent()
+	assert.Equal(t, 2, len(content), "OverflowPool accounts mismatched")
+	txs := content[from]
+	assert.Equal(t, 2, len(txs), "OverflowPool account content mismatched")
+	assert.Equal(t, tx0Bump.Hash(), txs[0].Tx.Hash(), "OverflowPool replacement not listed")
+	assert.Equal(t, tx1.Hash(), txs[1].Tx.Hash(), "OverflowPool content not sorted by nonce")
+
+	assert.Equal(t, 2, len(pool.OverflowContentFrom(from)), "OverflowPool account content mismatched")
+	assert.Equal(t, 0, len(pool.OverflowContentFrom(common.Address{})), "OverflowPool account content mismatched")
+}
+
+// Tests that the admission policy rejects the transactions of denied accounts,
+// and the ones over the destination quota or the sender rate, with the reason.
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	pool.Policy().SetConfig(txpool.PolicyConfig{Deny: []common.Address{from}})
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); !errors.Is(err, txpool.ErrPolicyDenied) {
+		t.Fatalf("denied sender error mismatch: have %v, want %v", err, txpool.ErrPolicyDenied)
+	}
+	pool.Policy().SetConfig(txpool.PolicyConfig{Deny: []common.Address{{}}})
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); !errors.Is(err, txpool.ErrPolicyDenied) {
+		t.Fatalf("denied destination error mismatch: have %v, want %v", err, txpool.ErrPolicyDenied)
+	}
+	// Every test transaction is sent to the zero address
+	pool.Policy().SetConfig(txpool.PolicyConfig{ContractQuota: 2})
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.addRemoteSync(transaction(nonce, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); !errors.Is(err, txpool.ErrPolicyQuotaExceeded) {
+		t.Fatalf("quota error mismatch: have %v, want %v", err, txpool.ErrPolicyQuotaExceeded)
+	}
+	pool.Policy().SetConfig(txpool.PolicyConfig{ContractQuota: 2, Allow: []common.Address{from}})
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction of allowed sender: %v", err)
+	}
+	pool.Policy().SetConfig(txpool.PolicyConfig{SenderRate: 0.001, SenderBurst: 1})
+	// A transaction rejected by the pool doesn't spend the rate limit
+	if err := pool.addRemoteSync(transaction(1, 100001, key)); !errors.Is(err, txpool.ErrReplaceUnderpriced) {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, txpool.ErrReplaceUnderpriced)
+	}
+	if err := pool.addRemoteSync(transaction(3, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction within rate: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(4, 100000, key)); !errors.Is(err, txpool.ErrPolicyRateLimited) {
+		t.Fatalf("rate limit error mismatch: have %v, want %v", err, txpool.ErrPolicyRateLimited)
+	}
+	if pending, _ := pool.Stats(); pending != 4 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 4)
+	}
+	// The transactions re-injected from the overflow pool bypass the policy
+	pool.localBufferPool = NewTxOverflowPoolHeap(10)
+	pool.localBufferPool.Add(transaction(4, 100000, key))
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, from))
+	if pending, _ := pool.Stats(); pending != 5 {
+		t.Fatalf("pending transactions mismatched after re-injection: have %d, want %d", pending, 5)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the transactions of a single batch are held to the sender rate and
+// the destination quota one by one, and that the rejected ones give them back.
+func TestPolicyBatch(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	// A batch larger than the burst only gets the burst in
+	pool.Policy().SetConfig(txpool.PolicyConfig{SenderRate: 0.001, SenderBurst: 3})
+	txs := make([]*types.Transaction, 10)
+	for nonce := range txs {
+		txs[nonce] = transaction(uint64(nonce), 100000, key)
+	}
+	errs := pool.addRemotesSync(txs)
+	for i, err := range errs {
+		if i < 3 && err != nil {
+			t.Fatalf("failed to add transaction %d within burst: %v", i, err)
+		}
+		if i >= 3 && !errors.Is(err, txpool.ErrPolicyRateLimited) {
+			t.Fatalf("transaction %d: rate limit error mismatch: have %v, want %v", i, err, txpool.ErrPolicyRateLimited)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	// Every test transaction is sent to the zero address, a batch larger than
+	// the quota only gets the remaining slots in
+	pool.Policy().SetConfig(txpool.PolicyConfig{ContractQuota: 5})
+	errs = pool.addRemotesSync(txs[3:])
+	for i, err := range errs {
+		if i < 2 && err != nil {
+			t.Fatalf("failed to add transaction %d within quota: %v", i+3, err)
+		}
+		if i >= 2 && !errors.Is(err, txpool.ErrPolicyQuotaExceeded) {
+			t.Fatalf("transaction %d: quota error mismatch: have %v, want %v", i+3, err, txpool.ErrPolicyQuotaExceeded)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 5 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 5)
+	}
+	// The transactions rejected by the pool give the token and the slot back
+	pool.Policy().SetConfig(txpool.PolicyConfig{SenderRate: 0.001, SenderBurst: 1, ContractQuota: 6})
+	if err := pool.addRemoteSync(transaction(1, 100001, key)); !errors.Is(err, txpool.ErrReplaceUnderpriced) {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, txpool.ErrReplaceUnderpriced)
+	}
+	if err := pool.addRemoteSync(transaction(5, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction after a rejected one: %v", err)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the lifecycle events of the transactions are recorded as they move
+// across the pool.
+func TestTransactionHistory(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	history := txpool.NewHistory(16)
+	history.TrackAll(true)
+	defer history.Close()
+	pool := New(testTxPoolConfig, blockchain)
+	pool.SetHistory(history)
+	if err := pool.Init(testTxPoolConfig.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	defer pool.Close()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	var (
+		gapped   = transaction(1, 100000, key)
+		first    = transaction(0, 100000, key)
+		replaced = pricedTransaction(0, 100000, big.NewInt(2), key)
+		rejected = pricedTransaction(0, 100001, big.NewInt(2), key)
+	)
+	for _, tx := range []*types.Transaction{gapped, first, replaced} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if err := pool.addRemoteSync(rejected); !errors.Is(err, txpool.ErrReplaceUnderpriced) {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, txpool.ErrReplaceUnderpriced)
+	}
+	history.Received("peer", []*types.Transaction{first})
+
+	tests := []struct {
+		tx    *types.Transaction
+		kinds []txpool.TxEventKind
+	}{
+		{gapped, []txpool.TxEventKind{txpool.TxEventQueued, txpool.TxEventPending}},
+		{first, []txpool.TxEventKind{txpool.TxEventQueued, txpool.TxEventPending, txpool.TxEventReplaced}},
+		{replaced, []txpool.TxEventKind{txpool.TxEventPending}},
+		{rejected, []txpool.TxEventKind{txpool.TxEventRejected}},
+	}
+	for i, tt := range tests {
+		events := history.Get(tt.tx.Hash())
+		if len(events) != len(tt.kinds) {
+			t.Fatalf("test %d: event count mismatch: have %d, want %d", i, len(events), len(tt.kinds))
+		}
+		for j, event := range events {
+			if event.Kind != tt.kinds[j] {
+				t.Errorf("test %d: event %d kind mismatch: have %s, want %s", i, j, event.Kind, tt.kinds[j])
+			}
+		}
+	}
+	if events := history.Get(first.Hash()); events[2].Replacement != replaced.Hash() {
+		t.Errorf("replacement mismatch: have %x, want %x", events[2].Replacement, replaced.Hash())
+	}
+	// Only the first delivery of an untracked transaction is recorded
+	history.Received("peer", []*types.Transaction{transaction(2, 100000, key)})
+	if events := history.Get(transaction(2, 100000, key).Hash()); len(events) != 1 || events[0].Peer != "peer" {
+		t.Errorf("received event mismatch: have %v", events)
+	}
+}
+
+// Tests that only the watched transactions are tracked unless all are, and that
+// their events are dispatched to the subscribers.
+func TestTransactionHistoryWatched(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	history := txpool.NewHistory(16)
+	defer history.Close()
+	pool := New(testTxPoolConfig, blockchain)
+	pool.SetHistory(history)
+	if err := pool.Init(testTxPoolConfig.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	defer pool.Close()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	events := make(chan txpool.TxEvent, 16)
+	sub := history.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	var (
+		unwatched = transaction(0, 100000, key)
+		watched   = transaction(1, 100000, key)
+	)
+	history.Watch([]common.Hash{watched.Hash()})
+	history.Received("peer", []*types.Transaction{unwatched, watched})
+	for _, tx := range []*types.Transaction{unwatched, watched} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if events := history.Get(unwatched.Hash()); len(events) != 0 {
+		t.Errorf("unwatched transaction tracked: %v", events)
+	}
+	kinds := []txpool.TxEventKind{txpool.TxEventReceived, txpool.TxEventQueued, txpool.TxEventPending}
+	if events := history.Get(watched.Hash()); len(events) != len(kinds) {
+		t.Errorf("watched transaction event count mismatch: have %d, want %d", len(events), len(kinds))
+	}
+	for i, kind := range kinds {
+		select {
+		case event := <-events:
+			if event.Hash != watched.Hash() || event.Kind != kind {
+				t.Errorf("event %d mismatch: have %x %s, want %x %s", i, event.Hash, event.Kind, watched.Hash(), kind)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not dispatched", i)
+		}
+	}
+}
+
+// Tests that a transaction watched once already pending is tracked from then on,
+// up to its inclusion.
+func TestTransactionHistoryWatchedPending(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	history := txpool.NewHistory(16)
+	defer history.Close()
+	pool := New(testTxPoolConfig, blockchain)
+	pool.SetHistory(history)
+	if err := pool.Init(testTxPoolConfig.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	defer pool.Close()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	tx := transaction(0, 100000, key)
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if !history.Empty() {
+		t.Fatal("unwatched transaction tracked")
+	}
+	events := make(chan txpool.TxEvent, 16)
+	sub := history.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	history.Watch([]common.Hash{tx.Hash()})
+	if history.Empty() {
+		t.Fatal("watched transaction not considered by the inclusion walk")
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, &types.Body{Transactions: types.Transactions{tx}}, nil, trie.NewStackTrie(nil))
+	history.Included(block)
+
+	recorded := history.Get(tx.Hash())
+	if len(recorded) != 1 || recorded[0].Kind != txpool.TxEventIncluded || recorded[0].BlockHash != block.Hash() {
+		t.Fatalf("inclusion of the watched transaction not recorded: %v", recorded)
+	}
+	select {
+	case event := <-events:
+		if event.Hash != tx.Hash() || event.Kind != txpool.TxEventIncluded || event.BlockNumber != 1 {
+			t.Errorf("event mismatch: have %x %s %d", event.Hash, event.Kind, event.BlockNumber)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("inclusion event not dispatched")
+	}
+}
+
+// Tests that a nil transaction history can be watched and subscribed to.
+func TestTransactionHistoryNil(t *testing.T) {
+	t.Parallel()
+
+	var history *txpool.History
+	hashes := []common.Hash{{0x1}}
+	history.TrackAll(true)
+	history.Watch(hashes)
+	history.Unwatch(hashes)
+	if history.TracksAll() {
+		t.Fatal("nil history tracks all the transactions")
+	}
+	sub := history.SubscribeEvents(make(chan txpool.TxEvent))
+	sub.Unsubscribe()
+	if err := <-sub.Err(); err != nil {
+		t.Fatalf("unexpected subscription error: %v", err)
+	}
 }
 
 // Tests that the pool rejects replacement dynamic fee transactions that don't
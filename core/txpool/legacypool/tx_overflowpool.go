@@ -3,7 +3,9 @@ package legacypool
 import (
 	"container/heap"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Ezkerrox/bsc/common"
@@ -62,12 +64,56 @@ func (h *txHeap) Pop() interface{} {
 	return item
 }
 
+// OverflowPolicy is the order the transactions of the overflow pool are
+// re-injected in into the main pool.
+type OverflowPolicy string
+
+const (
+	OverflowPolicyAge      OverflowPolicy = "age"      // Oldest transactions first
+	OverflowPolicyPrice    OverflowPolicy = "price"    // Highest gas tip first, oldest first on a tie
+	OverflowPolicyFairness OverflowPolicy = "fairness" // One transaction per sender in turn, by nonce
+)
+
+// valid reports whether the policy is a known one.
+func (p OverflowPolicy) valid() bool {
+	switch p {
+	case OverflowPolicyAge, OverflowPolicyPrice, OverflowPolicyFairness:
+		return true
+	}
+	return false
+}
+
+// OverflowTx is a transaction buffered in the overflow pool.
+type OverflowTx struct {
+	Tx    *types.Transaction
+	Added time.Time // When the transaction was buffered
+}
+
+// OverflowPoolStats is the content of the overflow pool, along with the
+// counters of the transactions which moved through it since the start.
+type OverflowPoolStats struct {
+	Txs      int            // Number of buffered transactions
+	Slots    uint64         // Number of slots taken by the buffered transactions
+	MaxSlots uint64         // Maximum number of slots
+	Policy   OverflowPolicy // Re-injection order into the main pool
+
+	Added      uint64 // Transactions buffered
+	Evicted    uint64 // Transactions evicted to make room for newer ones
+	Reinjected uint64 // Transactions moved back into the main pool
+	Rejected   uint64 // Transactions refused by the main pool on re-injection
+}
+
 type TxOverflowPool struct {
 	txHeap    txHeap
 	index     map[common.Hash]*txHeapItem
 	mu        sync.RWMutex
 	maxSize   uint64 // Maximum slots
 	totalSize uint64 // Total number of slots currently
+
+	added      atomic.Uint64
+	evicted    atomic.Uint64
+	reinjected atomic.Uint64
+	rejected   atomic.Uint64
 }
 
 func NewTxOverflowPoolHeap(estimatedMaxSize uint64) *TxOverflowPool {
@@ -111,6 +157,8 @@ func (tp *TxOverflowPool) Add(tx *types.Transaction) bool {
 		delete(tp.index, oldestItem.tx.Hash())
 		tp.totalSize -= uint64(numSlots(oldestItem.tx))
 		OverflowPoolGauge.Dec(1)
+		tp.evicted.Add(1)
+		overflowEvictedMeter.Mark(1)
 	}
 
 	// Add the new transaction
@@ -122,6 +170,8 @@ func (tp *TxOverflowPool) Add(tx *types.Transaction) bool {
 	tp.index[tx.Hash()] = item
 	tp.totalSize += txSlots
 	OverflowPoolGauge.Inc(1)
+	tp.added.Add(1)
+	overflowAddedMeter.Mark(1)
 
 	return true
 }
@@ -147,26 +197,127 @@ func (tp *TxOverflowPool) Remove(hash common.Hash) {
 }
 
 func (tp *TxOverflowPool) Flush(n int) []*types.Transaction {
+	return tp.FlushBy(n, OverflowPolicyAge, nil)
+}
+
+// FlushBy removes up to n transactions from the pool in the order of the given
+// policy. The sender function is only needed by the fairness policy.
+func (tp *TxOverflowPool) FlushBy(n int, policy OverflowPolicy, sender func(*types.Transaction) common.Address) []*types.Transaction {
 	tp.mu.Lock()
 	defer tp.mu.Unlock()
 	if n > tp.txHeap.Len() {
 		n = tp.txHeap.Len()
 	}
-	txs := make([]*types.Transaction, n)
-	for i := 0; i < n; i++ {
-		item, ok := heap.Pop(&tp.txHeap).(*txHeapItem)
-		if !ok || item == nil {
-			continue
+	var items []*txHeapItem
+	switch {
+	case policy == OverflowPolicyPrice:
+		items = make([]*txHeapItem, len(tp.txHeap))
+		copy(items, tp.txHeap)
+		sort.Slice(items, func(i, j int) bool {
+			if cmp := items[i].tx.GasTipCapCmp(items[j].tx); cmp != 0 {
+				return cmp > 0
+			}
+			return items[i].timestamp < items[j].timestamp
+		})
+	case policy == OverflowPolicyFairness && sender != nil:
+		items = make([]*txHeapItem, len(tp.txHeap))
+		copy(items, tp.txHeap)
+		items = roundRobin(items, sender)
+	default:
+		// Oldest first, straight from the heap
+		txs := make([]*types.Transaction, n)
+		for i := 0; i < n; i++ {
+			item, ok := heap.Pop(&tp.txHeap).(*txHeapItem)
+			if !ok || item == nil {
+				continue
+			}
+			txs[i] = item.tx
+			delete(tp.index, item.tx.Hash())
+			tp.totalSize -= uint64(numSlots(item.tx))
 		}
+		OverflowPoolGauge.Dec(int64(n))
+		return txs
+	}
+	txs := make([]*types.Transaction, n)
+	for i, item := range items[:n] {
+		heap.Remove(&tp.txHeap, item.index)
 		txs[i] = item.tx
 		delete(tp.index, item.tx.Hash())
 		tp.totalSize -= uint64(numSlots(item.tx))
 	}
-
 	OverflowPoolGauge.Dec(int64(n))
 	return txs
 }
 
+// roundRobin orders the items one per sender in turn, the senders by their
+// oldest item and the items of a sender by nonce.
+func roundRobin(items []*txHeapItem, sender func(*types.Transaction) common.Address) []*txHeapItem {
+	sort.Slice(items, func(i, j int) bool { return items[i].timestamp < items[j].timestamp })
+
+	var (
+		senders  []common.Address
+		bySender = make(map[common.Address][]*txHeapItem)
+	)
+	for _, item := range items {
+		from := sender(item.tx)
+		if _, ok := bySender[from]; !ok {
+			senders = append(senders, from)
+		}
+		bySender[from] = append(bySender[from], item)
+	}
+	for _, from := range senders {
+		list := bySender[from]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].tx.Nonce() < list[j].tx.Nonce() })
+	}
+	ordered := make([]*txHeapItem, 0, len(items))
+	for round := 0; len(ordered) < len(items); round++ {
+		for _, from := range senders {
+			if list := bySender[from]; round < len(list) {
+				ordered = append(ordered, list[round])
+			}
+		}
+	}
+	return ordered
+}
+
+// Transactions returns the transactions in the pool, oldest first.
+func (tp *TxOverflowPool) Transactions() []*OverflowTx {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	txs := make([]*OverflowTx, 0, len(tp.txHeap))
+	for _, item := range tp.txHeap {
+		txs = append(txs, &OverflowTx{Tx: item.tx, Added: time.Unix(0, item.timestamp)})
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Added.Before(txs[j].Added) })
+	return txs
+}
+
+// markReinjected accounts the outcome of re-injecting transactions into the
+// main pool.
+func (tp *TxOverflowPool) markReinjected(reinjected, rejected int) {
+	tp.reinjected.Add(uint64(reinjected))
+	tp.rejected.Add(uint64(rejected))
+	overflowReinjectedMeter.Mark(int64(reinjected))
+	overflowRejectedMeter.Mark(int64(rejected))
+}
+
+// Stats returns the content of the pool and its counters.
+func (tp *TxOverflowPool) Stats() OverflowPoolStats {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	return OverflowPoolStats{
+		Txs:        tp.txHeap.Len(),
+		Slots:      tp.totalSize,
+		MaxSlots:   tp.maxSize,
+		Added:      tp.added.Load(),
+		Evicted:    tp.evicted.Load(),
+		Reinjected: tp.reinjected.Load(),
+		Rejected:   tp.rejected.Load(),
+	}
+}
+
 func (tp *TxOverflowPool) Len() int {
 	tp.mu.RLock()
 	defer tp.mu.RUnlock()
@@ -137,6 +137,83 @@ func TestTxOverflowPoolHeapOrdering(t *testing.T) {
 	}
 }
 
+func TestTxOverflowPoolFlushByPrice(t *testing.T) {
+	pool := NewTxOverflowPoolHeap(3)
+	tx1 := createTestTx(1, big.NewInt(2000))
+	tx2 := createTestTx(2, big.NewInt(3000))
+	tx3 := createTestTx(3, big.NewInt(2000))
+
+	pool.Add(tx1)
+	time.Sleep(time.Millisecond) // Ensure different timestamps
+	pool.Add(tx2)
+	time.Sleep(time.Millisecond)
+	pool.Add(tx3)
+
+	popped := pool.FlushBy(2, OverflowPolicyPrice, nil)
+	if len(popped) != 2 || popped[0].Hash() != tx2.Hash() || popped[1].Hash() != tx1.Hash() {
+		t.Fatal("Transactions not flushed by highest tip first, then earliest timestamp")
+	}
+	if pool.Len() != 1 || pool.Size() != 1 {
+		t.Errorf("Pool should have 1 transaction left, got %d", pool.Len())
+	}
+	if gotTx, _ := pool.Get(tx3.Hash()); gotTx == nil {
+		t.Error("Remaining transaction not found")
+	}
+}
+
+func TestTxOverflowPoolFlushByFairness(t *testing.T) {
+	pool := NewTxOverflowPoolHeap(5)
+	var (
+		senderA = common.Address{0xa}
+		senderB = common.Address{0xb}
+		senders = make(map[common.Hash]common.Address)
+	)
+	add := func(sender common.Address, nonce uint64) *types.Transaction {
+		tx := createTestTx(nonce, big.NewInt(1000))
+		senders[tx.Hash()] = sender
+		pool.Add(tx)
+		time.Sleep(time.Millisecond) // Ensure different timestamps
+		return tx
+	}
+	a2 := add(senderA, 2)
+	a1 := add(senderA, 1)
+	a3 := add(senderA, 3)
+	b1 := add(senderB, 11)
+
+	popped := pool.FlushBy(3, OverflowPolicyFairness, func(tx *types.Transaction) common.Address {
+		return senders[tx.Hash()]
+	})
+	want := []*types.Transaction{a1, b1, a2}
+	if len(popped) != len(want) {
+		t.Fatalf("FlushBy(3) should return 3 transactions, got %d", len(popped))
+	}
+	for i, tx := range want {
+		if popped[i].Hash() != tx.Hash() {
+			t.Errorf("Transaction %d mismatch: have nonce %d, want nonce %d", i, popped[i].Nonce(), tx.Nonce())
+		}
+	}
+	if txs := pool.Transactions(); len(txs) != 1 || txs[0].Tx.Hash() != a3.Hash() {
+		t.Error("Remaining transaction mismatch")
+	}
+}
+
+func TestTxOverflowPoolStats(t *testing.T) {
+	pool := NewTxOverflowPoolHeap(2)
+	pool.Add(createTestTx(1, big.NewInt(1000)))
+	pool.Add(createTestTx(2, big.NewInt(1000)))
+	pool.Add(createTestTx(3, big.NewInt(1000))) // Evicts the oldest one
+	pool.markReinjected(1, 1)
+
+	stats := pool.Stats()
+	assert.Equal(t, 2, stats.Txs)
+	assert.Equal(t, uint64(2), stats.Slots)
+	assert.Equal(t, uint64(2), stats.MaxSlots)
+	assert.Equal(t, uint64(3), stats.Added)
+	assert.Equal(t, uint64(1), stats.Evicted)
+	assert.Equal(t, uint64(1), stats.Reinjected)
+	assert.Equal(t, uint64(1), stats.Rejected)
+}
+
 func TestTxOverflowPoolHeapLen(t *testing.T) {
 	pool := NewTxOverflowPoolHeap(2)
 	if pool.Len() != 0 {
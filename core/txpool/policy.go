@@ -0,0 +1,201 @@
+package txpool
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/lru"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/metrics"
+)
+
+// maxPolicyLimiters is the number of senders whose rate limiter is retained,
+// the least recently active ones start over with a full burst.
+const maxPolicyLimiters = 16384
+
+var (
+	policyDeniedMeter      = metrics.NewRegisteredMeter("txpool/policy/denied", nil)
+	policyRateLimitedMeter = metrics.NewRegisteredMeter("txpool/policy/ratelimited", nil)
+	policyQuotaMeter       = metrics.NewRegisteredMeter("txpool/policy/quota", nil)
+)
+
+// PolicyConfig are the admission rules applied to the transactions entering the
+// pool, on top of the validity and pricing rules.
+type PolicyConfig struct {
+	Allow         []common.Address `json:"allow"`         // Senders exempt from the rate limit and the quota
+	Deny          []common.Address `json:"deny"`          // Senders and destinations whose transactions are rejected
+	SenderRate    float64          `json:"senderRate"`    // Transactions admitted per second and sender, 0 for no limit
+	SenderBurst   int              `json:"senderBurst"`   // Transactions a sender may submit at once, defaults to the rate
+	ContractQuota int              `json:"contractQuota"` // Pooled transactions allowed per destination, 0 for no limit
+}
+
+// Policy enforces a PolicyConfig which can be replaced at runtime. A nil policy
+// admits every transaction.
+type Policy struct {
+	config   PolicyConfig
+	allow    map[common.Address]struct{}
+	deny     map[common.Address]struct{}
+	limiters lru.BasicLRU[common.Address, *rate.Limiter]
+	reserved map[common.Address]int // Quota slots held by the transactions being added
+	lock     sync.Mutex
+}
+
+// NewPolicy creates an admission policy enforcing the given rules.
+func NewPolicy(config PolicyConfig) *Policy {
+	p := &Policy{reserved: make(map[common.Address]int)}
+	p.SetConfig(config)
+	return p
+}
+
+// SetConfig replaces the rules of the policy, resetting the rate limits. The
+// quota slots held by the transactions being added are kept.
+func (p *Policy) SetConfig(config PolicyConfig) {
+	if config.SenderRate < 0 {
+		config.SenderRate = 0
+	}
+	if config.SenderRate > 0 && config.SenderBurst < 1 {
+		config.SenderBurst = int(math.Ceil(config.SenderRate))
+	}
+	if config.ContractQuota < 0 {
+		config.ContractQuota = 0
+	}
+	allow := make(map[common.Address]struct{}, len(config.Allow))
+	for _, addr := range config.Allow {
+		allow[addr] = struct{}{}
+	}
+	deny := make(map[common.Address]struct{}, len(config.Deny))
+	for _, addr := range config.Deny {
+		deny[addr] = struct{}{}
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.config = config
+	p.allow, p.deny = allow, deny
+	p.limiters = lru.NewBasicLRU[common.Address, *rate.Limiter](maxPolicyLimiters)
+}
+
+// Config returns the rules enforced by the policy.
+func (p *Policy) Config() PolicyConfig {
+	if p == nil {
+		return PolicyConfig{}
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.config
+}
+
+// Reserve reports whether a new transaction from the given sender is admitted,
+// and the reason if not. The pooled function returns the number of transactions
+// in the pool sent to a destination, a nil one disabling the quota.
+//
+// An admitted transaction holds a rate limit token of the sender and a quota slot
+// of the destination, so that the following transactions of the same batch are
+// checked against them. The reservation must be committed once the transaction
+// is pooled, or cancelled if it's rejected by the pool.
+func (p *Policy) Reserve(from common.Address, tx *types.Transaction, pooled func(common.Address) int) (*PolicyReservation, error) {
+	if p == nil {
+		return nil, nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.deny[from]; ok {
+		policyDeniedMeter.Mark(1)
+		return nil, fmt.Errorf("%w: sender %v", ErrPolicyDenied, from)
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.deny[*to]; ok {
+			policyDeniedMeter.Mark(1)
+			return nil, fmt.Errorf("%w: destination %v", ErrPolicyDenied, *to)
+		}
+	}
+	if _, ok := p.allow[from]; ok {
+		return nil, nil
+	}
+	var quota *common.Address
+	if to := tx.To(); to != nil && p.config.ContractQuota > 0 && pooled != nil {
+		if count := pooled(*to) + p.reserved[*to]; count >= p.config.ContractQuota {
+			policyQuotaMeter.Mark(1)
+			return nil, fmt.Errorf("%w: destination %v has %d pooled transactions, quota %d", ErrPolicyQuotaExceeded, *to, count, p.config.ContractQuota)
+		}
+		quota = to
+	}
+	res := &PolicyReservation{policy: p, quota: quota, at: time.Now()}
+	if limiter := p.limiter(from); limiter != nil {
+		res.token = limiter.ReserveN(res.at, 1)
+		if !res.token.OK() || res.token.DelayFrom(res.at) > 0 {
+			res.token.CancelAt(res.at)
+			policyRateLimitedMeter.Mark(1)
+			return nil, fmt.Errorf("%w: sender %v, limit %v txs/s", ErrPolicyRateLimited, from, p.config.SenderRate)
+		}
+	}
+	if quota != nil {
+		p.reserved[*quota]++
+	}
+	return res, nil
+}
+
+// PolicyReservation is the rate limit token and the quota slot held by a
+// transaction admitted by the policy, until it's pooled or rejected.
+type PolicyReservation struct {
+	policy *Policy
+	quota  *common.Address   // Destination whose quota slot is held
+	token  *rate.Reservation // Rate limit token of the sender
+	at     time.Time
+	done   bool
+}
+
+// Commit spends the rate limit token once the transaction is pooled. The quota
+// slot is released, as the destination now counts the pooled transaction.
+func (r *PolicyReservation) Commit() {
+	r.release(false)
+}
+
+// Cancel gives the rate limit token and the quota slot back once the transaction
+// is rejected by the pool.
+func (r *PolicyReservation) Cancel() {
+	r.release(true)
+}
+
+func (r *PolicyReservation) release(cancel bool) {
+	if r == nil {
+		return
+	}
+	p := r.policy
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if r.done {
+		return
+	}
+	r.done = true
+	if r.quota != nil {
+		if p.reserved[*r.quota]--; p.reserved[*r.quota] <= 0 {
+			delete(p.reserved, *r.quota)
+		}
+	}
+	if cancel && r.token != nil {
+		r.token.CancelAt(r.at)
+	}
+}
+
+// limiter returns the rate limiter of the sender, nil if the sender rate is not
+// limited. The lock is assumed to be held.
+func (p *Policy) limiter(from common.Address) *rate.Limiter {
+	if p.config.SenderRate <= 0 {
+		return nil
+	}
+	limiter, ok := p.limiters.Get(from)
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(p.config.SenderRate), p.config.SenderBurst)
+		p.limiters.Add(from, limiter)
+	}
+	return limiter
+}
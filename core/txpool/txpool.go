@@ -71,6 +71,8 @@ type TxPool struct {
 	reservations map[common.Address]SubPool // Map with the account to pool reservations
 	reserveLock  sync.Mutex                 // Lock protecting the account reservations
 
+	history *History // Lifecycle events of the recently seen transactions
+
 	subs event.SubscriptionScope // Subscription scope to unsubscribe all on shutdown
 	quit chan chan error         // Quit channel to tear down the head updater
 	term chan struct{}           // Termination channel to detect a closed pool
@@ -89,11 +91,15 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 	pool := &TxPool{
 		subpools:     subpools,
 		reservations: make(map[common.Address]SubPool),
+		history:      NewHistory(historyTxs),
 		quit:         make(chan chan error),
 		term:         make(chan struct{}),
 		sync:         make(chan chan error),
 	}
 	for i, subpool := range subpools {
+		if recorder, ok := subpool.(historyRecorder); ok {
+			recorder.SetHistory(pool.history)
+		}
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
 			for j := i - 1; j >= 0; j-- {
 				subpools[j].Close()
@@ -105,6 +111,20 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 	return pool, nil
 }
 
+// historyRecorder is implemented by the subpools recording the lifecycle events
+// of their transactions.
+type historyRecorder interface {
+	// SetHistory sets the history to record the events in, before the subpool
+	// is initialized.
+	SetHistory(history *History)
+}
+
+// History returns the lifecycle events recorded for the recently seen
+// transactions.
+func (p *TxPool) History() *History {
+	return p.history
+}
+
 // reserver is a method to create an address reservation callback to exclusively
 // assign/deassign addresses to/from subpools. This can ensure that at any point
 // in time, only a single subpool is able to manage an account, avoiding cross
@@ -169,6 +189,7 @@ func (p *TxPool) Close() error {
 	}
 	// Unsubscribe anyone still listening for tx events
 	p.subs.Close()
+	p.history.Close()
 
 	if len(errs) > 0 {
 		return fmt.Errorf("subpool close errors: %v", errs)
@@ -17,6 +17,8 @@
 package core
 
 import (
+	"time"
+
 	"github.com/Ezkerrox/bsc/core/state"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/core/vm"
@@ -66,4 +68,5 @@ type ProcessResult struct {
 	Requests [][]byte
 	Logs     []*types.Log
 	GasUsed  uint64
+	TxTimes  []time.Duration // Execution time of the non-system transactions, in the receipts order
 }
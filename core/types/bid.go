@@ -206,5 +206,47 @@ type MevParams struct {
 	GasCeil               uint64
 	GasPrice              *big.Int // Minimum avg gas price for bid block
 	BuilderFeeCeil        *big.Int
+	BidSelectionPolicy    string // The policy selecting the best bid
 	Version               string
 }
+
+// The simulation results of the bids in the bid history.
+const (
+	BidStatusWon     = "won"     // The bid was simulated and became the best bid
+	BidStatusLost    = "lost"    // The bid was simulated, but a better bid was around
+	BidStatusInvalid = "invalid" // The bid failed the simulation by its own fault, e.g. an invalid tx
+	BidStatusAborted = "aborted" // The simulation was interrupted, e.g. by a better bid, a timeout or a local failure
+)
+
+// BidRecord represents a bid in the bid history.
+type BidRecord struct {
+	BlockNumber  uint64         `json:"blockNumber"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	Builder      common.Address `json:"builder"`
+	BidHash      common.Hash    `json:"bidHash"`
+	GasUsed      uint64         `json:"gasUsed"`
+	GasFee       *big.Int       `json:"gasFee"`       // Block reward claimed by the builder
+	PackedReward *big.Int       `json:"packedReward"` // Block reward of the simulated block
+	Status       string         `json:"status"`
+	Error        string         `json:"error,omitempty"` // The issue reported to the builder
+	ReceivedAt   uint64         `json:"receivedAt"`      // Unix time in milliseconds
+	Latency      uint64         `json:"latency"`         // Milliseconds between the parent block and the bid arrival
+	SimElapsed   uint64         `json:"simElapsed"`      // Milliseconds spent simulating the bid
+}
+
+// BuilderStats represents the reputation of a builder, computed over the bids
+// in the bid history.
+type BuilderStats struct {
+	Builder             common.Address `json:"builder"`
+	Bids                uint64         `json:"bids"`
+	Won                 uint64         `json:"won"`
+	Lost                uint64         `json:"lost"`
+	Invalid             uint64         `json:"invalid"`
+	Aborted             uint64         `json:"aborted"`
+	SuccessRate         float64        `json:"successRate"` // Ratio of the bids passing the simulation
+	InvalidRate         float64        `json:"invalidRate"` // Ratio of the bids failing the simulation
+	AvgLatency          uint64         `json:"avgLatency"`  // Average milliseconds between the parent block and the bid arrival
+	ConsecutiveFailures uint32         `json:"consecutiveFailures"`
+	Penalty             string         `json:"penalty,omitempty"`      // The penalty applied to the builder, if any
+	PenaltyUntil        uint64         `json:"penaltyUntil,omitempty"` // Unix time in seconds the penalty lasts until
+}
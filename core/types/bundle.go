@@ -0,0 +1,118 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/crypto"
+)
+
+// MaxBundleTxs is the maximum number of transactions allowed in a bundle.
+const MaxBundleTxs = 64
+
+var (
+	ErrBundleEmpty      = errors.New("bundle is empty")
+	ErrBundleTooManyTxs = fmt.Errorf("bundle has more than %d transactions", MaxBundleTxs)
+	ErrBundleBlobTx     = errors.New("blob transaction is not allowed in bundle")
+)
+
+// SendBundleArgs represents the arguments to submit a bundle.
+type SendBundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	MaxBlockNumber    uint64          `json:"maxBlockNumber"`
+	MinTimestamp      *uint64         `json:"minTimestamp"`
+	MaxTimestamp      *uint64         `json:"maxTimestamp"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+}
+
+// ToBundle decodes the transactions of the arguments into a bundle.
+func (args *SendBundleArgs) ToBundle() (*Bundle, error) {
+	if len(args.Txs) == 0 {
+		return nil, ErrBundleEmpty
+	}
+	if len(args.Txs) > MaxBundleTxs {
+		return nil, ErrBundleTooManyTxs
+	}
+	txs := make(Transactions, 0, len(args.Txs))
+	for i, encodedTx := range args.Txs {
+		tx := new(Transaction)
+		if err := tx.UnmarshalBinary(encodedTx); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		if tx.Type() == BlobTxType {
+			return nil, ErrBundleBlobTx
+		}
+		txs = append(txs, tx)
+	}
+	bundle := &Bundle{
+		Txs:               txs,
+		MaxBlockNumber:    args.MaxBlockNumber,
+		RevertingTxHashes: mapset.NewThreadUnsafeSetWithSize[common.Hash](len(args.RevertingTxHashes)),
+	}
+	bundle.RevertingTxHashes.Append(args.RevertingTxHashes...)
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = *args.MinTimestamp
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = *args.MaxTimestamp
+	}
+	return bundle, nil
+}
+
+// Bundle is an ordered list of transactions which must be included atomically,
+// in the given order and on top of each other, or not at all.
+type Bundle struct {
+	Txs               Transactions
+	MaxBlockNumber    uint64 // The last block the bundle is valid for, 0 means no limit
+	MinTimestamp      uint64 // The earliest block timestamp the bundle is valid for, 0 means no limit
+	MaxTimestamp      uint64 // The latest block timestamp the bundle is valid for, 0 means no limit
+	RevertingTxHashes mapset.Set[common.Hash]
+
+	hash atomic.Value
+}
+
+// Hash returns the hash of the bundle, derived from the hashes of its transactions.
+func (bundle *Bundle) Hash() common.Hash {
+	if hash := bundle.hash.Load(); hash != nil {
+		return hash.(common.Hash)
+	}
+	hashes := make([]byte, 0, len(bundle.Txs)*common.HashLength)
+	for _, tx := range bundle.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	h := crypto.Keccak256Hash(hashes)
+	bundle.hash.Store(h)
+	return h
+}
+
+// AllowRevert returns whether the transaction is allowed to revert without
+// invalidating the bundle.
+func (bundle *Bundle) AllowRevert(txHash common.Hash) bool {
+	return bundle.RevertingTxHashes != nil && bundle.RevertingTxHashes.Contains(txHash)
+}
+
+// Expired returns whether the bundle can no longer be included in any block
+// after the given one.
+func (bundle *Bundle) Expired(number uint64) bool {
+	return bundle.MaxBlockNumber != 0 && bundle.MaxBlockNumber <= number
+}
+
+// ValidFor returns whether the bundle can be included in the block with the
+// given number and timestamp.
+func (bundle *Bundle) ValidFor(number, timestamp uint64) bool {
+	if bundle.MaxBlockNumber != 0 && number > bundle.MaxBlockNumber {
+		return false
+	}
+	if bundle.MinTimestamp != 0 && timestamp < bundle.MinTimestamp {
+		return false
+	}
+	if bundle.MaxTimestamp != 0 && timestamp > bundle.MaxTimestamp {
+		return false
+	}
+	return true
+}
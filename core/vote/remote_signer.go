@@ -0,0 +1,242 @@
+package vote
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/log"
+)
+
+const (
+	remoteSignerPublicKeysPath = "/api/v1/eth2/publicKeys"
+	remoteSignerSignPath       = "/api/v1/eth2/sign/"
+
+	// remoteSignerVoteType is the signing type of the votes for fast finality,
+	// which has no counterpart in the eth2 signing types.
+	remoteSignerVoteType = "BSC_VOTE"
+
+	maxRemoteSignerResponseSize = 64 * 1024
+)
+
+// RemoteSignerConfig is the configuration of a Web3Signer compatible signing service.
+type RemoteSignerConfig struct {
+	URL        string // Base URL of the signing service
+	PublicKey  string // Hex encoded BLS public key to sign with, the first key of the service if empty
+	CACert     string // CA certificate file to verify the service with, the system pool if empty
+	ClientCert string // Client certificate file for mutual TLS
+	ClientKey  string // Client key file for mutual TLS
+}
+
+// remoteSignRequest is the body of a Web3Signer signing request.
+type remoteSignRequest struct {
+	Type        string          `json:"type"`
+	SigningRoot hexutil.Bytes   `json:"signingRoot"`
+	Vote        *types.VoteData `json:"vote"`
+}
+
+// remoteSignResponse is the body of a Web3Signer signing response in json format.
+type remoteSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// RemoteSigner signs votes with a BLS key kept in a Web3Signer compatible
+// remote signing service, so that no keystore needs to be stored on the node.
+type RemoteSigner struct {
+	url     string
+	client  *http.Client
+	journal *VoteJournal
+
+	pubKey    [48]byte
+	blsPubKey bls.PublicKey
+}
+
+// NewRemoteSigner creates a signer backed by the given signing service. The
+// journal, if not nil, is checked before each vote is sent out for signing.
+func NewRemoteSigner(config *RemoteSignerConfig, journal *VoteJournal) (*RemoteSigner, error) {
+	if config.URL == "" {
+		return nil, errors.New("remote signer url is not specified")
+	}
+	tlsConfig, err := remoteSignerTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	signer := &RemoteSigner{
+		url:     strings.TrimSuffix(config.URL, "/"),
+		client:  &http.Client{Timeout: voteSignerTimeout, Transport: transport},
+		journal: journal,
+	}
+
+	pubKeyHex := config.PublicKey
+	if pubKeyHex == "" {
+		pubKeys, err := signer.fetchPublicKeys()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch public keys from remote signer")
+		}
+		if len(pubKeys) == 0 {
+			return nil, errors.New("no public key available in remote signer")
+		}
+		pubKeyHex = pubKeys[0]
+	}
+	pubKey, err := hexutil.Decode(pubKeyHex)
+	if err != nil || len(pubKey) != types.BLSPublicKeyLength {
+		return nil, fmt.Errorf("invalid BLS public key %q", pubKeyHex)
+	}
+	blsPubKey, err := bls.PublicKeyFromBytes(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "convert public key from bytes to bls failed")
+	}
+	copy(signer.pubKey[:], pubKey)
+	signer.blsPubKey = blsPubKey
+
+	log.Info("Initialized remote vote signer", "url", signer.url, "pubKey", pubKeyHex)
+	return signer, nil
+}
+
+// remoteSignerTLSConfig assembles the TLS configuration of the signing service
+// connection, nil if the defaults should be used.
+func remoteSignerTLSConfig(config *RemoteSignerConfig) (*tls.Config, error) {
+	if config.CACert == "" && config.ClientCert == "" && config.ClientKey == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CACert != "" {
+		pem, err := os.ReadFile(config.CACert)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read remote signer CA certificate")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("invalid remote signer CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.ClientCert != "" || config.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load remote signer client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// PublicKey implements Signer.
+func (signer *RemoteSigner) PublicKey() [48]byte {
+	return signer.pubKey
+}
+
+// SignVote implements Signer, requesting the signature from the remote service
+// once the vote passed the slashing protection checks against the journal.
+func (signer *RemoteSigner) SignVote(vote *types.VoteEnvelope) error {
+	if signer.journal != nil {
+		if err := signer.journal.checkSlashingRules(vote.Data.SourceNumber, vote.Data.TargetNumber); err != nil {
+			return errors.Wrap(err, "refuse to sign vote")
+		}
+	}
+	voteDataHash := vote.Data.Hash()
+	body, err := json.Marshal(&remoteSignRequest{
+		Type:        remoteSignerVoteType,
+		SigningRoot: voteDataHash[:],
+		Vote:        vote.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), voteSignerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, signer.url+remoteSignerSignPath+hexutil.Encode(signer.pubKey[:]), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	respBody, contentType, err := signer.do(req)
+	if err != nil {
+		return err
+	}
+	var sigBytes []byte
+	if strings.HasPrefix(contentType, "application/json") {
+		var resp remoteSignResponse
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			return errors.Wrap(err, "invalid remote signer response")
+		}
+		sigBytes = resp.Signature
+	} else {
+		if sigBytes, err = hexutil.Decode(strings.TrimSpace(string(respBody))); err != nil {
+			return errors.Wrap(err, "invalid remote signer response")
+		}
+	}
+
+	// Never trust the remote service blindly, a wrong signature would cost us
+	// the vote reward or even get the vote considered malicious.
+	signature, err := bls.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return errors.Wrap(err, "convert signature from bytes to bls failed")
+	}
+	if !signature.Verify(signer.blsPubKey, voteDataHash[:]) {
+		return errors.New("remote signer returned an invalid signature")
+	}
+
+	copy(vote.VoteAddress[:], signer.pubKey[:])
+	copy(vote.Signature[:], signature.Marshal()[:])
+	return nil
+}
+
+// fetchPublicKeys lists the BLS public keys available in the signing service.
+func (signer *RemoteSigner) fetchPublicKeys() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), voteSignerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signer.url+remoteSignerPublicKeysPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, _, err := signer.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var pubKeys []string
+	if err := json.Unmarshal(body, &pubKeys); err != nil {
+		return nil, err
+	}
+	return pubKeys, nil
+}
+
+// do sends the request to the signing service, returning the response body and
+// its content type.
+func (signer *RemoteSigner) do(req *http.Request) ([]byte, string, error) {
+	resp, err := signer.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSignerResponseSize))
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("remote signer returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
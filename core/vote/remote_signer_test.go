@@ -0,0 +1,121 @@
+package vote
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/types"
+)
+
+// newRemoteSignerStub starts a Web3Signer compatible stub service signing with
+// the given key. If corrupt is set, it signs with another key instead.
+func newRemoteSignerStub(t *testing.T, secretKey common.SecretKey, corrupt bool) *httptest.Server {
+	pubKey := hexutil.Encode(secretKey.PublicKey().Marshal())
+	signingKey := secretKey
+	if corrupt {
+		signingKey, _ = bls.RandKey()
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(remoteSignerPublicKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]string{pubKey})
+	})
+	mux.HandleFunc(remoteSignerSignPath, func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, remoteSignerSignPath) != pubKey {
+			http.Error(w, "unknown public key", http.StatusNotFound)
+			return
+		}
+		var req remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type != remoteSignerVoteType {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&remoteSignResponse{Signature: signingKey.Sign(req.SigningRoot).Marshal()})
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeServerCACert stores the certificate of the stub service into a pem file.
+func writeServerCACert(t *testing.T, server *httptest.Server) string {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write CA certificate: %v", err)
+	}
+	return path
+}
+
+func newTestJournal(t *testing.T) *VoteJournal {
+	journal, err := NewVoteJournal(filepath.Join(t.TempDir(), "journal"))
+	if err != nil {
+		t.Fatalf("failed to create vote journal: %v", err)
+	}
+	return journal
+}
+
+func TestRemoteSigner(t *testing.T) {
+	secretKey, _ := bls.RandKey()
+	server := newRemoteSignerStub(t, secretKey, false)
+	journal := newTestJournal(t)
+
+	// Without the CA certificate the stub service must not be trusted
+	if _, err := NewRemoteSigner(&RemoteSignerConfig{URL: server.URL}, journal); err == nil {
+		t.Fatal("expected TLS verification failure")
+	}
+	signer, err := NewRemoteSigner(&RemoteSignerConfig{URL: server.URL, CACert: writeServerCACert(t, server)}, journal)
+	if err != nil {
+		t.Fatalf("failed to create remote signer: %v", err)
+	}
+	pubKey := signer.PublicKey()
+	if hexutil.Encode(pubKey[:]) != hexutil.Encode(secretKey.PublicKey().Marshal()) {
+		t.Fatal("unexpected public key")
+	}
+
+	vote := &types.VoteEnvelope{Data: &types.VoteData{SourceNumber: 9, TargetNumber: 10}}
+	if err := signer.SignVote(vote); err != nil {
+		t.Fatalf("failed to sign vote: %v", err)
+	}
+	if err := vote.Verify(); err != nil {
+		t.Fatalf("invalid vote signature: %v", err)
+	}
+
+	// Votes violating the slashing rules must not reach the remote service
+	if err := journal.WriteVote(vote); err != nil {
+		t.Fatalf("failed to write vote journal: %v", err)
+	}
+	duplicate := &types.VoteEnvelope{Data: &types.VoteData{SourceNumber: 8, TargetNumber: 10}}
+	if err := signer.SignVote(duplicate); err == nil {
+		t.Fatal("expected slashing protection failure for the same target")
+	}
+	surround := &types.VoteEnvelope{Data: &types.VoteData{SourceNumber: 8, TargetNumber: 11}}
+	if err := signer.SignVote(surround); err == nil {
+		t.Fatal("expected slashing protection failure for surrounding vote")
+	}
+}
+
+func TestRemoteSignerInvalidSignature(t *testing.T) {
+	secretKey, _ := bls.RandKey()
+	server := newRemoteSignerStub(t, secretKey, true)
+
+	signer, err := NewRemoteSigner(&RemoteSignerConfig{URL: server.URL, CACert: writeServerCACert(t, server)}, nil)
+	if err != nil {
+		t.Fatalf("failed to create remote signer: %v", err)
+	}
+	vote := &types.VoteEnvelope{Data: &types.VoteData{SourceNumber: 9, TargetNumber: 10}}
+	if err := signer.SignVote(vote); err == nil {
+		t.Fatal("expected invalid signature failure")
+	}
+}
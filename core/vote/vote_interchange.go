@@ -0,0 +1,161 @@
+package vote
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/types"
+)
+
+// InterchangeFormatVersion is the version of the EIP-3076 slashing protection
+// interchange format the vote history is exported in.
+const InterchangeFormatVersion = "5"
+
+// Interchange is the EIP-3076 style slashing protection interchange document, used
+// to carry the vote history of a validator over to another machine. As there are
+// no epochs for fast finality, the source and target epochs are block numbers.
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []*InterchangeData  `json:"data"`
+}
+
+// InterchangeMetadata is the metadata of the interchange document.
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string      `json:"interchange_format_version"`
+	GenesisValidatorsRoot    common.Hash `json:"genesis_validators_root"` // Genesis block hash of the chain
+}
+
+// InterchangeData is the vote history of a single BLS key.
+type InterchangeData struct {
+	Pubkey             hexutil.Bytes        `json:"pubkey"`
+	SignedBlocks       []struct{}           `json:"signed_blocks"`
+	SignedAttestations []*SignedAttestation `json:"signed_attestations"`
+}
+
+// SignedAttestation is a single vote in the interchange document.
+type SignedAttestation struct {
+	SourceEpoch string       `json:"source_epoch"`
+	TargetEpoch string       `json:"target_epoch"`
+	SigningRoot *common.Hash `json:"signing_root,omitempty"` // Left out for the votes only known by their numbers
+}
+
+// ExportInterchange exports all the votes recorded in the journal.
+func (journal *VoteJournal) ExportInterchange(genesisHash common.Hash) (*Interchange, error) {
+	firstIndex, err := journal.walLog.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	lastIndex, err := journal.walLog.LastIndex()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		keys    []types.BLSPublicKey
+		history = make(map[types.BLSPublicKey]*InterchangeData)
+	)
+	for index := firstIndex; index <= lastIndex; index++ {
+		vote, err := journal.readEntry(index)
+		if err != nil {
+			return nil, err
+		}
+		if vote == nil || vote.Data == nil {
+			continue
+		}
+		data, ok := history[vote.VoteAddress]
+		if !ok {
+			data = &InterchangeData{
+				Pubkey:             common.CopyBytes(vote.VoteAddress[:]),
+				SignedBlocks:       []struct{}{},
+				SignedAttestations: []*SignedAttestation{},
+			}
+			history[vote.VoteAddress] = data
+			keys = append(keys, vote.VoteAddress)
+		}
+		attestation := &SignedAttestation{
+			SourceEpoch: strconv.FormatUint(vote.Data.SourceNumber, 10),
+			TargetEpoch: strconv.FormatUint(vote.Data.TargetNumber, 10),
+		}
+		// The imported votes carry no block hash, nothing was signed with them
+		if !vote.Imported {
+			root := vote.Data.Hash()
+			attestation.SigningRoot = &root
+		}
+		data.SignedAttestations = append(data.SignedAttestations, attestation)
+	}
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeFormatVersion,
+			GenesisValidatorsRoot:    genesisHash,
+		},
+		Data: make([]*InterchangeData, 0, len(keys)),
+	}
+	for _, key := range keys {
+		interchange.Data = append(interchange.Data, history[key])
+	}
+	return interchange, nil
+}
+
+// ImportInterchange records the votes of the interchange document into the
+// journal, so that the local validator refuses to sign any vote at or below
+// them. The genesis hash of the local chain is checked against the document,
+// the chain must therefore be initialized first, and the document must only
+// contain the votes of the local BLS key. It returns the number of imported
+// votes.
+func (journal *VoteJournal) ImportInterchange(interchange *Interchange, genesisHash common.Hash, voteKey types.BLSPublicKey) (int, error) {
+	if interchange.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		return 0, fmt.Errorf("unsupported interchange format version %q", interchange.Metadata.InterchangeFormatVersion)
+	}
+	if genesisHash == (common.Hash{}) {
+		return 0, errors.New("genesis of the local chain is unknown, initialize the chain first")
+	}
+	if interchange.Metadata.GenesisValidatorsRoot != genesisHash {
+		return 0, fmt.Errorf("genesis mismatch, want %s, have %s", genesisHash, interchange.Metadata.GenesisValidatorsRoot)
+	}
+	var votes []*journalEntry
+	for _, data := range interchange.Data {
+		if len(data.Pubkey) != types.BLSPublicKeyLength {
+			return 0, fmt.Errorf("invalid BLS public key %s", data.Pubkey)
+		}
+		if types.BLSPublicKey(data.Pubkey) != voteKey {
+			return 0, fmt.Errorf("BLS public key mismatch, want %s, have %s", hexutil.Bytes(voteKey[:]), data.Pubkey)
+		}
+		for _, attestation := range data.SignedAttestations {
+			source, err := strconv.ParseUint(attestation.SourceEpoch, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid source epoch %q: %v", attestation.SourceEpoch, err)
+			}
+			target, err := strconv.ParseUint(attestation.TargetEpoch, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid target epoch %q: %v", attestation.TargetEpoch, err)
+			}
+			if source >= target {
+				return 0, fmt.Errorf("invalid vote %d-->%d", source, target)
+			}
+			votes = append(votes, &journalEntry{
+				VoteEnvelope: &types.VoteEnvelope{
+					VoteAddress: voteKey,
+					Data:        &types.VoteData{SourceNumber: source, TargetNumber: target},
+				},
+				Imported: true,
+			})
+		}
+	}
+	if len(votes) == 0 {
+		return 0, errors.New("no vote to import")
+	}
+	// Write the votes in ascending order, so that the most recent ones survive
+	// the truncation of the journal.
+	sort.SliceStable(votes, func(i, j int) bool {
+		return votes[i].Data.TargetNumber < votes[j].Data.TargetNumber
+	})
+	for _, vote := range votes {
+		if err := journal.writeEntry(vote); err != nil {
+			return 0, err
+		}
+	}
+	return len(votes), nil
+}
@@ -0,0 +1,162 @@
+package vote
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+)
+
+func TestVoteJournalInterchange(t *testing.T) {
+	var (
+		genesis = common.HexToHash("0x01")
+		pubKey  = types.BLSPublicKey{0x1}
+	)
+	primary := newTestJournal(t)
+	for target := uint64(10); target < 20; target++ {
+		vote := &types.VoteEnvelope{
+			VoteAddress: pubKey,
+			Signature:   types.BLSSignature{0x1},
+			Data:        &types.VoteData{SourceNumber: target - 1, TargetNumber: target},
+		}
+		if err := primary.WriteVote(vote); err != nil {
+			t.Fatalf("failed to write vote: %v", err)
+		}
+	}
+	if primary.HasImportedVotes() {
+		t.Fatal("locally signed votes should not be considered as imported")
+	}
+	interchange, err := primary.ExportInterchange(genesis)
+	if err != nil {
+		t.Fatalf("failed to export journal: %v", err)
+	}
+	if len(interchange.Data) != 1 || len(interchange.Data[0].SignedAttestations) != 10 {
+		t.Fatalf("unexpected interchange content: %+v", interchange.Data)
+	}
+	for _, attestation := range interchange.Data[0].SignedAttestations {
+		if attestation.SigningRoot == nil {
+			t.Fatal("signing root missing from a locally signed vote")
+		}
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backup")
+	backup, err := NewVoteJournal(backupPath)
+	if err != nil {
+		t.Fatalf("failed to create vote journal: %v", err)
+	}
+	if _, err := backup.ImportInterchange(interchange, common.HexToHash("0x02"), pubKey); err == nil {
+		t.Fatal("expected genesis mismatch failure")
+	}
+	if _, err := backup.ImportInterchange(interchange, common.Hash{}, pubKey); err == nil {
+		t.Fatal("expected unknown genesis failure")
+	}
+	if _, err := backup.ImportInterchange(interchange, genesis, types.BLSPublicKey{0x2}); err == nil {
+		t.Fatal("expected BLS public key mismatch failure")
+	}
+	count, err := backup.ImportInterchange(interchange, genesis, pubKey)
+	if err != nil {
+		t.Fatalf("failed to import journal: %v", err)
+	}
+	if count != 10 {
+		t.Fatalf("imported vote count mismatch, want 10, have %d", count)
+	}
+
+	// The watermarks should survive the restart of the node
+	backup, err = NewVoteJournal(backupPath)
+	if err != nil {
+		t.Fatalf("failed to reopen vote journal: %v", err)
+	}
+	// The imported votes are exported again without signing root
+	reexported, err := backup.ExportInterchange(genesis)
+	if err != nil {
+		t.Fatalf("failed to export journal: %v", err)
+	}
+	if len(reexported.Data) != 1 || len(reexported.Data[0].SignedAttestations) != 10 {
+		t.Fatalf("unexpected interchange content: %+v", reexported.Data)
+	}
+	for _, attestation := range reexported.Data[0].SignedAttestations {
+		if attestation.SigningRoot != nil {
+			t.Fatalf("signing root exported for an imported vote: %x", *attestation.SigningRoot)
+		}
+	}
+	if data, _ := json.Marshal(reexported); strings.Contains(string(data), "signing_root") {
+		t.Fatalf("signing root not omitted: %s", data)
+	}
+	backup.SetVoteKey(types.BLSPublicKey{0x2})
+	if backup.HasImportedVotes() {
+		t.Fatal("votes imported for another BLS key should be ignored")
+	}
+	if err := backup.checkSlashingRules(4, 5); err != nil {
+		t.Fatalf("votes imported for another BLS key should be ignored: %v", err)
+	}
+	backup.SetVoteKey(pubKey)
+	if !backup.HasImportedVotes() {
+		t.Fatal("imported votes lost after reopening the journal")
+	}
+	for _, tt := range []struct {
+		source, target uint64
+		safe           bool
+	}{
+		{4, 5, false},   // only below the imported watermarks
+		{18, 19, false}, // same target as the imported vote
+		{17, 18, false}, // below the imported target
+		{17, 20, false}, // below the imported source
+		{18, 20, true},
+		{19, 20, true},
+	} {
+		err := backup.checkSlashingRules(tt.source, tt.target)
+		if (err == nil) != tt.safe {
+			t.Errorf("vote %d-->%d: safe mismatch, want %v, err %v", tt.source, tt.target, tt.safe, err)
+		}
+	}
+
+	// Voting past the imported votes clears the watermarks, also after restart
+	vote := &types.VoteEnvelope{
+		VoteAddress: pubKey,
+		Signature:   types.BLSSignature{0x1},
+		Data:        &types.VoteData{SourceNumber: 19, TargetNumber: 20},
+	}
+	if err := backup.WriteVote(vote); err != nil {
+		t.Fatalf("failed to write vote: %v", err)
+	}
+	if backup.HasImportedVotes() {
+		t.Fatal("imported votes not cleared after voting past them")
+	}
+	backup, err = NewVoteJournal(backupPath)
+	if err != nil {
+		t.Fatalf("failed to reopen vote journal: %v", err)
+	}
+	backup.SetVoteKey(pubKey)
+	if backup.HasImportedVotes() {
+		t.Fatal("imported votes restored after reopening the journal")
+	}
+}
+
+func TestVoteJournalUnsignedVote(t *testing.T) {
+	pubKey := types.BLSPublicKey{0x1}
+	journal := newTestJournal(t)
+	journal.SetVoteKey(pubKey)
+
+	// A vote without signature is not an imported one
+	vote := &types.VoteEnvelope{
+		VoteAddress: pubKey,
+		Data:        &types.VoteData{SourceNumber: 9, TargetNumber: 10},
+	}
+	if err := journal.WriteVote(vote); err != nil {
+		t.Fatalf("failed to write vote: %v", err)
+	}
+	if journal.HasImportedVotes() {
+		t.Fatal("unsigned vote should not be considered as imported")
+	}
+	reopened, err := NewVoteJournal(journal.journalPath)
+	if err != nil {
+		t.Fatalf("failed to reopen vote journal: %v", err)
+	}
+	reopened.SetVoteKey(pubKey)
+	if reopened.HasImportedVotes() {
+		t.Fatal("unsigned vote considered as imported after reopening the journal")
+	}
+}
@@ -2,10 +2,13 @@ package vote
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/tidwall/wal"
 
+	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/log"
 	"github.com/Ezkerrox/bsc/metrics"
@@ -22,6 +25,25 @@ type VoteJournal struct {
 	walLog *wal.Log
 
 	voteDataBuffer *lru.Cache
+
+	// The highest source and target numbers of the votes imported from another
+	// machine per BLS key, no vote at or below them is allowed to be signed with
+	// the key. They are cleared once the local validator has voted past them.
+	imported map[types.BLSPublicKey]*importedRange
+	voteKey  types.BLSPublicKey // BLS key of the local validator
+}
+
+// journalEntry is a vote recorded in the journal, either signed locally or
+// imported from the vote history of another machine.
+type journalEntry struct {
+	*types.VoteEnvelope
+	Imported bool `json:",omitempty"`
+}
+
+// importedRange is the highest source and target numbers of the imported votes.
+type importedRange struct {
+	source uint64
+	target uint64
 }
 
 var voteJournalErrorCounter = metrics.NewRegisteredCounter("voteJournal/error", nil)
@@ -55,13 +77,15 @@ func NewVoteJournal(filePath string) (*VoteJournal, error) {
 	voteJournal := &VoteJournal{
 		journalPath: filePath,
 		walLog:      walLog,
+		imported:    make(map[types.BLSPublicKey]*importedRange),
 	}
 
 	// Reload all voteData from journal to lru memory everytime node reboot.
 	for index := firstIndex; index <= lastIndex; index++ {
-		if voteEnvelop, err := voteJournal.ReadVote(index); err == nil && voteEnvelop != nil {
-			voteData := voteEnvelop.Data
+		if entry, err := voteJournal.readEntry(index); err == nil && entry != nil {
+			voteData := entry.Data
 			voteDataBuffer.Add(voteData.TargetNumber, voteData)
+			voteJournal.track(entry)
 		}
 	}
 	voteJournal.voteDataBuffer = voteDataBuffer
@@ -70,9 +94,13 @@ func NewVoteJournal(filePath string) (*VoteJournal, error) {
 }
 
 func (journal *VoteJournal) WriteVote(voteMessage *types.VoteEnvelope) error {
+	return journal.writeEntry(&journalEntry{VoteEnvelope: voteMessage})
+}
+
+func (journal *VoteJournal) writeEntry(entry *journalEntry) error {
 	walLog := journal.walLog
 
-	vote, err := json.Marshal(voteMessage)
+	vote, err := json.Marshal(entry)
 	if err != nil {
 		log.Error("Failed to unmarshal vote", "err", err)
 		return err
@@ -101,25 +129,126 @@ func (journal *VoteJournal) WriteVote(voteMessage *types.VoteEnvelope) error {
 		}
 	}
 
-	journal.voteDataBuffer.Add(voteMessage.Data.TargetNumber, voteMessage.Data)
+	journal.voteDataBuffer.Add(entry.Data.TargetNumber, entry.Data)
+	journal.track(entry)
 	return nil
 }
 
 func (journal *VoteJournal) ReadVote(index uint64) (*types.VoteEnvelope, error) {
+	entry, err := journal.readEntry(index)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return entry.VoteEnvelope, nil
+}
+
+func (journal *VoteJournal) readEntry(index uint64) (*journalEntry, error) {
 	voteMessage, err := journal.walLog.Read(index)
 	if err != nil && err != wal.ErrNotFound {
 		log.Error("Failed to read votes journal", "err", err)
 		return nil, err
 	}
 
-	var vote *types.VoteEnvelope
+	var entry *journalEntry
 	if voteMessage != nil {
-		vote = &types.VoteEnvelope{}
-		if err := json.Unmarshal(voteMessage, vote); err != nil {
+		entry = &journalEntry{}
+		if err := json.Unmarshal(voteMessage, entry); err != nil {
 			log.Error("Failed to read vote from voteJournal", "err", err)
 			return nil, err
 		}
+		if entry.VoteEnvelope == nil || entry.Data == nil {
+			return nil, nil
+		}
 	}
 
-	return vote, nil
+	return entry, nil
+}
+
+// track updates the imported watermarks of the entry's key. Imported entries
+// raise the watermarks, while a vote signed locally past them clears them, as
+// the imported range is then covered by the local votes.
+func (journal *VoteJournal) track(entry *journalEntry) {
+	key, data := entry.VoteAddress, entry.Data
+	watermarks := journal.imported[key]
+	if entry.Imported {
+		if watermarks == nil {
+			watermarks = new(importedRange)
+			journal.imported[key] = watermarks
+		}
+		watermarks.source = max(watermarks.source, data.SourceNumber)
+		watermarks.target = max(watermarks.target, data.TargetNumber)
+		return
+	}
+	if watermarks != nil && data.TargetNumber > watermarks.target {
+		log.Info("Voted past the imported votes", "source", watermarks.source, "target", watermarks.target)
+		delete(journal.imported, key)
+	}
+}
+
+// SetVoteKey sets the BLS key of the local validator, only the votes imported
+// for this key are enforced.
+func (journal *VoteJournal) SetVoteKey(key types.BLSPublicKey) {
+	journal.voteKey = key
+	for imported := range journal.imported {
+		if imported != key {
+			log.Warn("Ignore the votes imported for another BLS key", "key", common.Bytes2Hex(imported[:]))
+		}
+	}
+}
+
+// HasImportedVotes returns whether the journal contains the vote history of the
+// local BLS key carried over from another machine.
+func (journal *VoteJournal) HasImportedVotes() bool {
+	return journal.imported[journal.voteKey] != nil
+}
+
+// checkSlashingRules checks whether a vote from sourceNumber to targetNumber is
+// safe to sign against the votes recorded in the journal:
+// A validator must not publish two distinct votes for the same height. (Rule 1)
+// A validator must not vote within the span of its other votes . (Rule 2)
+func (journal *VoteJournal) checkSlashingRules(sourceNumber, targetNumber uint64) error {
+	// The votes signed on another machine are only known by their numbers.
+	if watermarks := journal.imported[journal.voteKey]; watermarks != nil && (targetNumber <= watermarks.target || sourceNumber < watermarks.source) {
+		return fmt.Errorf("cur vote %d-->%d is below the imported watermarks %d-->%d",
+			sourceNumber, targetNumber, watermarks.source, watermarks.target)
+	}
+
+	voteDataBuffer := journal.voteDataBuffer
+	//Rule 1:  A validator must not publish two distinct votes for the same height.
+	if voteDataBuffer.Contains(targetNumber) {
+		return errors.New("a validator must not publish two distinct votes for the same height")
+	}
+
+	//Rule 2: A validator must not vote within the span of its other votes.
+	blockNumber := sourceNumber + 1
+	if blockNumber+maliciousVoteSlashScope < targetNumber {
+		blockNumber = targetNumber - maliciousVoteSlashScope
+	}
+	for ; blockNumber < targetNumber; blockNumber++ {
+		if voteDataBuffer.Contains(blockNumber) {
+			voteData, ok := voteDataBuffer.Get(blockNumber)
+			if !ok {
+				log.Error("Failed to get voteData info from LRU cache.")
+				continue
+			}
+			if voteData.(*types.VoteData).SourceNumber > sourceNumber {
+				return fmt.Errorf("cur vote %d-->%d is across the span of other votes %d-->%d",
+					sourceNumber, targetNumber, voteData.(*types.VoteData).SourceNumber, voteData.(*types.VoteData).TargetNumber)
+			}
+		}
+	}
+	for blockNumber := targetNumber + 1; blockNumber <= targetNumber+upperLimitOfVoteBlockNumber; blockNumber++ {
+		if voteDataBuffer.Contains(blockNumber) {
+			voteData, ok := voteDataBuffer.Get(blockNumber)
+			if !ok {
+				log.Error("Failed to get voteData info from LRU cache.")
+				continue
+			}
+			if voteData.(*types.VoteData).SourceNumber < sourceNumber {
+				return fmt.Errorf("cur vote %d-->%d is within the span of other votes %d-->%d",
+					sourceNumber, targetNumber, voteData.(*types.VoteData).SourceNumber, voteData.(*types.VoteData).TargetNumber)
+			}
+		}
+	}
+	return nil
 }
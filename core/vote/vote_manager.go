@@ -2,7 +2,6 @@ package vote
 
 import (
 	"bytes"
-	"fmt"
 	"math/big"
 	"time"
 
@@ -22,6 +21,8 @@ import (
 // the new node may cast votes for the same block height that the previous node already voted on.
 // To avoid double-voting issues, the node should wait for a few blocks
 // before participating in voting after it starts mining.
+// The wait is skipped if the vote history of the previous node has been imported
+// into the journal, as the imported watermarks already prevent double-voting.
 const blocksNumberSinceMining = 20
 
 var diffInTurn = big.NewInt(2) // Block difficulty for in-turn signatures
@@ -52,13 +53,13 @@ type VoteManager struct {
 	syncVoteSub event.Subscription
 
 	pool    *VotePool
-	signer  *VoteSigner
+	signer  Signer
 	journal *VoteJournal
 
 	engine consensus.PoSA
 }
 
-func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journalPath, blsPasswordPath, blsWalletPath string, engine consensus.PoSA) (*VoteManager, error) {
+func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journalPath string, signerConfig *SignerConfig, engine consensus.PoSA) (*VoteManager, error) {
 	voteManager := &VoteManager{
 		eth:                    eth,
 		chain:                  chain,
@@ -68,15 +69,6 @@ func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journal
 		engine:                 engine,
 	}
 
-	// Create voteSigner.
-	voteSigner, err := NewVoteSigner(blsPasswordPath, blsWalletPath)
-	if err != nil {
-		return nil, err
-	}
-	log.Info("Create voteSigner successfully")
-	voteManager.signer = voteSigner
-	metrics.GetOrRegisterLabel("miner-info", nil).Mark(map[string]interface{}{"VoteKey": common.Bytes2Hex(voteManager.signer.PubKey[:])})
-
 	// Create voteJournal
 	voteJournal, err := NewVoteJournal(journalPath)
 	if err != nil {
@@ -85,6 +77,17 @@ func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journal
 	log.Info("Create voteJournal successfully")
 	voteManager.journal = voteJournal
 
+	// Create voteSigner.
+	voteSigner, err := NewSigner(signerConfig, voteJournal)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Create voteSigner successfully")
+	voteManager.signer = voteSigner
+	pubKey := voteManager.signer.PublicKey()
+	voteJournal.SetVoteKey(pubKey)
+	metrics.GetOrRegisterLabel("miner-info", nil).Mark(map[string]interface{}{"VoteKey": common.Bytes2Hex(pubKey[:])})
+
 	// Subscribe to chain head event.
 	voteManager.highestVerifiedBlockSub = voteManager.chain.SubscribeHighestVerifiedHeaderEvent(voteManager.highestVerifiedBlockCh)
 	voteManager.syncVoteSub = voteManager.pool.SubscribeNewVoteEvent(voteManager.syncVoteCh)
@@ -141,7 +144,7 @@ func (voteManager *VoteManager) loop() {
 				continue
 			}
 			blockCountSinceMining++
-			if blockCountSinceMining <= blocksNumberSinceMining {
+			if blockCountSinceMining <= blocksNumberSinceMining && !voteManager.journal.HasImportedVotes() {
 				log.Debug("skip voting", "blockCountSinceMining", blockCountSinceMining, "blocksNumberSinceMining", blocksNumberSinceMining)
 				continue
 			}
@@ -169,7 +172,8 @@ func (voteManager *VoteManager) loop() {
 			// Check if cur validator is within the validatorSet at curHead
 			if !voteManager.engine.IsActiveValidatorAt(voteManager.chain, curHead,
 				func(bLSPublicKey *types.BLSPublicKey) bool {
-					return bytes.Equal(voteManager.signer.PubKey[:], bLSPublicKey[:])
+					pubKey := voteManager.signer.PublicKey()
+					return bytes.Equal(pubKey[:], bLSPublicKey[:])
 				}) {
 				log.Debug("local validator with voteKey is not within the validatorSet at curHead")
 				continue
@@ -243,7 +247,8 @@ func (voteManager *VoteManager) loop() {
 
 		case event := <-voteManager.syncVoteCh:
 			voteMessage := event.Vote
-			if voteManager.eth.IsMining() || !bytes.Equal(voteManager.signer.PubKey[:], voteMessage.VoteAddress[:]) {
+			pubKey := voteManager.signer.PublicKey()
+			if voteManager.eth.IsMining() || !bytes.Equal(pubKey[:], voteMessage.VoteAddress[:]) {
 				continue
 			}
 			if err := voteManager.journal.WriteVote(voteMessage); err != nil {
@@ -276,47 +281,11 @@ func (voteManager *VoteManager) UnderRules(header *types.Header) (bool, uint64,
 
 	targetNumber := header.Number.Uint64()
 
-	voteDataBuffer := voteManager.journal.voteDataBuffer
-	//Rule 1:  A validator must not publish two distinct votes for the same height.
-	if voteDataBuffer.Contains(targetNumber) {
-		log.Debug("err: A validator must not publish two distinct votes for the same height.")
+	if err := voteManager.journal.checkSlashingRules(sourceNumber, targetNumber); err != nil {
+		log.Debug("err: vote violates the slashing rules", "err", err)
 		return false, 0, common.Hash{}
 	}
 
-	//Rule 2: A validator must not vote within the span of its other votes.
-	blockNumber := sourceNumber + 1
-	if blockNumber+maliciousVoteSlashScope < targetNumber {
-		blockNumber = targetNumber - maliciousVoteSlashScope
-	}
-	for ; blockNumber < targetNumber; blockNumber++ {
-		if voteDataBuffer.Contains(blockNumber) {
-			voteData, ok := voteDataBuffer.Get(blockNumber)
-			if !ok {
-				log.Error("Failed to get voteData info from LRU cache.")
-				continue
-			}
-			if voteData.(*types.VoteData).SourceNumber > sourceNumber {
-				log.Debug(fmt.Sprintf("error: cur vote %d-->%d is across the span of other votes %d-->%d",
-					sourceNumber, targetNumber, voteData.(*types.VoteData).SourceNumber, voteData.(*types.VoteData).TargetNumber))
-				return false, 0, common.Hash{}
-			}
-		}
-	}
-	for blockNumber := targetNumber + 1; blockNumber <= targetNumber+upperLimitOfVoteBlockNumber; blockNumber++ {
-		if voteDataBuffer.Contains(blockNumber) {
-			voteData, ok := voteDataBuffer.Get(blockNumber)
-			if !ok {
-				log.Error("Failed to get voteData info from LRU cache.")
-				continue
-			}
-			if voteData.(*types.VoteData).SourceNumber < sourceNumber {
-				log.Debug(fmt.Sprintf("error: cur vote %d-->%d is within the span of other votes %d-->%d",
-					sourceNumber, targetNumber, voteData.(*types.VoteData).SourceNumber, voteData.(*types.VoteData).TargetNumber))
-				return false, 0, common.Hash{}
-			}
-		}
-	}
-
 	// Rule 3: Validators always vote for their canonical chain’s latest block.
 	// Since the header subscribed to is the canonical chain, so this rule is satisfied by default.
 	log.Debug("All three rules check passed")
@@ -2,7 +2,10 @@ package vote
 
 import (
 	"container/heap"
+	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -67,6 +70,8 @@ type VotePool struct {
 	scope     event.SubscriptionScope
 
 	receivedVotes mapset.Set[common.Hash]
+	receipts      map[common.Hash]VoteReceipt // How the votes in the pool were received, by vote hash
+	dropped       droppedVotes
 
 	curVotes    map[common.Hash]*VoteBox
 	futureVotes map[common.Hash]*VoteBox
@@ -77,23 +82,56 @@ type VotePool struct {
 	highestVerifiedBlockCh  chan core.HighestVerifiedBlockEvent
 	highestVerifiedBlockSub event.Subscription
 
-	votesCh chan *types.VoteEnvelope
+	votesCh chan *voteArrival
 
 	engine consensus.PoSA
 }
 
 type votesPriorityQueue []*types.VoteData
 
+// VoteReceipt records how a vote reached the pool.
+type VoteReceipt struct {
+	From string    // ID of the peer which delivered the vote, empty for the local votes
+	Time time.Time // Time the vote was received
+}
+
+type voteArrival struct {
+	vote    *types.VoteEnvelope
+	receipt VoteReceipt
+}
+
+// droppedVotes counts the votes rejected by the pool, per reason.
+type droppedVotes struct {
+	outOfRange       atomic.Uint64 // The target block is too old or too far ahead
+	duplicated       atomic.Uint64 // The vote is already in the pool
+	overflow         atomic.Uint64 // The target block has got the maximum amount of votes
+	badSignature     atomic.Uint64 // The BLS signature is invalid
+	unknownValidator atomic.Uint64 // The vote address isn't one of a validator
+	invalid          atomic.Uint64 // The target or source block doesn't match the local chain
+	invalidErr       atomic.Value  // The error of the last invalid vote
+}
+
+// countUnverified counts a vote rejected by the consensus engine, per reason.
+func (d *droppedVotes) countUnverified(err error) {
+	if errors.Is(err, consensus.ErrUnknownVoteAddress) {
+		d.unknownValidator.Add(1)
+		return
+	}
+	d.invalid.Add(1)
+	d.invalidErr.Store(err.Error())
+}
+
 func NewVotePool(chain *core.BlockChain, engine consensus.PoSA) *VotePool {
 	votePool := &VotePool{
 		chain:                  chain,
 		receivedVotes:          mapset.NewSet[common.Hash](),
+		receipts:               make(map[common.Hash]VoteReceipt),
 		curVotes:               make(map[common.Hash]*VoteBox),
 		futureVotes:            make(map[common.Hash]*VoteBox),
 		curVotesPq:             &votesPriorityQueue{},
 		futureVotesPq:          &votesPriorityQueue{},
 		highestVerifiedBlockCh: make(chan core.HighestVerifiedBlockEvent, highestVerifiedBlockChanSize),
-		votesCh:                make(chan *types.VoteEnvelope, voteBufferForPut),
+		votesCh:                make(chan *voteArrival, voteBufferForPut),
 		engine:                 engine,
 	}
 
@@ -121,17 +159,23 @@ func (pool *VotePool) loop() {
 			return
 
 		// Handle votes channel and put the vote into vote pool.
-		case vote := <-pool.votesCh:
-			pool.putIntoVotePool(vote)
+		case arrival := <-pool.votesCh:
+			pool.putIntoVotePool(arrival.vote, arrival.receipt)
 		}
 	}
 }
 
+// PutVote puts a vote produced locally into the pool.
 func (pool *VotePool) PutVote(vote *types.VoteEnvelope) {
-	pool.votesCh <- vote
+	pool.votesCh <- &voteArrival{vote: vote, receipt: VoteReceipt{Time: time.Now()}}
+}
+
+// PutRemoteVote puts a vote delivered by the given peer into the pool.
+func (pool *VotePool) PutRemoteVote(vote *types.VoteEnvelope, peer string) {
+	pool.votesCh <- &voteArrival{vote: vote, receipt: VoteReceipt{From: peer, Time: time.Now()}}
 }
 
-func (pool *VotePool) putIntoVotePool(vote *types.VoteEnvelope) bool {
+func (pool *VotePool) putIntoVotePool(vote *types.VoteEnvelope, receipt VoteReceipt) bool {
 	targetNumber := vote.Data.TargetNumber
 	targetHash := vote.Data.TargetHash
 	header := pool.chain.CurrentBlock()
@@ -140,6 +184,7 @@ func (pool *VotePool) putIntoVotePool(vote *types.VoteEnvelope) bool {
 	// Make sure in the range (currentHeight-lowerLimitOfVoteBlockNumber, currentHeight+upperLimitOfVoteBlockNumber].
 	if targetNumber+lowerLimitOfVoteBlockNumber-1 < headNumber || targetNumber > headNumber+upperLimitOfVoteBlockNumber {
 		log.Debug("BlockNumber of vote is outside the range of header-256~header+11, will be discarded")
+		pool.dropped.outOfRange.Add(1)
 		return false
 	}
 
@@ -169,7 +214,8 @@ func (pool *VotePool) putIntoVotePool(vote *types.VoteEnvelope) bool {
 
 	if !isFutureVote {
 		// Verify if the vote comes from valid validators based on voteAddress (BLSPublicKey), only verify curVotes here, will verify futureVotes in transfer process.
-		if pool.engine.VerifyVote(pool.chain, vote) != nil {
+		if err := pool.engine.VerifyVote(pool.chain, vote); err != nil {
+			pool.dropped.countUnverified(err)
 			return false
 		}
 
@@ -178,7 +224,7 @@ func (pool *VotePool) putIntoVotePool(vote *types.VoteEnvelope) bool {
 		pool.votesFeed.Send(voteEv)
 	}
 
-	pool.putVote(votes, votesPq, vote, voteData, voteHash, isFutureVote)
+	pool.putVote(votes, votesPq, vote, voteData, voteHash, isFutureVote, receipt)
 
 	return true
 }
@@ -187,7 +233,7 @@ func (pool *VotePool) SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.S
 	return pool.scope.Track(pool.votesFeed.Subscribe(ch))
 }
 
-func (pool *VotePool) putVote(m map[common.Hash]*VoteBox, votesPq *votesPriorityQueue, vote *types.VoteEnvelope, voteData *types.VoteData, voteHash common.Hash, isFutureVote bool) {
+func (pool *VotePool) putVote(m map[common.Hash]*VoteBox, votesPq *votesPriorityQueue, vote *types.VoteEnvelope, voteData *types.VoteData, voteHash common.Hash, isFutureVote bool, receipt VoteReceipt) {
 	targetHash := vote.Data.TargetHash
 	targetNumber := vote.Data.TargetNumber
 
@@ -218,6 +264,7 @@ func (pool *VotePool) putVote(m map[common.Hash]*VoteBox, votesPq *votesPriority
 	m[targetHash].trySetRecvVoteTime(pool.chain)
 	// Add into received vote to avoid future duplicated vote comes.
 	pool.receivedVotes.Add(voteHash)
+	pool.receipts[voteHash] = receipt
 	log.Debug("VoteHash put into votepool is:", "voteHash", voteHash)
 
 	if isFutureVote {
@@ -274,8 +321,10 @@ func (pool *VotePool) transfer(blockHash common.Hash) {
 	validVotes := make([]*types.VoteEnvelope, 0, len(voteBox.voteMessages))
 	for _, vote := range voteBox.voteMessages {
 		// Verify if the vote comes from valid validators based on voteAddress (BLSPublicKey).
-		if pool.engine.VerifyVote(pool.chain, vote) != nil {
+		if err := pool.engine.VerifyVote(pool.chain, vote); err != nil {
 			pool.receivedVotes.Remove(vote.Hash())
+			delete(pool.receipts, vote.Hash())
+			pool.dropped.countUnverified(err)
 			continue
 		}
 
@@ -322,6 +371,7 @@ func (pool *VotePool) prune(latestBlockNumber uint64) {
 			for _, voteMessage := range voteMessages {
 				voteHash := voteMessage.Hash()
 				pool.receivedVotes.Remove(voteHash)
+				delete(pool.receipts, voteHash)
 			}
 			// Prune curVotes Map.
 			delete(curVotes, blockHash)
@@ -354,6 +404,90 @@ func (pool *VotePool) FetchVoteByBlockHash(blockHash common.Hash) []*types.VoteE
 	return nil
 }
 
+// PoolVote is a vote in the pool along with how it was received.
+type PoolVote struct {
+	Vote    *types.VoteEnvelope
+	Receipt VoteReceipt
+	Future  bool // Whether the target block isn't verified locally yet
+}
+
+// FetchPoolVotes returns the current and future votes for the given block,
+// along with how they were received.
+func (pool *VotePool) FetchPoolVotes(blockHash common.Hash) []*PoolVote {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var votes []*PoolVote
+	for _, box := range []*VoteBox{pool.curVotes[blockHash], pool.futureVotes[blockHash]} {
+		if box == nil {
+			continue
+		}
+		for _, vote := range box.voteMessages {
+			votes = append(votes, &PoolVote{
+				Vote:    vote,
+				Receipt: pool.receipts[vote.Hash()],
+				Future:  box == pool.futureVotes[blockHash],
+			})
+		}
+	}
+	return votes
+}
+
+// BlockVotes is the number of votes the pool holds for a target block.
+type BlockVotes struct {
+	Number uint64
+	Hash   common.Hash
+	Votes  int
+	Future bool
+}
+
+// PoolStatus summarizes the content of the pool.
+type PoolStatus struct {
+	CurVotes    int
+	FutureVotes int
+	Blocks      []BlockVotes // Votes per target block, by descending block number
+
+	DroppedOutOfRange       uint64
+	DroppedDuplicated       uint64
+	DroppedOverflow         uint64
+	DroppedBadSignature     uint64
+	DroppedUnknownValidator uint64
+	DroppedInvalid          uint64
+	LastInvalidError        string // Error of the last vote dropped as invalid, empty if none
+}
+
+// Status returns the number of current and future votes per target block, and
+// the number of votes dropped per reason since the pool started.
+func (pool *VotePool) Status() *PoolStatus {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	status := &PoolStatus{
+		Blocks:                  make([]BlockVotes, 0, len(pool.curVotes)+len(pool.futureVotes)),
+		DroppedOutOfRange:       pool.dropped.outOfRange.Load(),
+		DroppedDuplicated:       pool.dropped.duplicated.Load(),
+		DroppedOverflow:         pool.dropped.overflow.Load(),
+		DroppedBadSignature:     pool.dropped.badSignature.Load(),
+		DroppedUnknownValidator: pool.dropped.unknownValidator.Load(),
+		DroppedInvalid:          pool.dropped.invalid.Load(),
+	}
+	if err, ok := pool.dropped.invalidErr.Load().(string); ok {
+		status.LastInvalidError = err
+	}
+	for _, box := range pool.curVotes {
+		status.CurVotes += len(box.voteMessages)
+		status.Blocks = append(status.Blocks, BlockVotes{Number: box.blockNumber, Hash: box.blockHash, Votes: len(box.voteMessages)})
+	}
+	for _, box := range pool.futureVotes {
+		status.FutureVotes += len(box.voteMessages)
+		status.Blocks = append(status.Blocks, BlockVotes{Number: box.blockNumber, Hash: box.blockHash, Votes: len(box.voteMessages), Future: true})
+	}
+	sort.Slice(status.Blocks, func(i, j int) bool {
+		return status.Blocks[i].Number > status.Blocks[j].Number
+	})
+	return status
+}
+
 func (pool *VotePool) basicVerify(vote *types.VoteEnvelope, headNumber uint64, m map[common.Hash]*VoteBox, isFutureVote bool, voteHash common.Hash) bool {
 	targetHash := vote.Data.TargetHash
 	pool.mu.RLock()
@@ -362,6 +496,7 @@ func (pool *VotePool) basicVerify(vote *types.VoteEnvelope, headNumber uint64, m
 	// Check duplicate voteMessage firstly.
 	if pool.receivedVotes.Contains(voteHash) {
 		log.Trace("Vote pool already contained the same vote", "voteHash", voteHash)
+		pool.dropped.duplicated.Add(1)
 		return false
 	}
 
@@ -373,6 +508,7 @@ func (pool *VotePool) basicVerify(vote *types.VoteEnvelope, headNumber uint64, m
 	}
 	if voteBox, ok := m[targetHash]; ok {
 		if len(voteBox.voteMessages) >= maxVoteAmountPerBlock {
+			pool.dropped.overflow.Add(1)
 			return false
 		}
 	}
@@ -380,6 +516,7 @@ func (pool *VotePool) basicVerify(vote *types.VoteEnvelope, headNumber uint64, m
 	// Verify bls signature.
 	if err := vote.Verify(); err != nil {
 		log.Error("Failed to verify voteMessage", "err", err)
+		pool.dropped.badSignature.Add(1)
 		return false
 	}
 
@@ -140,6 +140,75 @@ func TestInvalidVotePool(t *testing.T) {
 	testVotePool(t, false)
 }
 
+func TestVotePoolRemoteVote(t *testing.T) {
+	walletPasswordDir, walletDir := setUpKeyManager(t)
+	signer, err := NewVoteSigner(walletPasswordDir, walletDir)
+	if err != nil {
+		t.Fatalf("failed to create vote signer: %v", err)
+	}
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
+	}
+	chain, _ := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFullFaker(), vm.Config{}, nil, nil)
+	votePool := NewVotePool(chain, &mockPOSA{})
+
+	newVote := func(target uint64) *types.VoteEnvelope {
+		vote := &types.VoteEnvelope{
+			Data: &types.VoteData{TargetNumber: target, TargetHash: common.Hash{byte(target)}},
+		}
+		if err := signer.SignVote(vote); err != nil {
+			t.Fatalf("sign vote failed: %v", err)
+		}
+		return vote
+	}
+	remoteVote := newVote(5)
+	votePool.PutRemoteVote(remoteVote, "remote")
+	if !votePool.verifyStructureSizeOfVotePool(1, 0, 1, 0, 1) {
+		t.Fatalf("put vote failed")
+	}
+	votes := votePool.FetchPoolVotes(remoteVote.Data.TargetHash)
+	if len(votes) != 1 || !votes[0].Future || votes[0].Receipt.From != "remote" || votes[0].Receipt.Time.IsZero() {
+		t.Fatalf("remote vote receipt mismatch")
+	}
+
+	// The dropped votes are counted per reason, the local votes have no sender
+	votePool.PutRemoteVote(remoteVote, "remote")
+	votePool.PutRemoteVote(newVote(1000), "remote")
+	localVote := newVote(6)
+	votePool.PutVote(localVote)
+	if !votePool.verifyStructureSizeOfVotePool(2, 0, 2, 0, 2) {
+		t.Fatalf("put vote failed")
+	}
+	if votes := votePool.FetchPoolVotes(localVote.Data.TargetHash); len(votes) != 1 || votes[0].Receipt.From != "" {
+		t.Fatalf("local vote receipt mismatch")
+	}
+	status := votePool.Status()
+	if status.FutureVotes != 2 || status.DroppedDuplicated != 1 || status.DroppedOutOfRange != 1 {
+		t.Fatalf("pool status mismatch: %+v", status)
+	}
+	if len(status.Blocks) != 2 || status.Blocks[0].Number != 6 || !status.Blocks[0].Future {
+		t.Fatalf("pool blocks mismatch: %+v", status.Blocks)
+	}
+}
+
+func TestDroppedVotesUnverified(t *testing.T) {
+	var dropped droppedVotes
+	dropped.countUnverified(consensus.ErrUnknownVoteAddress)
+	dropped.countUnverified(errors.New("target number mismatch"))
+	dropped.countUnverified(errors.New("vote source block mismatch"))
+
+	if have := dropped.unknownValidator.Load(); have != 1 {
+		t.Fatalf("unknown validator count mismatch: have %d, want 1", have)
+	}
+	if have := dropped.invalid.Load(); have != 2 {
+		t.Fatalf("invalid count mismatch: have %d, want 2", have)
+	}
+	if have := dropped.invalidErr.Load(); have != "vote source block mismatch" {
+		t.Fatalf("last invalid error mismatch: have %v", have)
+	}
+}
+
 func testVotePool(t *testing.T, isValidRules bool) {
 	walletPasswordDir, walletDir := setUpKeyManager(t)
 
@@ -175,7 +244,7 @@ func testVotePool(t *testing.T, isValidRules bool) {
 	file.Close()
 	os.Remove(journal)
 
-	voteManager, err := NewVoteManager(newTestBackend(), chain, votePool, journal, walletPasswordDir, walletDir, mockEngine)
+	voteManager, err := NewVoteManager(newTestBackend(), chain, votePool, journal, &SignerConfig{PasswordPath: walletPasswordDir, WalletPath: walletDir}, mockEngine)
 	if err != nil {
 		t.Fatalf("failed to create vote managers")
 	}
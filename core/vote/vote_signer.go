@@ -24,6 +24,31 @@ const (
 
 var votesSigningErrorCounter = metrics.NewRegisteredCounter("votesSigner/error", nil)
 
+// Signer signs the votes produced by the local validator with its BLS key.
+type Signer interface {
+	// PublicKey returns the BLS public key the votes are signed with.
+	PublicKey() [48]byte
+
+	// SignVote signs the vote data and fills in the vote address and signature.
+	SignVote(vote *types.VoteEnvelope) error
+}
+
+// SignerConfig specifies where the BLS key used to sign votes is kept.
+type SignerConfig struct {
+	PasswordPath string              // Password file of the local BLS wallet
+	WalletPath   string              // Directory of the local BLS wallet
+	Remote       *RemoteSignerConfig // Remote signing service, the local wallet is not used if set
+}
+
+// NewSigner creates the vote signer specified by the config. The journal is used
+// by the remote signer to double check a vote is safe before sending it out.
+func NewSigner(config *SignerConfig, journal *VoteJournal) (Signer, error) {
+	if config.Remote != nil {
+		return NewRemoteSigner(config.Remote, journal)
+	}
+	return NewVoteSigner(config.PasswordPath, config.WalletPath)
+}
+
 type VoteSigner struct {
 	km     *keymanager.IKeymanager
 	PubKey [48]byte
@@ -78,6 +103,11 @@ func NewVoteSigner(blsPasswordPath, blsWalletPath string) (*VoteSigner, error) {
 	}, nil
 }
 
+// PublicKey implements Signer, returning the first public key of the wallet.
+func (signer *VoteSigner) PublicKey() [48]byte {
+	return signer.PubKey
+}
+
 func (signer *VoteSigner) SignVote(vote *types.VoteEnvelope) error {
 	// Sign the vote, fetch the first pubKey as validator's bls public key.
 	pubKey := signer.PubKey
@@ -19,12 +19,14 @@ package eth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/Ezkerrox/bsc"
 	"github.com/Ezkerrox/bsc/accounts"
 	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/lru"
 	"github.com/Ezkerrox/bsc/consensus"
 	"github.com/Ezkerrox/bsc/consensus/misc/eip4844"
 	"github.com/Ezkerrox/bsc/consensus/parlia"
@@ -45,12 +47,24 @@ import (
 	"github.com/Ezkerrox/bsc/rpc"
 )
 
+const (
+	// archiveMissCacheSize is the number of hashes recently missed in the blob
+	// archive, which are not looked up again for a while.
+	archiveMissCacheSize = 1024
+
+	// archiveMissExpiry is the duration a hash missed in the blob archive is not
+	// looked up again for.
+	archiveMissExpiry = time.Minute
+)
+
 // EthAPIBackend implements ethapi.Backend and tracers.Backend for full nodes
 type EthAPIBackend struct {
 	extRPCEnabled       bool
 	allowUnprotectedTxs bool
 	eth                 *Ethereum
 	gpo                 *gasprice.Oracle
+
+	archiveMisses *lru.Cache[common.Hash, time.Time] // Hashes recently missed in the blob archive, with the time of the miss
 }
 
 // ChainConfig returns the active chain configuration.
@@ -206,13 +220,27 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	if header == nil {
 		return nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(header.Root)
 	if err != nil {
 		return nil, nil, err
 	}
 	return stateDb, header, nil
 }
 
+// stateAt returns the state of the given root, falling back to the historical
+// states of the path scheme if enabled.
+func (b *EthAPIBackend) stateAt(root common.Hash) (*state.StateDB, error) {
+	stateDb, err := b.eth.BlockChain().StateAt(root)
+	if err != nil && b.eth.config.HistoricalState && b.eth.blockchain.TrieDB().Scheme() == rawdb.PathScheme {
+		historic, herr := b.eth.BlockChain().HistoricState(root)
+		if herr != nil {
+			return nil, fmt.Errorf("historical state %x is not available: %w", root, herr)
+		}
+		return historic, nil
+	}
+	return stateDb, err
+}
+
 func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
@@ -228,7 +256,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number.Uint64()) != hash {
 			return nil, nil, errors.New("hash is not currently canonical")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(header.Root)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -241,9 +269,40 @@ func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (type
 	return b.eth.blockchain.GetReceiptsByHash(hash), nil
 }
 
+// GetBlobSidecars retrieves the blob sidecars of the block, falling back to the
+// blob archive for the ones pruned from the database.
 func (b *EthAPIBackend) GetBlobSidecars(ctx context.Context, hash common.Hash) (types.BlobSidecars, error) {
-	return b.eth.blockchain.GetSidecarsByHash(hash), nil
+	if sidecars := b.eth.blockchain.GetSidecarsByHash(hash); sidecars != nil {
+		return sidecars, nil
+	}
+	if b.archiveMissed(hash) {
+		return nil, nil
+	}
+	sidecars := b.eth.blockchain.GetArchivedSidecars(hash)
+	if sidecars == nil {
+		b.archiveMisses.Add(hash, time.Now())
+	}
+	return sidecars, nil
+}
+
+func (b *EthAPIBackend) GetArchivedBlobSidecar(ctx context.Context, txHash common.Hash) (*types.BlobSidecar, error) {
+	if b.archiveMissed(txHash) {
+		return nil, nil
+	}
+	sidecar := b.eth.blockchain.GetArchivedSidecarByTxHash(txHash)
+	if sidecar == nil {
+		b.archiveMisses.Add(txHash, time.Now())
+	}
+	return sidecar, nil
 }
+
+// archiveMissed reports whether the hash was missed in the blob archive shortly
+// before, in which case it's not looked up again.
+func (b *EthAPIBackend) archiveMissed(hash common.Hash) bool {
+	missed, ok := b.archiveMisses.Get(hash)
+	return ok && time.Since(missed) < archiveMissExpiry
+}
+
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
 	return rawdb.ReadLogs(b.eth.chainDb, hash, number), nil
 }
@@ -284,6 +343,10 @@ func (b *EthAPIBackend) SubscribeFinalizedHeaderEvent(ch chan<- core.FinalizedHe
 	return b.eth.BlockChain().SubscribeFinalizedHeaderEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeBlockTimelineEvent(ch chan<- core.BlockTimelineEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeBlockTimelineEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return b.eth.BlockChain().SubscribeLogsEvent(ch)
 }
@@ -509,6 +572,26 @@ func (b *EthAPIBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 	return b.Miner().BestPackedBlockReward(parentHash)
 }
 
+func (b *EthAPIBackend) SendBundle(ctx context.Context, bundle *types.Bundle) error {
+	return b.Miner().SendBundle(bundle)
+}
+
+func (b *EthAPIBackend) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error {
+	if err := b.Miner().SendPrivateTransaction(tx, maxBlockNumber); err != nil {
+		return err
+	}
+	b.eth.handler.SendPrivateTransactions(types.Transactions{tx}, maxBlockNumber)
+	return nil
+}
+
+func (b *EthAPIBackend) BidHistory(number *uint64, builder *common.Address) []*types.BidRecord {
+	return b.Miner().BidHistory(number, builder)
+}
+
+func (b *EthAPIBackend) BuilderStats() []*types.BuilderStats {
+	return b.Miner().BuilderStats()
+}
+
 func (b *EthAPIBackend) MinerInTurn() bool {
 	return b.Miner().InTurn()
 }
@@ -24,6 +24,7 @@ import (
 
 	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core"
 	"github.com/Ezkerrox/bsc/core/rawdb"
 	"github.com/Ezkerrox/bsc/core/state"
 	"github.com/Ezkerrox/bsc/core/types"
@@ -446,3 +447,195 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// StartPruning starts the online pruning of the stale state in the background,
+// or resumes the paused one. It's only available for non-archive nodes in the
+// hash-based scheme.
+func (api *DebugAPI) StartPruning() error {
+	if api.eth.onlinePruner == nil {
+		return errors.New("online pruning is only available for non-archive nodes in hash-based scheme")
+	}
+	if !api.eth.Synced() {
+		return errors.New("online pruning is not available during the initial sync")
+	}
+	if api.eth.stateMigrator.migrating() {
+		return errors.New("online pruning is not available during the state migration")
+	}
+	return api.eth.onlinePruner.Start()
+}
+
+// StopPruning pauses the running online pruning, it's resumed by StartPruning.
+func (api *DebugAPI) StopPruning() error {
+	if api.eth.onlinePruner == nil {
+		return errors.New("online pruning is only available for non-archive nodes in hash-based scheme")
+	}
+	return api.eth.onlinePruner.Stop()
+}
+
+// StartStateMigration starts converting the hash-based state of the given block,
+// the head one by default, into the path-based scheme in the background. The
+// paused conversion is resumed if no block is given. Once it completes, the node
+// switches to the path-based scheme at the next restart.
+//
+// The path-based state stays at the converted block: at the restart the chain
+// is rewound to it, discarding all the blocks imported since, including those
+// imported during the conversion, which are then synced again. Blocks further
+// behind the head than the in-memory tries are refused.
+func (api *DebugAPI) StartStateMigration(number *hexutil.Uint64) error {
+	if !api.eth.Synced() {
+		return errors.New("state migration is not available during the initial sync")
+	}
+	return api.eth.stateMigrator.start((*uint64)(number))
+}
+
+// StopStateMigration pauses the running state migration, it's resumed by
+// StartStateMigration.
+func (api *DebugAPI) StopStateMigration() error {
+	return api.eth.stateMigrator.stop()
+}
+
+// GetBlockTimeline returns the propagation timeline of a recent block: when it
+// was announced, received, imported or sealed locally, and when its votes were
+// sent and received, along with the peers it came from.
+func (api *DebugAPI) GetBlockTimeline(hash common.Hash) (*core.BlockTimeline, error) {
+	timeline := api.eth.blockchain.GetBlockTimeline(hash)
+	if timeline == nil {
+		return nil, fmt.Errorf("block %#x not found", hash)
+	}
+	return timeline, nil
+}
+
+// TxProfileResult is the execution time of a transaction during a block import.
+type TxProfileResult struct {
+	Hash     common.Hash    `json:"hash"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	Duration uint64         `json:"duration"`
+}
+
+// BlockProfileResult is the time spent in each stage of a block import, in
+// microseconds.
+type BlockProfileResult struct {
+	Number           hexutil.Uint64     `json:"number"`
+	Hash             common.Hash        `json:"hash"`
+	GasUsed          hexutil.Uint64     `json:"gasUsed"`
+	ImportedAt       int64              `json:"importedAt"` // Unix milliseconds the import started at
+	Verify           uint64             `json:"verify"`
+	DataAvailability uint64             `json:"dataAvailability"` // Shared by the blocks imported in a batch
+	Prefetch         uint64             `json:"prefetch"`         // Concurrent with the execution
+	Execution        uint64             `json:"execution"`
+	StateRead        uint64             `json:"stateRead"`
+	Validation       uint64             `json:"validation"`
+	StateRoot        uint64             `json:"stateRoot"`
+	Write            uint64             `json:"write"`
+	TrieCommit       uint64             `json:"trieCommit"`
+	SnapshotCommit   uint64             `json:"snapshotCommit"`
+	TrieDBCommit     uint64             `json:"trieDBCommit"`
+	Total            uint64             `json:"total"`
+	Transactions     []*TxProfileResult `json:"transactions"`
+}
+
+// GetBlockProfile returns the time spent in each stage of the import of the
+// block with the given number, and the execution time of its transactions. The
+// profiles of the recent blocks are only retained if the block profiler is
+// enabled.
+func (api *DebugAPI) GetBlockProfile(number rpc.BlockNumber) (*BlockProfileResult, error) {
+	if !api.eth.blockchain.BlockProfilerEnabled() {
+		return nil, errors.New("block profiler is disabled")
+	}
+	var n uint64
+	switch {
+	case number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber:
+		n = api.eth.blockchain.CurrentBlock().Number.Uint64()
+	case number < 0:
+		return nil, fmt.Errorf("unsupported block number: %v", number)
+	default:
+		n = uint64(number.Int64())
+	}
+	profile := api.eth.blockchain.GetBlockProfile(n)
+	if profile == nil {
+		return nil, fmt.Errorf("block #%d not profiled", n)
+	}
+	result := &BlockProfileResult{
+		Number:           hexutil.Uint64(profile.Number),
+		Hash:             profile.Hash,
+		GasUsed:          hexutil.Uint64(profile.GasUsed),
+		ImportedAt:       profile.Time.UnixMilli(),
+		Verify:           uint64(profile.Verify.Microseconds()),
+		DataAvailability: uint64(profile.DataAvailability.Microseconds()),
+		Prefetch:         uint64(profile.Prefetch.Microseconds()),
+		Execution:        uint64(profile.Execution.Microseconds()),
+		StateRead:        uint64(profile.StateRead.Microseconds()),
+		Validation:       uint64(profile.Validation.Microseconds()),
+		StateRoot:        uint64(profile.StateRoot.Microseconds()),
+		Write:            uint64(profile.Write.Microseconds()),
+		TrieCommit:       uint64(profile.TrieCommit.Microseconds()),
+		SnapshotCommit:   uint64(profile.SnapshotCommit.Microseconds()),
+		TrieDBCommit:     uint64(profile.TrieDBCommit.Microseconds()),
+		Total:            uint64(profile.Total.Microseconds()),
+		Transactions:     make([]*TxProfileResult, 0, len(profile.Transactions)),
+	}
+	for _, tx := range profile.Transactions {
+		result.Transactions = append(result.Transactions, &TxProfileResult{
+			Hash:     tx.Hash,
+			GasUsed:  hexutil.Uint64(tx.GasUsed),
+			Duration: uint64(tx.Duration.Microseconds()),
+		})
+	}
+	return result, nil
+}
+
+// OpcodeStatResult is the number of executions of an opcode in a transaction,
+// and the gas they were charged.
+type OpcodeStatResult struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// SlowTxResult is an imported transaction whose execution time per million gas
+// exceeded the threshold, durations in microseconds.
+type SlowTxResult struct {
+	Hash        common.Hash                  `json:"hash"`
+	From        common.Address               `json:"from"`
+	To          *common.Address              `json:"to"`
+	BlockNumber hexutil.Uint64               `json:"blockNumber"`
+	BlockHash   common.Hash                  `json:"blockHash"`
+	GasUsed     hexutil.Uint64               `json:"gasUsed"`
+	Duration    uint64                       `json:"duration"`
+	TimePerMgas uint64                       `json:"timePerMgas"`
+	DetectedAt  int64                        `json:"detectedAt"` // Unix milliseconds the execution ended at
+	Contract    common.Address               `json:"contract"`   // Contract whose code executed the most opcodes
+	Contracts   map[common.Address]uint64    `json:"contracts"`  // Number of opcodes executed per contract code
+	Opcodes     map[string]*OpcodeStatResult `json:"opcodes"`
+}
+
+// GetSlowTransactions returns the recently imported transactions whose execution
+// time per million gas exceeded the threshold of the slow transaction detector,
+// oldest first, with the opcodes they executed.
+func (api *DebugAPI) GetSlowTransactions() ([]*SlowTxResult, error) {
+	if !api.eth.blockchain.SlowTxDetectorEnabled() {
+		return nil, errors.New("slow transaction detector is disabled")
+	}
+	reports := api.eth.blockchain.GetSlowTransactions()
+	results := make([]*SlowTxResult, 0, len(reports))
+	for _, report := range reports {
+		result := &SlowTxResult{
+			Hash:        report.Hash,
+			From:        report.From,
+			To:          report.To,
+			BlockNumber: hexutil.Uint64(report.BlockNumber),
+			BlockHash:   report.BlockHash,
+			GasUsed:     hexutil.Uint64(report.GasUsed),
+			Duration:    uint64(report.Duration.Microseconds()),
+			TimePerMgas: uint64(report.Duration.Microseconds()) * 1000000 / report.GasUsed,
+			DetectedAt:  report.Time.UnixMilli(),
+			Contract:    report.Contract,
+			Contracts:   report.Contracts,
+			Opcodes:     make(map[string]*OpcodeStatResult, len(report.Opcodes)),
+		}
+		for op, stat := range report.Opcodes {
+			result.Opcodes[op.String()] = &OpcodeStatResult{Count: stat.Count, Gas: stat.Gas}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
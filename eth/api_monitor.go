@@ -0,0 +1,27 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/Ezkerrox/bsc/core/monitor"
+)
+
+// MonitorAPI provides an API to access the evidences found by the monitors.
+type MonitorAPI struct {
+	e *Ethereum
+}
+
+// NewMonitorAPI creates a new MonitorAPI instance.
+func NewMonitorAPI(e *Ethereum) *MonitorAPI {
+	return &MonitorAPI{e}
+}
+
+// GetDoubleSignEvidence returns all the double sign evidences found by the
+// double sign monitor, including those found before the last restart.
+func (api *MonitorAPI) GetDoubleSignEvidence() ([]*monitor.DoubleSignEvidence, error) {
+	m := api.e.blockchain.DoubleSignMonitor()
+	if m == nil {
+		return nil, errors.New("double sign monitor is not enabled")
+	}
+	return m.Evidences()
+}
@@ -0,0 +1,205 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/gopool"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/txpool"
+	"github.com/Ezkerrox/bsc/core/txpool/legacypool"
+	"github.com/Ezkerrox/bsc/internal/ethapi"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+// maxHistoryWatchedTxs is the maximum number of transactions a transaction
+// history subscription can watch.
+const maxHistoryWatchedTxs = 256
+
+// TxPoolAPI provides an API to inspect the parts of the transaction pool that
+// are specific to this client, next to the generic txpool namespace.
+type TxPoolAPI struct {
+	e *Ethereum
+}
+
+// NewTxPoolAPI creates a new TxPoolAPI instance.
+func NewTxPoolAPI(e *Ethereum) *TxPoolAPI {
+	return &TxPoolAPI{e}
+}
+
+// RPCOverflowTransaction is a transaction buffered in the overflow pool.
+type RPCOverflowTransaction struct {
+	*ethapi.RPCTransaction
+	AddedAt int64 `json:"addedAt"` // Unix milliseconds the transaction was buffered at
+}
+
+// RPCOverflowStatus summarizes the overflow pool and the transactions which
+// moved through it since the start.
+type RPCOverflowStatus struct {
+	Transactions hexutil.Uint   `json:"transactions"`
+	Slots        hexutil.Uint64 `json:"slots"`
+	MaxSlots     hexutil.Uint64 `json:"maxSlots"`
+	Policy       string         `json:"policy"`
+	Added        hexutil.Uint64 `json:"added"`
+	Evicted      hexutil.Uint64 `json:"evicted"`    // Evicted to make room for newer transactions
+	Reinjected   hexutil.Uint64 `json:"reinjected"` // Moved back into the pool
+	Rejected     hexutil.Uint64 `json:"rejected"`   // Refused by the pool on re-injection
+}
+
+// OverflowContent returns the transactions buffered in the overflow pool,
+// grouped by account and nonce.
+func (api *TxPoolAPI) OverflowContent() map[string]map[string]*RPCOverflowTransaction {
+	content := make(map[string]map[string]*RPCOverflowTransaction)
+	for account, txs := range api.e.legacyPool.OverflowContent() {
+		content[account.Hex()] = api.overflowDump(txs)
+	}
+	return content
+}
+
+// OverflowContentFrom returns the transactions of the given account buffered in
+// the overflow pool, by nonce.
+func (api *TxPoolAPI) OverflowContentFrom(addr common.Address) map[string]*RPCOverflowTransaction {
+	return api.overflowDump(api.e.legacyPool.OverflowContentFrom(addr))
+}
+
+// OverflowStatus returns the number of transactions in the overflow pool, its
+// re-injection policy, and how many transactions were buffered, evicted and
+// re-injected since the start.
+func (api *TxPoolAPI) OverflowStatus() *RPCOverflowStatus {
+	stats := api.e.legacyPool.OverflowStats()
+	return &RPCOverflowStatus{
+		Transactions: hexutil.Uint(stats.Txs),
+		Slots:        hexutil.Uint64(stats.Slots),
+		MaxSlots:     hexutil.Uint64(stats.MaxSlots),
+		Policy:       string(stats.Policy),
+		Added:        hexutil.Uint64(stats.Added),
+		Evicted:      hexutil.Uint64(stats.Evicted),
+		Reinjected:   hexutil.Uint64(stats.Reinjected),
+		Rejected:     hexutil.Uint64(stats.Rejected),
+	}
+}
+
+// Policy returns the admission rules applied to the new transactions.
+func (api *TxPoolAPI) Policy() txpool.PolicyConfig {
+	return api.e.legacyPool.Policy().Config()
+}
+
+// SetPolicy replaces the admission rules applied to the new transactions: the
+// allowed and denied addresses, the sender rate limit and the destination quota.
+// The pooled transactions are left untouched.
+func (api *TxPoolAPI) SetPolicy(config txpool.PolicyConfig) bool {
+	api.e.legacyPool.Policy().SetConfig(config)
+	log.Info("Updated txpool policy", "allow", len(config.Allow), "deny", len(config.Deny),
+		"senderRate", config.SenderRate, "senderBurst", config.SenderBurst, "contractQuota", config.ContractQuota)
+	return true
+}
+
+// RPCTxEvent is a step in the lifecycle of a transaction.
+type RPCTxEvent struct {
+	Hash        common.Hash     `json:"hash"`
+	Kind        string          `json:"kind"`
+	Time        int64           `json:"time"`                  // Unix milliseconds the event was recorded at
+	Peer        string          `json:"peer,omitempty"`        // Peer the transaction was received from
+	Replacement *common.Hash    `json:"replacement,omitempty"` // Transaction replacing this one
+	Reason      string          `json:"reason,omitempty"`      // Why the transaction was rejected, dropped or skipped
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"` // Block the transaction was included in
+	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
+}
+
+func newRPCTxEvent(event *txpool.TxEvent) *RPCTxEvent {
+	result := &RPCTxEvent{
+		Hash:   event.Hash,
+		Kind:   string(event.Kind),
+		Time:   event.Time.UnixMilli(),
+		Peer:   event.Peer,
+		Reason: event.Reason,
+	}
+	if event.Kind == txpool.TxEventReplaced {
+		result.Replacement = &event.Replacement
+	}
+	if event.Kind == txpool.TxEventIncluded {
+		result.BlockNumber = (*hexutil.Uint64)(&event.BlockNumber)
+		result.BlockHash = &event.BlockHash
+	}
+	return result
+}
+
+// GetTransactionHistory returns the lifecycle events recorded for the
+// transaction, oldest first: its reception, its moves across the pool, its
+// replacement or eviction and its inclusion. Only the recently seen
+// transactions are tracked, all of them if enabled by --txpool.history, else
+// the ones watched by a subscription.
+func (api *TxPoolAPI) GetTransactionHistory(hash common.Hash) []*RPCTxEvent {
+	events := api.e.txPool.History().Get(hash)
+	result := make([]*RPCTxEvent, 0, len(events))
+	for i := range events {
+		result = append(result, newRPCTxEvent(&events[i]))
+	}
+	return result
+}
+
+// TransactionHistory creates a subscription that is triggered each time a
+// lifecycle event is recorded for one of the given transactions, at most
+// maxHistoryWatchedTxs, which are tracked until unsubscribed. If none is given,
+// the events of all the transactions are notified, which is only allowed if all
+// of them are tracked, see GetTransactionHistory.
+func (api *TxPoolAPI) TransactionHistory(ctx context.Context, hashes []common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	history := api.e.txPool.History()
+	if len(hashes) > maxHistoryWatchedTxs {
+		return nil, fmt.Errorf("too many transactions watched: %d, at most %d are allowed", len(hashes), maxHistoryWatchedTxs)
+	}
+	if len(hashes) == 0 && !history.TracksAll() {
+		return nil, errors.New("no transaction to watch, all of them are only notified if tracked by --txpool.history")
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	filter := make(map[common.Hash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		filter[hash] = struct{}{}
+	}
+	history.Watch(hashes)
+
+	gopool.Submit(func() {
+		defer history.Unwatch(hashes)
+
+		events := make(chan txpool.TxEvent, 128)
+		eventsSub := history.SubscribeEvents(events)
+		defer eventsSub.Unsubscribe()
+
+		for {
+			select {
+			case event := <-events:
+				if _, ok := filter[event.Hash]; ok || len(filter) == 0 {
+					notifier.Notify(rpcSub.ID, newRPCTxEvent(&event))
+				}
+			case <-rpcSub.Err():
+				return
+			case <-eventsSub.Err():
+				return
+			}
+		}
+	})
+	return rpcSub, nil
+}
+
+func (api *TxPoolAPI) overflowDump(txs []*legacypool.OverflowTx) map[string]*RPCOverflowTransaction {
+	var (
+		config = api.e.blockchain.Config()
+		head   = api.e.blockchain.CurrentHeader()
+		dump   = make(map[string]*RPCOverflowTransaction, len(txs))
+	)
+	for _, otx := range txs {
+		dump[fmt.Sprintf("%d", otx.Tx.Nonce())] = &RPCOverflowTransaction{
+			RPCTransaction: ethapi.NewRPCPendingTransaction(otx.Tx, head, config),
+			AddedAt:        otx.Added.UnixMilli(),
+		}
+	}
+	return dump
+}
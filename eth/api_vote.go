@@ -0,0 +1,116 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core/vote"
+)
+
+// VoteAPI provides an API to inspect the votes for fast finality collected by
+// the vote pool.
+type VoteAPI struct {
+	e *Ethereum
+}
+
+// NewVoteAPI creates a new VoteAPI instance.
+func NewVoteAPI(e *Ethereum) *VoteAPI {
+	return &VoteAPI{e}
+}
+
+// RPCVote is a vote in the pool, along with the peer which delivered it and
+// when.
+type RPCVote struct {
+	Hash         common.Hash    `json:"hash"`
+	VoteAddress  hexutil.Bytes  `json:"voteAddress"`
+	SourceNumber hexutil.Uint64 `json:"sourceNumber"`
+	SourceHash   common.Hash    `json:"sourceHash"`
+	TargetNumber hexutil.Uint64 `json:"targetNumber"`
+	TargetHash   common.Hash    `json:"targetHash"`
+	Future       bool           `json:"future"`     // The target block isn't verified locally yet
+	From         string         `json:"from"`       // ID of the peer which delivered the vote, empty for the local vote
+	ReceivedAt   int64          `json:"receivedAt"` // Unix milliseconds the vote was received at
+}
+
+// RPCPoolStatus summarizes the content of the vote pool.
+type RPCPoolStatus struct {
+	CurVotes    int               `json:"curVotes"`
+	FutureVotes int               `json:"futureVotes"`
+	Blocks      []*RPCBlockVote   `json:"blocks"`
+	Dropped     map[string]uint64 `json:"dropped"`               // Votes dropped since the start, per reason
+	LastInvalid string            `json:"lastInvalid,omitempty"` // Error of the last vote dropped as invalid
+}
+
+// RPCBlockVote is the number of votes the pool holds for a target block.
+type RPCBlockVote struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	Votes  int            `json:"votes"`
+	Future bool           `json:"future"`
+}
+
+func (api *VoteAPI) pool() (*vote.VotePool, error) {
+	if api.e.votePool == nil {
+		return nil, errors.New("vote pool is not enabled")
+	}
+	return api.e.votePool, nil
+}
+
+// GetVotes returns the votes the pool holds for the given block, along with the
+// peers which delivered them and when.
+func (api *VoteAPI) GetVotes(blockHash common.Hash) ([]*RPCVote, error) {
+	pool, err := api.pool()
+	if err != nil {
+		return nil, err
+	}
+	votes := pool.FetchPoolVotes(blockHash)
+	result := make([]*RPCVote, 0, len(votes))
+	for _, v := range votes {
+		result = append(result, &RPCVote{
+			Hash:         v.Vote.Hash(),
+			VoteAddress:  v.Vote.VoteAddress.Bytes(),
+			SourceNumber: hexutil.Uint64(v.Vote.Data.SourceNumber),
+			SourceHash:   v.Vote.Data.SourceHash,
+			TargetNumber: hexutil.Uint64(v.Vote.Data.TargetNumber),
+			TargetHash:   v.Vote.Data.TargetHash,
+			Future:       v.Future,
+			From:         v.Receipt.From,
+			ReceivedAt:   v.Receipt.Time.UnixMilli(),
+		})
+	}
+	return result, nil
+}
+
+// GetPoolStatus returns the number of current and future votes per target
+// block, and the number of votes dropped per reason.
+func (api *VoteAPI) GetPoolStatus() (*RPCPoolStatus, error) {
+	pool, err := api.pool()
+	if err != nil {
+		return nil, err
+	}
+	status := pool.Status()
+	result := &RPCPoolStatus{
+		CurVotes:    status.CurVotes,
+		FutureVotes: status.FutureVotes,
+		Blocks:      make([]*RPCBlockVote, 0, len(status.Blocks)),
+		Dropped: map[string]uint64{
+			"outOfRange":       status.DroppedOutOfRange,
+			"duplicated":       status.DroppedDuplicated,
+			"overflow":         status.DroppedOverflow,
+			"badSignature":     status.DroppedBadSignature,
+			"unknownValidator": status.DroppedUnknownValidator,
+			"invalid":          status.DroppedInvalid,
+		},
+		LastInvalid: status.LastInvalidError,
+	}
+	for _, block := range status.Blocks {
+		result.Blocks = append(result.Blocks, &RPCBlockVote{
+			Number: hexutil.Uint64(block.Number),
+			Hash:   block.Hash,
+			Votes:  block.Votes,
+			Future: block.Future,
+		})
+	}
+	return result, nil
+}
@@ -32,9 +32,11 @@ import (
 	"github.com/Ezkerrox/bsc/accounts"
 	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/common/lru"
 	"github.com/Ezkerrox/bsc/consensus"
 	"github.com/Ezkerrox/bsc/consensus/parlia"
 	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/blobarchive"
 	"github.com/Ezkerrox/bsc/core/bloombits"
 	"github.com/Ezkerrox/bsc/core/monitor"
 	"github.com/Ezkerrox/bsc/core/rawdb"
@@ -46,6 +48,7 @@ import (
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/core/vm"
 	"github.com/Ezkerrox/bsc/core/vote"
+	"github.com/Ezkerrox/bsc/crypto"
 	"github.com/Ezkerrox/bsc/eth/downloader"
 	"github.com/Ezkerrox/bsc/eth/ethconfig"
 	"github.com/Ezkerrox/bsc/eth/filters"
@@ -55,6 +58,7 @@ import (
 	"github.com/Ezkerrox/bsc/eth/protocols/snap"
 	"github.com/Ezkerrox/bsc/eth/protocols/trust"
 	"github.com/Ezkerrox/bsc/eth/tracers"
+	"github.com/Ezkerrox/bsc/ethclient"
 	"github.com/Ezkerrox/bsc/ethdb"
 	"github.com/Ezkerrox/bsc/event"
 	"github.com/Ezkerrox/bsc/internal/ethapi"
@@ -103,8 +107,12 @@ type Ethereum struct {
 	// core protocol objects
 	config         *ethconfig.Config
 	txPool         *txpool.TxPool
+	legacyPool     *legacypool.LegacyPool
 	localTxTracker *locals.TxTracker
 	blockchain     *core.BlockChain
+	onlinePruner   *pruner.OnlinePruner
+	stateMigrator  *stateMigrator
+	blobArchive    *blobarchive.Archive
 
 	handler *handler
 	discmix *enode.FairMix
@@ -236,10 +244,31 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if stack.CheckIfMultiDataBase() {
 		freezeDb = chainDb.BlockStore()
 	}
-	if err = freezeDb.SetupFreezerEnv(&ethdb.FreezerEnv{
+	freezerEnv := &ethdb.FreezerEnv{
 		ChainCfg:         chainConfig,
 		BlobExtraReserve: config.BlobExtraReserve,
-	}); err != nil {
+	}
+	// The blob archive receives the sidecars pruned by the freezer, and serves
+	// them back to the chain afterwards.
+	var blobArchive *blobarchive.Archive
+	if config.BlobArchive != "" {
+		credentials := config.BlobArchiveCredentials
+		if credentials != "" {
+			credentials = stack.ResolvePath(credentials)
+		}
+		accessKey, secretKey, err := blobarchive.LoadCredentials(credentials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load blob archive credentials: %w", err)
+		}
+		backend, err := blobarchive.NewBackend(config.BlobArchive, config.BlobArchiveRegion, accessKey, secretKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open blob archive: %w", err)
+		}
+		blobArchive = blobarchive.New(backend)
+		freezerEnv.BlobArchiver = blobArchive
+		log.Info("Archiving pruned blob sidecars", "location", config.BlobArchive)
+	}
+	if err = freezeDb.SetupFreezerEnv(freezerEnv); err != nil {
 		return nil, err
 	}
 
@@ -253,6 +282,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		eventMux:          stack.EventMux(),
 		accountManager:    stack.AccountManager(),
 		closeBloomHandler: make(chan struct{}),
+		blobArchive:       blobArchive,
 		networkID:         networkID,
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
@@ -264,7 +294,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		stopCh:            make(chan struct{}),
 	}
 
-	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
+	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil, lru.NewCache[common.Hash, time.Time](archiveMissCacheSize)}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
@@ -341,6 +371,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if stack.Config().EnableDoubleSignMonitor {
 		bcOps = append(bcOps, core.EnableDoubleSignChecker)
 	}
+	if config.BlockProfiles > 0 {
+		bcOps = append(bcOps, core.EnableBlockProfiler(config.BlockProfiles))
+	}
+	if config.SlowTxThreshold > 0 {
+		bcOps = append(bcOps, core.EnableSlowTxDetector(config.SlowTxThreshold))
+	}
+	if blobArchive != nil {
+		bcOps = append(bcOps, core.EnableBlobArchive(blobArchive))
+	}
 
 	peers := newPeerSet()
 	bcOps = append(bcOps, core.EnableBlockValidator(chainConfig, config.TriesVerifyMode, peers))
@@ -359,6 +398,42 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	// Online state pruning is only meaningful for non-archive hash-based nodes.
+	if config.StateScheme == rawdb.HashScheme && !config.NoPruning {
+		eth.onlinePruner, err = pruner.NewOnlinePruner(eth.blockchain, chainDb, pruner.OnlineConfig{
+			BloomSize: config.OnlinePruningBloomSize,
+			Rate:      config.OnlinePruningRate,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Resume the migration of the hash-based state into the path-based scheme,
+	// or the removal of the hash-based trie nodes left after it.
+	if eth.stateMigrator, err = newStateMigrator(eth); err != nil {
+		return nil, err
+	}
+	if eth.onlinePruner == nil || !eth.onlinePruner.Interrupted() {
+		eth.stateMigrator.resume()
+	}
+
+	if keyfile := stack.Config().DoubleSignEvidenceSubmitKey; keyfile != "" && eth.blockchain.DoubleSignMonitor() != nil {
+		p, ok := eth.engine.(*parlia.Parlia)
+		if !ok {
+			return nil, errors.New("double sign evidence submission requires the parlia engine")
+		}
+		key, err := crypto.LoadECDSA(stack.ResolvePath(keyfile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load double sign evidence submit key: %v", err)
+		}
+		submitter, err := monitor.NewEvidenceSubmitter(key, chainConfig.ChainID, p.SlashABI(), ethclient.NewClient(stack.Attach()))
+		if err != nil {
+			return nil, err
+		}
+		eth.blockchain.DoubleSignMonitor().SetEvidenceSubmitter(submitter)
+		log.Info("Double sign evidences will be submitted automatically", "submitter", crypto.PubkeyToAddress(key.PublicKey))
+	}
+
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
 	}
@@ -367,12 +442,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
-	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
+	eth.legacyPool = legacypool.New(config.TxPool, eth.blockchain)
+	blobPool.SetPolicy(eth.legacyPool.Policy())
 
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{eth.legacyPool, blobPool})
 	if err != nil {
 		return nil, err
 	}
+	eth.txPool.History().TrackAll(config.TxPool.History)
 
 	if !config.TxPool.NoLocals {
 		rejournal := config.TxPool.Rejournal
@@ -390,6 +467,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		Database:                  chainDb,
 		Chain:                     eth.blockchain,
 		TxPool:                    eth.txPool,
+		TxHistory:                 eth.txPool.History(),
 		Network:                   networkID,
 		Sync:                      config.SyncMode,
 		BloomCache:                uint64(cacheLimit),
@@ -406,7 +484,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
+	if config.Miner.Mev.BuildersFile != "" {
+		config.Miner.Mev.BuildersFile = stack.ResolvePath(config.Miner.Mev.BuildersFile)
+	}
 	eth.miner = miner.New(eth, &config.Miner, eth.EventMux(), eth.engine)
+	eth.handler.privateTxs = eth.miner
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 	eth.miner.SetPrioAddresses(config.TxPool.Locals)
 
@@ -432,10 +514,27 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 		if config.Miner.VoteEnable {
 			conf := stack.Config()
-			blsPasswordPath := stack.ResolvePath(conf.BLSPasswordFile)
-			blsWalletPath := stack.ResolvePath(conf.BLSWalletDir)
+			signerConfig := &vote.SignerConfig{
+				PasswordPath: stack.ResolvePath(conf.BLSPasswordFile),
+				WalletPath:   stack.ResolvePath(conf.BLSWalletDir),
+			}
+			if conf.BLSRemoteSigner != "" {
+				resolvePath := func(path string) string {
+					if path == "" {
+						return ""
+					}
+					return stack.ResolvePath(path)
+				}
+				signerConfig.Remote = &vote.RemoteSignerConfig{
+					URL:        conf.BLSRemoteSigner,
+					PublicKey:  conf.BLSRemoteSignerPubKey,
+					CACert:     resolvePath(conf.BLSRemoteSignerCACert),
+					ClientCert: resolvePath(conf.BLSRemoteSignerClientCert),
+					ClientKey:  resolvePath(conf.BLSRemoteSignerClientKey),
+				}
+			}
 			voteJournalPath := stack.ResolvePath(conf.VoteJournalDir)
-			if _, err := vote.NewVoteManager(eth, eth.blockchain, votePool, voteJournalPath, blsPasswordPath, blsWalletPath, posa); err != nil {
+			if _, err := vote.NewVoteManager(eth, eth.blockchain, votePool, voteJournalPath, signerConfig, posa); err != nil {
 				log.Error("Failed to Initialize voteManager", "err", err)
 				return nil, err
 			}
@@ -506,6 +605,15 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
+		}, {
+			Namespace: "monitor",
+			Service:   NewMonitorAPI(s),
+		}, {
+			Namespace: "vote",
+			Service:   NewVoteAPI(s),
+		}, {
+			Namespace: "txpool",
+			Service:   NewTxPoolAPI(s),
 		},
 	}...)
 }
@@ -768,7 +876,8 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	if s.config.EnableTrustProtocol {
 		protos = append(protos, trust.MakeProtocols((*trustHandler)(s.handler))...)
 	}
-	protos = append(protos, bsc.MakeProtocols((*bscHandler)(s.handler))...)
+	evn := s.handler.enableEVNFeatures || len(s.handler.proxyedValidatorAddressMap) > 0
+	protos = append(protos, bsc.MakeProtocols((*bscHandler)(s.handler), evn)...)
 
 	return protos
 }
@@ -789,9 +898,49 @@ func (s *Ethereum) Start() error {
 	s.handler.Start(s.p2pServer.MaxPeers, s.p2pServer.MaxPeersPerIP)
 
 	go s.reportRecentBlocksLoop()
+
+	// Submit the double sign evidences once the in-proc RPC APIs are served
+	if m := s.blockchain.DoubleSignMonitor(); m != nil {
+		m.Start()
+	}
+
+	// Resume the online pruning round interrupted by the shutdown, once synced
+	if s.onlinePruner != nil && s.onlinePruner.Interrupted() {
+		go s.resumeOnlinePruning()
+	}
 	return nil
 }
 
+// resumeOnlinePruning waits for the node to be fully synced and resumes the
+// interrupted online pruning round, like the pruning started via the API.
+func (s *Ethereum) resumeOnlinePruning() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			if !s.Synced() {
+				continue
+			}
+			// The round might be paused or started via the API meanwhile
+			if !s.onlinePruner.Interrupted() || s.onlinePruner.Running() {
+				return
+			}
+			if s.stateMigrator.migrating() {
+				log.Warn("Online state pruning not resumed during the state migration")
+				return
+			}
+			if err := s.onlinePruner.Start(); err != nil {
+				log.Error("Failed to resume online state pruning", "err", err)
+			}
+			return
+		}
+	}
+}
+
 func (s *Ethereum) setupDiscovery() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
@@ -857,6 +1006,13 @@ func (s *Ethereum) Stop() error {
 	close(s.closeBloomHandler)
 	s.txPool.Close()
 	s.miner.Close()
+	if s.onlinePruner != nil {
+		s.onlinePruner.Close()
+	}
+	s.stateMigrator.close()
+	if m := s.blockchain.DoubleSignMonitor(); m != nil {
+		m.Stop()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 
@@ -864,6 +1020,9 @@ func (s *Ethereum) Stop() error {
 	s.shutdownTracker.Stop()
 
 	s.chainDb.Close()
+	if s.blobArchive != nil {
+		s.blobArchive.Close()
+	}
 	s.eventMux.Stop()
 
 	// stop report loop
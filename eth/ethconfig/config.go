@@ -50,28 +50,30 @@ var FullNodeGPO = gasprice.Config{
 
 // Defaults contains default settings for use on the BSC main net.
 var Defaults = Config{
-	SyncMode:           SnapSync,
-	NetworkId:          0, // enable auto configuration of networkID == chainID
-	TxLookupLimit:      2350000,
-	TransactionHistory: 2350000,
-	StateHistory:       params.FullImmutabilityThreshold,
-	DatabaseCache:      512,
-	TrieCleanCache:     154,
-	TrieDirtyCache:     256,
-	TrieTimeout:        10 * time.Minute,
-	TriesInMemory:      128,
-	TriesVerifyMode:    core.LocalVerify,
-	SnapshotCache:      102,
-	DiffBlock:          uint64(86400),
-	FilterLogCacheSize: 32,
-	Miner:              minerconfig.DefaultConfig,
-	TxPool:             legacypool.DefaultConfig,
-	BlobPool:           blobpool.DefaultConfig,
-	RPCGasCap:          50000000,
-	RPCEVMTimeout:      5 * time.Second,
-	GPO:                FullNodeGPO,
-	RPCTxFeeCap:        1,                                         // 1 ether
-	BlobExtraReserve:   params.DefaultExtraReserveForBlobRequests, // Extra reserve threshold for blob, blob never expires when -1 is set, default 28800
+	SyncMode:               SnapSync,
+	NetworkId:              0, // enable auto configuration of networkID == chainID
+	TxLookupLimit:          2350000,
+	TransactionHistory:     2350000,
+	StateHistory:           params.FullImmutabilityThreshold,
+	OnlinePruningBloomSize: 2048,
+	OnlinePruningRate:      100000,
+	DatabaseCache:          512,
+	TrieCleanCache:         154,
+	TrieDirtyCache:         256,
+	TrieTimeout:            10 * time.Minute,
+	TriesInMemory:          128,
+	TriesVerifyMode:        core.LocalVerify,
+	SnapshotCache:          102,
+	DiffBlock:              uint64(86400),
+	FilterLogCacheSize:     32,
+	Miner:                  minerconfig.DefaultConfig,
+	TxPool:                 legacypool.DefaultConfig,
+	BlobPool:               blobpool.DefaultConfig,
+	RPCGasCap:              50000000,
+	RPCEVMTimeout:          5 * time.Second,
+	GPO:                    FullNodeGPO,
+	RPCTxFeeCap:            1,                                         // 1 ether
+	BlobExtraReserve:       params.DefaultExtraReserveForBlobRequests, // Extra reserve threshold for blob, blob never expires when -1 is set, default 28800
 }
 
 //go:generate go run github.com/fjl/gencodec -type Config -formats toml -out gen_config.go
@@ -117,6 +119,10 @@ type Config struct {
 
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	HistoricalState    bool   `toml:",omitempty"` // Whether the states covered by the state histories are served in path scheme.
+
+	OnlinePruningBloomSize uint64 `toml:",omitempty"` // Megabytes of memory allocated to the bloom filter of online state pruning.
+	OnlinePruningRate      uint64 `toml:",omitempty"` // Maximum number of trie nodes deleted per second by online state pruning, 0 for unlimited.
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
 	// consistent with persistent state.
@@ -176,6 +182,14 @@ type Config struct {
 	VMTrace           string
 	VMTraceJsonConfig string
 
+	// Number of recent block imports whose stage timings are retained, 0 disables
+	// the block profiler
+	BlockProfiles int
+
+	// Execution time per million gas above which an imported transaction is
+	// reported as slow, 0 disables the slow transaction detector
+	SlowTxThreshold time.Duration
+
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap uint64
 
@@ -203,6 +217,16 @@ type Config struct {
 
 	// blob setting
 	BlobExtraReserve uint64
+
+	// Archive of the blob sidecars pruned past the extra reserve, a directory or
+	// the URL of an S3-compatible bucket. Disabled if empty.
+	BlobArchive       string `toml:",omitempty"`
+	BlobArchiveRegion string `toml:",omitempty"`
+
+	// File holding the access key and the secret key of the bucket, read from
+	// the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables if
+	// empty. The keys themselves are never part of the configuration.
+	BlobArchiveCredentials string `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
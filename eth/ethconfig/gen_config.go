@@ -36,6 +36,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxLookupLimit           uint64 `toml:",omitempty"`
 		TransactionHistory      uint64 `toml:",omitempty"`
 		StateHistory            uint64 `toml:",omitempty"`
+		HistoricalState         bool   `toml:",omitempty"`
+		OnlinePruningBloomSize  uint64 `toml:",omitempty"`
+		OnlinePruningRate       uint64 `toml:",omitempty"`
 		StateScheme             string `toml:",omitempty"`
 		PathSyncFlush           bool   `toml:",omitempty"`
 		JournalFileEnabled      bool
@@ -64,6 +67,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		EnablePreimageRecording bool
 		VMTrace                 string
 		VMTraceJsonConfig       string
+		BlockProfiles           int
+		SlowTxThreshold         time.Duration
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
 		RPCTxFeeCap             float64
@@ -73,6 +78,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		OverrideFermi           *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
 		BlobExtraReserve        uint64
+		BlobArchive             string `toml:",omitempty"`
+		BlobArchiveRegion       string `toml:",omitempty"`
+		BlobArchiveCredentials  string `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -94,6 +102,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.TransactionHistory = c.TransactionHistory
 	enc.StateHistory = c.StateHistory
+	enc.HistoricalState = c.HistoricalState
+	enc.OnlinePruningBloomSize = c.OnlinePruningBloomSize
+	enc.OnlinePruningRate = c.OnlinePruningRate
 	enc.StateScheme = c.StateScheme
 	enc.PathSyncFlush = c.PathSyncFlush
 	enc.JournalFileEnabled = c.JournalFileEnabled
@@ -122,6 +133,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.VMTrace = c.VMTrace
 	enc.VMTraceJsonConfig = c.VMTraceJsonConfig
+	enc.BlockProfiles = c.BlockProfiles
+	enc.SlowTxThreshold = c.SlowTxThreshold
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
@@ -131,6 +144,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.OverrideFermi = c.OverrideFermi
 	enc.OverrideVerkle = c.OverrideVerkle
 	enc.BlobExtraReserve = c.BlobExtraReserve
+	enc.BlobArchive = c.BlobArchive
+	enc.BlobArchiveRegion = c.BlobArchiveRegion
+	enc.BlobArchiveCredentials = c.BlobArchiveCredentials
 	return &enc, nil
 }
 
@@ -156,6 +172,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxLookupLimit           *uint64 `toml:",omitempty"`
 		TransactionHistory      *uint64 `toml:",omitempty"`
 		StateHistory            *uint64 `toml:",omitempty"`
+		HistoricalState         *bool   `toml:",omitempty"`
+		OnlinePruningBloomSize  *uint64 `toml:",omitempty"`
+		OnlinePruningRate       *uint64 `toml:",omitempty"`
 		StateScheme             *string `toml:",omitempty"`
 		PathSyncFlush           *bool   `toml:",omitempty"`
 		JournalFileEnabled      *bool
@@ -184,6 +203,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		EnablePreimageRecording *bool
 		VMTrace                 *string
 		VMTraceJsonConfig       *string
+		BlockProfiles           *int
+		SlowTxThreshold         *time.Duration
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
 		RPCTxFeeCap             *float64
@@ -193,6 +214,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		OverrideFermi           *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
 		BlobExtraReserve        *uint64
+		BlobArchive             *string `toml:",omitempty"`
+		BlobArchiveRegion       *string `toml:",omitempty"`
+		BlobArchiveCredentials  *string `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -255,6 +279,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.StateHistory != nil {
 		c.StateHistory = *dec.StateHistory
 	}
+	if dec.HistoricalState != nil {
+		c.HistoricalState = *dec.HistoricalState
+	}
+	if dec.OnlinePruningBloomSize != nil {
+		c.OnlinePruningBloomSize = *dec.OnlinePruningBloomSize
+	}
+	if dec.OnlinePruningRate != nil {
+		c.OnlinePruningRate = *dec.OnlinePruningRate
+	}
 	if dec.StateScheme != nil {
 		c.StateScheme = *dec.StateScheme
 	}
@@ -339,6 +372,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.VMTraceJsonConfig != nil {
 		c.VMTraceJsonConfig = *dec.VMTraceJsonConfig
 	}
+	if dec.BlockProfiles != nil {
+		c.BlockProfiles = *dec.BlockProfiles
+	}
+	if dec.SlowTxThreshold != nil {
+		c.SlowTxThreshold = *dec.SlowTxThreshold
+	}
 	if dec.RPCGasCap != nil {
 		c.RPCGasCap = *dec.RPCGasCap
 	}
@@ -366,5 +405,14 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobExtraReserve != nil {
 		c.BlobExtraReserve = *dec.BlobExtraReserve
 	}
+	if dec.BlobArchive != nil {
+		c.BlobArchive = *dec.BlobArchive
+	}
+	if dec.BlobArchiveRegion != nil {
+		c.BlobArchiveRegion = *dec.BlobArchiveRegion
+	}
+	if dec.BlobArchiveCredentials != nil {
+		c.BlobArchiveCredentials = *dec.BlobArchiveCredentials
+	}
 	return nil
 }
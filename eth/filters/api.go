@@ -29,6 +29,7 @@ import (
 	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/common/gopool"
 	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/internal/ethapi"
 	"github.com/Ezkerrox/bsc/rpc"
@@ -374,6 +375,34 @@ func (api *FilterAPI) NewFinalizedHeaders(ctx context.Context) (*rpc.Subscriptio
 	return rpcSub, nil
 }
 
+// BlockTimeline sends a notification with the propagation timeline of each
+// block once its child is imported, by then its votes have been collected.
+func (api *FilterAPI) BlockTimeline(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	gopool.Submit(func() {
+		timelines := make(chan *core.BlockTimeline)
+		timelinesSub := api.events.SubscribeBlockTimelines(timelines)
+
+		for {
+			select {
+			case t := <-timelines:
+				notifier.Notify(rpcSub.ID, t)
+			case <-rpcSub.Err():
+				timelinesSub.Unsubscribe()
+				return
+			}
+		}
+	})
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -70,6 +70,7 @@ type Backend interface {
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribeNewVoteEvent(chan<- core.NewVoteEvent) event.Subscription
+	SubscribeBlockTimelineEvent(ch chan<- core.BlockTimelineEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
@@ -160,6 +161,8 @@ const (
 	VotesSubscription
 	// FinalizedHeadersSubscription queries hashes for finalized headers that are reached
 	FinalizedHeadersSubscription
+	// BlockTimelinesSubscription queries the propagation timelines of the imported blocks
+	BlockTimelinesSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -176,6 +179,8 @@ const (
 	chainEvChanSize = 10
 	// finalizedHeaderEvChanSize is the size of channel listening to FinalizedHeaderEvent.
 	finalizedHeaderEvChanSize = 10
+	// blockTimelineEvChanSize is the size of channel listening to BlockTimelineEvent.
+	blockTimelineEvChanSize = 10
 	// voteChanSize is the size of channel listening to NewVoteEvent.
 	// The number is referenced from the size of vote pool.
 	voteChanSize = 256
@@ -190,6 +195,7 @@ type subscription struct {
 	txs       chan []*types.Transaction
 	headers   chan *types.Header
 	votes     chan *types.VoteEnvelope
+	timelines chan *core.BlockTimeline
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -207,6 +213,7 @@ type EventSystem struct {
 	chainSub           event.Subscription // Subscription for new chain event
 	finalizedHeaderSub event.Subscription // Subscription for new finalized header
 	voteSub            event.Subscription // Subscription for new vote event
+	blockTimelineSub   event.Subscription // Subscription for block timeline event
 
 	// Channels
 	install           chan *subscription             // install filter for event notification
@@ -217,6 +224,7 @@ type EventSystem struct {
 	chainCh           chan core.ChainEvent           // Channel to receive new chain event
 	finalizedHeaderCh chan core.FinalizedHeaderEvent // Channel to receive new finalized header event
 	voteCh            chan core.NewVoteEvent         // Channel to receive new vote event
+	blockTimelineCh   chan core.BlockTimelineEvent   // Channel to receive block timeline event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -237,6 +245,7 @@ func NewEventSystem(sys *FilterSystem) *EventSystem {
 		chainCh:           make(chan core.ChainEvent, chainEvChanSize),
 		finalizedHeaderCh: make(chan core.FinalizedHeaderEvent, finalizedHeaderEvChanSize),
 		voteCh:            make(chan core.NewVoteEvent, voteChanSize),
+		blockTimelineCh:   make(chan core.BlockTimelineEvent, blockTimelineEvChanSize),
 	}
 
 	// Subscribe events
@@ -246,9 +255,10 @@ func NewEventSystem(sys *FilterSystem) *EventSystem {
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.finalizedHeaderSub = m.backend.SubscribeFinalizedHeaderEvent(m.finalizedHeaderCh)
 	m.voteSub = m.backend.SubscribeNewVoteEvent(m.voteCh)
+	m.blockTimelineSub = m.backend.SubscribeBlockTimelineEvent(m.blockTimelineCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.blockTimelineSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 	if m.voteSub == nil || m.finalizedHeaderSub == nil {
@@ -288,6 +298,7 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.txs:
 			case <-sub.f.headers:
 			case <-sub.f.votes:
+			case <-sub.f.timelines:
 			}
 		}
 
@@ -356,6 +367,7 @@ func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		votes:     make(chan *types.VoteEnvelope),
+		timelines: make(chan *core.BlockTimeline),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -373,6 +385,7 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		txs:       make(chan []*types.Transaction),
 		headers:   headers,
 		votes:     make(chan *types.VoteEnvelope),
+		timelines: make(chan *core.BlockTimeline),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -390,6 +403,7 @@ func (es *EventSystem) SubscribeNewFinalizedHeaders(headers chan *types.Header)
 		txs:       make(chan []*types.Transaction),
 		headers:   headers,
 		votes:     make(chan *types.VoteEnvelope),
+		timelines: make(chan *core.BlockTimeline),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -407,6 +421,7 @@ func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subsc
 		txs:       txs,
 		headers:   make(chan *types.Header),
 		votes:     make(chan *types.VoteEnvelope),
+		timelines: make(chan *core.BlockTimeline),
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -424,6 +439,25 @@ func (es *EventSystem) SubscribeNewVotes(votes chan *types.VoteEnvelope) *Subscr
 		txs:       make(chan []*types.Transaction),
 		headers:   make(chan *types.Header),
 		votes:     votes,
+		timelines: make(chan *core.BlockTimeline),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeBlockTimelines creates a subscription that writes the propagation
+// timeline of a block once its child is imported.
+func (es *EventSystem) SubscribeBlockTimelines(timelines chan *core.BlockTimeline) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       BlockTimelinesSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		headers:   make(chan *types.Header),
+		votes:     make(chan *types.VoteEnvelope),
+		timelines: timelines,
 		installed: make(chan struct{}),
 		err:       make(chan error),
 	}
@@ -468,6 +502,12 @@ func (es *EventSystem) handleFinalizedHeaderEvent(filters filterIndex, ev core.F
 	}
 }
 
+func (es *EventSystem) handleBlockTimelineEvent(filters filterIndex, ev core.BlockTimelineEvent) {
+	for _, f := range filters[BlockTimelinesSubscription] {
+		f.timelines <- ev.Timeline
+	}
+}
+
 // eventLoop (un)installs filters and processes mux events.
 func (es *EventSystem) eventLoop() {
 	// Ensure all subscriptions get cleaned up
@@ -477,6 +517,7 @@ func (es *EventSystem) eventLoop() {
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
 		es.finalizedHeaderSub.Unsubscribe()
+		es.blockTimelineSub.Unsubscribe()
 		if es.voteSub != nil {
 			es.voteSub.Unsubscribe()
 		}
@@ -505,6 +546,8 @@ func (es *EventSystem) eventLoop() {
 			es.handleFinalizedHeaderEvent(index, ev)
 		case ev := <-es.voteCh:
 			es.handleVoteEvent(index, ev)
+		case ev := <-es.blockTimelineCh:
+			es.handleBlockTimelineEvent(index, ev)
 
 		case f := <-es.install:
 			index[f.typ][f.id] = f
@@ -525,6 +568,8 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.finalizedHeaderSub.Err():
 			return
+		case <-es.blockTimelineSub.Err():
+			return
 		case <-voteSubErr:
 			return
 		}
@@ -48,6 +48,7 @@ type testBackend struct {
 	chainFeed           event.Feed
 	finalizedHeaderFeed event.Feed
 	voteFeed            event.Feed
+	timelineFeed        event.Feed
 	pendingBlock        *types.Block
 	pendingReceipts     types.Receipts
 }
@@ -142,6 +143,10 @@ func (b *testBackend) SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.S
 	return b.voteFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeBlockTimelineEvent(ch chan<- core.BlockTimelineEvent) event.Subscription {
+	return b.timelineFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
@@ -706,3 +711,40 @@ func TestVoteSubscription(t *testing.T) {
 
 	<-sub0.Err()
 }
+
+func TestBlockTimelineSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{Timeout: 5 * time.Minute})
+		api          = NewFilterAPI(sys, false)
+		timelines    = []*core.BlockTimeline{
+			{Number: 1, Hash: common.Hash{0x1}, RecvNewBlockTime: 1000, RecvNewBlockFrom: "127.0.0.1:30311"},
+			{Number: 2, Hash: common.Hash{0x2}, SendBlockTime: 2000, FirstRecvVoteTime: 2100},
+		}
+	)
+
+	chan0 := make(chan *core.BlockTimeline)
+	sub0 := api.events.SubscribeBlockTimelines(chan0)
+
+	go func() { // simulate client
+		i := 0
+		for i != len(timelines) {
+			timeline := <-chan0
+			if timeline.Hash != timelines[i].Hash || *timeline != *timelines[i] {
+				t.Errorf("sub received invalid timeline on index %d, want %+v, got %+v", i, timelines[i], timeline)
+			}
+			i++
+		}
+
+		sub0.Unsubscribe()
+	}()
+
+	time.Sleep(1 * time.Second)
+	for _, timeline := range timelines {
+		backend.timelineFeed.Send(core.BlockTimelineEvent{Timeline: timeline})
+	}
+
+	<-sub0.Err()
+}
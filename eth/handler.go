@@ -105,6 +105,7 @@ type txPool interface {
 // support all the operations needed by the Ethereum chain protocols.
 type votePool interface {
 	PutVote(vote *types.VoteEnvelope)
+	PutRemoteVote(vote *types.VoteEnvelope, peer string)
 	GetVotes() []*types.VoteEnvelope
 
 	// SubscribeNewVoteEvent should return an event subscription of
@@ -112,6 +113,12 @@ type votePool interface {
 	SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.Subscription
 }
 
+// privateTxPool defines the methods needed to keep the private transactions
+// received from the EVN peers, out of the transaction pool.
+type privateTxPool interface {
+	SendPrivateTransaction(tx *types.Transaction, maxBlockNumber uint64) error
+}
+
 // handlerConfig is the collection of initialization parameters to create a full
 // node network handler.
 type handlerConfig struct {
@@ -119,6 +126,7 @@ type handlerConfig struct {
 	Database                  ethdb.Database   // Database for direct sync insertions
 	Chain                     *core.BlockChain // Blockchain to serve data from
 	TxPool                    txPool           // Transaction pool to propagate from
+	TxHistory                 *txpool.History  // Lifecycle events of the transactions, nil if not recorded
 	VotePool                  votePool
 	Network                   uint64                 // Network identifier to adfvertise
 	Sync                      ethconfig.SyncMode     // Whether to snap or full sync
@@ -150,7 +158,9 @@ type handler struct {
 
 	database             ethdb.Database
 	txpool               txPool
+	txHistory            *txpool.History
 	votepool             votePool
+	privateTxs           privateTxPool
 	maliciousVoteMonitor *monitor.MaliciousVoteMonitor
 	chain                *core.BlockChain
 	maxPeers             int
@@ -203,6 +213,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		eventMux:                   config.EventMux,
 		database:                   config.Database,
 		txpool:                     config.TxPool,
+		txHistory:                  config.TxHistory,
 		votepool:                   config.VotePool,
 		chain:                      config.Chain,
 		peers:                      config.PeerSet,
@@ -320,7 +331,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		if p.bscExt == nil {
 			return nil, fmt.Errorf("peer does not support bsc protocol, peer: %v", p.ID())
 		}
-		if p.bscExt.Version() != bsc.Bsc2 {
+		if p.bscExt.Version() < bsc.Bsc2 {
 			return nil, fmt.Errorf("remote peer does not support the required Bsc2 protocol version, peer: %v", p.ID())
 		}
 		res, err := p.bscExt.RequestBlocksByRange(startHeight, startHash, count)
@@ -984,6 +995,23 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		"bcastpeers", len(txset), "bcastcount", directCount, "annpeers", len(annos), "anncount", annCount)
 }
 
+// SendPrivateTransactions sends the private transactions directly to the EVN peers
+// of the proxyed validators, and to no other peer. The peers keep them out of
+// their transaction pool and never propagate them. Nothing is sent if the EVN
+// features are disabled.
+func (h *handler) SendPrivateTransactions(txs types.Transactions, maxBlockNumber uint64) {
+	if !h.enableEVNFeatures {
+		return
+	}
+	peers := h.peers.proxyedValidatorPeers(h.proxyedValidatorAddressMap)
+	for _, peer := range peers {
+		if err := peer.bscExt.SendPrivateTransactions(txs, maxBlockNumber); err != nil {
+			log.Debug("Failed to send private transactions", "peer", peer.ID(), "err", err)
+		}
+	}
+	log.Debug("Sent private transactions", "txs", len(txs), "peers", len(peers))
+}
+
 // ReannounceTransactions will announce a batch of local pending transactions
 // to a square root of all peers.
 func (h *handler) ReannounceTransactions(txs types.Transactions) {
@@ -6,6 +6,7 @@ import (
 	"github.com/Ezkerrox/bsc/core"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/eth/protocols/bsc"
+	"github.com/Ezkerrox/bsc/miner"
 	"github.com/Ezkerrox/bsc/p2p/enode"
 )
 
@@ -53,6 +54,9 @@ func (h *bscHandler) Handle(peer *bsc.Peer, packet bsc.Packet) error {
 	case *bsc.VotesPacket:
 		return h.handleVotesBroadcast(peer, packet.Votes)
 
+	case *bsc.PrivateTransactionsPacket:
+		return h.handlePrivateTransactions(peer, packet)
+
 	default:
 		return fmt.Errorf("unexpected bsc packet type: %T", packet)
 	}
@@ -67,8 +71,40 @@ func (h *bscHandler) handleVotesBroadcast(peer *bsc.Peer, votes []*types.VoteEnv
 	// Here we only put the first vote, to avoid ddos attack by sending a large batch of votes.
 	// This won't abandon any valid vote, because one vote is sent every time referring to func voteBroadcastLoop
 	if len(votes) > 0 {
-		h.votepool.PutVote(votes[0])
+		h.votepool.PutRemoteVote(votes[0], peer.ID())
+
+		// Track the peer of the first vote, for the blocks known locally only
+		if stats := h.chain.PeekBlockStats(votes[0].Data.TargetHash); stats != nil {
+			if addr := peer.RemoteAddr(); addr != nil {
+				stats.FirstRecvVoteFrom.CompareAndSwap(nil, addr.String())
+			}
+		}
 	}
 
 	return nil
 }
+
+// handlePrivateTransactions is invoked from a peer's message handler when it sends
+// private transactions for the local node to include. Only the ones sent by the
+// EVN peers are kept, in the private pool of the miner, so they are never added
+// to the transaction pool nor propagated.
+func (h *bscHandler) handlePrivateTransactions(peer *bsc.Peer, packet *bsc.PrivateTransactionsPacket) error {
+	if !h.enableEVNFeatures || h.privateTxs == nil {
+		return nil
+	}
+	if p := h.peers.peer(peer.ID()); p == nil || !p.EVNPeerFlag.Load() {
+		peer.Log().Debug("Ignoring private transactions from non-EVN peer", "txs", len(packet.Txs))
+		return nil
+	}
+	head := h.chain.CurrentBlock().Number.Uint64()
+	if packet.MaxBlockNumber <= head || packet.MaxBlockNumber > head+miner.MaxPrivateTxAliveBlock {
+		peer.Log().Debug("Ignoring private transactions with invalid max block number", "txs", len(packet.Txs), "maxBlockNumber", packet.MaxBlockNumber, "head", head)
+		return nil
+	}
+	for _, tx := range packet.Txs {
+		if err := h.privateTxs.SendPrivateTransaction(tx, packet.MaxBlockNumber); err != nil {
+			peer.Log().Debug("Failed to add private transaction", "hash", tx.Hash(), "err", err)
+		}
+	}
+	return nil
+}
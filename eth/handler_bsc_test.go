@@ -2,6 +2,7 @@ package eth
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/Ezkerrox/bsc/eth/protocols/bsc"
 	"github.com/Ezkerrox/bsc/eth/protocols/eth"
 	"github.com/Ezkerrox/bsc/event"
+	"github.com/Ezkerrox/bsc/miner"
 	"github.com/Ezkerrox/bsc/p2p"
 	"github.com/Ezkerrox/bsc/p2p/enode"
 )
@@ -257,3 +259,152 @@ func testRecvVotes(t *testing.T, protocol uint) {
 		t.Errorf("no NewVotesEvent received within 2 seconds")
 	}
 }
+
+// testPrivateTxPool is a mock private transaction pool recording the private
+// transactions with their max block number.
+type testPrivateTxPool struct {
+	txs  map[common.Hash]uint64
+	lock sync.Mutex
+}
+
+func (p *testPrivateTxPool) SendPrivateTransaction(tx *types.Transaction, maxBlockNumber uint64) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.txs[tx.Hash()] = maxBlockNumber
+	return nil
+}
+
+func (p *testPrivateTxPool) get(hash common.Hash) (uint64, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	maxBlockNumber, ok := p.txs[hash]
+	return maxBlockNumber, ok
+}
+
+// newTestBscPeer creates a peer on the `bsc` protocol registered in the peer set
+// of the handler, communicating over the given pipe end.
+func newTestBscPeer(h *handler, id enode.ID, version uint, rw p2p.MsgReadWriter, evn bool) *ethPeer {
+	p2pPeer := p2p.NewPeer(id, "", nil)
+	p2pPeer.EVNPeerFlag.Store(evn)
+	peer := &ethPeer{
+		Peer:   eth.NewPeer(eth.ETH68, p2pPeer, rw, nil),
+		bscExt: &bscPeer{bsc.NewPeer(version, p2pPeer, rw)},
+	}
+	h.peers.lock.Lock()
+	h.peers.peers[peer.ID()] = peer
+	h.peers.lock.Unlock()
+	return peer
+}
+
+func TestSendPrivateTransactions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	var (
+		h         = handler.handler
+		validator = common.Address{0x1}
+		tx        = types.NewTransaction(0, common.Address{0x2}, common.Big1, 21000, common.Big1, nil)
+	)
+	h.enableEVNFeatures = true
+	h.proxyedValidatorAddressMap = map[common.Address]struct{}{validator: {}}
+
+	validatorSrc, validatorSink := p2p.MsgPipe()
+	defer validatorSrc.Close()
+	defer validatorSink.Close()
+	otherSrc, otherSink := p2p.MsgPipe()
+	defer otherSrc.Close()
+	defer otherSink.Close()
+	legacySrc, legacySink := p2p.MsgPipe()
+	defer legacySrc.Close()
+	defer legacySink.Close()
+
+	// Only the EVN peer of the proxyed validator supporting the private
+	// transactions is sent them, not the other EVN peers
+	validatorPeer := newTestBscPeer(h, enode.ID{1}, bsc.BscEVN, validatorSrc, true)
+	otherPeer := newTestBscPeer(h, enode.ID{2}, bsc.BscEVN, otherSrc, true)
+	legacyPeer := newTestBscPeer(h, enode.ID{3}, bsc.Bsc2, legacySrc, true)
+	defer validatorPeer.Close()
+	defer otherPeer.Close()
+	defer legacyPeer.Close()
+
+	h.peers.lock.Lock()
+	h.peers.validatorNodeIDsMap = map[common.Address][]enode.ID{validator: {{1}, {3}}}
+	h.peers.lock.Unlock()
+
+	if peers := h.peers.proxyedValidatorPeers(h.proxyedValidatorAddressMap); len(peers) != 1 || peers[0] != validatorPeer {
+		t.Fatalf("proxyed validator peers mismatch: have %d, want 1", len(peers))
+	}
+	go h.SendPrivateTransactions(types.Transactions{tx}, 10)
+
+	want := &bsc.PrivateTransactionsPacket{MaxBlockNumber: 10, Txs: []*types.Transaction{tx}}
+	if err := p2p.ExpectMsg(validatorSink, bsc.PrivateTransactionsMsg, want); err != nil {
+		t.Fatalf("private transactions not sent: %v", err)
+	}
+}
+
+func TestRecvPrivateTransactions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	var (
+		h       = handler.handler
+		private = &testPrivateTxPool{txs: make(map[common.Hash]uint64)}
+	)
+	h.enableEVNFeatures = true
+	h.privateTxs = private
+
+	evnSrc, evnSink := p2p.MsgPipe()
+	defer evnSrc.Close()
+	defer evnSink.Close()
+	otherSrc, otherSink := p2p.MsgPipe()
+	defer otherSrc.Close()
+	defer otherSink.Close()
+
+	evnPeer := newTestBscPeer(h, enode.ID{1}, bsc.BscEVN, evnSrc, true)
+	otherPeer := newTestBscPeer(h, enode.ID{2}, bsc.BscEVN, otherSrc, false)
+	defer evnPeer.Close()
+	defer otherPeer.Close()
+
+	go bsc.Handle((*bscHandler)(h), evnPeer.bscExt.Peer)
+	go bsc.Handle((*bscHandler)(h), otherPeer.bscExt.Peer)
+
+	var (
+		evnTx     = types.NewTransaction(0, common.Address{0x2}, common.Big1, 21000, common.Big1, nil)
+		staleTx   = types.NewTransaction(1, common.Address{0x2}, common.Big1, 21000, common.Big1, nil)
+		distantTx = types.NewTransaction(2, common.Address{0x2}, common.Big1, 21000, common.Big1, nil)
+		otherTx   = types.NewTransaction(3, common.Address{0x2}, common.Big1, 21000, common.Big1, nil)
+	)
+	send := func(rw p2p.MsgReadWriter, tx *types.Transaction, maxBlockNumber uint64) {
+		packet := &bsc.PrivateTransactionsPacket{MaxBlockNumber: maxBlockNumber, Txs: []*types.Transaction{tx}}
+		if err := p2p.Send(rw, bsc.PrivateTransactionsMsg, packet); err != nil {
+			t.Fatalf("failed to send private transactions: %v", err)
+		}
+	}
+	send(evnSink, evnTx, 10)
+	send(evnSink, staleTx, 0)
+	send(evnSink, distantTx, miner.MaxPrivateTxAliveBlock+1)
+	send(otherSink, otherTx, 10)
+	time.Sleep(100 * time.Millisecond)
+
+	// Only the private transactions of the EVN peers with a valid max block
+	// number are kept, out of the transaction pool
+	if maxBlockNumber, ok := private.get(evnTx.Hash()); !ok || maxBlockNumber != 10 {
+		t.Errorf("private transaction from EVN peer not kept")
+	}
+	for _, tx := range []*types.Transaction{staleTx, distantTx, otherTx} {
+		if _, ok := private.get(tx.Hash()); ok {
+			t.Errorf("private transaction %x kept", tx.Hash())
+		}
+	}
+	for _, tx := range []*types.Transaction{evnTx, staleTx, distantTx, otherTx} {
+		if handler.txpool.Has(tx.Hash()) {
+			t.Errorf("private transaction %x added to the transaction pool", tx.Hash())
+		}
+	}
+}
@@ -77,9 +77,11 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 				return errors.New("disallowed broadcast blob transaction")
 			}
 		}
+		h.txHistory.Received(peer.ID(), *packet)
 		return h.txFetcher.Enqueue(peer.ID(), *packet, false)
 
 	case *eth.PooledTransactionsResponse:
+		h.txHistory.Received(peer.ID(), *packet)
 		return h.txFetcher.Enqueue(peer.ID(), *packet, true)
 
 	default:
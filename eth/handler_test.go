@@ -342,6 +342,10 @@ func (t *testVotePool) PutVote(vote *types.VoteEnvelope) {
 	t.voteFeed.Send(core.NewVoteEvent{Vote: vote})
 }
 
+func (t *testVotePool) PutRemoteVote(vote *types.VoteEnvelope, peer string) {
+	t.PutVote(vote)
+}
+
 func (t *testVotePool) FetchVoteByBlockHash(blockHash common.Hash) []*types.VoteEnvelope {
 	panic("implement me")
 }
@@ -19,6 +19,7 @@ package eth
 import (
 	"net"
 
+	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/eth/protocols/bsc"
 	"github.com/Ezkerrox/bsc/eth/protocols/trust"
 
@@ -69,7 +70,10 @@ type trustPeerInfo struct {
 // bscPeerInfo represents a short summary of the `bsc` sub-protocol metadata known
 // about a connected peer.
 type bscPeerInfo struct {
-	Version uint `json:"version"` // bsc protocol version negotiated
+	Version       uint   `json:"version"`                // bsc protocol version negotiated
+	ReceivedVotes uint64 `json:"receivedVotes"`          // Votes received from the peer
+	DroppedVotes  uint64 `json:"droppedVotes"`           // Votes dropped for exceeding the rate limit
+	LastVoteTime  string `json:"lastVoteTime,omitempty"` // Time the last votes were received at
 }
 
 // snapPeer is a wrapper around snap.Peer to maintain a few extra metadata.
@@ -103,7 +107,14 @@ func (p *trustPeer) info() *trustPeerInfo {
 
 // info gathers and returns some `bsc` protocol metadata known about a peer.
 func (p *bscPeer) info() *bscPeerInfo {
-	return &bscPeerInfo{
-		Version: p.Version(),
+	received, dropped, last := p.VoteStats()
+	info := &bscPeerInfo{
+		Version:       p.Version(),
+		ReceivedVotes: received,
+		DroppedVotes:  dropped,
+	}
+	if !last.IsZero() {
+		info.LastVoteTime = common.FormatMilliTime(last.UnixMilli())
 	}
+	return info
 }
@@ -72,8 +72,9 @@ const (
 )
 
 var (
-	evnWhiteListPeerGuage        = metrics.NewRegisteredGauge("evn/peer/whiteList", nil)
-	evnOnchainValidatorPeerGuage = metrics.NewRegisteredGauge("evn/peer/onchainValidator", nil)
+	evnWhiteListPeerGuage         = metrics.NewRegisteredGauge("evn/peer/whiteList", nil)
+	evnOnchainValidatorPeerGuage  = metrics.NewRegisteredGauge("evn/peer/onchainValidator", nil)
+	evnOnchainValidatorPeersGuage = metrics.NewRegisteredGaugeVec("evn/peer/onchainValidatorPeers", nil, "validator")
 )
 
 // peerSet represents the collection of active peers currently participating in
@@ -455,20 +456,24 @@ func (ps *peerSet) enableEVNFeatures(validatorNodeIDsMap map[common.Address][]en
 	ps.lock.Unlock()
 
 	// convert to nodeID filter map, avoid too slow operation for slices.Contains
-	valNodeIDMap := make(map[enode.ID]struct{})
-	for _, nodeIDs := range validatorNodeIDsMap {
+	valNodeIDMap := make(map[enode.ID]common.Address)
+	for validator, nodeIDs := range validatorNodeIDsMap {
 		for _, nodeID := range nodeIDs {
-			valNodeIDMap[nodeID] = struct{}{}
+			valNodeIDMap[nodeID] = validator
 		}
 	}
 
 	var (
 		whiteListPeerCnt        int64 = 0
 		onchainValidatorPeerCnt int64 = 0
+		validatorPeerCnt              = make(map[common.Address]int64, len(validatorNodeIDsMap))
 	)
+	for validator := range validatorNodeIDsMap {
+		validatorPeerCnt[validator] = 0
+	}
 	for _, peer := range peers {
 		nodeID := peer.NodeID()
-		_, isValidatorPeer := valNodeIDMap[nodeID]
+		validator, isValidatorPeer := valNodeIDMap[nodeID]
 		_, isWhitelistPeer := evnWhitelistMap[nodeID]
 
 		if isValidatorPeer || isWhitelistPeer {
@@ -480,6 +485,7 @@ func (ps *peerSet) enableEVNFeatures(validatorNodeIDsMap map[common.Address][]en
 
 		if isValidatorPeer {
 			onchainValidatorPeerCnt++
+			validatorPeerCnt[validator]++
 		}
 		if isWhitelistPeer {
 			whiteListPeerCnt++
@@ -487,6 +493,16 @@ func (ps *peerSet) enableEVNFeatures(validatorNodeIDsMap map[common.Address][]en
 	}
 	evnWhiteListPeerGuage.Update(whiteListPeerCnt)
 	evnOnchainValidatorPeerGuage.Update(onchainValidatorPeerCnt)
+	// drop the validators which left the validator set, report the others even
+	// without connected peers
+	evnOnchainValidatorPeersGuage.Each(func(values []string, _ *metrics.Gauge) {
+		if _, ok := validatorPeerCnt[common.HexToAddress(values[0])]; !ok {
+			evnOnchainValidatorPeersGuage.Delete(values...)
+		}
+	})
+	for validator, cnt := range validatorPeerCnt {
+		evnOnchainValidatorPeersGuage.With(validator.String()).Update(cnt)
+	}
 	log.Info("enable EVN features", "total", len(peers), "whiteListPeerCnt", whiteListPeerCnt, "onchainValidatorPeerCnt", onchainValidatorPeerCnt)
 }
 
@@ -507,6 +523,25 @@ func (ps *peerSet) isProxyedValidator(validator common.Address, proxyedAddressMa
 	return true
 }
 
+// proxyedValidatorPeers retrieves the EVN peers whose node IDs are registered by
+// the proxyed validators, and which support the private transactions.
+func (ps *peerSet) proxyedValidatorPeers(proxyedAddressMap map[common.Address]struct{}) []*ethPeer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	var list []*ethPeer
+	for validator := range proxyedAddressMap {
+		for _, nodeID := range ps.validatorNodeIDsMap[validator] {
+			p, ok := ps.peers[nodeID.String()]
+			if !ok || !p.EVNPeerFlag.Load() || p.bscExt == nil || p.bscExt.Version() < bsc.BscEVN {
+				continue
+			}
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
 // headPeers retrieves a specified number list of peers.
 func (ps *peerSet) headPeers(num uint) []*ethPeer {
 	ps.lock.RLock()
@@ -40,11 +40,16 @@ type Backend interface {
 	Handle(peer *Peer, packet Packet) error
 }
 
-// MakeProtocols constructs the P2P protocol definitions for `bsc`.
-func MakeProtocols(backend Backend) []p2p.Protocol {
-	protocols := make([]p2p.Protocol, len(ProtocolVersions))
-	for i, version := range ProtocolVersions {
-		protocols[i] = p2p.Protocol{
+// MakeProtocols constructs the P2P protocol definitions for `bsc`. The fork-only
+// BscEVN version is only advertised if evn is set, i.e. if the EVN features or
+// the private transaction proxying are configured.
+func MakeProtocols(backend Backend, evn bool) []p2p.Protocol {
+	protocols := make([]p2p.Protocol, 0, len(ProtocolVersions))
+	for _, version := range ProtocolVersions {
+		if version == BscEVN && !evn {
+			continue
+		}
+		protocols = append(protocols, p2p.Protocol{
 			Name:    ProtocolName,
 			Version: version,
 			Length:  protocolLengths[version],
@@ -63,7 +68,7 @@ func MakeProtocols(backend Backend) []p2p.Protocol {
 				return backend.PeerInfo(id)
 			},
 			Attributes: []enr.Entry{&enrEntry{}},
-		}
+		})
 	}
 	return protocols
 }
@@ -94,6 +99,13 @@ var bsc2 = map[uint64]msgHandler{
 	BlocksByRangeMsg:    handleBlocksByRange,
 }
 
+var bscEVN = map[uint64]msgHandler{
+	VotesMsg:               handleVotes,
+	GetBlocksByRangeMsg:    handleGetBlocksByRange,
+	BlocksByRangeMsg:       handleBlocksByRange,
+	PrivateTransactionsMsg: handlePrivateTransactions,
+}
+
 // handleMessage is invoked whenever an inbound message is received from a
 // remote peer on the `bsc` protocol. The remote connection is torn down upon
 // returning any error.
@@ -109,7 +121,9 @@ func handleMessage(backend Backend, peer *Peer) error {
 	defer msg.Discard()
 
 	var handlers = bsc1
-	if peer.Version() >= Bsc2 {
+	if peer.Version() >= BscEVN {
+		handlers = bscEVN
+	} else if peer.Version() >= Bsc2 {
 		handlers = bsc2
 	}
 
@@ -138,6 +152,20 @@ func handleVotes(backend Backend, msg Decoder, peer *Peer) error {
 	}
 	// Schedule all the unknown hashes for retrieval
 	peer.markVotes(ann.Votes)
+	peer.recordVotes(ann.Votes)
+	return backend.Handle(peer, ann)
+}
+
+func handlePrivateTransactions(backend Backend, msg Decoder, peer *Peer) error {
+	ann := new(PrivateTransactionsPacket)
+	if err := msg.Decode(ann); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	for i, tx := range ann.Txs {
+		if tx == nil {
+			return fmt.Errorf("%w: transaction %d is nil", errDecode, i)
+		}
+	}
 	return backend.Handle(peer, ann)
 }
 
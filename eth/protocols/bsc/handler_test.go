@@ -247,3 +247,21 @@ func TestHandleBlocksByRange(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeProtocolsEVN(t *testing.T) {
+	for _, evn := range []bool{false, true} {
+		protocols := MakeProtocols(&mockBackend{}, evn)
+		var advertised bool
+		for _, protocol := range protocols {
+			if protocol.Version == BscEVN {
+				advertised = true
+			}
+		}
+		if advertised != evn {
+			t.Errorf("evn %v: BscEVN advertised %v", evn, advertised)
+		}
+		if protocols[0].Version != Bsc1 || protocols[1].Version != Bsc2 {
+			t.Errorf("evn %v: unexpected upstream versions", evn)
+		}
+	}
+}
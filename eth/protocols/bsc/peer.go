@@ -1,6 +1,7 @@
 package bsc
 
 import (
+	"sync/atomic"
 	"time"
 
 	"errors"
@@ -37,6 +38,9 @@ type Peer struct {
 	voteBroadcast chan []*types.VoteEnvelope // Channel used to queue votes propagation requests
 	periodBegin   time.Time                  // Begin time of the latest period for votes counting
 	periodCounter uint                       // Votes number in the latest period
+	receivedVotes atomic.Uint64              // Votes received from the peer
+	droppedVotes  atomic.Uint64              // Votes received over the rate limit
+	lastVoteTime  atomic.Int64               // Unix milliseconds the last votes were received at
 	dispatcher    *Dispatcher                // Message request-response dispatcher
 
 	*p2p.Peer                   // The embedded P2P package peer
@@ -105,6 +109,22 @@ func (p *Peer) markVotes(votes []*types.VoteEnvelope) {
 	}
 }
 
+// recordVotes accounts a batch of votes received from the peer.
+func (p *Peer) recordVotes(votes []*types.VoteEnvelope) {
+	p.receivedVotes.Add(uint64(len(votes)))
+	p.lastVoteTime.Store(time.Now().UnixMilli())
+}
+
+// VoteStats returns the number of votes received from the peer, the number of
+// them dropped for exceeding the rate limit, and when the last ones were
+// received.
+func (p *Peer) VoteStats() (received uint64, dropped uint64, last time.Time) {
+	if ms := p.lastVoteTime.Load(); ms > 0 {
+		last = time.UnixMilli(ms)
+	}
+	return p.receivedVotes.Load(), p.droppedVotes.Load(), last
+}
+
 // sendVotes propagates a batch of votes to the remote peer.
 func (p *Peer) sendVotes(votes []*types.VoteEnvelope) error {
 	// Mark all the votes as known, but ensure we don't overflow our limits
@@ -112,6 +132,15 @@ func (p *Peer) sendVotes(votes []*types.VoteEnvelope) error {
 	return p2p.Send(p.rw, VotesMsg, &VotesPacket{votes})
 }
 
+// SendPrivateTransactions sends the private transactions directly to the remote
+// peer, which keeps them out of its transaction pool.
+func (p *Peer) SendPrivateTransactions(txs types.Transactions, maxBlockNumber uint64) error {
+	return p2p.Send(p.rw, PrivateTransactionsMsg, &PrivateTransactionsPacket{
+		MaxBlockNumber: maxBlockNumber,
+		Txs:            txs,
+	})
+}
+
 // AsyncSendVotes queues a batch of vote hashes for propagation to a remote peer. If
 // the peer's broadcast queue is full, the event is silently dropped.
 func (p *Peer) AsyncSendVotes(votes []*types.VoteEnvelope) {
@@ -136,7 +165,11 @@ func (p *Peer) IsOverLimitAfterReceiving() bool {
 		return false
 	}
 	p.periodCounter += 1
-	return p.periodCounter > uint(secondsPerPeriod*receiveRateLimitPerSecond)
+	if p.periodCounter > uint(secondsPerPeriod*receiveRateLimitPerSecond) {
+		p.droppedVotes.Add(1)
+		return true
+	}
+	return false
 }
 
 // broadcastVotes is a write loop that schedules votes broadcasts
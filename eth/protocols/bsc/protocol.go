@@ -12,6 +12,11 @@ import (
 const (
 	Bsc1 = 1
 	Bsc2 = 2
+
+	// BscEVN is private to this fork and only adds the private transactions of
+	// the EVN peers on top of Bsc2. It is kept well above the upstream versions,
+	// so an upstream bsc/3 never negotiates into it.
+	BscEVN = 0x80
 )
 
 // ProtocolName is the official short name of the `bsc` protocol used during
@@ -20,11 +25,11 @@ const ProtocolName = "bsc"
 
 // ProtocolVersions are the supported versions of the `bsc` protocol (first
 // is primary).
-var ProtocolVersions = []uint{Bsc1, Bsc2}
+var ProtocolVersions = []uint{Bsc1, Bsc2, BscEVN}
 
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
-var protocolLengths = map[uint]uint64{Bsc1: 2, Bsc2: 4}
+var protocolLengths = map[uint]uint64{Bsc1: 2, Bsc2: 4, BscEVN: 5}
 
 // maxMessageSize is the maximum cap on the size of a protocol message.
 const maxMessageSize = 10 * 1024 * 1024
@@ -34,6 +39,8 @@ const (
 	VotesMsg            = 0x01
 	GetBlocksByRangeMsg = 0x02 // it can request (StartBlockHeight-Count, StartBlockHeight] range blocks from remote peer
 	BlocksByRangeMsg    = 0x03 // the replied blocks from remote peer
+
+	PrivateTransactionsMsg = 0x04 // BscEVN only: private transactions sent directly to an EVN peer, never gossiped
 )
 
 var defaultExtra = []byte{0x00}
@@ -106,3 +113,13 @@ type BlocksByRangePacket struct {
 
 func (*BlocksByRangePacket) Name() string { return "BlocksByRange" }
 func (*BlocksByRangePacket) Kind() byte   { return BlocksByRangeMsg }
+
+// PrivateTransactionsPacket is the network packet for the private transactions,
+// which the receiver keeps out of its transaction pool and never propagates.
+type PrivateTransactionsPacket struct {
+	MaxBlockNumber uint64 // Last block the transactions can be included in
+	Txs            []*types.Transaction
+}
+
+func (*PrivateTransactionsPacket) Name() string { return "PrivateTransactions" }
+func (*PrivateTransactionsPacket) Kind() byte   { return PrivateTransactionsMsg }
@@ -0,0 +1,244 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/trie"
+)
+
+// stateMigrationJobs is the number of subtries converted concurrently by the
+// state migration, kept low to spare the disk of the live node.
+const stateMigrationJobs = 16
+
+var (
+	errMigrationRunning    = errors.New("state migration is already running")
+	errMigrationNotRunning = errors.New("state migration is not running")
+	errMigrationDone       = errors.New("state is already migrated, restart the node to switch to the path-based scheme")
+)
+
+// stateMigrator converts the hash-based state of a live node into the
+// path-based scheme in the background. The node keeps running on the hash-based
+// state meanwhile and switches to the path-based one at the next restart.
+//
+// The path-based state doesn't follow the chain during and after the conversion,
+// it stays at the converted block. At the restart the chain is rewound to that
+// block, all the blocks imported since are discarded and imported again from the
+// network. The converted block is therefore limited to the recent ones.
+//
+// Once switched, the migrator removes the hash-based trie nodes left in the
+// database in the background.
+type stateMigrator struct {
+	eth  *Ethereum
+	disk ethdb.Database // The database of the trie nodes
+
+	h2p      *trie.Hbss2Pbss // The conversion in progress, nil if none
+	migrated atomic.Bool     // Whether the conversion completed
+
+	quit    chan struct{} // Channel to abort the running conversion or removal
+	done    chan struct{} // Channel closed when the running conversion or removal exits
+	runLock sync.Mutex    // Lock protecting the conversion lifecycle
+}
+
+// newStateMigrator creates the state migrator of the node, loading the
+// conversion in progress.
+func newStateMigrator(eth *Ethereum) (*stateMigrator, error) {
+	m := &stateMigrator{
+		eth:  eth,
+		disk: eth.chainDb,
+	}
+	if eth.chainDb.StateStore() != nil {
+		m.disk = eth.chainDb.StateStore()
+	}
+	if eth.blockchain.TrieDB().Scheme() == rawdb.HashScheme {
+		h2p, err := trie.ResumeHbss2Pbss(eth.blockchain.TrieDB(), stateMigrationJobs)
+		if err != nil {
+			return nil, err
+		}
+		m.h2p = h2p
+	}
+	return m, nil
+}
+
+// resume resumes the conversion interrupted by a shutdown in the hash-based
+// scheme, or the removal of the hash-based trie nodes in the path-based one.
+func (m *stateMigrator) resume() {
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	if m.eth.blockchain.TrieDB().Scheme() == rawdb.PathScheme {
+		if _, pending := rawdb.ReadHashTriePruning(m.disk); pending {
+			m.quit, m.done = make(chan struct{}), make(chan struct{})
+			go m.prune(m.quit, m.done)
+		}
+		return
+	}
+	if m.h2p != nil && !m.h2p.Paused() {
+		log.Info("Resuming state migration", "number", m.h2p.Number(), "root", m.h2p.Root())
+		m.quit, m.done = make(chan struct{}), make(chan struct{})
+		go m.run(m.h2p, m.quit, m.done)
+	}
+}
+
+// migrating reports whether a conversion is in progress or completed.
+func (m *stateMigrator) migrating() bool {
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	return m.h2p != nil
+}
+
+// start starts the conversion of the state of the given block, the head one if
+// nil, or resumes the current conversion if no block is given.
+func (m *stateMigrator) start(number *uint64) error {
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	if m.eth.blockchain.TrieDB().Scheme() != rawdb.HashScheme {
+		return errors.New("state migration is only available in hash-based scheme")
+	}
+	if m.migrated.Load() {
+		return errMigrationDone
+	}
+	if m.running() {
+		return errMigrationRunning
+	}
+	if m.eth.onlinePruner != nil && m.eth.onlinePruner.Running() {
+		return errors.New("state migration is not available during the online pruning")
+	}
+	if m.h2p == nil || number != nil {
+		header := m.eth.blockchain.CurrentBlock()
+		if number != nil {
+			header = m.eth.blockchain.GetHeaderByNumber(*number)
+		}
+		if header == nil {
+			return fmt.Errorf("block %d not found", *number)
+		}
+		head := m.eth.blockchain.CurrentBlock().Number.Uint64()
+		if limit := m.eth.blockchain.TriesInMemory(); header.Number.Uint64()+limit < head {
+			return fmt.Errorf("block %d is too far behind the head %d, at most %d blocks are allowed", header.Number, head, limit)
+		}
+		if !m.eth.blockchain.HasState(header.Root) {
+			return fmt.Errorf("state of block %d is not available", header.Number)
+		}
+		// Persist the state, the in-memory trie nodes might be dereferenced
+		// during the conversion.
+		triedb := m.eth.blockchain.TrieDB()
+		if err := triedb.Commit(header.Root, false); err != nil {
+			return err
+		}
+		h2p, err := trie.NewHbss2Pbss(triedb, header.Root, header.Number.Uint64(), stateMigrationJobs)
+		if err != nil {
+			return err
+		}
+		m.h2p = h2p
+		log.Info("Started state migration", "number", header.Number, "root", header.Root)
+	} else {
+		log.Info("Resumed state migration", "number", m.h2p.Number(), "root", m.h2p.Root())
+	}
+	m.quit, m.done = make(chan struct{}), make(chan struct{})
+	go m.run(m.h2p, m.quit, m.done)
+	return nil
+}
+
+// stop pauses the running conversion, it's not resumed after a restart.
+func (m *stateMigrator) stop() error {
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	if m.h2p == nil || !m.running() {
+		return errMigrationNotRunning
+	}
+	close(m.quit)
+	<-m.done
+
+	if m.migrated.Load() {
+		return errMigrationDone
+	}
+	if err := m.h2p.Pause(); err != nil {
+		return err
+	}
+	log.Info("Paused state migration", "number", m.h2p.Number(), "root", m.h2p.Root())
+	return nil
+}
+
+// close terminates the running conversion or removal without pausing it, it's
+// resumed after the restart.
+func (m *stateMigrator) close() {
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	if m.running() {
+		close(m.quit)
+		<-m.done
+	}
+}
+
+// running reports whether a conversion or removal is running. The run lock is
+// assumed to be held.
+func (m *stateMigrator) running() bool {
+	if m.done == nil {
+		return false
+	}
+	select {
+	case <-m.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// run converts the state, preparing the state history freezer of the
+// path-based scheme once completed.
+func (m *stateMigrator) run(h2p *trie.Hbss2Pbss, quit chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	if err := h2p.Run(quit); err != nil {
+		if !errors.Is(err, trie.ErrHbss2PbssAborted) {
+			log.Error("State migration failed", "err", err)
+		}
+		return
+	}
+	m.migrated.Store(true)
+
+	ancient, err := m.disk.AncientDatadir()
+	if err == nil {
+		err = rawdb.ResetStateFreezerTableOffset(ancient, h2p.Number())
+	}
+	if err != nil {
+		log.Error("Failed to reset state freezer table offset", "err", err)
+		return
+	}
+	log.Info("Migrated state to path-based scheme, restart the node to switch", "number", h2p.Number(), "root", h2p.Root())
+	log.Warn("The chain is rewound to the migrated state at the restart", "number", h2p.Number(), "head", m.eth.blockchain.CurrentBlock().Number)
+}
+
+// prune removes the hash-based trie nodes left by the conversion.
+func (m *stateMigrator) prune(quit chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	if err := rawdb.PruneHashTrieNodeInDataBase(m.disk, quit); err != nil {
+		log.Error("Failed to prune hash-based trie nodes", "err", err)
+	}
+}
@@ -20,6 +20,7 @@ package ethdb
 import (
 	"io"
 
+	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/params"
 )
 
@@ -161,6 +162,19 @@ type AncientWriter interface {
 type FreezerEnv struct {
 	ChainCfg         *params.ChainConfig
 	BlobExtraReserve uint64
+	BlobArchiver     BlobArchiver // Optional, receives the blob sidecars before they are pruned
+}
+
+// BlobArchiver stores the blob sidecars of the blocks pruned from the ancient
+// store, so they remain retrievable past the retention window.
+type BlobArchiver interface {
+	// ArchiveBlobSidecars queues the RLP-encoded blob sidecars of a block to be
+	// archived in the background, it fails if the queue is full.
+	ArchiveBlobSidecars(number uint64, hash common.Hash, sidecars []byte) error
+
+	// ArchivedBlobs returns the number of the first queued block whose blob
+	// sidecars aren't archived yet, the ones below it can be pruned.
+	ArchivedBlobs() uint64
 }
 
 // AncientFreezer defines the help functions for freezing ancient data
@@ -42,7 +42,7 @@ import (
 // The structure can be summarized through this definition:
 //
 //	era1 := Version | block-tuple* | other-entries* | Accumulator | BlockIndex
-//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty
+//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty | CompressedBlobSidecars?
 //
 // Each basic element is its own entry:
 //
@@ -51,9 +51,12 @@ import (
 //	CompressedBody     = { type: [0x04, 0x00], data: snappyFramed(rlp(body)) }
 //	CompressedReceipts = { type: [0x05, 0x00], data: snappyFramed(rlp(receipts)) }
 //	TotalDifficulty    = { type: [0x06, 0x00], data: uint256(header.total_difficulty) }
+//	CompressedBlobSidecars = { type: [0x08, 0x00], data: snappyFramed(rlp(sidecars)) }
 //	AccumulatorRoot    = { type: [0x07, 0x00], data: accumulator-root }
 //	BlockIndex         = { type: [0x32, 0x66], data: block-index }
 //
+// CompressedBlobSidecars is only present for the blocks carrying blob sidecars.
+//
 // Accumulator is computed by constructing an SSZ list of header-records of length at most
 // 8192 and then calculating the hash_tree_root of that list.
 //
@@ -95,7 +98,7 @@ func NewBuilder(w io.Writer) *Builder {
 }
 
 // Add writes a compressed block entry and compressed receipts entry to the
-// underlying e2store file.
+// underlying e2store file, followed by the blob sidecars of the block if any.
 func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
 	eh, err := rlp.EncodeToBytes(block.Header())
 	if err != nil {
@@ -109,12 +112,19 @@ func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int)
 	if err != nil {
 		return err
 	}
-	return b.AddRLP(eh, eb, er, block.NumberU64(), block.Hash(), td, block.Difficulty())
+	var es []byte
+	if len(block.Sidecars()) > 0 {
+		if es, err = rlp.EncodeToBytes(block.Sidecars()); err != nil {
+			return err
+		}
+	}
+	return b.AddRLP(eh, eb, er, es, block.NumberU64(), block.Hash(), td, block.Difficulty())
 }
 
 // AddRLP writes a compressed block entry and compressed receipts entry to the
-// underlying e2store file.
-func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash common.Hash, td, difficulty *big.Int) error {
+// underlying e2store file. The blob sidecars entry is only written if sidecars
+// is not nil.
+func (b *Builder) AddRLP(header, body, receipts, sidecars []byte, number uint64, hash common.Hash, td, difficulty *big.Int) error {
 	// Write Era1 version entry before first block.
 	if b.startNum == nil {
 		n, err := b.w.Write(TypeVersion, nil)
@@ -153,6 +163,11 @@ func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash comm
 		return err
 	}
 
+	if sidecars != nil {
+		if err := b.snappyWrite(TypeCompressedBlobSidecars, sidecars); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
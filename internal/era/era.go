@@ -36,13 +36,14 @@ import (
 )
 
 var (
-	TypeVersion            uint16 = 0x3265
-	TypeCompressedHeader   uint16 = 0x03
-	TypeCompressedBody     uint16 = 0x04
-	TypeCompressedReceipts uint16 = 0x05
-	TypeTotalDifficulty    uint16 = 0x06
-	TypeAccumulator        uint16 = 0x07
-	TypeBlockIndex         uint16 = 0x3266
+	TypeVersion                uint16 = 0x3265
+	TypeCompressedHeader       uint16 = 0x03
+	TypeCompressedBody         uint16 = 0x04
+	TypeCompressedReceipts     uint16 = 0x05
+	TypeTotalDifficulty        uint16 = 0x06
+	TypeAccumulator            uint16 = 0x07
+	TypeCompressedBlobSidecars uint16 = 0x08
+	TypeBlockIndex             uint16 = 0x3266
 
 	MaxEra1Size = 8192
 )
@@ -154,6 +155,35 @@ func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
 	return types.NewBlockWithHeader(&header).WithBody(body), nil
 }
 
+// GetBlobSidecarsByNumber returns the blob sidecars of the block, nil if the
+// block has none.
+func (e *Era) GetBlobSidecarsByNumber(num uint64) (types.BlobSidecars, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	// Skip over header, body, receipts and total difficulty.
+	for i := 0; i < 4; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += length
+	}
+	r, _, err := newBlobSidecarsReader(e.s, off)
+	if r == nil || err != nil {
+		return nil, err
+	}
+	var sidecars types.BlobSidecars
+	if err := rlp.Decode(r, &sidecars); err != nil {
+		return nil, err
+	}
+	return sidecars, nil
+}
+
 // Accumulator reads the accumulator entry in the Era1 file.
 func (e *Era) Accumulator() (common.Hash, error) {
 	entry, err := e.s.Find(TypeAccumulator)
@@ -248,6 +278,20 @@ func newSnappyReader(e *e2store.Reader, expectedType uint16, off int64) (io.Read
 	return snappy.NewReader(r), int64(n), err
 }
 
+// newBlobSidecarsReader returns a snappy.Reader for the blob sidecars entry at
+// off. As the entry is optional, a nil reader is returned if the entry at off is
+// of another type.
+func newBlobSidecarsReader(e *e2store.Reader, off int64) (io.Reader, int64, error) {
+	typ, _, err := e.ReadMetadataAt(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ != TypeCompressedBlobSidecars {
+		return nil, 0, nil
+	}
+	return newSnappyReader(e, TypeCompressedBlobSidecars, off)
+}
+
 // metadata wraps the metadata in the block index.
 type metadata struct {
 	start  uint64
@@ -24,12 +24,15 @@ import (
 	"testing"
 
 	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto/kzg4844"
 )
 
 type testchain struct {
 	headers  [][]byte
 	bodies   [][]byte
 	receipts [][]byte
+	sidecars [][]byte
 	tds      []*big.Int
 }
 
@@ -51,6 +54,11 @@ func TestEra1Builder(t *testing.T) {
 		chain.headers = append(chain.headers, []byte{byte('h'), byte(i)})
 		chain.bodies = append(chain.bodies, []byte{byte('b'), byte(i)})
 		chain.receipts = append(chain.receipts, []byte{byte('r'), byte(i)})
+		if i%3 == 0 {
+			chain.sidecars = append(chain.sidecars, []byte{byte('s'), byte(i)})
+		} else {
+			chain.sidecars = append(chain.sidecars, nil)
+		}
 		chain.tds = append(chain.tds, big.NewInt(int64(i)))
 	}
 
@@ -60,10 +68,11 @@ func TestEra1Builder(t *testing.T) {
 			header   = chain.headers[i]
 			body     = chain.bodies[i]
 			receipts = chain.receipts[i]
+			sidecars = chain.sidecars[i]
 			hash     = common.Hash{byte(i)}
 			td       = chain.tds[i]
 		)
-		if err = builder.AddRLP(header, body, receipts, uint64(i), hash, td, big.NewInt(1)); err != nil {
+		if err = builder.AddRLP(header, body, receipts, sidecars, uint64(i), hash, td, big.NewInt(1)); err != nil {
 			t.Fatalf("error adding entry: %v", err)
 		}
 	}
@@ -123,6 +132,98 @@ func TestEra1Builder(t *testing.T) {
 		if td.Cmp(chain.tds[i]) != 0 {
 			t.Fatalf("mismatched tds: want %s, got %s", chain.tds[i], td)
 		}
+
+		// Check blob sidecars.
+		if chain.sidecars[i] == nil {
+			if it.BlobSidecars != nil {
+				t.Fatalf("unexpected sidecars in block %d", i)
+			}
+			continue
+		}
+		if it.BlobSidecars == nil {
+			t.Fatalf("missing sidecars in block %d", i)
+		}
+		sidecars, err := io.ReadAll(it.BlobSidecars)
+		if err != nil {
+			t.Fatalf("error reading sidecars: %v", err)
+		}
+		if !bytes.Equal(sidecars, chain.sidecars[i]) {
+			t.Fatalf("mismatched sidecars: want %s, got %s", chain.sidecars[i], sidecars)
+		}
+	}
+}
+
+func TestEra1BlobSidecars(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.CreateTemp("", "era1-test")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer f.Close()
+
+	var (
+		builder = NewBuilder(f)
+		blocks  []*types.Block
+	)
+	for i := 0; i < 8; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), Difficulty: common.Big1})
+		if i%2 == 1 {
+			block = block.WithSidecars(types.BlobSidecars{{
+				BlobTxSidecar: types.BlobTxSidecar{
+					Blobs:       []kzg4844.Blob{{byte(i)}},
+					Commitments: []kzg4844.Commitment{{byte(i)}},
+					Proofs:      []kzg4844.Proof{{byte(i)}},
+				},
+				BlockNumber: block.Number(),
+				BlockHash:   block.Hash(),
+				TxHash:      common.Hash{byte(i)},
+			}})
+		}
+		blocks = append(blocks, block)
+		if err := builder.Add(block, nil, big.NewInt(int64(i+1))); err != nil {
+			t.Fatalf("error adding block: %v", err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatalf("error finalizing era1: %v", err)
+	}
+	e, err := Open(f.Name())
+	if err != nil {
+		t.Fatalf("failed to open era: %v", err)
+	}
+	defer e.Close()
+
+	it, err := NewIterator(e)
+	if err != nil {
+		t.Fatalf("failed to make iterator: %v", err)
+	}
+	for _, block := range blocks {
+		if !it.Next() {
+			t.Fatalf("expected more entries")
+		}
+		if it.Error() != nil {
+			t.Fatalf("unexpected error %v", it.Error())
+		}
+		iterated, err := it.BlobSidecars()
+		if err != nil {
+			t.Fatalf("error reading sidecars: %v", err)
+		}
+		fetched, err := e.GetBlobSidecarsByNumber(block.NumberU64())
+		if err != nil {
+			t.Fatalf("error fetching sidecars: %v", err)
+		}
+		for _, sidecars := range []types.BlobSidecars{iterated, fetched} {
+			if len(sidecars) != len(block.Sidecars()) {
+				t.Fatalf("block %d: sidecar count mismatch: have %d, want %d", block.NumberU64(), len(sidecars), len(block.Sidecars()))
+			}
+			for j, sidecar := range sidecars {
+				want := block.Sidecars()[j]
+				if sidecar.TxHash != want.TxHash || sidecar.BlockHash != want.BlockHash || sidecar.Commitments[0] != want.Commitments[0] {
+					t.Fatalf("block %d: sidecar %d mismatch", block.NumberU64(), j)
+				}
+			}
+		}
 	}
 }
 
@@ -110,6 +110,19 @@ func (it *Iterator) TotalDifficulty() (*big.Int, error) {
 	return new(big.Int).SetBytes(reverseOrder(td)), nil
 }
 
+// BlobSidecars returns the blob sidecars for the iterator's current position,
+// nil if the block has none.
+func (it *Iterator) BlobSidecars() (types.BlobSidecars, error) {
+	if it.inner.BlobSidecars == nil {
+		return nil, nil
+	}
+	var sidecars types.BlobSidecars
+	if err := rlp.Decode(it.inner.BlobSidecars, &sidecars); err != nil {
+		return nil, err
+	}
+	return sidecars, nil
+}
+
 // RawIterator reads an RLP-encode Era1 entries.
 type RawIterator struct {
 	e    *Era   // backing Era1
@@ -120,6 +133,7 @@ type RawIterator struct {
 	Body            io.Reader
 	Receipts        io.Reader
 	TotalDifficulty io.Reader
+	BlobSidecars    io.Reader // nil if the block has no blob sidecars
 }
 
 // NewRawIterator returns a new RawIterator instance. Next must be immediately
@@ -133,7 +147,7 @@ func NewRawIterator(e *Era) (*RawIterator, error) {
 
 // Next moves the iterator to the next block entry. It returns false when all
 // items have been read or an error has halted its progress. Header, Body,
-// Receipts, TotalDifficulty will be set to nil in the case returning false or
+// Receipts, TotalDifficulty, BlobSidecars will be set to nil in the case returning false or
 // finding an error and should therefore no longer be read from.
 func (it *RawIterator) Next() bool {
 	// Clear old errors.
@@ -166,7 +180,13 @@ func (it *RawIterator) Next() bool {
 		return true
 	}
 	off += n
-	if it.TotalDifficulty, _, it.err = it.e.s.ReaderAt(TypeTotalDifficulty, off); it.err != nil {
+	var length int
+	if it.TotalDifficulty, length, it.err = it.e.s.ReaderAt(TypeTotalDifficulty, off); it.err != nil {
+		it.clear()
+		return true
+	}
+	off += int64(length)
+	if it.BlobSidecars, _, it.err = newBlobSidecarsReader(it.e.s, off); it.err != nil {
 		it.clear()
 		return true
 	}
@@ -194,4 +214,5 @@ func (it *RawIterator) clear() {
 	it.Body = nil
 	it.Receipts = nil
 	it.TotalDifficulty = nil
+	it.BlobSidecars = nil
 }
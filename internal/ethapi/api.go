@@ -745,7 +745,12 @@ func (api *BlockChainAPI) GetBlobSidecarByTxHash(ctx context.Context, hash commo
 	}
 	txTarget, blockHash, _, Index := rawdb.ReadTransaction(api.b.ChainDb(), hash)
 	if txTarget == nil {
-		return nil, nil
+		// The transaction may no longer be indexed, its sidecar may still be archived
+		sidecar, err := api.b.GetArchivedBlobSidecar(ctx, hash)
+		if sidecar == nil || err != nil {
+			return nil, nil
+		}
+		return marshalBlobSidecar(sidecar, showBlob), nil
 	}
 	block, err := api.b.BlockByHash(ctx, blockHash)
 	if block == nil || err != nil {
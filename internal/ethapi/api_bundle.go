@@ -0,0 +1,208 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/gopool"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/consensus/misc/eip1559"
+	"github.com/Ezkerrox/bsc/consensus/misc/eip4844"
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+// maxBundleAliveBlock is the maximum number of blocks a bundle can wait for
+// its inclusion.
+const maxBundleAliveBlock = 100
+
+// BundleAPI offers the methods for the searchers to submit and simulate bundles,
+// i.e. atomic lists of ordered transactions.
+type BundleAPI struct {
+	b Backend
+}
+
+// NewBundleAPI creates a new BundleAPI.
+func NewBundleAPI(b Backend) *BundleAPI {
+	return &BundleAPI{b}
+}
+
+// SendBundle submits the bundle to the miner, which merges it into the blocks
+// sealed locally if it's profitable enough. The bundle is never broadcast.
+// If no max block number is given, the bundle is only valid for the next block.
+func (api *BundleAPI) SendBundle(ctx context.Context, args types.SendBundleArgs) (common.Hash, error) {
+	bundle, err := args.ToBundle()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	currentNumber := api.b.CurrentHeader().Number.Uint64()
+	if bundle.MaxBlockNumber == 0 {
+		bundle.MaxBlockNumber = currentNumber + 1
+	}
+	if bundle.MaxBlockNumber <= currentNumber {
+		return common.Hash{}, fmt.Errorf("stale max block number: %d, latest block: %d", bundle.MaxBlockNumber, currentNumber)
+	}
+	if bundle.MaxBlockNumber > currentNumber+maxBundleAliveBlock {
+		return common.Hash{}, fmt.Errorf("max block number too far in the future: %d, latest block: %d", bundle.MaxBlockNumber, currentNumber)
+	}
+	if bundle.MinTimestamp != 0 && bundle.MaxTimestamp != 0 && bundle.MinTimestamp > bundle.MaxTimestamp {
+		return common.Hash{}, errors.New("min timestamp is greater than max timestamp")
+	}
+	if bundle.MaxTimestamp != 0 && bundle.MaxTimestamp < uint64(time.Now().Unix()) {
+		return common.Hash{}, errors.New("bundle expired")
+	}
+	if err := api.b.SendBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
+
+// CallBundleArgs represents the arguments to simulate a bundle.
+type CallBundleArgs struct {
+	Txs                    []hexutil.Bytes        `json:"txs"`
+	StateBlockNumberOrHash *rpc.BlockNumberOrHash `json:"stateBlockNumber"` // The block to simulate on top of, latest if empty
+	Timestamp              *uint64                `json:"timestamp"`        // The timestamp of the simulated block, parent's + 1 if empty
+	Coinbase               *common.Address        `json:"coinbase"`         // The coinbase of the simulated block, parent's if empty
+}
+
+// CallBundleTxResult is the execution result of a transaction in the bundle.
+type CallBundleTxResult struct {
+	TxHash       common.Hash     `json:"txHash"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	GasPrice     *hexutil.Big    `json:"gasPrice"`     // Coinbase diff per gas unit
+	CoinbaseDiff *hexutil.Big    `json:"coinbaseDiff"` // Balance increase of the block rewards receivers
+	Logs         []*types.Log    `json:"logs"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// CallBundleResult is the execution result of a bundle.
+type CallBundleResult struct {
+	BundleHash       common.Hash           `json:"bundleHash"`
+	StateBlockNumber hexutil.Uint64        `json:"stateBlockNumber"`
+	TotalGasUsed     hexutil.Uint64        `json:"totalGasUsed"`
+	BundleGasPrice   *hexutil.Big          `json:"bundleGasPrice"`
+	CoinbaseDiff     *hexutil.Big          `json:"coinbaseDiff"`
+	Results          []*CallBundleTxResult `json:"results"`
+}
+
+// CallBundle executes the transactions of the bundle in order, in a block built
+// on top of the given one, and returns the gas used, the logs and the coinbase
+// diff of each of them. It doesn't make any changes in the state/blockchain.
+func (api *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	bundle, err := (&types.SendBundleArgs{Txs: args.Txs}).ToBundle()
+	if err != nil {
+		return nil, err
+	}
+	blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if args.StateBlockNumberOrHash != nil {
+		blockNrOrHash = *args.StateBlockNumberOrHash
+	}
+	statedb, parent, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	header := api.nextHeader(parent, args.Timestamp, args.Coinbase)
+
+	// Setup context so it may be cancelled the call has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if timeout := api.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		config   = api.b.ChainConfig()
+		signer   = types.MakeSigner(config, header.Number, header.Time)
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, api.b), &header.Coinbase)
+		evm      = api.b.GetEVM(ctx, statedb, header, nil, &blockCtx)
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+		total    = new(big.Int)
+		result   = &CallBundleResult{
+			BundleHash:       bundle.Hash(),
+			StateBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+		}
+	)
+	gopool.Submit(func() {
+		<-ctx.Done()
+		evm.Cancel()
+	})
+	for i, tx := range bundle.Txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sender of transaction %s: %v", tx.Hash(), err)
+		}
+		before := core.RewardBalance(statedb, config, header.Coinbase)
+		statedb.SetTxContext(tx.Hash(), i)
+		receipt, err := core.ApplyTransaction(evm, gp, statedb, header, tx, &header.GasUsed)
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", api.b.RPCEVMTimeout())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s failed: %w", tx.Hash(), err)
+		}
+		diff := new(big.Int).Sub(core.RewardBalance(statedb, config, header.Coinbase).ToBig(), before.ToBig())
+		total.Add(total, diff)
+
+		txResult := &CallBundleTxResult{
+			TxHash:       tx.Hash(),
+			From:         from,
+			To:           tx.To(),
+			GasUsed:      hexutil.Uint64(receipt.GasUsed),
+			GasPrice:     (*hexutil.Big)(new(big.Int).Div(diff, new(big.Int).SetUint64(receipt.GasUsed))),
+			CoinbaseDiff: (*hexutil.Big)(diff),
+			Logs:         receipt.Logs,
+		}
+		if txResult.Logs == nil {
+			txResult.Logs = []*types.Log{}
+		}
+		if receipt.Status == types.ReceiptStatusFailed {
+			txResult.Error = "execution reverted"
+		}
+		result.Results = append(result.Results, txResult)
+	}
+	result.TotalGasUsed = hexutil.Uint64(header.GasUsed)
+	result.CoinbaseDiff = (*hexutil.Big)(total)
+	result.BundleGasPrice = (*hexutil.Big)(new(big.Int).Div(total, new(big.Int).SetUint64(header.GasUsed)))
+	return result, nil
+}
+
+// nextHeader assembles the header of the block simulated on top of the parent.
+func (api *BundleAPI) nextHeader(parent *types.Header, timestamp *uint64, coinbase *common.Address) *types.Header {
+	config := api.b.ChainConfig()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 1,
+		Coinbase:   parent.Coinbase,
+		Difficulty: parent.Difficulty,
+	}
+	if timestamp != nil {
+		header.Time = *timestamp
+	}
+	if coinbase != nil {
+		header.Coinbase = *coinbase
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if config.IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if config.IsCancun(parent.Number, parent.Time) {
+			excessBlobGas = eip4844.CalcExcessBlobGas(config, parent, header.Time)
+		}
+		header.ExcessBlobGas = &excessBlobGas
+		header.BlobGasUsed = new(uint64)
+	}
+	return header
+}
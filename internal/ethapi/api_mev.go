@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/params"
 )
@@ -92,6 +93,18 @@ func (m *MevAPI) BestBidGasFee(_ context.Context, parentHash common.Hash) *big.I
 	return m.b.BestBidGasFee(parentHash)
 }
 
+// GetBidHistory returns the records of the recently simulated bids of the given
+// block and builder, all the blocks or builders if not specified.
+func (m *MevAPI) GetBidHistory(number *hexutil.Uint64, builder *common.Address) []*types.BidRecord {
+	return m.b.BidHistory((*uint64)(number), builder)
+}
+
+// GetBuilderStats returns the reputation of the builders, computed over the
+// recently simulated bids.
+func (m *MevAPI) GetBuilderStats() []*types.BuilderStats {
+	return m.b.BuilderStats()
+}
+
 func (m *MevAPI) Params() *types.MevParams {
 	return m.b.MevParams()
 }
@@ -0,0 +1,82 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/hexutil"
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+// defaultPrivateTxAliveBlock is the number of blocks a private transaction waits
+// for its inclusion if no max block number is given. The maximum is enforced by
+// the miner, for the transactions of the EVN peers too.
+const defaultPrivateTxAliveBlock = 25
+
+// SendPrivateTransactionArgs represents the arguments to submit a private
+// transaction.
+type SendPrivateTransactionArgs struct {
+	Tx             hexutil.Bytes   `json:"tx"`
+	MaxBlockNumber *hexutil.Uint64 `json:"maxBlockNumber"` // Last block the transaction can be included in
+}
+
+// PrivateTxAPI offers the methods to submit transactions which are kept out of
+// the transaction pool and never broadcast to the network.
+type PrivateTxAPI struct {
+	b Backend
+}
+
+// NewPrivateTxAPI creates a new PrivateTxAPI.
+func NewPrivateTxAPI(b Backend) *PrivateTxAPI {
+	return &PrivateTxAPI{b}
+}
+
+// SendPrivateTransaction submits a signed transaction which is only included in
+// the blocks sealed by this node, or by the proxyed validators it is directly
+// connected to over the EVN. It is not pooled, and only sent to these validators
+// in a dedicated message they never propagate. The transaction is dropped after
+// the max block number, 25 blocks ahead of the latest one if not given.
+func (api *PrivateTxAPI) SendPrivateTransaction(ctx context.Context, args SendPrivateTransactionArgs) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(args.Tx); err != nil {
+		return common.Hash{}, err
+	}
+	if tx.Type() == types.BlobTxType {
+		return common.Hash{}, errors.New("blob transactions can't be sent privately")
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), api.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	if !api.b.UnprotectedAllowed() && !tx.Protected() {
+		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	statedb, header, err := api.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if statedb == nil || err != nil {
+		return common.Hash{}, err
+	}
+	currentNumber := header.Number.Uint64()
+	maxBlockNumber := currentNumber + defaultPrivateTxAliveBlock
+	if args.MaxBlockNumber != nil {
+		maxBlockNumber = uint64(*args.MaxBlockNumber)
+	}
+	if maxBlockNumber <= currentNumber {
+		return common.Hash{}, fmt.Errorf("stale max block number: %d, latest block: %d", maxBlockNumber, currentNumber)
+	}
+	from, err := types.Sender(types.LatestSigner(api.b.ChainConfig()), tx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid sender: %v", err)
+	}
+	if nonce := statedb.GetNonce(from); tx.Nonce() < nonce {
+		return common.Hash{}, fmt.Errorf("%w: address %v, tx: %d state: %d", core.ErrNonceTooLow, from, tx.Nonce(), nonce)
+	}
+	if err := api.b.SendPrivateTransaction(ctx, tx, maxBlockNumber); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "hash", tx.Hash(), "from", from, "nonce", tx.Nonce(), "maxBlockNumber", maxBlockNumber)
+	return tx.Hash(), nil
+}
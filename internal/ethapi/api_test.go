@@ -582,6 +582,9 @@ func (b testBackend) GetBlobSidecars(ctx context.Context, hash common.Hash) (typ
 	blobSidecars := rawdb.ReadBlobSidecars(b.db, hash, header.Number.Uint64())
 	return blobSidecars, nil
 }
+func (b testBackend) GetArchivedBlobSidecar(ctx context.Context, txHash common.Hash) (*types.BlobSidecar, error) {
+	return nil, nil
+}
 func (b testBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
 	if b.pending != nil && hash == b.pending.Hash() {
 		return nil
@@ -610,6 +613,9 @@ func (b testBackend) SubscribeFinalizedHeaderEvent(ch chan<- core.FinalizedHeade
 func (b testBackend) SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeBlockTimelineEvent(ch chan<- core.BlockTimelineEvent) event.Subscription {
+	panic("implement me")
+}
 func (b testBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	panic("implement me")
 }
@@ -665,6 +671,16 @@ func (b *testBackend) SendBid(ctx context.Context, bid *types.BidArgs) (common.H
 	panic("implement me")
 }
 func (b *testBackend) MinerInTurn() bool { return false }
+func (b *testBackend) BidHistory(number *uint64, builder *common.Address) []*types.BidRecord {
+	return nil
+}
+func (b *testBackend) BuilderStats() []*types.BuilderStats { return nil }
+func (b *testBackend) SendBundle(ctx context.Context, bundle *types.Bundle) error {
+	return nil
+}
+func (b *testBackend) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error {
+	return nil
+}
 func (b *testBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 	//TODO implement me
 	panic("implement me")
@@ -3883,3 +3899,45 @@ func TestCreateAccessListWithStateOverrides(t *testing.T) {
 	}}
 	require.Equal(t, expected, result.Accesslist)
 }
+
+func TestCallBundle(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		signer   = types.LatestSigner(params.MergedTestChainConfig)
+		gasPrice = big.NewInt(10 * params.InitialBaseFee)
+	)
+	api := NewBundleAPI(newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	}))
+	newTx := func(nonce uint64) hexutil.Bytes {
+		tx := types.MustSignNewTx(accounts[0].key, signer, &types.LegacyTx{Nonce: nonce, To: &accounts[1].addr, Value: big.NewInt(1), Gas: params.TxGas, GasPrice: gasPrice})
+		enc, _ := tx.MarshalBinary()
+		return enc
+	}
+	result, err := api.CallBundle(context.Background(), CallBundleArgs{Txs: []hexutil.Bytes{newTx(0), newTx(1)}})
+	if err != nil {
+		t.Fatalf("failed to call bundle: %v", err)
+	}
+	require.Equal(t, hexutil.Uint64(1), result.StateBlockNumber)
+	require.Equal(t, hexutil.Uint64(2*params.TxGas), result.TotalGasUsed)
+	require.Len(t, result.Results, 2)
+	for _, res := range result.Results {
+		require.Equal(t, accounts[0].addr, res.From)
+		require.Equal(t, hexutil.Uint64(params.TxGas), res.GasUsed)
+		require.Empty(t, res.Error)
+		require.Positive(t, res.CoinbaseDiff.ToInt().Sign())
+	}
+	require.Equal(t, result.CoinbaseDiff.ToInt(), new(big.Int).Add(result.Results[0].CoinbaseDiff.ToInt(), result.Results[1].CoinbaseDiff.ToInt()))
+
+	// The transactions must be executed on top of each other
+	if _, err := api.CallBundle(context.Background(), CallBundleArgs{Txs: []hexutil.Bytes{newTx(1)}}); err == nil {
+		t.Fatal("expected nonce failure")
+	}
+}
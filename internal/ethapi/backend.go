@@ -75,6 +75,7 @@ type Backend interface {
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 	GetBlobSidecars(ctx context.Context, hash common.Hash) (types.BlobSidecars, error)
+	GetArchivedBlobSidecar(ctx context.Context, txHash common.Hash) (*types.BlobSidecar, error)
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
@@ -103,6 +104,7 @@ type Backend interface {
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 	SubscribeFinalizedHeaderEvent(ch chan<- core.FinalizedHeaderEvent) event.Subscription
 	SubscribeNewVoteEvent(chan<- core.NewVoteEvent) event.Subscription
+	SubscribeBlockTimelineEvent(ch chan<- core.BlockTimelineEvent) event.Subscription
 
 	// MevRunning return true if mev is running
 	MevRunning() bool
@@ -122,8 +124,17 @@ type Backend interface {
 	SendBid(ctx context.Context, bid *types.BidArgs) (common.Hash, error)
 	// BestBidGasFee returns the gas fee of the best bid for the given parent hash.
 	BestBidGasFee(parentHash common.Hash) *big.Int
+	// BidHistory returns the records of the recently simulated bids.
+	BidHistory(number *uint64, builder *common.Address) []*types.BidRecord
+	// BuilderStats returns the reputation of the builders.
+	BuilderStats() []*types.BuilderStats
 	// MinerInTurn returns true if the validator is in turn to propose the block.
 	MinerInTurn() bool
+	// SendBundle submits the bundle to the miner.
+	SendBundle(ctx context.Context, bundle *types.Bundle) error
+	// SendPrivateTransaction submits the transaction to the miner, and to the
+	// proxyed validators over the EVN, without adding it to the pool.
+	SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error
 }
 
 func GetAPIs(apiBackend Backend) []rpc.API {
@@ -150,6 +161,12 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		}, {
 			Namespace: "mev",
 			Service:   NewMevAPI(apiBackend),
+		}, {
+			Namespace: "eth",
+			Service:   NewBundleAPI(apiBackend),
+		}, {
+			Namespace: "eth",
+			Service:   NewPrivateTxAPI(apiBackend),
 		},
 	}
 }
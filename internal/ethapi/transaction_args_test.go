@@ -372,6 +372,9 @@ func (b *backendMock) GetReceipts(ctx context.Context, hash common.Hash) (types.
 func (b *backendMock) GetBlobSidecars(ctx context.Context, hash common.Hash) (types.BlobSidecars, error) {
 	return nil, nil
 }
+func (b *backendMock) GetArchivedBlobSidecar(ctx context.Context, txHash common.Hash) (*types.BlobSidecar, error) {
+	return nil, nil
+}
 func (b *backendMock) GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error) {
 	return nil, nil
 }
@@ -389,6 +392,9 @@ func (b *backendMock) SubscribeFinalizedHeaderEvent(ch chan<- core.FinalizedHead
 func (b *backendMock) SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.Subscription {
 	return nil
 }
+func (b *backendMock) SubscribeBlockTimelineEvent(ch chan<- core.BlockTimelineEvent) event.Subscription {
+	return nil
+}
 func (b *backendMock) SendTx(ctx context.Context, signedTx *types.Transaction) error { return nil }
 func (b *backendMock) GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error) {
 	return false, nil, [32]byte{}, 0, 0, nil
@@ -430,6 +436,16 @@ func (b *backendMock) SendBid(ctx context.Context, bid *types.BidArgs) (common.H
 	panic("implement me")
 }
 func (b *backendMock) MinerInTurn() bool { return false }
+func (b *backendMock) BidHistory(number *uint64, builder *common.Address) []*types.BidRecord {
+	return nil
+}
+func (b *backendMock) BuilderStats() []*types.BuilderStats { return nil }
+func (b *backendMock) SendBundle(ctx context.Context, bundle *types.Bundle) error {
+	return nil
+}
+func (b *backendMock) SendPrivateTransaction(ctx context.Context, tx *types.Transaction, maxBlockNumber uint64) error {
+	return nil
+}
 func (b *backendMock) BestBidGasFee(parentHash common.Hash) *big.Int {
 	panic("implement me")
 }
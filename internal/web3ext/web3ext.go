@@ -18,15 +18,17 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":  AdminJs,
-	"parlia": ParliaJs,
-	"debug":  DebugJs,
-	"eth":    EthJs,
-	"miner":  MinerJs,
-	"net":    NetJs,
-	"rpc":    RpcJs,
-	"txpool": TxpoolJs,
-	"dev":    DevJs,
+	"admin":   AdminJs,
+	"parlia":  ParliaJs,
+	"debug":   DebugJs,
+	"eth":     EthJs,
+	"miner":   MinerJs,
+	"net":     NetJs,
+	"rpc":     RpcJs,
+	"txpool":  TxpoolJs,
+	"dev":     DevJs,
+	"monitor": MonitorJs,
+	"vote":    VoteJs,
 }
 
 const ParliaJs = `
@@ -67,6 +69,18 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getValidatorPerformance',
+			call: 'parlia_getValidatorPerformance',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAttestation',
+			call: 'parlia_getAttestation',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: []
 });
@@ -406,6 +420,17 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'getBlockProfile',
+			call: 'debug_getBlockProfile',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getSlowTransactions',
+			call: 'debug_getSlowTransactions',
+			params: 0,
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -459,6 +484,32 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'startPruning',
+			call: 'debug_startPruning',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'stopPruning',
+			call: 'debug_stopPruning',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'startStateMigration',
+			call: 'debug_startStateMigration',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'stopStateMigration',
+			call: 'debug_stopStateMigration',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getBlockTimeline',
+			call: 'debug_getBlockTimeline',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -590,6 +641,21 @@ web3._extend({
 			call: 'eth_getBlobSidecarByTxHash',
 			params: 2,
 		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'eth_sendBundle',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'callBundle',
+			call: 'eth_callBundle',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'sendPrivateTransaction',
+			call: 'eth_sendPrivateTransaction',
+			params: 1,
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -708,6 +774,20 @@ web3._extend({
 });
 `
 
+const MonitorJs = `
+web3._extend({
+	property: 'monitor',
+	methods: [
+		new web3._extend.Method({
+			name: 'getDoubleSignEvidence',
+			call: 'monitor_getDoubleSignEvidence',
+			params: 0
+		}),
+	],
+	properties: []
+});
+`
+
 const TxpoolJs = `
 web3._extend({
 	property: 'txpool',
@@ -736,6 +816,33 @@ web3._extend({
 			call: 'txpool_contentFrom',
 			params: 1,
 		}),
+		new web3._extend.Property({
+			name: 'overflowContent',
+			getter: 'txpool_overflowContent'
+		}),
+		new web3._extend.Property({
+			name: 'overflowStatus',
+			getter: 'txpool_overflowStatus'
+		}),
+		new web3._extend.Method({
+			name: 'overflowContentFrom',
+			call: 'txpool_overflowContentFrom',
+			params: 1,
+		}),
+		new web3._extend.Property({
+			name: 'policy',
+			getter: 'txpool_policy'
+		}),
+		new web3._extend.Method({
+			name: 'setPolicy',
+			call: 'txpool_setPolicy',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'getTransactionHistory',
+			call: 'txpool_getTransactionHistory',
+			params: 1,
+		}),
 	]
 });
 `
@@ -758,3 +865,22 @@ web3._extend({
 	],
 });
 `
+
+const VoteJs = `
+web3._extend({
+	property: 'vote',
+	methods: [
+		new web3._extend.Method({
+			name: 'getVotes',
+			call: 'vote_getVotes',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getPoolStatus',
+			call: 'vote_getPoolStatus',
+			params: 0
+		}),
+	],
+	properties: []
+});
+`
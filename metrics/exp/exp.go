@@ -7,6 +7,7 @@ import (
 	"expvar"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/Ezkerrox/bsc/log"
@@ -277,8 +278,26 @@ func (exp *exp) syncToExpvar() {
 			exp.publishResettingTimer(name, i)
 		case *metrics.Label:
 			exp.publishLabel(name, i)
+		case *metrics.CounterVec:
+			i.Each(func(values []string, c *metrics.Counter) {
+				exp.publishCounter(seriesName(name, values), c.Snapshot())
+			})
+		case *metrics.GaugeVec:
+			i.Each(func(values []string, g *metrics.Gauge) {
+				exp.publishGauge(seriesName(name, values), g.Snapshot())
+			})
+		case *metrics.HistogramVec:
+			i.Each(func(values []string, h metrics.Histogram) {
+				exp.publishHistogram(seriesName(name, values), h)
+			})
 		default:
 			panic(fmt.Sprintf("unsupported type for '%s': %T", name, i))
 		}
 	})
 }
+
+// seriesName returns the name of the metric of a vector with the given label
+// values.
+func seriesName(name string, values []string) string {
+	return name + "." + strings.Join(values, ".")
+}
@@ -6,6 +6,32 @@ import (
 	"github.com/Ezkerrox/bsc/metrics"
 )
 
+// readSeries calls fn with the measurement, fields and tags of each series of
+// the metric. A metric vector has a series per label set, tagged with its labels
+// on top of the given tags.
+func readSeries(namespace, name string, i interface{}, tags map[string]string, fn func(measurement string, fields map[string]interface{}, tags map[string]string)) {
+	labeled := metrics.EachLabeled(i, func(labels []string, values []string, metric interface{}) {
+		measurement, fields := readMeter(namespace, name, metric)
+		if fields == nil {
+			return
+		}
+		series := make(map[string]string, len(tags)+len(labels))
+		for k, v := range tags {
+			series[k] = v
+		}
+		for j, label := range labels {
+			series[label] = values[j]
+		}
+		fn(measurement, fields, series)
+	})
+	if labeled {
+		return
+	}
+	if measurement, fields := readMeter(namespace, name, i); fields != nil {
+		fn(measurement, fields, tags)
+	}
+}
+
 func readMeter(namespace, name string, i interface{}) (string, map[string]interface{}) {
 	switch metric := i.(type) {
 	case *metrics.Counter:
@@ -109,6 +109,21 @@ func TestExampleV2(t *testing.T) {
 	}
 }
 
+func TestReadSeriesVec(t *testing.T) {
+	c := metrics.NewCounterVec("builder")
+	c.With("b").Inc(2)
+	c.With("a").Inc(1)
+
+	var have []string
+	readSeries("goth.", "wins", c, map[string]string{"host": "h"}, func(measurement string, fields map[string]interface{}, tags map[string]string) {
+		have = append(have, fmt.Sprintf("%s %v host=%s builder=%s", measurement, fields["value"], tags["host"], tags["builder"]))
+	})
+	want := []string{"goth.wins.count 1 host=h builder=a", "goth.wins.count 2 host=h builder=b"}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("series mismatch:\nhave: %v\nwant: %v", have, want)
+	}
+}
+
 func findFirstDiffPos(a, b string) string {
 	yy := strings.Split(b, "\n")
 	for i, x := range strings.Split(a, "\n") {
@@ -140,13 +140,11 @@ func (r *reporter) send(tstamp int64) error {
 		} else {
 			now = time.Unix(tstamp, 0)
 		}
-		measurement, fields := readMeter(r.namespace, name, i)
-		if fields == nil {
-			return
-		}
-		if p, err := client.NewPoint(measurement, r.tags, fields, now); err == nil {
-			bps.AddPoint(p)
-		}
+		readSeries(r.namespace, name, i, r.tags, func(measurement string, fields map[string]interface{}, tags map[string]string) {
+			if p, err := client.NewPoint(measurement, tags, fields, now); err == nil {
+				bps.AddPoint(p)
+			}
+		})
 	})
 	return r.client.Write(bps)
 }
@@ -20,63 +20,74 @@ func LogScaled(r Registry, freq time.Duration, scale time.Duration, l Logger) {
 
 	for range time.Tick(freq) {
 		r.Each(func(name string, i interface{}) {
-			switch metric := i.(type) {
-			case *Counter:
-				l.Printf("counter %s\n", name)
-				l.Printf("  count:       %9d\n", metric.Snapshot().Count())
-			case *CounterFloat64:
-				l.Printf("counter %s\n", name)
-				l.Printf("  count:       %f\n", metric.Snapshot().Count())
-			case *Gauge:
-				l.Printf("gauge %s\n", name)
-				l.Printf("  value:       %9d\n", metric.Snapshot().Value())
-			case *GaugeFloat64:
-				l.Printf("gauge %s\n", name)
-				l.Printf("  value:       %f\n", metric.Snapshot().Value())
-			case *GaugeInfo:
-				l.Printf("gauge %s\n", name)
-				l.Printf("  value:       %s\n", metric.Snapshot().Value())
-			case Histogram:
-				h := metric.Snapshot()
-				ps := h.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
-				l.Printf("histogram %s\n", name)
-				l.Printf("  count:       %9d\n", h.Count())
-				l.Printf("  min:         %9d\n", h.Min())
-				l.Printf("  max:         %9d\n", h.Max())
-				l.Printf("  mean:        %12.2f\n", h.Mean())
-				l.Printf("  stddev:      %12.2f\n", h.StdDev())
-				l.Printf("  median:      %12.2f\n", ps[0])
-				l.Printf("  75%%:         %12.2f\n", ps[1])
-				l.Printf("  95%%:         %12.2f\n", ps[2])
-				l.Printf("  99%%:         %12.2f\n", ps[3])
-				l.Printf("  99.9%%:       %12.2f\n", ps[4])
-			case *Meter:
-				m := metric.Snapshot()
-				l.Printf("meter %s\n", name)
-				l.Printf("  count:       %9d\n", m.Count())
-				l.Printf("  1-min rate:  %12.2f\n", m.Rate1())
-				l.Printf("  5-min rate:  %12.2f\n", m.Rate5())
-				l.Printf("  15-min rate: %12.2f\n", m.Rate15())
-				l.Printf("  mean rate:   %12.2f\n", m.RateMean())
-			case *Timer:
-				t := metric.Snapshot()
-				ps := t.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
-				l.Printf("timer %s\n", name)
-				l.Printf("  count:       %9d\n", t.Count())
-				l.Printf("  min:         %12.2f%s\n", float64(t.Min())/du, duSuffix)
-				l.Printf("  max:         %12.2f%s\n", float64(t.Max())/du, duSuffix)
-				l.Printf("  mean:        %12.2f%s\n", t.Mean()/du, duSuffix)
-				l.Printf("  stddev:      %12.2f%s\n", t.StdDev()/du, duSuffix)
-				l.Printf("  median:      %12.2f%s\n", ps[0]/du, duSuffix)
-				l.Printf("  75%%:         %12.2f%s\n", ps[1]/du, duSuffix)
-				l.Printf("  95%%:         %12.2f%s\n", ps[2]/du, duSuffix)
-				l.Printf("  99%%:         %12.2f%s\n", ps[3]/du, duSuffix)
-				l.Printf("  99.9%%:       %12.2f%s\n", ps[4]/du, duSuffix)
-				l.Printf("  1-min rate:  %12.2f\n", t.Rate1())
-				l.Printf("  5-min rate:  %12.2f\n", t.Rate5())
-				l.Printf("  15-min rate: %12.2f\n", t.Rate15())
-				l.Printf("  mean rate:   %12.2f\n", t.RateMean())
+			labeled := EachLabeled(i, func(labels []string, values []string, metric interface{}) {
+				logMetric(l, labeledName(name, labels, values), metric, du, duSuffix)
+			})
+			if !labeled {
+				logMetric(l, name, i, du, duSuffix)
 			}
 		})
 	}
 }
+
+// logMetric outputs a single metric using the given logger, the timings in
+// du units suffixed with duSuffix.
+func logMetric(l Logger, name string, i interface{}, du float64, duSuffix string) {
+	switch metric := i.(type) {
+	case *Counter:
+		l.Printf("counter %s\n", name)
+		l.Printf("  count:       %9d\n", metric.Snapshot().Count())
+	case *CounterFloat64:
+		l.Printf("counter %s\n", name)
+		l.Printf("  count:       %f\n", metric.Snapshot().Count())
+	case *Gauge:
+		l.Printf("gauge %s\n", name)
+		l.Printf("  value:       %9d\n", metric.Snapshot().Value())
+	case *GaugeFloat64:
+		l.Printf("gauge %s\n", name)
+		l.Printf("  value:       %f\n", metric.Snapshot().Value())
+	case *GaugeInfo:
+		l.Printf("gauge %s\n", name)
+		l.Printf("  value:       %s\n", metric.Snapshot().Value())
+	case Histogram:
+		h := metric.Snapshot()
+		ps := h.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
+		l.Printf("histogram %s\n", name)
+		l.Printf("  count:       %9d\n", h.Count())
+		l.Printf("  min:         %9d\n", h.Min())
+		l.Printf("  max:         %9d\n", h.Max())
+		l.Printf("  mean:        %12.2f\n", h.Mean())
+		l.Printf("  stddev:      %12.2f\n", h.StdDev())
+		l.Printf("  median:      %12.2f\n", ps[0])
+		l.Printf("  75%%:         %12.2f\n", ps[1])
+		l.Printf("  95%%:         %12.2f\n", ps[2])
+		l.Printf("  99%%:         %12.2f\n", ps[3])
+		l.Printf("  99.9%%:       %12.2f\n", ps[4])
+	case *Meter:
+		m := metric.Snapshot()
+		l.Printf("meter %s\n", name)
+		l.Printf("  count:       %9d\n", m.Count())
+		l.Printf("  1-min rate:  %12.2f\n", m.Rate1())
+		l.Printf("  5-min rate:  %12.2f\n", m.Rate5())
+		l.Printf("  15-min rate: %12.2f\n", m.Rate15())
+		l.Printf("  mean rate:   %12.2f\n", m.RateMean())
+	case *Timer:
+		t := metric.Snapshot()
+		ps := t.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
+		l.Printf("timer %s\n", name)
+		l.Printf("  count:       %9d\n", t.Count())
+		l.Printf("  min:         %12.2f%s\n", float64(t.Min())/du, duSuffix)
+		l.Printf("  max:         %12.2f%s\n", float64(t.Max())/du, duSuffix)
+		l.Printf("  mean:        %12.2f%s\n", t.Mean()/du, duSuffix)
+		l.Printf("  stddev:      %12.2f%s\n", t.StdDev()/du, duSuffix)
+		l.Printf("  median:      %12.2f%s\n", ps[0]/du, duSuffix)
+		l.Printf("  75%%:         %12.2f%s\n", ps[1]/du, duSuffix)
+		l.Printf("  95%%:         %12.2f%s\n", ps[2]/du, duSuffix)
+		l.Printf("  99%%:         %12.2f%s\n", ps[3]/du, duSuffix)
+		l.Printf("  99.9%%:       %12.2f%s\n", ps[4]/du, duSuffix)
+		l.Printf("  1-min rate:  %12.2f\n", t.Rate1())
+		l.Printf("  5-min rate:  %12.2f\n", t.Rate5())
+		l.Printf("  15-min rate: %12.2f\n", t.Rate15())
+		l.Printf("  mean rate:   %12.2f\n", t.RateMean())
+	}
+}
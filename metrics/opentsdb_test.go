@@ -52,6 +52,26 @@ func TestExampleOpenTSB(t *testing.T) {
 	}
 }
 
+func TestOpenTSBVec(t *testing.T) {
+	r := NewOrderedRegistry()
+	c := NewRegisteredCounterVec("wins", r, "builder", "validator")
+	c.With("0xb1", "v 1").Inc(2)
+	c.With("0xb2", "").Inc(1)
+
+	w := new(strings.Builder)
+	(&OpenTSDBConfig{
+		Registry:     r,
+		DurationUnit: time.Millisecond,
+		Prefix:       "pre",
+	}).writeRegistry(w, 978307200, "hal9000")
+
+	want := "put pre.wins.count 978307200 2 host=hal9000 builder=0xb1 validator=v_1\n" +
+		"put pre.wins.count 978307200 1 host=hal9000 builder=0xb2 validator=_\n"
+	if have := w.String(); have != want {
+		t.Errorf("\nhave:\n%v\nwant:\n%v\n", have, want)
+	}
+}
+
 func findFirstDiffPos(a, b string) string {
 	yy := strings.Split(b, "\n")
 	for i, x := range strings.Split(a, "\n") {
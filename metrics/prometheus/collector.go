@@ -19,6 +19,7 @@ package prometheus
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -33,6 +34,7 @@ var (
 	keyValueTpl            = "%s %v\n\n"
 	keyQuantileTagValueTpl = "%s {quantile=\"%s\"} %v\n"
 	keyLabelValueTpl       = "%s%s %v\n\n"
+	keyLabelSetValueTpl    = "%s%s %v\n"
 )
 
 // collector is a collection of byte buffers that aggregate Prometheus reports
@@ -72,6 +74,12 @@ func (c *collector) Add(name string, i any) error {
 		c.addResettingTimer(name, m.Snapshot())
 	case *metrics.Label:
 		c.addLabel(name, m.Snapshot())
+	case *metrics.CounterVec:
+		c.addCounterVec(name, m)
+	case *metrics.GaugeVec:
+		c.addGaugeVec(name, m)
+	case *metrics.HistogramVec:
+		c.addHistogramVec(name, m)
 	default:
 		return fmt.Errorf("unknown prometheus metric type %T", i)
 	}
@@ -147,6 +155,61 @@ func (c *collector) addLabel(name string, m *metrics.LabelSnapshot) {
 	c.writeLabel(mutateKey(name), "{"+strings.Join(labels, ", ")+"}")
 }
 
+func (c *collector) addCounterVec(name string, m *metrics.CounterVec) {
+	name = mutateKey(name)
+	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
+	m.Each(func(values []string, counter *metrics.Counter) {
+		c.buff.WriteString(fmt.Sprintf(keyLabelSetValueTpl, name, labelSet(m.Labels(), values), counter.Snapshot().Count()))
+	})
+	c.buff.WriteRune('\n')
+}
+
+func (c *collector) addGaugeVec(name string, m *metrics.GaugeVec) {
+	name = mutateKey(name)
+	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
+	m.Each(func(values []string, gauge *metrics.Gauge) {
+		c.buff.WriteString(fmt.Sprintf(keyLabelSetValueTpl, name, labelSet(m.Labels(), values), gauge.Snapshot().Value()))
+	})
+	c.buff.WriteRune('\n')
+}
+
+func (c *collector) addHistogramVec(name string, m *metrics.HistogramVec) {
+	var (
+		pv     = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+		labels = m.Labels()
+		count  = mutateKey(name + "_count")
+	)
+	name = mutateKey(name)
+	c.buff.WriteString(fmt.Sprintf(typeCounterTpl, count))
+	m.Each(func(values []string, h metrics.Histogram) {
+		c.buff.WriteString(fmt.Sprintf(keyLabelSetValueTpl, count, labelSet(labels, values), h.Snapshot().Count()))
+	})
+	c.buff.WriteString(fmt.Sprintf("\n"+typeSummaryTpl, name))
+	m.Each(func(values []string, h metrics.Histogram) {
+		ps := h.Snapshot().Percentiles(pv)
+		for i := range pv {
+			qlabels := append(slices.Clone(labels), "quantile")
+			qvalues := append(slices.Clone(values), strconv.FormatFloat(pv[i], 'f', -1, 64))
+			c.buff.WriteString(fmt.Sprintf(keyLabelSetValueTpl, name, labelSet(qlabels, qvalues), ps[i]))
+		}
+	})
+	c.buff.WriteRune('\n')
+}
+
+// labelValueEscaper escapes the label values as the Prometheus exposition format
+// requires, only the backslash, the double quote and the line feed.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelSet formats the labels with their values, in the Prometheus exposition
+// format.
+func labelSet(labels []string, values []string) string {
+	kvs := make([]string, len(labels))
+	for i := range labels {
+		kvs[i] = mutateKey(labels[i]) + `="` + labelValueEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(kvs, ",") + "}"
+}
+
 func (c *collector) writeLabel(name string, value interface{}) {
 	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
 	c.buff.WriteString(fmt.Sprintf(keyLabelValueTpl, name, value, 1))
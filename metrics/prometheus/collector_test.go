@@ -63,3 +63,52 @@ func findFirstDiffPos(a, b string) string {
 	}
 	return ""
 }
+
+func TestCollectorVec(t *testing.T) {
+	var (
+		c        = newCollector()
+		registry = metrics.NewOrderedRegistry()
+	)
+	counters := metrics.NewRegisteredCounterVec("test/counter_vec", registry, "builder")
+	counters.With("0xb").Inc(2)
+	counters.With("0xa").Inc(1)
+
+	gauges := metrics.NewRegisteredGaugeVec("test/gauge_vec", registry, "validator")
+	gauges.With(`quote"d`).Update(7)
+	gauges.With("tab\tback\\slash\nline").Update(8)
+
+	histograms := metrics.NewRegisteredHistogramVec("test/histogram_vec", registry, func() metrics.Sample { return metrics.NewUniformSample(3) }, "builder")
+	histograms.With("0xa").Update(1)
+	histograms.With("0xa").Update(3)
+
+	registry.Each(func(name string, i interface{}) {
+		if err := c.Add(name, i); err != nil {
+			t.Fatal(err)
+		}
+	})
+	want := `# TYPE test_counter_vec gauge
+test_counter_vec{builder="0xa"} 1
+test_counter_vec{builder="0xb"} 2
+
+# TYPE test_gauge_vec gauge
+test_gauge_vec{validator="quote\"d"} 7
+test_gauge_vec{validator="tab	back\\slash\nline"} 8
+
+# TYPE test_histogram_vec_count counter
+test_histogram_vec_count{builder="0xa"} 2
+
+# TYPE test_histogram_vec summary
+test_histogram_vec{builder="0xa",quantile="0.5"} 2
+test_histogram_vec{builder="0xa",quantile="0.75"} 3
+test_histogram_vec{builder="0xa",quantile="0.95"} 3
+test_histogram_vec{builder="0xa",quantile="0.99"} 3
+test_histogram_vec{builder="0xa",quantile="0.999"} 3
+test_histogram_vec{builder="0xa",quantile="0.9999"} 3
+
+`
+	if have := c.buff.String(); have != want {
+		t.Logf("have\n%v", have)
+		t.Logf("have vs want:\n%v", findFirstDiffPos(have, want))
+		t.Fatalf("unexpected collector output")
+	}
+}
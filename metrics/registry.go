@@ -197,6 +197,18 @@ func (r *StandardRegistry) GetAll() map[string]map[string]interface{} {
 			values["5m.rate"] = t.Rate5()
 			values["15m.rate"] = t.Rate15()
 			values["mean.rate"] = t.RateMean()
+		case *CounterVec:
+			metric.Each(func(labels []string, c *Counter) {
+				values[strings.Join(labels, ",")] = c.Snapshot().Count()
+			})
+		case *GaugeVec:
+			metric.Each(func(labels []string, g *Gauge) {
+				values[strings.Join(labels, ",")] = g.Snapshot().Value()
+			})
+		case *HistogramVec:
+			metric.Each(func(labels []string, h Histogram) {
+				values[strings.Join(labels, ",")] = h.Snapshot().Count()
+			})
 		}
 		data[name] = values
 	})
@@ -211,7 +223,7 @@ func (r *StandardRegistry) Unregister(name string) {
 
 func (r *StandardRegistry) loadOrRegister(name string, i interface{}) (interface{}, bool, bool) {
 	switch i.(type) {
-	case *Counter, *CounterFloat64, *Gauge, *GaugeFloat64, *GaugeInfo, *Healthcheck, Histogram, *Meter, *Timer, *ResettingTimer, *Label:
+	case *Counter, *CounterFloat64, *Gauge, *GaugeFloat64, *GaugeInfo, *Healthcheck, Histogram, *Meter, *Timer, *ResettingTimer, *Label, *CounterVec, *GaugeVec, *HistogramVec:
 	default:
 		return nil, false, false
 	}
@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// labelSeparator joins the label values of a metric into its key in a vector,
+// it can't appear in a valid UTF-8 label value.
+const labelSeparator = "\xff"
+
+// vec is a set of metrics of the same kind, partitioned by the values of a fixed
+// set of labels. The metrics are created on first use.
+type vec[T any] struct {
+	labels  []string
+	create  func() T
+	metrics map[string]*labeledMetric[T]
+	lock    sync.RWMutex
+}
+
+// labeledMetric is a metric of a vector, with the label values it was created
+// for.
+type labeledMetric[T any] struct {
+	values []string
+	metric T
+}
+
+func newVec[T any](create func() T, labels []string) *vec[T] {
+	return &vec[T]{
+		labels:  slices.Clone(labels),
+		create:  create,
+		metrics: make(map[string]*labeledMetric[T]),
+	}
+}
+
+// Labels returns the names of the labels partitioning the metrics.
+func (v *vec[T]) Labels() []string {
+	return v.labels
+}
+
+// With returns the metric of the given label values, in the order of the labels,
+// creating it if needed. It panics if the number of values doesn't match the
+// number of labels.
+func (v *vec[T]) With(values ...string) T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %d label values given for %d labels", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, labelSeparator)
+
+	v.lock.RLock()
+	m, ok := v.metrics[key]
+	v.lock.RUnlock()
+	if ok {
+		return m.metric
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if m, ok := v.metrics[key]; ok {
+		return m.metric
+	}
+	m = &labeledMetric[T]{values: slices.Clone(values), metric: v.create()}
+	v.metrics[key] = m
+	return m.metric
+}
+
+// Delete drops the metric of the given label values, it reports whether the
+// metric existed.
+func (v *vec[T]) Delete(values ...string) bool {
+	key := strings.Join(values, labelSeparator)
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	_, ok := v.metrics[key]
+	delete(v.metrics, key)
+	return ok
+}
+
+// Each calls fn for each metric of the vector with its label values, sorted by
+// the label values.
+func (v *vec[T]) Each(fn func(values []string, metric T)) {
+	v.lock.RLock()
+	keys := make([]string, 0, len(v.metrics))
+	for key := range v.metrics {
+		keys = append(keys, key)
+	}
+	metrics := make([]*labeledMetric[T], 0, len(keys))
+	sort.Strings(keys)
+	for _, key := range keys {
+		metrics = append(metrics, v.metrics[key])
+	}
+	v.lock.RUnlock()
+
+	for _, m := range metrics {
+		fn(m.values, m.metric)
+	}
+}
+
+// EachLabeled calls fn for each metric of the given vector, along with the
+// names and values of its labels, and reports whether i is a vector at all. It
+// lets the reporters without native labels export a series per label set.
+func EachLabeled(i interface{}, fn func(labels []string, values []string, metric interface{})) bool {
+	switch v := i.(type) {
+	case *CounterVec:
+		v.Each(func(values []string, c *Counter) { fn(v.labels, values, c) })
+	case *GaugeVec:
+		v.Each(func(values []string, g *Gauge) { fn(v.labels, values, g) })
+	case *HistogramVec:
+		v.Each(func(values []string, h Histogram) { fn(v.labels, values, h) })
+	default:
+		return false
+	}
+	return true
+}
+
+// labeledName returns the name of the series of a vector with the given label
+// values, in the form name{label="value",...}.
+func labeledName(name string, labels []string, values []string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", label, values[i])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// CounterVec is a set of counters partitioned by the values of a fixed set of
+// labels.
+type CounterVec struct {
+	*vec[*Counter]
+}
+
+// NewCounterVec constructs a new CounterVec partitioned by the given labels.
+func NewCounterVec(labels ...string) *CounterVec {
+	return &CounterVec{newVec(NewCounter, labels)}
+}
+
+// NewRegisteredCounterVec constructs and registers a new CounterVec.
+func NewRegisteredCounterVec(name string, r Registry, labels ...string) *CounterVec {
+	c := NewCounterVec(labels...)
+	if r == nil {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// GaugeVec is a set of gauges partitioned by the values of a fixed set of
+// labels.
+type GaugeVec struct {
+	*vec[*Gauge]
+}
+
+// NewGaugeVec constructs a new GaugeVec partitioned by the given labels.
+func NewGaugeVec(labels ...string) *GaugeVec {
+	return &GaugeVec{newVec(NewGauge, labels)}
+}
+
+// NewRegisteredGaugeVec constructs and registers a new GaugeVec.
+func NewRegisteredGaugeVec(name string, r Registry, labels ...string) *GaugeVec {
+	c := NewGaugeVec(labels...)
+	if r == nil {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// HistogramVec is a set of histograms partitioned by the values of a fixed set
+// of labels, each histogram using its own sample.
+type HistogramVec struct {
+	*vec[Histogram]
+}
+
+// NewHistogramVec constructs a new HistogramVec partitioned by the given labels,
+// the samples of its histograms are created by s.
+func NewHistogramVec(s func() Sample, labels ...string) *HistogramVec {
+	return &HistogramVec{newVec(func() Histogram { return NewHistogram(s()) }, labels)}
+}
+
+// NewRegisteredHistogramVec constructs and registers a new HistogramVec.
+func NewRegisteredHistogramVec(name string, r Registry, s func() Sample, labels ...string) *HistogramVec {
+	c := NewHistogramVec(s, labels...)
+	if r == nil {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := NewRegisteredCounterVec("foo", r, "builder", "validator")
+	c.With("b", "v1").Inc(2)
+	c.With("a", "v2").Inc(1)
+	c.With("b", "v1").Inc(3)
+
+	if have := r.Get("foo"); have != c {
+		t.Fatalf("vector not registered: %v", have)
+	}
+	var (
+		keys   [][]string
+		counts []int64
+	)
+	c.Each(func(values []string, counter *Counter) {
+		keys = append(keys, values)
+		counts = append(counts, counter.Snapshot().Count())
+	})
+	if len(keys) != 2 || !slices.Equal(keys[0], []string{"a", "v2"}) || !slices.Equal(keys[1], []string{"b", "v1"}) {
+		t.Fatalf("label values mismatch: %v", keys)
+	}
+	if !slices.Equal(counts, []int64{1, 5}) {
+		t.Fatalf("counts mismatch: %v", counts)
+	}
+	if !c.Delete("a", "v2") || c.Delete("a", "v2") {
+		t.Fatal("delete mismatch")
+	}
+	if count := c.With("a", "v2").Snapshot().Count(); count != 0 {
+		t.Fatalf("deleted counter not reset: %d", count)
+	}
+}
+
+func TestVecLabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	NewGaugeVec("builder").With("a", "b")
+}
+
+func TestHistogramVec(t *testing.T) {
+	h := NewHistogramVec(func() Sample { return NewUniformSample(100) }, "builder")
+	h.With("a").Update(1)
+	h.With("a").Update(3)
+	h.With("b").Update(5)
+
+	if snap := h.With("a").Snapshot(); snap.Count() != 2 || snap.Max() != 3 {
+		t.Fatalf("histogram mismatch: count %d, max %d", snap.Count(), snap.Max())
+	}
+	if snap := h.With("b").Snapshot(); snap.Count() != 1 {
+		t.Fatalf("histogram mismatch: count %d", snap.Count())
+	}
+}
@@ -21,7 +21,12 @@ func Write(r Registry, d time.Duration, w io.Writer) {
 func WriteOnce(r Registry, w io.Writer) {
 	var namedMetrics []namedMetric
 	r.Each(func(name string, i interface{}) {
-		namedMetrics = append(namedMetrics, namedMetric{name, i})
+		labeled := EachLabeled(i, func(labels []string, values []string, metric interface{}) {
+			namedMetrics = append(namedMetrics, namedMetric{labeledName(name, labels, values), metric})
+		})
+		if !labeled {
+			namedMetrics = append(namedMetrics, namedMetric{name, i})
+		}
 	})
 	slices.SortFunc(namedMetrics, namedMetric.cmp)
 	for _, namedMetric := range namedMetrics {
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -20,3 +21,16 @@ func TestMetricsSorting(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteOnceVec(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounterVec("foo", r, "builder").With("b").Inc(2)
+	NewRegisteredGaugeVec("bar", r, "builder").With("a").Update(3)
+
+	w := new(strings.Builder)
+	WriteOnce(r, w)
+	want := "gauge bar{builder=\"a\"}\n  value:               3\ncounter foo{builder=\"b\"}\n  count:               2\n"
+	if have := w.String(); have != want {
+		t.Fatalf("output mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
@@ -0,0 +1,344 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/naoina/toml"
+
+	"github.com/Ezkerrox/bsc/accounts/abi"
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/consensus/parlia"
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/core/vm"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/miner/builderclient"
+	"github.com/Ezkerrox/bsc/miner/minerconfig"
+	"github.com/Ezkerrox/bsc/rpc"
+)
+
+const (
+	// buildersFileCheckInterval is the interval the builders file is checked for
+	// changes at.
+	buildersFileCheckInterval = 3 * time.Second
+
+	// defaultRegistryEpoch is the number of blocks between two reads of the builder
+	// registry if the engine doesn't tell the epoch length.
+	defaultRegistryEpoch = 200
+)
+
+// builderRegistryABI is the ABI of the contract listing the builders, which must
+// return the addresses of the builders and their URLs at the same indexes.
+const builderRegistryABI = `[{"inputs":[],"name":"getBuilders","outputs":[{"internalType":"address[]","name":"builders","type":"address[]"},{"internalType":"string[]","name":"urls","type":"string[]"}],"stateMutability":"view","type":"function"}]`
+
+var registryABI, _ = abi.JSON(strings.NewReader(builderRegistryABI))
+
+// buildersFile is the content of the builders file, the same list of builders
+// as in the [Eth.Miner.Mev] section of the config file:
+//
+//	[[Builders]]
+//	Address = "0x..."
+//	URL = "https://..."
+type buildersFile struct {
+	Builders []minerconfig.BuilderConfig
+}
+
+// initBuilderSources loads the builders configured locally. If the builders file
+// exists, it wins over the builders of the config, so that the changes made
+// through the RPC, removals included, survive a restart, a warning is logged if
+// they differ. Otherwise the builders file is seeded from the config.
+func (b *bidSimulator) initBuilderSources() {
+	b.localBuilders = make(map[common.Address]string)
+	b.registryBuilders = make(map[common.Address]string)
+	b.appliedBuilders = make(map[common.Address]string)
+
+	for _, v := range b.config.Builders {
+		b.localBuilders[v.Address] = v.URL
+	}
+	if b.config.BuildersFile == "" {
+		return
+	}
+	builders, modTime, err := loadBuildersFile(b.config.BuildersFile)
+	switch {
+	case err == nil:
+		if len(b.config.Builders) > 0 && !maps.Equal(b.localBuilders, builders) {
+			log.Warn("BidSimulator: builders of the config differ from the builders file, the file wins",
+				"file", b.config.BuildersFile, "config", len(b.localBuilders), "builders", len(builders))
+		}
+		b.localBuilders, b.buildersFileTime = builders, modTime
+		log.Info("BidSimulator: loaded builders file", "file", b.config.BuildersFile, "builders", len(builders))
+	case errors.Is(err, os.ErrNotExist):
+		b.saveBuildersFile()
+	default:
+		log.Error("BidSimulator: failed to load builders file", "file", b.config.BuildersFile, "err", err)
+	}
+}
+
+// loadBuildersFile reads the builders from the file, with its modification time.
+func loadBuildersFile(path string) (map[common.Address]string, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var file buildersFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return nil, time.Time{}, err
+	}
+	builders := make(map[common.Address]string, len(file.Builders))
+	for _, v := range file.Builders {
+		builders[v.Address] = v.URL
+	}
+	return builders, info.ModTime(), nil
+}
+
+// saveBuildersFile persists the builders configured locally to the builders file
+// if any, so that the changes made through the RPC survive a restart. The caller
+// must hold sourcesMu.
+func (b *bidSimulator) saveBuildersFile() {
+	path := b.config.BuildersFile
+	if path == "" {
+		return
+	}
+	var file buildersFile
+	for address, url := range b.localBuilders {
+		file.Builders = append(file.Builders, minerconfig.BuilderConfig{Address: address, URL: url})
+	}
+	// Keep the file stable across the writes
+	sort.Slice(file.Builders, func(i, j int) bool {
+		return file.Builders[i].Address.Cmp(file.Builders[j].Address) < 0
+	})
+
+	data, err := toml.Marshal(file)
+	if err != nil {
+		log.Error("BidSimulator: failed to encode builders file", "err", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		log.Error("BidSimulator: failed to write builders file", "file", path, "err", err)
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		log.Error("BidSimulator: failed to write builders file", "file", path, "err", err)
+		return
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		log.Error("BidSimulator: failed to write builders file", "file", path, "err", err)
+		return
+	}
+	// Remember the modification time to not reload our own changes
+	if info, err := os.Stat(path); err == nil {
+		b.buildersFileTime = info.ModTime()
+	}
+}
+
+// buildersFileLoop reloads the builders file whenever it's modified, and
+// reconciles the builders with its content.
+func (b *bidSimulator) buildersFileLoop() {
+	ticker := time.NewTicker(buildersFileCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.reloadBuildersFile()
+		case <-b.exitCh:
+			return
+		}
+	}
+}
+
+func (b *bidSimulator) reloadBuildersFile() {
+	info, err := os.Stat(b.config.BuildersFile)
+	if err != nil {
+		return
+	}
+	b.sourcesMu.Lock()
+	defer b.sourcesMu.Unlock()
+
+	if info.ModTime().Equal(b.buildersFileTime) {
+		return
+	}
+	builders, modTime, err := loadBuildersFile(b.config.BuildersFile)
+	if err != nil {
+		log.Error("BidSimulator: failed to reload builders file", "file", b.config.BuildersFile, "err", err)
+		return
+	}
+	log.Info("BidSimulator: builders file changed", "file", b.config.BuildersFile, "builders", len(builders))
+	b.localBuilders, b.buildersFileTime = builders, modTime
+	b.reconcileBuilders(false)
+}
+
+// syncRegistryBuilders reads the builders listed by the registry contract at the
+// first head seen and at each epoch boundary, and reconciles the builders.
+func (b *bidSimulator) syncRegistryBuilders(header *types.Header) {
+	number := header.Number.Uint64()
+	if b.registrySynced {
+		epoch := uint64(defaultRegistryEpoch)
+		if p, ok := b.engine.(*parlia.Parlia); ok {
+			if length, err := p.EpochLength(b.chain, header); err == nil {
+				epoch = length
+			}
+		}
+		if number%epoch != 0 {
+			return
+		}
+	}
+	builders, err := b.readRegistryBuilders(header)
+	if err != nil {
+		log.Error("BidSimulator: failed to read builder registry", "registry", b.config.BuilderRegistry, "number", number, "err", err)
+		return
+	}
+	b.registrySynced = true
+
+	b.sourcesMu.Lock()
+	defer b.sourcesMu.Unlock()
+
+	b.registryBuilders = builders
+	b.reconcileBuilders(false)
+}
+
+// readRegistryBuilders calls the registry contract on the state of the given block.
+func (b *bidSimulator) readRegistryBuilders(header *types.Header) (map[common.Address]string, error) {
+	statedb, err := b.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	data, err := registryABI.Pack("getBuilders")
+	if err != nil {
+		return nil, err
+	}
+	var (
+		blockCtx = core.NewEVMBlockContext(header, b.chain, nil)
+		evm      = vm.NewEVM(blockCtx, statedb, b.chainConfig, vm.Config{})
+	)
+	ret, _, err := evm.Call(vm.AccountRef(common.Address{}), b.config.BuilderRegistry, data, header.GasLimit, new(uint256.Int))
+	if err != nil {
+		return nil, err
+	}
+	out, err := registryABI.Unpack("getBuilders", ret)
+	if err != nil {
+		return nil, err
+	}
+	addresses, ok := out[0].([]common.Address)
+	if !ok {
+		return nil, errors.New("invalid builder addresses")
+	}
+	urls, ok := out[1].([]string)
+	if !ok || len(urls) != len(addresses) {
+		return nil, errors.New("invalid builder urls")
+	}
+	builders := make(map[common.Address]string, len(addresses))
+	for i, address := range addresses {
+		builders[address] = urls[i]
+	}
+	return builders, nil
+}
+
+// reconcileBuilders dials the builders configured locally or listed by the
+// registry, the local URL winning, and drops the others. The builders already
+// dialed with the same URL are kept unless redial is set. The caller must hold
+// sourcesMu.
+func (b *bidSimulator) reconcileBuilders(redial bool) {
+	desired := make(map[common.Address]string, len(b.localBuilders)+len(b.registryBuilders))
+	for address, url := range b.registryBuilders {
+		desired[address] = url
+	}
+	for address, url := range b.localBuilders {
+		desired[address] = url
+	}
+	for address := range b.appliedBuilders {
+		if _, ok := desired[address]; !ok {
+			b.removeBuilder(address)
+			delete(b.appliedBuilders, address)
+			log.Info("BidSimulator: builder removed", "builder", address)
+		}
+	}
+	for address, url := range desired {
+		if prev, ok := b.appliedBuilders[address]; ok && prev == url && !redial {
+			continue
+		}
+		if err := b.addBuilder(address, url); err != nil {
+			continue
+		}
+		b.appliedBuilders[address] = url
+		log.Info("BidSimulator: builder added", "builder", address, "url", url)
+	}
+}
+
+// AddBuilder adds the builder and persists it to the builders file if any.
+func (b *bidSimulator) AddBuilder(builder common.Address, url string) error {
+	b.sourcesMu.Lock()
+	defer b.sourcesMu.Unlock()
+
+	if err := b.addBuilder(builder, url); err != nil {
+		return err
+	}
+	b.appliedBuilders[builder] = url
+	b.localBuilders[builder] = url
+	b.saveBuildersFile()
+	return nil
+}
+
+// RemoveBuilder removes the builder and persists it to the builders file if any.
+// A builder listed by the registry is added back at the next epoch.
+func (b *bidSimulator) RemoveBuilder(builder common.Address) error {
+	b.sourcesMu.Lock()
+	defer b.sourcesMu.Unlock()
+
+	b.removeBuilder(builder)
+	delete(b.appliedBuilders, builder)
+	if _, ok := b.localBuilders[builder]; ok {
+		delete(b.localBuilders, builder)
+		b.saveBuildersFile()
+	}
+	return nil
+}
+
+func (b *bidSimulator) addBuilder(builder common.Address, url string) error {
+	b.buildersMu.Lock()
+	defer b.buildersMu.Unlock()
+
+	if b.sentryCli != nil {
+		b.builders[builder] = b.sentryCli
+	} else {
+		var builderCli *builderclient.Client
+
+		if url != "" {
+			var err error
+
+			builderCli, err = builderclient.DialOptions(context.Background(), url, rpc.WithHTTPClient(client))
+			if err != nil {
+				log.Error("BidSimulator: failed to dial builder", "url", url, "err", err)
+				return err
+			}
+		}
+
+		b.builders[builder] = builderCli
+	}
+
+	return nil
+}
+
+func (b *bidSimulator) removeBuilder(builder common.Address) {
+	b.buildersMu.Lock()
+	defer b.buildersMu.Unlock()
+
+	delete(b.builders, builder)
+}
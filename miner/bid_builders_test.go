@@ -0,0 +1,128 @@
+package miner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/miner/builderclient"
+	"github.com/Ezkerrox/bsc/miner/minerconfig"
+)
+
+func newTestBuildersSimulator(config *minerconfig.MevConfig) *bidSimulator {
+	b := &bidSimulator{
+		config:   config,
+		exitCh:   make(chan struct{}),
+		builders: make(map[common.Address]*builderclient.Client),
+	}
+	b.initBuilderSources()
+	b.dialSentryAndBuilders()
+	return b
+}
+
+func checkBuilders(t *testing.T, b *bidSimulator, want ...common.Address) {
+	t.Helper()
+
+	b.buildersMu.RLock()
+	defer b.buildersMu.RUnlock()
+
+	if len(b.builders) != len(want) {
+		t.Fatalf("builders mismatch: have %d, want %d", len(b.builders), len(want))
+	}
+	for _, builder := range want {
+		if _, ok := b.builders[builder]; !ok {
+			t.Fatalf("builder %s missing", builder)
+		}
+	}
+}
+
+func TestBuildersFile(t *testing.T) {
+	var (
+		file   = filepath.Join(t.TempDir(), "builders.toml")
+		first  = common.Address{0x1}
+		second = common.Address{0x2}
+		third  = common.Address{0x3}
+		config = &minerconfig.MevConfig{
+			BuildersFile: file,
+			Builders:     []minerconfig.BuilderConfig{{Address: first, URL: "http://localhost:8545"}},
+		}
+	)
+	// The builders file is created from the config
+	b := newTestBuildersSimulator(config)
+	checkBuilders(t, b, first)
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("builders file not created: %v", err)
+	}
+
+	// The changes made through the RPC are persisted
+	if err := b.AddBuilder(second, "http://localhost:8546"); err != nil {
+		t.Fatalf("failed to add builder: %v", err)
+	}
+	if err := b.RemoveBuilder(first); err != nil {
+		t.Fatalf("failed to remove builder: %v", err)
+	}
+	checkBuilders(t, b, second)
+
+	// Our own writes are not reloaded, while the external changes are
+	b.reloadBuildersFile()
+	checkBuilders(t, b, second)
+
+	content := "[[Builders]]\nAddress = \"" + third.Hex() + "\"\nURL = \"http://localhost:8547\"\n"
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write builders file: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, future, future); err != nil {
+		t.Fatalf("failed to touch builders file: %v", err)
+	}
+	b.reloadBuildersFile()
+	checkBuilders(t, b, third)
+
+	// The builders file wins over the config after a restart, the builders
+	// removed through the RPC staying removed
+	config.Builders = append(config.Builders, minerconfig.BuilderConfig{Address: second, URL: "http://localhost:9546"})
+	b = newTestBuildersSimulator(config)
+	checkBuilders(t, b, third)
+
+	if err := b.RemoveBuilder(third); err != nil {
+		t.Fatalf("failed to remove builder: %v", err)
+	}
+	b = newTestBuildersSimulator(config)
+	checkBuilders(t, b)
+}
+
+func TestRegistryBuilders(t *testing.T) {
+	var (
+		local    = common.Address{0x1}
+		listed   = common.Address{0x2}
+		unlisted = common.Address{0x3}
+		config   = &minerconfig.MevConfig{
+			Builders: []minerconfig.BuilderConfig{{Address: local, URL: "http://localhost:8545"}},
+		}
+	)
+	b := newTestBuildersSimulator(config)
+
+	b.sourcesMu.Lock()
+	b.registryBuilders = map[common.Address]string{
+		local:    "http://localhost:9545",
+		listed:   "http://localhost:9546",
+		unlisted: "http://localhost:9547",
+	}
+	b.reconcileBuilders(false)
+	b.sourcesMu.Unlock()
+	checkBuilders(t, b, local, listed, unlisted)
+
+	// The local url wins over the registry one
+	if url := b.appliedBuilders[local]; url != "http://localhost:8545" {
+		t.Fatalf("builder url mismatch: have %s, want %s", url, "http://localhost:8545")
+	}
+
+	// The builders dropped from the registry are removed at the next epoch
+	b.sourcesMu.Lock()
+	b.registryBuilders = map[common.Address]string{listed: "http://localhost:9546"}
+	b.reconcileBuilders(false)
+	b.sourcesMu.Unlock()
+	checkBuilders(t, b, local, listed)
+}
@@ -0,0 +1,274 @@
+package miner
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/miner/minerconfig"
+	"github.com/Ezkerrox/bsc/rlp"
+)
+
+const (
+	// maxBidRecords is the maximum number of bid records kept in memory, which
+	// the builder reputations are computed over.
+	maxBidRecords = 4096
+
+	// bidRecordsRetention is the number of blocks the bid records are persisted for.
+	bidRecordsRetention = 10000
+)
+
+// builderPenalty tracks the simulation failures of a builder.
+type builderPenalty struct {
+	consecutiveFailures uint32
+	until               time.Time // The penalty lasts until, zero if the builder is not penalised
+}
+
+// bidHistory keeps the records of the recently simulated bids, and penalises
+// the builders whose bids repeatedly fail the simulation if configured.
+type bidHistory struct {
+	db     ethdb.KeyValueStore
+	config *minerconfig.MevConfig
+
+	mu        sync.RWMutex
+	records   []*types.BidRecord // Ordered by arrival, capped at maxBidRecords
+	index     map[common.Hash]*types.BidRecord
+	dirty     map[common.Hash]*types.BidRecord // Records not persisted yet, written in batches by flush
+	penalties map[common.Address]*builderPenalty
+
+	pruned uint64 // Height below which the persisted records are deleted, only accessed by prune
+}
+
+// newBidHistory creates the bid history, loading the persisted records of the
+// latest blocks, up to the given height, if the database is given.
+func newBidHistory(db ethdb.KeyValueStore, config *minerconfig.MevConfig, head uint64) *bidHistory {
+	h := &bidHistory{
+		db:        db,
+		config:    config,
+		index:     make(map[common.Hash]*types.BidRecord),
+		dirty:     make(map[common.Hash]*types.BidRecord),
+		penalties: make(map[common.Address]*builderPenalty),
+	}
+	if db == nil {
+		return h
+	}
+	// Read the latest records of the blocks within the retention limit in a
+	// single pass over the database.
+	var from uint64
+	if head > bidRecordsRetention {
+		from = head - bidRecordsRetention
+	}
+	for _, blob := range rawdb.ReadBidRecordsRLP(db, from, head, maxBidRecords) {
+		record := new(types.BidRecord)
+		if err := rlp.DecodeBytes(blob, record); err != nil {
+			log.Warn("Failed to decode bid record", "err", err)
+			continue
+		}
+		h.records = append(h.records, record)
+		h.index[record.BidHash] = record
+	}
+	return h
+}
+
+// add records the simulation result of a bid. A bid simulated several times
+// keeps a single record with the latest result. The record is persisted by the
+// next flush.
+func (h *bidHistory) add(record *types.BidRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if prev, ok := h.index[record.BidHash]; ok {
+		if record.ReceivedAt == 0 {
+			record.ReceivedAt, record.Latency = prev.ReceivedAt, prev.Latency
+		}
+		*prev = *record
+		record = prev
+	} else {
+		h.records = append(h.records, record)
+		h.index[record.BidHash] = record
+		if len(h.records) > maxBidRecords {
+			delete(h.index, h.records[0].BidHash)
+			h.records[0] = nil
+			h.records = h.records[1:]
+		}
+	}
+	if h.db != nil {
+		cpy := *record
+		h.dirty[record.BidHash] = &cpy
+	}
+	h.updatePenalty(record)
+}
+
+// flush persists the records added since the last flush in a single batch.
+func (h *bidHistory) flush() {
+	if h.db == nil {
+		return
+	}
+	h.mu.Lock()
+	dirty := h.dirty
+	h.dirty = make(map[common.Hash]*types.BidRecord)
+	h.mu.Unlock()
+
+	if len(dirty) == 0 {
+		return
+	}
+	batch := h.db.NewBatch()
+	for _, record := range dirty {
+		blob, err := rlp.EncodeToBytes(record)
+		if err != nil {
+			log.Error("Failed to encode bid record", "err", err)
+			continue
+		}
+		rawdb.WriteBidRecordRLP(batch, record.BlockNumber, record.BidHash, blob)
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to write bid records", "err", err)
+	}
+}
+
+// updatePenalty penalises the builder once its bids failed the simulation too
+// many times in a row.
+func (h *bidHistory) updatePenalty(record *types.BidRecord) {
+	if record.Status == types.BidStatusAborted {
+		return
+	}
+	penalty, ok := h.penalties[record.Builder]
+	if !ok {
+		penalty = new(builderPenalty)
+		h.penalties[record.Builder] = penalty
+	}
+	if record.Status != types.BidStatusInvalid {
+		penalty.consecutiveFailures = 0
+		return
+	}
+	penalty.consecutiveFailures++
+
+	threshold := h.config.BuilderFailureThreshold
+	if threshold == nil || *threshold == 0 || penalty.consecutiveFailures < *threshold {
+		return
+	}
+	penalty.consecutiveFailures = 0
+	penalty.until = time.Now().Add(*h.config.BuilderPenaltyTime)
+	log.Warn("BidSimulator: builder penalised for failed simulations", "builder", record.Builder,
+		"penalty", h.config.BuilderPenalty, "until", penalty.until)
+}
+
+// penalised returns whether the builder is under penalty.
+func (h *bidHistory) penalised(builder common.Address) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	penalty, ok := h.penalties[builder]
+	return ok && time.Now().Before(penalty.until)
+}
+
+// deprioritised returns whether the bids of the builder should only win over the
+// ones of other deprioritised builders.
+func (h *bidHistory) deprioritised(builder common.Address) bool {
+	return h.config.BuilderPenalty != minerconfig.BuilderPenaltyBan && h.penalised(builder)
+}
+
+// checkBanned returns an error if the bids of the builder must be rejected.
+func (h *bidHistory) checkBanned(builder common.Address) error {
+	if h.config.BuilderPenalty != minerconfig.BuilderPenaltyBan {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if penalty, ok := h.penalties[builder]; ok && time.Now().Before(penalty.until) {
+		return fmt.Errorf("builder is banned until %s for failed simulations", penalty.until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// prune drops the persisted records which are too old for the given head.
+func (h *bidHistory) prune(head uint64) {
+	if h.db == nil || head <= bidRecordsRetention {
+		return
+	}
+	if limit := head - bidRecordsRetention; limit > h.pruned {
+		rawdb.DeleteBidRecords(h.db, h.pruned, limit)
+		h.pruned = limit
+	}
+}
+
+// history returns the bid records of the given block and builder, all the blocks
+// or builders if not specified.
+func (h *bidHistory) history(number *uint64, builder *common.Address) []*types.BidRecord {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	records := make([]*types.BidRecord, 0)
+	for _, record := range h.records {
+		if number != nil && record.BlockNumber != *number {
+			continue
+		}
+		if builder != nil && record.Builder != *builder {
+			continue
+		}
+		cpy := *record
+		records = append(records, &cpy)
+	}
+	return records
+}
+
+// stats computes the reputation of the builders over the bid records.
+func (h *bidHistory) stats() []*types.BuilderStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var (
+		stats      = make(map[common.Address]*types.BuilderStats)
+		latencySum = make(map[common.Address]uint64)
+		latencyCnt = make(map[common.Address]uint64)
+	)
+	for _, record := range h.records {
+		s, ok := stats[record.Builder]
+		if !ok {
+			s = &types.BuilderStats{Builder: record.Builder}
+			stats[record.Builder] = s
+		}
+		s.Bids++
+		switch record.Status {
+		case types.BidStatusWon:
+			s.Won++
+		case types.BidStatusLost:
+			s.Lost++
+		case types.BidStatusInvalid:
+			s.Invalid++
+		default:
+			s.Aborted++
+		}
+		if record.ReceivedAt != 0 {
+			latencySum[record.Builder] += record.Latency
+			latencyCnt[record.Builder]++
+		}
+	}
+	result := make([]*types.BuilderStats, 0, len(stats))
+	for builder, s := range stats {
+		s.SuccessRate = float64(s.Won+s.Lost) / float64(s.Bids)
+		s.InvalidRate = float64(s.Invalid) / float64(s.Bids)
+		if latencyCnt[builder] > 0 {
+			s.AvgLatency = latencySum[builder] / latencyCnt[builder]
+		}
+		if penalty, ok := h.penalties[builder]; ok {
+			s.ConsecutiveFailures = penalty.consecutiveFailures
+			if time.Now().Before(penalty.until) {
+				s.Penalty = h.config.BuilderPenalty
+				s.PenaltyUntil = uint64(penalty.until.Unix())
+			}
+		}
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Builder.Cmp(result[j].Builder) < 0
+	})
+	return result
+}
@@ -0,0 +1,151 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/miner/minerconfig"
+)
+
+func newTestBidRecord(number uint64, builder common.Address, hash byte, status string) *types.BidRecord {
+	return &types.BidRecord{
+		BlockNumber:  number,
+		Builder:      builder,
+		BidHash:      common.Hash{hash},
+		GasFee:       big.NewInt(1),
+		PackedReward: big.NewInt(1),
+		Status:       status,
+		ReceivedAt:   uint64(time.Now().UnixMilli()),
+		Latency:      100,
+	}
+}
+
+func TestBidHistory(t *testing.T) {
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		threshold = uint32(2)
+		penalty   = time.Hour
+		config    = &minerconfig.MevConfig{
+			BuilderFailureThreshold: &threshold,
+			BuilderPenalty:          minerconfig.BuilderPenaltyBan,
+			BuilderPenaltyTime:      &penalty,
+		}
+		good = common.Address{0x1}
+		bad  = common.Address{0x2}
+	)
+	history := newBidHistory(db, config, 2)
+	history.add(newTestBidRecord(1, good, 0x1, types.BidStatusLost))
+	history.add(newTestBidRecord(1, bad, 0x2, types.BidStatusInvalid))
+
+	// The simulation of the same bid again only updates its record
+	rerun := newTestBidRecord(1, good, 0x1, types.BidStatusWon)
+	rerun.ReceivedAt, rerun.Latency = 0, 0
+	history.add(rerun)
+	records := history.history(nil, &good)
+	if len(records) != 1 || records[0].Status != types.BidStatusWon || records[0].Latency != 100 {
+		t.Fatalf("unexpected records of the builder: %+v", records)
+	}
+	if err := history.checkBanned(bad); err != nil {
+		t.Fatalf("builder banned before reaching the threshold: %v", err)
+	}
+	history.add(newTestBidRecord(2, bad, 0x3, types.BidStatusInvalid))
+	if err := history.checkBanned(bad); err == nil {
+		t.Fatal("expected the failing builder to be banned")
+	}
+	if err := history.checkBanned(good); err != nil {
+		t.Fatalf("healthy builder banned: %v", err)
+	}
+
+	stats := history.stats()
+	if len(stats) != 2 {
+		t.Fatalf("builder stats count mismatch, want 2, have %d", len(stats))
+	}
+	if s := stats[0]; s.Builder != good || s.Won != 1 || s.SuccessRate != 1 || s.AvgLatency != 100 {
+		t.Errorf("unexpected stats of healthy builder: %+v", s)
+	}
+	if s := stats[1]; s.Builder != bad || s.Invalid != 2 || s.InvalidRate != 1 || s.Penalty != minerconfig.BuilderPenaltyBan {
+		t.Errorf("unexpected stats of failing builder: %+v", s)
+	}
+
+	// The records are persisted once flushed, and the old ones pruned
+	if records := newBidHistory(db, config, 2).history(nil, nil); len(records) != 0 {
+		t.Fatalf("records persisted before the flush: %d", len(records))
+	}
+	history.flush()
+	if records := newBidHistory(db, config, 2).history(nil, nil); len(records) != 3 {
+		t.Fatalf("persisted records count mismatch, want 3, have %d", len(records))
+	}
+	history.prune(bidRecordsRetention + 2)
+	if records := newBidHistory(db, config, bidRecordsRetention+2).history(nil, nil); len(records) != 1 || records[0].BlockNumber != 2 {
+		t.Fatalf("unexpected records after pruning: %+v", records)
+	}
+}
+
+func TestBidHistoryLoad(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		config  = &minerconfig.MevConfig{}
+		builder = common.Address{0x1}
+		blocks  = uint64(maxBidRecords/2 + 10)
+	)
+	// Two records per block, more than the records kept in memory
+	history := newBidHistory(db, config, 0)
+	for number := uint64(1); number <= blocks; number++ {
+		for i := uint64(0); i < 2; i++ {
+			record := newTestBidRecord(number, builder, 0, types.BidStatusLost)
+			record.BidHash = common.BigToHash(new(big.Int).SetUint64(number<<1 | i))
+			history.add(record)
+		}
+	}
+	history.flush()
+
+	// Only the records of the latest blocks are loaded
+	records := newBidHistory(db, config, blocks).history(nil, nil)
+	if len(records) != maxBidRecords {
+		t.Fatalf("loaded records count mismatch, want %d, have %d", maxBidRecords, len(records))
+	}
+	if first, last := records[0].BlockNumber, records[len(records)-1].BlockNumber; first != blocks-maxBidRecords/2+1 || last != blocks {
+		t.Fatalf("loaded blocks mismatch, have %d-%d", first, last)
+	}
+	// The records are pruned incrementally
+	history.prune(bidRecordsRetention + 5)
+	history.prune(bidRecordsRetention + 10)
+	if records := newBidHistory(db, config, blocks).history(nil, nil); len(records) != maxBidRecords {
+		t.Fatalf("loaded records count mismatch after pruning, want %d, have %d", maxBidRecords, len(records))
+	}
+	if records := newBidHistory(db, config, 11).history(nil, nil); len(records) != 4 || records[0].BlockNumber != 10 {
+		t.Fatalf("unexpected records after pruning: %+v", records)
+	}
+}
+
+func TestRecordBidStatus(t *testing.T) {
+	b := &bidSimulator{
+		history: newBidHistory(rawdb.NewMemoryDatabase(), &minerconfig.MevConfig{}, 0),
+	}
+	tests := []struct {
+		err       error
+		simulated bool
+		success   bool
+		status    string
+	}{
+		{nil, true, true, types.BidStatusWon},
+		{nil, true, false, types.BidStatusLost},
+		{nil, false, false, types.BidStatusAborted},
+		{errBetterBid, false, false, types.BidStatusAborted},
+		{errors.New("miner exit"), false, false, types.BidStatusAborted},
+		{invalidBid(errors.New("invalid tx in bid")), false, false, types.BidStatusInvalid},
+	}
+	for i, tt := range tests {
+		builder := common.Address{byte(i + 1)}
+		b.recordBid(&BidRuntime{bid: &types.Bid{Builder: builder, BlockNumber: 1}}, tt.err, tt.simulated, tt.success, 0)
+		records := b.history.history(nil, &builder)
+		if len(records) != 1 || records[0].Status != tt.status {
+			t.Errorf("test %d: unexpected records: %+v, want status %s", i, records, tt.status)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package miner
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/log"
+	"github.com/Ezkerrox/bsc/miner/minerconfig"
+)
+
+// BidSelectionPolicy decides which bid is sealed among the bids of a block.
+type BidSelectionPolicy interface {
+	// Name returns the name of the policy, as configured.
+	Name() string
+
+	// ExpectedBetter returns whether the new bid should be simulated in place of
+	// the best bid to run, based on the rewards claimed by the builders.
+	ExpectedBetter(bid, best *BidRuntime) bool
+
+	// Better returns whether the simulated bid should replace the best bid, based
+	// on the rewards of the simulated blocks.
+	Better(bid, best *BidRuntime) bool
+
+	// BeatsLocal returns whether the best bid should be sealed rather than the
+	// local block with the given block reward.
+	BeatsLocal(bid *BidRuntime, localReward *big.Int) bool
+
+	// Sealed notifies the policy the block of the bid is being sealed.
+	Sealed(bid *BidRuntime)
+}
+
+// newBidSelectionPolicy creates the bid selection policy configured, the reward
+// policy if unknown.
+func newBidSelectionPolicy(config *minerconfig.MevConfig) BidSelectionPolicy {
+	switch config.BidSelectionPolicy {
+	case "", minerconfig.BidSelectionReward:
+		return rewardPolicy{}
+	case minerconfig.BidSelectionMargin:
+		var margin uint64
+		if config.BidMinMargin != nil {
+			margin = *config.BidMinMargin
+		}
+		return &marginPolicy{margin: margin}
+	case minerconfig.BidSelectionAllowList:
+		priority := make(map[common.Address]struct{}, len(config.PriorityBuilders))
+		for _, builder := range config.PriorityBuilders {
+			priority[builder] = struct{}{}
+		}
+		return &allowListPolicy{priority: priority}
+	case minerconfig.BidSelectionFairness:
+		var epsilon uint64
+		if config.BidFairnessEpsilon != nil {
+			epsilon = *config.BidFairnessEpsilon
+		}
+		return &fairnessPolicy{epsilon: epsilon, sealed: make(map[common.Address]uint64)}
+	default:
+		log.Error("BidSimulator: unknown bid selection policy, fallback to reward", "policy", config.BidSelectionPolicy)
+		return rewardPolicy{}
+	}
+}
+
+// rewardPolicy selects the bid with the highest reward, the simplest strategy:
+// best for all the delegators.
+type rewardPolicy struct{}
+
+func (rewardPolicy) Name() string { return minerconfig.BidSelectionReward }
+
+func (rewardPolicy) ExpectedBetter(bid, best *BidRuntime) bool {
+	return bid.isExpectedBetterThan(best)
+}
+
+func (rewardPolicy) Better(bid, best *BidRuntime) bool {
+	return bid.packedBlockReward.Cmp(best.packedBlockReward) > 0
+}
+
+func (rewardPolicy) BeatsLocal(bid *BidRuntime, localReward *big.Int) bool {
+	return localReward.Cmp(bid.packedBlockReward) < 0
+}
+
+func (rewardPolicy) Sealed(*BidRuntime) {}
+
+// marginPolicy selects the bid with the highest reward, as long as it beats the
+// local block by the minimum margin, 100 meaning 1%.
+type marginPolicy struct {
+	rewardPolicy
+	margin uint64
+}
+
+func (p *marginPolicy) Name() string { return minerconfig.BidSelectionMargin }
+
+func (p *marginPolicy) BeatsLocal(bid *BidRuntime, localReward *big.Int) bool {
+	minReward := new(big.Int).Mul(localReward, big.NewInt(int64(10000+p.margin)))
+	minReward.Div(minReward, big.NewInt(10000))
+	return minReward.Cmp(bid.packedBlockReward) < 0 && localReward.Cmp(bid.packedBlockReward) < 0
+}
+
+// allowListPolicy selects the bids of the priority builders over the bids of the
+// other builders, the highest reward winning among the bids of the same priority.
+type allowListPolicy struct {
+	rewardPolicy
+	priority map[common.Address]struct{}
+}
+
+func (p *allowListPolicy) Name() string { return minerconfig.BidSelectionAllowList }
+
+// compare returns 1 if the builder of the bid has a higher priority than the
+// one of the best bid, -1 if it's lower, 0 if they have the same priority.
+func (p *allowListPolicy) compare(bid, best *BidRuntime) int {
+	_, bidPriority := p.priority[bid.bid.Builder]
+	_, bestPriority := p.priority[best.bid.Builder]
+	switch {
+	case bidPriority && !bestPriority:
+		return 1
+	case !bidPriority && bestPriority:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (p *allowListPolicy) ExpectedBetter(bid, best *BidRuntime) bool {
+	if cmp := p.compare(bid, best); cmp != 0 {
+		return cmp > 0
+	}
+	return p.rewardPolicy.ExpectedBetter(bid, best)
+}
+
+func (p *allowListPolicy) Better(bid, best *BidRuntime) bool {
+	if cmp := p.compare(bid, best); cmp != 0 {
+		return cmp > 0
+	}
+	return p.rewardPolicy.Better(bid, best)
+}
+
+// fairnessWindow is the number of the last sealed blocks the fairness policy
+// counts the blocks of the builders over, so that a builder joining late, or
+// back after a downtime, only catches up with the recent blocks of the others.
+const fairnessWindow = 100
+
+// fairnessPolicy rotates among the builders whose bids are within the epsilon of
+// the best reward, 100 meaning 1%, by selecting the builder with the fewest
+// blocks sealed among the last fairnessWindow ones. The highest reward wins
+// otherwise.
+type fairnessPolicy struct {
+	rewardPolicy
+	epsilon uint64
+
+	mu     sync.RWMutex
+	sealed map[common.Address]uint64 // builder -> number of blocks sealed in the window
+	window []common.Address          // builders of the last sealed blocks, oldest first
+}
+
+func (p *fairnessPolicy) Name() string { return minerconfig.BidSelectionFairness }
+
+// close returns whether the two rewards are within the epsilon of the highest.
+func (p *fairnessPolicy) close(a, b *big.Int) bool {
+	diff := new(big.Int).Sub(a, b)
+	diff.Abs(diff).Mul(diff, big.NewInt(10000))
+	highest := a
+	if b.Cmp(a) > 0 {
+		highest = b
+	}
+	return diff.Cmp(new(big.Int).Mul(highest, big.NewInt(int64(p.epsilon)))) <= 0
+}
+
+// compare returns 1 if the builder of the bid sealed fewer blocks than the one
+// of the best bid, -1 if it sealed more, 0 otherwise.
+func (p *fairnessPolicy) compare(bid, best *BidRuntime) int {
+	if bid.bid.Builder == best.bid.Builder {
+		return 0
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	bidSealed, bestSealed := p.sealed[bid.bid.Builder], p.sealed[best.bid.Builder]
+	switch {
+	case bidSealed < bestSealed:
+		return 1
+	case bidSealed > bestSealed:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (p *fairnessPolicy) ExpectedBetter(bid, best *BidRuntime) bool {
+	if p.close(bid.expectedBlockReward, best.expectedBlockReward) {
+		if cmp := p.compare(bid, best); cmp != 0 {
+			return cmp > 0
+		}
+	}
+	return p.rewardPolicy.ExpectedBetter(bid, best)
+}
+
+func (p *fairnessPolicy) Better(bid, best *BidRuntime) bool {
+	if p.close(bid.packedBlockReward, best.packedBlockReward) {
+		if cmp := p.compare(bid, best); cmp != 0 {
+			return cmp > 0
+		}
+	}
+	return p.rewardPolicy.Better(bid, best)
+}
+
+func (p *fairnessPolicy) Sealed(bid *BidRuntime) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.window) >= fairnessWindow {
+		oldest := p.window[0]
+		if p.sealed[oldest]--; p.sealed[oldest] == 0 {
+			delete(p.sealed, oldest)
+		}
+		p.window = p.window[1:]
+	}
+	p.window = append(p.window, bid.bid.Builder)
+	p.sealed[bid.bid.Builder]++
+}
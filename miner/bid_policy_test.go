@@ -0,0 +1,124 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/miner/minerconfig"
+)
+
+func newTestPolicyBid(builder common.Address, reward int64) *BidRuntime {
+	return &BidRuntime{
+		bid:                     &types.Bid{Builder: builder},
+		expectedBlockReward:     big.NewInt(reward),
+		expectedValidatorReward: big.NewInt(reward / 100),
+		packedBlockReward:       big.NewInt(reward),
+		packedValidatorReward:   big.NewInt(reward / 100),
+	}
+}
+
+func TestBidSelectionPolicies(t *testing.T) {
+	var (
+		first  = common.Address{0x1}
+		second = common.Address{0x2}
+		margin = uint64(1000)
+		eps    = uint64(500)
+	)
+	// Reward policy, also the fallback of the unknown policies
+	for _, name := range []string{minerconfig.BidSelectionReward, "unknown"} {
+		policy := newBidSelectionPolicy(&minerconfig.MevConfig{BidSelectionPolicy: name})
+		if policy.Name() != minerconfig.BidSelectionReward {
+			t.Fatalf("policy mismatch: have %s, want %s", policy.Name(), minerconfig.BidSelectionReward)
+		}
+		if !policy.Better(newTestPolicyBid(first, 1001), newTestPolicyBid(second, 1000)) {
+			t.Fatal("higher reward should win")
+		}
+		if !policy.BeatsLocal(newTestPolicyBid(first, 1001), big.NewInt(1000)) {
+			t.Fatal("higher reward should beat the local block")
+		}
+	}
+
+	// Margin policy, 10%
+	policy := newBidSelectionPolicy(&minerconfig.MevConfig{BidSelectionPolicy: minerconfig.BidSelectionMargin, BidMinMargin: &margin})
+	if policy.BeatsLocal(newTestPolicyBid(first, 1100), big.NewInt(1000)) {
+		t.Fatal("bid within the margin should not beat the local block")
+	}
+	if !policy.BeatsLocal(newTestPolicyBid(first, 1101), big.NewInt(1000)) {
+		t.Fatal("bid over the margin should beat the local block")
+	}
+
+	// Allow-list policy
+	policy = newBidSelectionPolicy(&minerconfig.MevConfig{BidSelectionPolicy: minerconfig.BidSelectionAllowList, PriorityBuilders: []common.Address{second}})
+	if !policy.Better(newTestPolicyBid(second, 1), newTestPolicyBid(first, 1000)) {
+		t.Fatal("priority builder should win")
+	}
+	if policy.ExpectedBetter(newTestPolicyBid(first, 1000), newTestPolicyBid(second, 1)) {
+		t.Fatal("other builder should not win over priority builder")
+	}
+	if !policy.Better(newTestPolicyBid(first, 1001), newTestPolicyBid(first, 1000)) {
+		t.Fatal("higher reward should win among the same priority")
+	}
+
+	// Fairness policy, 5%
+	policy = newBidSelectionPolicy(&minerconfig.MevConfig{BidSelectionPolicy: minerconfig.BidSelectionFairness, BidFairnessEpsilon: &eps})
+	policy.Sealed(newTestPolicyBid(first, 1000))
+	if !policy.Better(newTestPolicyBid(second, 960), newTestPolicyBid(first, 1000)) {
+		t.Fatal("builder with fewer sealed blocks should win within the epsilon")
+	}
+	if policy.ExpectedBetter(newTestPolicyBid(first, 1000), newTestPolicyBid(second, 960)) {
+		t.Fatal("builder with more sealed blocks should not win within the epsilon")
+	}
+	if policy.Better(newTestPolicyBid(second, 940), newTestPolicyBid(first, 1000)) {
+		t.Fatal("lower reward should not win out of the epsilon")
+	}
+}
+
+func TestFairnessPolicyLateBuilder(t *testing.T) {
+	var (
+		early = []common.Address{{0x1}, {0x2}}
+		late  = common.Address{0x3}
+		eps   = uint64(500)
+	)
+	policy := newBidSelectionPolicy(&minerconfig.MevConfig{BidSelectionPolicy: minerconfig.BidSelectionFairness, BidFairnessEpsilon: &eps})
+
+	// seal runs a block where the given builders bid the same reward, sealing
+	// the bid selected by the policy.
+	seal := func(builders ...common.Address) common.Address {
+		best := newTestPolicyBid(builders[0], 1000)
+		for _, builder := range builders[1:] {
+			if bid := newTestPolicyBid(builder, 1000); policy.Better(bid, best) {
+				best = bid
+			}
+		}
+		policy.Sealed(best)
+		return best.bid.Builder
+	}
+	for i := 0; i < 10*fairnessWindow; i++ {
+		seal(early...)
+	}
+	// The late builder only catches up with the blocks in the window, not with
+	// all the blocks sealed by the others since the start.
+	builders := append(early, late)
+	wins := 0
+	for seal(builders...) == late {
+		wins++
+		if wins > fairnessWindow/2 {
+			t.Fatalf("late builder monopolizes the blocks")
+		}
+	}
+	if wins == 0 {
+		t.Fatal("late builder never selected")
+	}
+	// The builders share the blocks from then on
+	sealed := make(map[common.Address]int)
+	for i := 0; i < 3*fairnessWindow; i++ {
+		sealed[seal(builders...)]++
+	}
+	for _, builder := range builders {
+		if sealed[builder] < fairnessWindow*3/4 || sealed[builder] > fairnessWindow*5/4 {
+			t.Fatalf("builder %x not selected in turn: %v", builder, sealed)
+		}
+	}
+}
@@ -39,9 +39,12 @@ var (
 	bidSim1stBidTimer    = metrics.NewRegisteredTimer("bid/sim/sim1stBid", nil)
 	bidSimTimer          = metrics.NewRegisteredTimer("bid/sim/duration", nil)
 
-	simulateSpeedGauge = metrics.NewRegisteredGauge("bid/sim/simulateSpeed", nil) // Mps
+	simulateSpeedGauge        = metrics.NewRegisteredGauge("bid/sim/simulateSpeed", nil)                       // Mps
+	simulateSpeedBuilderGauge = metrics.NewRegisteredGaugeVec("bid/sim/simulateSpeed/builder", nil, "builder") // Mps
 
-	bidSimTimeoutCounter = metrics.NewRegisteredCounter("bid/sim/simTimeout", nil)
+	bidSimTimeoutCounter        = metrics.NewRegisteredCounter("bid/sim/simTimeout", nil)
+	bidSimTimeoutBuilderCounter = metrics.NewRegisteredCounterVec("bid/sim/simTimeout/builder", nil, "builder")
+	bidErrCounter               = metrics.NewRegisteredCounterVec("bid/err", nil, "builder")
 )
 
 var (
@@ -66,6 +69,21 @@ var (
 	errBetterBid = errors.New("simulation abort due to better bid arrived")
 )
 
+// invalidBidError is the simulation failure caused by the bid itself, e.g. an
+// invalid transaction or an unmet fee expectation, which is counted against the
+// builder. Other failures are local to the validator and only abort the bid.
+type invalidBidError struct {
+	err error
+}
+
+func (e *invalidBidError) Error() string { return e.err.Error() }
+func (e *invalidBidError) Unwrap() error { return e.err }
+
+// invalidBid marks the simulation error as caused by the bid.
+func invalidBid(err error) error {
+	return &invalidBidError{err: err}
+}
+
 type bidWorker interface {
 	prepareWork(params *generateParams, witness bool) (*environment, error)
 	etherbase() common.Address
@@ -108,10 +126,17 @@ type bidSimulator struct {
 
 	sentryCli *builderclient.Client
 
-	// builder info (warning: only keep status in memory!)
+	// builder info, reconciled from the builder sources below
 	buildersMu sync.RWMutex
 	builders   map[common.Address]*builderclient.Client
 
+	sourcesMu        sync.Mutex
+	localBuilders    map[common.Address]string // builders of the config, the builders file and the RPC
+	registryBuilders map[common.Address]string // builders listed by the registry contract
+	appliedBuilders  map[common.Address]string // builders dialed, with their url
+	buildersFileTime time.Time                 // modification time of the builders file last loaded or written
+	registrySynced   bool                      // whether the registry was read, only accessed by clearLoop
+
 	// channels
 	simBidCh chan *simBidReq
 	newBidCh chan newBidPackage
@@ -128,6 +153,9 @@ type bidSimulator struct {
 	bidsToSim     map[uint64][]*BidRuntime    // blockNumber -->  bidRuntime list, used to discard envs
 
 	maxBidsPerBuilder uint32 // Maximum number of bids allowed per builder per block
+
+	history *bidHistory        // records of the simulated bids and the builder penalties
+	policy  BidSelectionPolicy // selects the best bid
 }
 
 func newBidSimulator(
@@ -157,6 +185,8 @@ func newBidSimulator(
 		bestBidToRun:  make(map[common.Hash]*types.Bid),
 		simulatingBid: make(map[common.Hash]*BidRuntime),
 		bidsToSim:     make(map[uint64][]*BidRuntime),
+		history:       newBidHistory(eth.BlockChain().DB(), config, eth.BlockChain().CurrentBlock().Number.Uint64()+1),
+		policy:        newBidSelectionPolicy(config),
 	}
 	if delayLeftOver != nil {
 		b.delayLeftOver = *delayLeftOver
@@ -165,6 +195,8 @@ func newBidSimulator(
 		b.maxBidsPerBuilder = *config.MaxBidsPerBuilder
 	}
 
+	b.initBuilderSources()
+
 	b.chainHeadSub = b.chain.SubscribeChainHeadEvent(b.chainHeadCh)
 
 	if config.Enabled != nil && *config.Enabled {
@@ -179,6 +211,9 @@ func newBidSimulator(
 	go b.clearLoop()
 	go b.mainLoop()
 	go b.newBidLoop()
+	if config.Enabled != nil && *config.Enabled && config.BuildersFile != "" {
+		go b.buildersFileLoop()
+	}
 
 	return b
 }
@@ -194,11 +229,14 @@ func (b *bidSimulator) dialSentryAndBuilders() {
 		}
 	}
 
+	b.sourcesMu.Lock()
+	defer b.sourcesMu.Unlock()
+
+	b.buildersMu.Lock()
 	b.sentryCli = sentryCli
+	b.buildersMu.Unlock()
 
-	for _, v := range b.config.Builders {
-		_ = b.AddBuilder(v.Address, v.URL)
-	}
+	b.reconcileBuilders(true)
 }
 
 func (b *bidSimulator) start() {
@@ -209,9 +247,17 @@ func (b *bidSimulator) stop() {
 	b.running.Store(false)
 }
 
+// penalised reports whether the bid may not replace the best one because its
+// builder is deprioritised: bids of the deprioritised builders never replace the
+// ones of the healthy builders.
+func (b *bidSimulator) penalised(bid, best *types.Bid) bool {
+	return b.history.deprioritised(bid.Builder) && !b.history.deprioritised(best.Builder)
+}
+
 func (b *bidSimulator) close() {
 	b.running.Store(false)
 	close(b.exitCh)
+	b.history.flush()
 }
 
 func (b *bidSimulator) isRunning() bool {
@@ -231,40 +277,6 @@ func (b *bidSimulator) stopReceivingBid() {
 	b.bidReceiving.Store(false)
 }
 
-func (b *bidSimulator) AddBuilder(builder common.Address, url string) error {
-	b.buildersMu.Lock()
-	defer b.buildersMu.Unlock()
-
-	if b.sentryCli != nil {
-		b.builders[builder] = b.sentryCli
-	} else {
-		var builderCli *builderclient.Client
-
-		if url != "" {
-			var err error
-
-			builderCli, err = builderclient.DialOptions(context.Background(), url, rpc.WithHTTPClient(client))
-			if err != nil {
-				log.Error("BidSimulator: failed to dial builder", "url", url, "err", err)
-				return err
-			}
-		}
-
-		b.builders[builder] = builderCli
-	}
-
-	return nil
-}
-
-func (b *bidSimulator) RemoveBuilder(builder common.Address) error {
-	b.buildersMu.Lock()
-	defer b.buildersMu.Unlock()
-
-	delete(b.builders, builder)
-
-	return nil
-}
-
 func (b *bidSimulator) ExistBuilder(builder common.Address) bool {
 	b.buildersMu.RLock()
 	defer b.buildersMu.RUnlock()
@@ -274,6 +286,11 @@ func (b *bidSimulator) ExistBuilder(builder common.Address) bool {
 	return ok
 }
 
+// SelectionPolicy returns the policy selecting the best bid.
+func (b *bidSimulator) SelectionPolicy() BidSelectionPolicy {
+	return b.policy
+}
+
 // best bid here is based on packedBlockReward after the bid is simulated
 func (b *bidSimulator) SetBestBid(prevBlockHash common.Hash, bid *BidRuntime) {
 	b.bestBidMu.Lock()
@@ -432,13 +449,14 @@ func (b *bidSimulator) newBidLoop() {
 				}
 				continue
 			}
+			bidRuntime.receiveTime = newBid.receiveTime
 
 			var replyErr error
 			toCommit := true
 			bestBidToRun := b.GetBestBidToRun(newBid.bid.ParentHash)
 			if bestBidToRun != nil {
 				bestBidRuntime, _ := newBidRuntime(bestBidToRun, *b.config.ValidatorCommission)
-				if bidRuntime.isExpectedBetterThan(bestBidRuntime) {
+				if b.policy.ExpectedBetter(bidRuntime, bestBidRuntime) && !b.penalised(bidRuntime.bid, bestBidToRun) {
 					// new bid has better expectedBlockReward, use bidRuntime
 					log.Debug("new bid has better expectedBlockReward",
 						"builder", bidRuntime.bid.Builder, "bidHash", bidRuntime.bid.Hash().TerminalString())
@@ -533,6 +551,8 @@ func (b *bidSimulator) clearLoop() {
 		delete(b.pending, blockNumber)
 		b.pendingMu.Unlock()
 
+		b.history.prune(blockNumber)
+
 		// clearThreshold := b.chain.GetFinalizedNumber(b.chain.GetHeaderByHash(parentHash))
 		clearThreshold := uint64(0) // Leave a sufficient buffer to avoid clearing active bids, which could cause panic
 		if blockNumber > b.chain.TriesInMemory() {
@@ -573,6 +593,13 @@ func (b *bidSimulator) clearLoop() {
 	}
 
 	for head := range b.chainHeadCh {
+		if b.config.Enabled != nil && *b.config.Enabled && b.config.BuilderRegistry != (common.Address{}) {
+			b.syncRegistryBuilders(head.Header)
+		}
+		// Persist the bid records of the past block in a batch, off the
+		// simulation path
+		b.history.flush()
+
 		if !b.isRunning() {
 			continue
 		}
@@ -661,8 +688,9 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		bidTxLen = len(bidTxs)
 		payBidTx = bidTxs[bidTxLen-1]
 
-		err     error
-		success bool
+		err       error
+		simulated bool // whether the simulation ran to the end
+		success   bool
 	)
 
 	// ensure simulation exited then start next simulation
@@ -690,6 +718,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 			}
 		}
 
+		b.recordBid(bidRuntime, err, simulated, success, time.Since(simStart))
 		b.RemoveSimulatingBid(parentHash)
 		close(bidRuntime.finished)
 
@@ -749,7 +778,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	// error fix:
 	//	136782406 > 136791878 => false, Or 136807406 > 136816878 => false
 	if bidRuntime.bid.GasUsed > bidRuntime.env.gasPool.Gas() {
-		err = errors.New("gas used exceeds gas limit")
+		err = invalidBid(errors.New("gas used exceeds gas limit"))
 		return
 	}
 
@@ -788,7 +817,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		err = bidRuntime.commitTransaction(b.chain, b.chainConfig, tx, bidRuntime.bid.UnRevertible.Contains(tx.Hash()))
 		if err != nil {
 			log.Error("BidSimulator: failed to commit tx", "bidHash", bidRuntime.bid.Hash(), "tx", tx.Hash(), "err", err)
-			err = fmt.Errorf("invalid tx in bid, %v", err)
+			err = invalidBid(fmt.Errorf("invalid tx in bid, %v", err))
 			return
 		}
 	}
@@ -797,6 +826,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	delay = b.engine.Delay(b.chain, bidRuntime.env.header, &b.delayLeftOver)
 	if delay != nil && *delay < 0 {
 		bidSimTimeoutCounter.Inc(1)
+		bidSimTimeoutBuilderCounter.With(bidRuntime.bid.Builder.String()).Inc(1)
 		log.Info("BidSimulator: fail to commit, timeout when simulating completed")
 		return
 	}
@@ -805,7 +835,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	{
 		bidRuntime.packReward(*b.config.ValidatorCommission)
 		if !bidRuntime.validReward() {
-			err = errors.New("reward does not achieve the expectation")
+			err = invalidBid(errors.New("reward does not achieve the expectation"))
 			return
 		}
 	}
@@ -821,7 +851,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 				bidGasUsed += receipt.GasUsed
 				effectiveTip, er := tx.EffectiveGasTip(bidRuntime.env.header.BaseFee)
 				if er != nil {
-					err = errors.New("failed to calculate effective tip")
+					err = invalidBid(errors.New("failed to calculate effective tip"))
 					return
 				}
 
@@ -843,7 +873,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		if bidGasUsed != 0 {
 			bidGasPrice := new(big.Int).Div(bidGasFee, new(big.Int).SetUint64(bidGasUsed))
 			if bidGasPrice.Cmp(b.minGasPrice) < 0 {
-				err = fmt.Errorf("bid gas price is lower than min gas price, bid:%v, min:%v", bidGasPrice, b.minGasPrice)
+				err = invalidBid(fmt.Errorf("bid gas price is lower than min gas price, bid:%v, min:%v", bidGasPrice, b.minGasPrice))
 				return
 			}
 		}
@@ -880,18 +910,20 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	if err != nil {
 		log.Error("BidSimulator: failed to commit tx", "builder", bidRuntime.bid.Builder,
 			"bidHash", bidRuntime.bid.Hash(), "tx", payBidTx.Hash(), "err", err)
-		err = fmt.Errorf("invalid tx in bid, %v", err)
+		err = invalidBid(fmt.Errorf("invalid tx in bid, %v", err))
 		return
 	}
 
+	simulated = true
 	bestBid := b.GetBestBid(parentHash)
+	better := bestBid == nil || (b.policy.Better(bidRuntime, bestBid) && !b.penalised(bidRuntime.bid, bestBid.bid))
 	simElapsed := time.Since(startTS)
 	if bestBid == nil {
 		winResult := "true[first]"
 		log.Info("[BID RESULT]", "win", winResult, "builder", bidRuntime.bid.Builder, "hash", bidRuntime.bid.Hash().TerminalString(), "simElapsed", simElapsed)
 	} else if bidRuntime.bid.Hash() != bestBid.bid.Hash() { // skip log flushing when only one bid is present
 		log.Info("[BID RESULT]",
-			"win", bidRuntime.packedBlockReward.Cmp(bestBid.packedBlockReward) > 0,
+			"win", better,
 
 			"bidHash", bidRuntime.bid.Hash().TerminalString(),
 			"bestHash", bestBid.bid.Hash().TerminalString(),
@@ -909,12 +941,13 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	if bidRuntime.bid.GasUsed > minGasForSpeedMetric {
 		timeCostMs := (simElapsed - greedyMergeElapsed).Microseconds()
 		if timeCostMs > 0 {
-			simulateSpeedGauge.Update(int64(float64(bidRuntime.bid.GasUsed) / float64(timeCostMs) / 1000))
+			speed := int64(float64(bidRuntime.bid.GasUsed) / float64(timeCostMs) / 1000)
+			simulateSpeedGauge.Update(speed)
+			simulateSpeedBuilderGauge.With(bidRuntime.bid.Builder.String()).Update(speed)
 		}
 	}
 
-	// this is the simplest strategy: best for all the delegators.
-	if bestBid == nil || bidRuntime.packedBlockReward.Cmp(bestBid.packedBlockReward) > 0 {
+	if better {
 		b.SetBestBid(bidRuntime.bid.ParentHash, bidRuntime)
 		bidRuntime.duration = time.Since(startTS)
 		bidSimTimer.UpdateSince(startTS)
@@ -922,11 +955,64 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	}
 }
 
+// recordBid records the simulation result of the bid into the bid history.
+func (b *bidSimulator) recordBid(bidRuntime *BidRuntime, err error, simulated, success bool, elapsed time.Duration) {
+	bid := bidRuntime.bid
+	record := &types.BidRecord{
+		BlockNumber:  bid.BlockNumber,
+		ParentHash:   bid.ParentHash,
+		Builder:      bid.Builder,
+		BidHash:      bid.Hash(),
+		GasUsed:      bid.GasUsed,
+		GasFee:       bid.GasFee,
+		PackedReward: bidRuntime.packedBlockReward,
+		ReceivedAt:   uint64(bidRuntime.receiveTime),
+		SimElapsed:   uint64(elapsed.Milliseconds()),
+	}
+	var invalid *invalidBidError
+	switch {
+	case errors.As(err, &invalid):
+		record.Status = types.BidStatusInvalid
+		record.Error = err.Error()
+	case success:
+		record.Status = types.BidStatusWon
+	case simulated:
+		record.Status = types.BidStatusLost
+	default:
+		// Interrupted by a better bid or a new head, timed out, or failed
+		// locally, e.g. the miner exited or the work could not be prepared.
+		record.Status = types.BidStatusAborted
+		if err != nil && err != errBetterBid {
+			record.Error = err.Error()
+		}
+	}
+	if bidRuntime.receiveTime > 0 {
+		if parent := b.chain.GetHeaderByHash(bid.ParentHash); parent != nil && uint64(bidRuntime.receiveTime) > parent.MilliTimestamp() {
+			record.Latency = uint64(bidRuntime.receiveTime) - parent.MilliTimestamp()
+		}
+	}
+	b.history.add(record)
+}
+
+// BidHistory returns the records of the recently simulated bids of the given
+// block and builder, all the blocks or builders if not specified.
+func (b *bidSimulator) BidHistory(number *uint64, builder *common.Address) []*types.BidRecord {
+	return b.history.history(number, builder)
+}
+
+// BuilderStats returns the reputation of the builders over the recently simulated bids.
+func (b *bidSimulator) BuilderStats() []*types.BuilderStats {
+	return b.history.stats()
+}
+
 // reportIssue reports the issue to the mev-sentry
 func (b *bidSimulator) reportIssue(bidRuntime *BidRuntime, err error) {
-	metrics.GetOrRegisterCounter(fmt.Sprintf("bid/err/%v", bidRuntime.bid.Builder), nil).Inc(1)
+	bidErrCounter.With(bidRuntime.bid.Builder.String()).Inc(1)
 
+	b.buildersMu.RLock()
 	cli := b.builders[bidRuntime.bid.Builder]
+	b.buildersMu.RUnlock()
+
 	if cli != nil {
 		err = cli.ReportIssue(context.Background(), &types.BidIssue{
 			Validator: bidRuntime.env.header.Coinbase,
@@ -952,8 +1038,9 @@ type BidRuntime struct {
 	packedBlockReward     *big.Int
 	packedValidatorReward *big.Int
 
-	finished chan struct{}
-	duration time.Duration
+	finished    chan struct{}
+	duration    time.Duration
+	receiveTime int64 // unix milliseconds the bid arrived, 0 if unknown
 }
 
 func newBidRuntime(newBid *types.Bid, validatorCommission uint64) (*BidRuntime, error) {
@@ -0,0 +1,155 @@
+package miner
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/metrics"
+)
+
+// maxBundles is the maximum number of bundles kept in the pool.
+const maxBundles = 1024
+
+var (
+	errBundleExists   = errors.New("bundle already known")
+	errBundlePoolFull = errors.New("bundle pool is full")
+
+	bundleGauge         = metrics.NewRegisteredGauge("bundle/pending", nil)
+	bundleMergedMeter   = metrics.NewRegisteredMeter("bundle/merged", nil)
+	bundleIncludedMeter = metrics.NewRegisteredMeter("bundle/included", nil)
+	bundleStaleMeter    = metrics.NewRegisteredMeter("bundle/stale", nil)
+	bundleFailedMeter   = metrics.NewRegisteredMeter("bundle/failed", nil)
+)
+
+// bundlePool keeps the bundles submitted by the searchers until they are
+// included or expire.
+type bundlePool struct {
+	mu      sync.RWMutex
+	bundles map[common.Hash]*types.Bundle
+}
+
+func newBundlePool() *bundlePool {
+	return &bundlePool{
+		bundles: make(map[common.Hash]*types.Bundle),
+	}
+}
+
+// add inserts the bundle into the pool, dropping the expired bundles first if
+// the pool is full.
+func (p *bundlePool) add(bundle *types.Bundle, head uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hash := bundle.Hash()
+	if _, ok := p.bundles[hash]; ok {
+		return errBundleExists
+	}
+	if len(p.bundles) >= maxBundles {
+		p.pruneLocked(head)
+		if len(p.bundles) >= maxBundles {
+			return errBundlePoolFull
+		}
+	}
+	p.bundles[hash] = bundle
+	bundleGauge.Update(int64(len(p.bundles)))
+	return nil
+}
+
+// prune drops the bundles which can't be included after the given block.
+func (p *bundlePool) prune(head uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pruneLocked(head)
+}
+
+func (p *bundlePool) pruneLocked(head uint64) {
+	for hash, bundle := range p.bundles {
+		if bundle.Expired(head) {
+			delete(p.bundles, hash)
+		}
+	}
+	bundleGauge.Update(int64(len(p.bundles)))
+}
+
+// pending returns the bundles which can be included in the block with the given
+// number and timestamp, ordered by the tip per gas unit they offer at the given
+// base fee, then by hash, so that the same bundles are picked when capped. The
+// bundles with a transaction whose nonce is already used in the state are
+// included ones, or can't be included anymore, they are dropped.
+func (p *bundlePool) pending(number, timestamp uint64, baseFee *big.Int, signer types.Signer, nonce func(common.Address) uint64) []*types.Bundle {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		bundles = make([]*types.Bundle, 0, len(p.bundles))
+		tips    = make(map[common.Hash]*big.Int, len(p.bundles))
+	)
+	for hash, bundle := range p.bundles {
+		if bundleStale(bundle, signer, nonce) {
+			delete(p.bundles, hash)
+			bundleStaleMeter.Mark(1)
+			continue
+		}
+		if bundle.ValidFor(number, timestamp) {
+			bundles = append(bundles, bundle)
+			tips[hash] = bundleTip(bundle, baseFee)
+		}
+	}
+	bundleGauge.Update(int64(len(p.bundles)))
+	sort.Slice(bundles, func(i, j int) bool {
+		hi, hj := bundles[i].Hash(), bundles[j].Hash()
+		if cmp := tips[hi].Cmp(tips[hj]); cmp != 0 {
+			return cmp > 0
+		}
+		return bytes.Compare(hi[:], hj[:]) < 0
+	})
+	return bundles
+}
+
+// bundleStale reports whether a transaction of the bundle has a nonce below the
+// one of its sender in the state, the bundle then always fails.
+func bundleStale(bundle *types.Bundle, signer types.Signer, nonce func(common.Address) uint64) bool {
+	for _, tx := range bundle.Txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil || tx.Nonce() < nonce(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleTip returns the tip per gas unit offered by the transactions of the
+// bundle at the given base fee, weighted by their gas limit. It's the price the
+// bundles are ordered by before their simulation.
+func bundleTip(bundle *types.Bundle, baseFee *big.Int) *big.Int {
+	var (
+		tips = new(big.Int)
+		gas  uint64
+	)
+	for _, tx := range bundle.Txs {
+		tip := tx.EffectiveGasTipValue(baseFee)
+		if tip.Sign() < 0 {
+			tip.SetInt64(0)
+		}
+		tips.Add(tips, tip.Mul(tip, new(big.Int).SetUint64(tx.Gas())))
+		gas += tx.Gas()
+	}
+	if gas == 0 {
+		return tips
+	}
+	return tips.Div(tips, new(big.Int).SetUint64(gas))
+}
+
+// size returns the number of bundles in the pool.
+func (p *bundlePool) size() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.bundles)
+}
@@ -0,0 +1,56 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ezkerrox/bsc/core/txpool"
+	"github.com/Ezkerrox/bsc/core/types"
+)
+
+// bundleTxMaxSize is the maximum size of a bundle transaction, the same as the
+// one of the transactions accepted by the legacy pool.
+const bundleTxMaxSize = 4 * 32 * 1024
+
+// SendBundle adds the bundle to the pool, from which the most profitable bundles
+// are merged into the blocks sealed locally. The transactions are validated like
+// the pool does, so that no bundle which can't be mined waits for its expiry.
+// Blob and set code transactions are not accepted in bundles.
+func (miner *Miner) SendBundle(bundle *types.Bundle) error {
+	var (
+		chain = miner.worker.chain
+		head  = chain.CurrentBlock()
+	)
+	miner.worker.confMu.RLock()
+	tip := miner.worker.tip.ToBig()
+	miner.worker.confMu.RUnlock()
+
+	opts := &txpool.ValidationOptions{
+		Config: chain.Config(),
+		Accept: 0 |
+			1<<types.LegacyTxType |
+			1<<types.AccessListTxType |
+			1<<types.DynamicFeeTxType,
+		MaxSize: bundleTxMaxSize,
+		MinTip:  tip,
+	}
+	var (
+		signer = types.LatestSigner(chain.Config())
+		gas    uint64
+	)
+	for _, tx := range bundle.Txs {
+		if err := txpool.ValidateTransaction(tx, head, signer, opts); err != nil {
+			return fmt.Errorf("invalid transaction %s: %w", tx.Hash(), err)
+		}
+		gas += tx.Gas()
+	}
+	if gas > head.GasLimit {
+		return errors.New("bundle gas exceeds the block gas limit")
+	}
+	return miner.worker.bundles.add(bundle, head.Number.Uint64())
+}
+
+// PendingBundles returns the number of bundles waiting to be merged.
+func (miner *Miner) PendingBundles() int {
+	return miner.worker.bundles.size()
+}
@@ -52,6 +52,10 @@ func (miner *Miner) SendBid(ctx context.Context, bidArgs *types.BidArgs) (common
 		return common.Hash{}, types.NewInvalidBidError("builder is not registered")
 	}
 
+	if err := miner.bidSimulator.history.checkBanned(builder); err != nil {
+		return common.Hash{}, types.NewInvalidBidError(err.Error())
+	}
+
 	err = miner.bidSimulator.CheckPending(bidArgs.RawBid.BlockNumber, builder, bidArgs.RawBid.Hash())
 	if err != nil {
 		return common.Hash{}, err
@@ -89,6 +93,17 @@ func (miner *Miner) BestPackedBlockReward(parentHash common.Hash) *big.Int {
 	return bidRuntime.packedBlockReward
 }
 
+// BidHistory returns the records of the recently simulated bids of the given
+// block and builder, all the blocks or builders if not specified.
+func (miner *Miner) BidHistory(number *uint64, builder *common.Address) []*types.BidRecord {
+	return miner.bidSimulator.BidHistory(number, builder)
+}
+
+// BuilderStats returns the reputation of the builders over the recently simulated bids.
+func (miner *Miner) BuilderStats() []*types.BuilderStats {
+	return miner.bidSimulator.BuilderStats()
+}
+
 func (miner *Miner) MevParams() *types.MevParams {
 	builderFeeCeil, ok := big.NewInt(0).SetString(*miner.worker.config.Mev.BuilderFeeCeil, 10)
 	if !ok {
@@ -104,6 +119,7 @@ func (miner *Miner) MevParams() *types.MevParams {
 		GasCeil:               miner.worker.config.GasCeil,
 		GasPrice:              miner.worker.config.GasPrice,
 		BuilderFeeCeil:        builderFeeCeil,
+		BidSelectionPolicy:    miner.bidSimulator.SelectionPolicy().Name(),
 		Version:               version.Semantic,
 	}
 }
@@ -0,0 +1,63 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/txpool"
+	"github.com/Ezkerrox/bsc/core/types"
+)
+
+// maxPrivateTxNonceGap is the maximum distance between the nonce of a private
+// transaction and the state nonce of its sender.
+const maxPrivateTxNonceGap = 16
+
+// SendPrivateTransaction adds the transaction to the private pool, from which it
+// is only included in the blocks sealed locally, up to the given block number,
+// at most MaxPrivateTxAliveBlock blocks ahead of the latest one.
+// As the transactions also come from the EVN peers, the nonce, fee and balance
+// are checked against the latest state, like the pool does.
+func (miner *Miner) SendPrivateTransaction(tx *types.Transaction, maxBlockNumber uint64) error {
+	chain := miner.worker.chain
+	head := chain.CurrentBlock()
+
+	if number := head.Number.Uint64(); maxBlockNumber <= number || maxBlockNumber > number+MaxPrivateTxAliveBlock {
+		return fmt.Errorf("%w: %d, latest block: %d, at most %d blocks ahead", errPrivateTxBlockNumber, maxBlockNumber, number, MaxPrivateTxAliveBlock)
+	}
+
+	from, err := types.Sender(types.LatestSigner(chain.Config()), tx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", txpool.ErrInvalidSender, err)
+	}
+	statedb, err := chain.StateAt(head.Root)
+	if err != nil {
+		return err
+	}
+	nonce := statedb.GetNonce(from)
+	if tx.Nonce() < nonce {
+		return fmt.Errorf("%w: address %v, tx: %d state: %d", core.ErrNonceTooLow, from, tx.Nonce(), nonce)
+	}
+	if tx.Nonce() >= nonce+maxPrivateTxNonceGap {
+		return fmt.Errorf("%w: address %v, tx: %d state: %d", core.ErrNonceTooHigh, from, tx.Nonce(), nonce)
+	}
+	if head.BaseFee != nil && tx.GasFeeCapIntCmp(head.BaseFee) < 0 {
+		return fmt.Errorf("%w: address %v, maxFeePerGas: %v, baseFee: %v", core.ErrFeeCapTooLow, from, tx.GasFeeCap(), head.BaseFee)
+	}
+	miner.worker.confMu.RLock()
+	tip := miner.worker.tip.ToBig()
+	miner.worker.confMu.RUnlock()
+	if tx.EffectiveGasTipIntCmp(tip, head.BaseFee) < 0 {
+		return fmt.Errorf("%w: tip needed %v, tip permitted %v", txpool.ErrUnderpriced, tip, tx.GasTipCap())
+	}
+	if balance, cost := statedb.GetBalance(from).ToBig(), tx.Cost(); balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w: balance %v, tx cost %v, overshot %v", core.ErrInsufficientFunds, balance, cost, new(big.Int).Sub(cost, balance))
+	}
+	return miner.worker.privateTxs.add(tx, from, maxBlockNumber, head.Number.Uint64())
+}
+
+// PendingPrivateTransactions returns the number of private transactions waiting
+// to be included.
+func (miner *Miner) PendingPrivateTransactions() int {
+	return miner.worker.privateTxs.size()
+}
@@ -227,6 +227,51 @@ func TestCloseMiner(t *testing.T) {
 	waitForMiningState(t, miner, false)
 }
 
+// TestSendBundleValidation checks that the bundles which can't be mined are
+// rejected on submission.
+func TestSendBundleValidation(t *testing.T) {
+	t.Parallel()
+	miner, _, cleanup := createMiner(t)
+	defer cleanup(false)
+	miner.SetGasTip(big.NewInt(params.GWei))
+
+	var (
+		signer = types.LatestSigner(miner.worker.chainConfig)
+		gasCap = big.NewInt(10 * params.GWei)
+		to     = common.HexToAddress("0xdead")
+		newTx  = func(nonce uint64, gas uint64, tip *big.Int) *types.Transaction {
+			return types.MustSignNewTx(testBankKey, signer, &types.DynamicFeeTx{
+				ChainID:   miner.worker.chainConfig.ChainID,
+				Nonce:     nonce,
+				GasTipCap: tip,
+				GasFeeCap: gasCap,
+				Gas:       gas,
+				To:        &to,
+			})
+		}
+		gasLimit = miner.worker.chain.CurrentBlock().GasLimit
+	)
+	tests := []struct {
+		txs   []*types.Transaction
+		valid bool
+	}{
+		{txs: []*types.Transaction{newTx(0, params.TxGas, big.NewInt(params.GWei))}, valid: true},
+		{txs: []*types.Transaction{newTx(1, params.TxGas, big.NewInt(params.GWei-1))}},              // Underpriced
+		{txs: []*types.Transaction{newTx(2, params.TxGas-1, big.NewInt(params.GWei))}},              // Intrinsic gas
+		{txs: []*types.Transaction{newTx(3, gasLimit+1, big.NewInt(params.GWei))}},                  // Over the block gas limit
+		{txs: []*types.Transaction{newTx(4, gasLimit/2+1, gasCap), newTx(5, gasLimit/2+1, gasCap)}}, // Bundle over the block gas limit
+	}
+	for i, test := range tests {
+		err := miner.SendBundle(&types.Bundle{Txs: test.txs, MaxBlockNumber: 1})
+		if test.valid && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("test %d: invalid bundle accepted", i)
+		}
+	}
+}
+
 // TestMinerSetEtherbase checks that etherbase becomes set even if mining isn't
 // possible at the moment
 func TestMinerSetEtherbase(t *testing.T) {
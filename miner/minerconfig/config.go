@@ -39,6 +39,23 @@ var (
 	defaultBidSimulationLeftOver        = 50 * time.Millisecond
 	defaultNoInterruptLeftOver          = 250 * time.Millisecond
 	defaultMaxBidsPerBuilder     uint32 = 2
+	defaultBuilderPenaltyTime           = 10 * time.Minute
+	defaultBidMinMargin          uint64 = 100
+	defaultBidFairnessEpsilon    uint64 = 100
+)
+
+// The penalties applied to the builders whose bids repeatedly fail the simulation.
+const (
+	BuilderPenaltyDeprioritise = "deprioritise" // Bids of the builder only win over the ones of other penalised builders
+	BuilderPenaltyBan          = "ban"          // Bids of the builder are rejected
+)
+
+// The policies selecting the best bid.
+const (
+	BidSelectionReward    = "reward"    // The bid with the highest reward wins
+	BidSelectionMargin    = "margin"    // As "reward", but the bid must beat the local block by BidMinMargin
+	BidSelectionAllowList = "allowlist" // The bids of the PriorityBuilders win over the others, then as "reward"
+	BidSelectionFairness  = "fairness"  // The builders with a reward within BidFairnessEpsilon of the best one win in turn
 )
 
 // Config is the configuration parameters of mining.
@@ -91,6 +108,18 @@ type MevConfig struct {
 	BidSimulationLeftOver *time.Duration  `toml:",omitempty"`
 	NoInterruptLeftOver   *time.Duration  `toml:",omitempty"`
 	MaxBidsPerBuilder     *uint32         `toml:",omitempty"` // Maximum number of bids allowed per builder per block
+
+	BuildersFile    string         `toml:",omitempty"` // TOML file of the builders, reloaded on change and updated by the builder RPCs. Seeded from Builders if missing, else it wins over Builders
+	BuilderRegistry common.Address `toml:",omitempty"` // Contract listing the builders, read at each epoch if set
+
+	BuilderFailureThreshold *uint32        `toml:",omitempty"` // Consecutive failed simulations before penalising the builder, 0 to disable
+	BuilderPenalty          string         `toml:",omitempty"` // The penalty of the failing builders, "deprioritise" or "ban"
+	BuilderPenaltyTime      *time.Duration `toml:",omitempty"` // How long the penalty of a builder lasts
+
+	BidSelectionPolicy string           `toml:",omitempty"` // The policy selecting the best bid, "reward", "margin", "allowlist" or "fairness"
+	BidMinMargin       *uint64          `toml:",omitempty"` // 100 means the bid must beat the local block reward by 1%, for "margin"
+	PriorityBuilders   []common.Address `toml:",omitempty"` // The builders preferred by "allowlist"
+	BidFairnessEpsilon *uint64          `toml:",omitempty"` // 100 means the bids within 1% of the best reward are rotated, for "fairness"
 }
 
 var DefaultMevConfig = MevConfig{
@@ -103,6 +132,11 @@ var DefaultMevConfig = MevConfig{
 	BidSimulationLeftOver: &defaultBidSimulationLeftOver,
 	NoInterruptLeftOver:   &defaultNoInterruptLeftOver,
 	MaxBidsPerBuilder:     &defaultMaxBidsPerBuilder,
+	BuilderPenalty:        BuilderPenaltyDeprioritise,
+	BuilderPenaltyTime:    &defaultBuilderPenaltyTime,
+	BidSelectionPolicy:    BidSelectionReward,
+	BidMinMargin:          &defaultBidMinMargin,
+	BidFairnessEpsilon:    &defaultBidFairnessEpsilon,
 }
 
 func ApplyDefaultMinerConfig(cfg *Config) {
@@ -154,4 +188,24 @@ func ApplyDefaultMinerConfig(cfg *Config) {
 		cfg.Mev.MaxBidsPerBuilder = &defaultMaxBidsPerBuilder
 		log.Info("ApplyDefaultMinerConfig", "Mev.MaxBidsPerBuilder", *cfg.Mev.MaxBidsPerBuilder)
 	}
+	if cfg.Mev.BuilderPenalty == "" {
+		cfg.Mev.BuilderPenalty = BuilderPenaltyDeprioritise
+		log.Info("ApplyDefaultMinerConfig", "Mev.BuilderPenalty", cfg.Mev.BuilderPenalty)
+	}
+	if cfg.Mev.BuilderPenaltyTime == nil {
+		cfg.Mev.BuilderPenaltyTime = &defaultBuilderPenaltyTime
+		log.Info("ApplyDefaultMinerConfig", "Mev.BuilderPenaltyTime", *cfg.Mev.BuilderPenaltyTime)
+	}
+	if cfg.Mev.BidSelectionPolicy == "" {
+		cfg.Mev.BidSelectionPolicy = BidSelectionReward
+		log.Info("ApplyDefaultMinerConfig", "Mev.BidSelectionPolicy", cfg.Mev.BidSelectionPolicy)
+	}
+	if cfg.Mev.BidMinMargin == nil {
+		cfg.Mev.BidMinMargin = &defaultBidMinMargin
+		log.Info("ApplyDefaultMinerConfig", "Mev.BidMinMargin", *cfg.Mev.BidMinMargin)
+	}
+	if cfg.Mev.BidFairnessEpsilon == nil {
+		cfg.Mev.BidFairnessEpsilon = &defaultBidFairnessEpsilon
+		log.Info("ApplyDefaultMinerConfig", "Mev.BidFairnessEpsilon", *cfg.Mev.BidFairnessEpsilon)
+	}
 }
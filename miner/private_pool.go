@@ -0,0 +1,158 @@
+package miner
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/txpool"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/metrics"
+)
+
+const (
+	// maxPrivateTxs is the maximum number of private transactions kept in the pool.
+	maxPrivateTxs = 4096
+
+	// maxPrivateTxsPerAccount is the maximum number of private transactions kept
+	// in the pool for a single sender.
+	maxPrivateTxsPerAccount = 16
+
+	// MaxPrivateTxAliveBlock is the maximum number of blocks a private transaction
+	// can wait for its inclusion, whether submitted over RPC or by an EVN peer.
+	MaxPrivateTxAliveBlock = 100
+)
+
+var (
+	errPrivateTxExists      = errors.New("private transaction already known")
+	errPrivateTxBlockNumber = errors.New("invalid max block number")
+	errPrivateTxPoolFull    = errors.New("private transaction pool is full")
+	errPrivateTxAccountFull = errors.New("private transaction account limit exceeded")
+
+	privateTxGauge         = metrics.NewRegisteredGauge("privatetx/pending", nil)
+	privateTxIncludedMeter = metrics.NewRegisteredMeter("privatetx/included", nil)
+	privateTxExpiredMeter  = metrics.NewRegisteredMeter("privatetx/expired", nil)
+)
+
+// privateTx is a transaction which is only included in the blocks sealed
+// locally, up to the max block number.
+type privateTx struct {
+	tx       *types.Transaction
+	from     common.Address
+	maxBlock uint64
+	time     time.Time
+}
+
+// privateTxPool keeps the private transactions, which are never announced to
+// the network, until they are included or expire.
+type privateTxPool struct {
+	mu      sync.RWMutex
+	txs     map[common.Hash]*privateTx
+	senders map[common.Address]int // Number of transactions kept per sender
+}
+
+func newPrivateTxPool() *privateTxPool {
+	return &privateTxPool{
+		txs:     make(map[common.Hash]*privateTx),
+		senders: make(map[common.Address]int),
+	}
+}
+
+// add inserts the transaction into the pool, dropping the expired transactions
+// first if the pool is full.
+func (p *privateTxPool) add(tx *types.Transaction, from common.Address, maxBlock, head uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hash := tx.Hash()
+	if _, ok := p.txs[hash]; ok {
+		return errPrivateTxExists
+	}
+	if len(p.txs) >= maxPrivateTxs {
+		p.pruneLocked(head)
+		if len(p.txs) >= maxPrivateTxs {
+			return errPrivateTxPoolFull
+		}
+	}
+	if p.senders[from] >= maxPrivateTxsPerAccount {
+		return errPrivateTxAccountFull
+	}
+	p.txs[hash] = &privateTx{tx: tx, from: from, maxBlock: maxBlock, time: time.Now()}
+	p.senders[from]++
+	privateTxGauge.Update(int64(len(p.txs)))
+	return nil
+}
+
+// prune drops the transactions which can't be included after the given block.
+func (p *privateTxPool) prune(head uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pruneLocked(head)
+}
+
+func (p *privateTxPool) pruneLocked(head uint64) {
+	for hash, ptx := range p.txs {
+		if ptx.maxBlock <= head {
+			p.removeLocked(hash, ptx)
+			privateTxExpiredMeter.Mark(1)
+		}
+	}
+	privateTxGauge.Update(int64(len(p.txs)))
+}
+
+// removeLocked drops the transaction from the pool and its sender count.
+func (p *privateTxPool) removeLocked(hash common.Hash, ptx *privateTx) {
+	delete(p.txs, hash)
+	if p.senders[ptx.from]--; p.senders[ptx.from] == 0 {
+		delete(p.senders, ptx.from)
+	}
+}
+
+// pending returns the transactions which can be included in the block with the
+// given number, grouped by sender and sorted by nonce. The transactions whose
+// nonce is already used in the state are included ones, they are dropped.
+func (p *privateTxPool) pending(number uint64, nonce func(common.Address) uint64) map[common.Address][]*txpool.LazyTransaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending := make(map[common.Address][]*txpool.LazyTransaction)
+	for hash, ptx := range p.txs {
+		if ptx.tx.Nonce() < nonce(ptx.from) {
+			p.removeLocked(hash, ptx)
+			privateTxIncludedMeter.Mark(1)
+			continue
+		}
+		if ptx.maxBlock < number {
+			continue
+		}
+		pending[ptx.from] = append(pending[ptx.from], &txpool.LazyTransaction{
+			Hash:      hash,
+			Tx:        ptx.tx,
+			Time:      ptx.time,
+			GasFeeCap: uint256.MustFromBig(ptx.tx.GasFeeCap()),
+			GasTipCap: uint256.MustFromBig(ptx.tx.GasTipCap()),
+			Gas:       ptx.tx.Gas(),
+			BlobGas:   ptx.tx.BlobGas(),
+		})
+	}
+	for _, txs := range pending {
+		sort.Slice(txs, func(i, j int) bool {
+			return txs[i].Tx.Nonce() < txs[j].Tx.Nonce()
+		})
+	}
+	privateTxGauge.Update(int64(len(p.txs)))
+	return pending
+}
+
+// size returns the number of transactions in the pool.
+func (p *privateTxPool) size() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.txs)
+}
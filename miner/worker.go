@@ -74,6 +74,7 @@ const (
 var (
 	bidExistGauge        = metrics.NewRegisteredGauge("worker/bidExist", nil)
 	bidWinGauge          = metrics.NewRegisteredGauge("worker/bidWin", nil)
+	bidWinBuilderGauge   = metrics.NewRegisteredGaugeVec("worker/bidWin/builder", nil, "builder")
 	inturnBlocksGauge    = metrics.NewRegisteredGauge("worker/inturnBlocks", nil)
 	bestBidGasUsedGauge  = metrics.NewRegisteredGauge("worker/bestBidGasUsed", nil)  // MGas
 	bestWorkGasUsedGauge = metrics.NewRegisteredGauge("worker/bestWorkGasUsed", nil) // MGas
@@ -189,12 +190,15 @@ type getWorkReq struct {
 type bidFetcher interface {
 	GetBestBid(parentHash common.Hash) *BidRuntime
 	GetSimulatingBid(prevBlockHash common.Hash) *BidRuntime
+	SelectionPolicy() BidSelectionPolicy
 }
 
 // worker is the main object which takes care of submitting new work to consensus engine
 // and gathering the sealing result.
 type worker struct {
 	bidFetcher  bidFetcher
+	bundles     *bundlePool
+	privateTxs  *privateTxPool
 	prefetcher  core.Prefetcher
 	config      *minerconfig.Config
 	chainConfig *params.ChainConfig
@@ -258,6 +262,8 @@ func newWorker(config *minerconfig.Config, engine consensus.Engine, eth Backend,
 	chainConfig := eth.BlockChain().Config()
 	worker := &worker{
 		prefetcher:         core.NewStatePrefetcher(chainConfig, eth.BlockChain().HeadChain()),
+		bundles:            newBundlePool(),
+		privateTxs:         newPrivateTxPool(),
 		config:             config,
 		chainConfig:        chainConfig,
 		engine:             engine,
@@ -460,6 +466,8 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 				interruptCh = nil
 			}
 			clearPending(head.Header.Number.Uint64())
+			w.bundles.prune(head.Header.Number.Uint64())
+			w.privateTxs.prune(head.Header.Number.Uint64())
 			timestamp = time.Now().Unix()
 			if p, ok := w.engine.(*parlia.Parlia); ok {
 				signedRecent, err := p.SignRecently(w.chain, head.Header)
@@ -799,17 +807,23 @@ func (w *worker) applyTransaction(env *environment, tx *types.Transaction, recei
 	return receipt, err
 }
 
+// initGasPool sets up the gas pool of the environment if it's not created yet,
+// reserving the gas for the system transactions.
+func (w *worker) initGasPool(env *environment) {
+	if env.gasPool != nil {
+		return
+	}
+	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	if p, ok := w.engine.(*parlia.Parlia); ok {
+		gasReserved := p.EstimateGasReservedForSystemTxs(w.chain, env.header)
+		env.gasPool.SubGas(gasReserved)
+		log.Debug("commitTransactions", "number", env.header.Number.Uint64(), "time", env.header.Time, "EstimateGasReservedForSystemTxs", gasReserved)
+	}
+}
+
 func (w *worker) commitTransactions(env *environment, plainTxs, blobTxs *transactionsByPriceAndNonce,
 	interruptCh chan int32, stopTimer *time.Timer) error {
-	gasLimit := env.header.GasLimit
-	if env.gasPool == nil {
-		env.gasPool = new(core.GasPool).AddGas(gasLimit)
-		if p, ok := w.engine.(*parlia.Parlia); ok {
-			gasReserved := p.EstimateGasReservedForSystemTxs(w.chain, env.header)
-			env.gasPool.SubGas(gasReserved)
-			log.Debug("commitTransactions", "number", env.header.Number.Uint64(), "time", env.header.Time, "EstimateGasReservedForSystemTxs", gasReserved)
-		}
-	}
+	w.initGasPool(env)
 
 	var coalescedLogs []*types.Log
 	// initialize bloom processors
@@ -955,6 +969,7 @@ LOOP:
 			// Transaction is regarded as invalid, drop all consecutive transactions from
 			// the same sender because of `nonce-too-high` clause.
 			log.Debug("Transaction failed, account skipped", "hash", ltx.Hash, "err", err)
+			w.eth.TxPool().History().Skipped(ltx.Hash, err)
 			txs.Pop()
 		}
 	}
@@ -1111,11 +1126,23 @@ func (w *worker) fillTransactions(interruptCh chan int32, env *environment, stop
 	filter.OnlyPlainTxs, filter.OnlyBlobTxs = false, true
 	pendingBlobTxs := w.eth.TxPool().Pending(filter)
 
-	if bidTxs != nil {
+	// Merge the most profitable bundles ahead of the mempool transactions, their
+	// transactions are then filtered out of the pending ones. The bids are left
+	// as built by the builders, the bundles are only merged into local blocks.
+	includedTxs := bidTxs
+	if bidTxs == nil {
+		bundleTxs, err := w.commitBundles(env, interruptCh, stopTimer)
+		if err != nil {
+			return err
+		}
+		includedTxs = bundleTxs
+	}
+
+	if includedTxs != nil {
 		filterBidTxs := func(commonTxs map[common.Address][]*txpool.LazyTransaction) {
 			for acc, txs := range commonTxs {
 				for i := len(txs) - 1; i >= 0; i-- {
-					if bidTxs.Contains(txs[i].Hash) {
+					if includedTxs.Contains(txs[i].Hash) {
 						if i == len(txs)-1 {
 							delete(commonTxs, acc)
 						} else {
@@ -1131,6 +1158,17 @@ func (w *worker) fillTransactions(interruptCh chan int32, env *environment, stop
 		filterBidTxs(pendingBlobTxs)
 	}
 
+	// The private transactions are committed ahead of the mempool ones, they are
+	// never announced so no other block could include them.
+	if privateTxs := w.privateTxs.pending(env.header.Number.Uint64(), env.state.GetNonce); len(privateTxs) > 0 {
+		plainTxs := newTransactionsByPriceAndNonce(env.signer, privateTxs, env.header.BaseFee)
+		blobTxs := newTransactionsByPriceAndNonce(env.signer, nil, env.header.BaseFee)
+
+		if err := w.commitTransactions(env, plainTxs, blobTxs, interruptCh, stopTimer); err != nil {
+			return err
+		}
+	}
+
 	// Split the pending transactions into locals and remotes.
 	prioPlainTxs, normalPlainTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingPlainTxs
 	prioBlobTxs, normalBlobTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingBlobTxs
@@ -1428,7 +1466,8 @@ LOOP:
 				"bidBlockReward", bestBid.packedBlockReward.String())
 		}
 
-		if bestBid != nil && bestReward.CmpBig(bestBid.packedBlockReward) < 0 {
+		policy := w.bidFetcher.SelectionPolicy()
+		if bestBid != nil && policy.BeatsLocal(bestBid, bestReward.ToBig()) {
 			// localValidatorReward is the reward for the validator self by the local block.
 			localValidatorReward := new(uint256.Int).Mul(bestReward, uint256.NewInt(*w.config.Mev.ValidatorCommission))
 			localValidatorReward.Div(localValidatorReward, uint256.NewInt(10000))
@@ -1440,6 +1479,8 @@ LOOP:
 			// blockReward(benefits delegators) and validatorReward(benefits the validator) are both optimal
 			if localValidatorReward.CmpBig(bestBid.packedValidatorReward) < 0 {
 				bidWinGauge.Inc(1)
+				bidWinBuilderGauge.With(bestBid.bid.Builder.String()).Inc(1)
+				policy.Sealed(bestBid)
 
 				bestWork = bestBid.env
 
@@ -0,0 +1,237 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core"
+	"github.com/Ezkerrox/bsc/core/state"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/core/vm"
+	"github.com/Ezkerrox/bsc/log"
+)
+
+const (
+	// maxBundlesPerBlock is the maximum number of bundles simulated for a block.
+	maxBundlesPerBlock = 128
+
+	// maxBundleSimulationTime is the maximum time spent simulating the bundles of
+	// a block, the rest of the sealing time is left to the mempool transactions.
+	maxBundleSimulationTime = 100 * time.Millisecond
+)
+
+// simulatedBundle is a bundle executed on top of a sealing block.
+type simulatedBundle struct {
+	bundle  *types.Bundle
+	gasUsed uint64
+	profit  *big.Int // Balance increase of the block rewards receivers
+	price   *big.Int // Profit per gas unit, which the bundles are ordered by
+}
+
+// simulateBundle executes the bundle on a copy of the environment state, the
+// environment itself is left untouched.
+func (w *worker) simulateBundle(env *environment, bundle *types.Bundle) (*simulatedBundle, error) {
+	var (
+		statedb = env.state.Copy()
+		evm     = vm.NewEVM(env.evm.Context, statedb, w.chainConfig, env.evm.Config)
+		gasPool = *env.gasPool
+		header  = types.CopyHeader(env.header)
+		before  = core.RewardBalance(statedb, w.chainConfig, env.coinbase)
+		gasUsed uint64
+	)
+	for i, tx := range bundle.Txs {
+		statedb.SetTxContext(tx.Hash(), env.tcount+i)
+		receipt, err := core.ApplyTransaction(evm, &gasPool, statedb, header, tx, &header.GasUsed)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s failed: %w", tx.Hash(), err)
+		}
+		if receipt.Status == types.ReceiptStatusFailed && !bundle.AllowRevert(tx.Hash()) {
+			return nil, fmt.Errorf("transaction %s reverted", tx.Hash())
+		}
+		gasUsed += receipt.GasUsed
+	}
+	profit := new(big.Int).Sub(core.RewardBalance(statedb, w.chainConfig, env.coinbase).ToBig(), before.ToBig())
+	if gasUsed == 0 || profit.Sign() <= 0 {
+		return nil, errors.New("bundle is not profitable")
+	}
+	return &simulatedBundle{
+		bundle:  bundle,
+		gasUsed: gasUsed,
+		profit:  profit,
+		price:   new(big.Int).Div(profit, new(big.Int).SetUint64(gasUsed)),
+	}, nil
+}
+
+// bundleSnapshot is the sealing environment before merging a bundle, to revert
+// the bundle if any of its transactions can't be merged.
+type bundleSnapshot struct {
+	state       *state.StateDB
+	gas         uint64
+	gasUsed     uint64
+	tcount      int
+	txs         int
+	sidecars    int
+	blobs       int
+	blobGasUsed uint64
+}
+
+// snapshotBundle records the environment before merging a bundle. The state is
+// copied, as it's finalised after each transaction.
+func (w *worker) snapshotBundle(env *environment) *bundleSnapshot {
+	snap := &bundleSnapshot{
+		state:    env.state.Copy(),
+		gas:      env.gasPool.Gas(),
+		gasUsed:  env.header.GasUsed,
+		tcount:   env.tcount,
+		txs:      len(env.txs),
+		sidecars: len(env.sidecars),
+		blobs:    env.blobs,
+	}
+	if env.header.BlobGasUsed != nil {
+		snap.blobGasUsed = *env.header.BlobGasUsed
+	}
+	return snap
+}
+
+// revertBundle restores the environment recorded before merging a bundle.
+func (w *worker) revertBundle(env *environment, snap *bundleSnapshot) {
+	env.state.StopPrefetcher()
+	env.state = snap.state
+	env.evm = vm.NewEVM(env.evm.Context, env.state, w.chainConfig, env.evm.Config)
+	env.gasPool.SetGas(snap.gas)
+	env.header.GasUsed = snap.gasUsed
+	env.tcount = snap.tcount
+	env.txs = env.txs[:snap.txs]
+	env.receipts = env.receipts[:snap.txs]
+	env.sidecars = env.sidecars[:snap.sidecars]
+	env.blobs = snap.blobs
+	if env.header.BlobGasUsed != nil {
+		*env.header.BlobGasUsed = snap.blobGasUsed
+	}
+}
+
+// commitBundles merges the pending bundles into the sealing block, the most
+// profitable ones first. At most maxBundlesPerBlock bundles are simulated, the
+// ones offering the highest tips per gas unit, within maxBundleSimulationTime.
+// The bundles paying less than the minimal tip per gas unit are skipped, and so
+// are the ones failing on top of the bundles merged before them, which are
+// reverted as a whole. It returns the hashes of the merged transactions, nil if
+// there is no bundle merged, or the error of the interruption of the block
+// building.
+func (w *worker) commitBundles(env *environment, interruptCh chan int32, stopTimer *time.Timer) (mapset.Set[common.Hash], error) {
+	bundles := w.bundles.pending(env.header.Number.Uint64(), env.header.Time, env.header.BaseFee, env.signer, env.state.GetNonce)
+	if len(bundles) == 0 {
+		return nil, nil
+	}
+	if len(bundles) > maxBundlesPerBlock {
+		bundles = bundles[:maxBundlesPerBlock]
+	}
+	w.initGasPool(env)
+
+	tip := new(big.Int)
+	w.confMu.RLock()
+	if w.tip != nil {
+		tip = w.tip.ToBig()
+	}
+	w.confMu.RUnlock()
+
+	// interrupted checks whether the block building is interrupted, or out of
+	// time for further bundles.
+	interrupted := func() (bool, error) {
+		if interruptCh != nil {
+			select {
+			case signal := <-interruptCh:
+				return true, signalToErr(signal)
+			default:
+			}
+		}
+		if stopTimer != nil {
+			select {
+			case <-stopTimer.C:
+				log.Info("Not enough time for further bundles", "txs", len(env.txs))
+				stopTimer.Reset(0) // re-active the timer, it's checked again when filling the transactions
+				return true, nil
+			default:
+			}
+		}
+		return false, nil
+	}
+	var (
+		simulated = make([]*simulatedBundle, 0, len(bundles))
+		deadline  = time.Now().Add(maxBundleSimulationTime)
+	)
+	for i, bundle := range bundles {
+		if stop, err := interrupted(); stop {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			log.Debug("Not enough time for further bundle simulations", "simulated", i, "skipped", len(bundles)-i)
+			break
+		}
+		sim, err := w.simulateBundle(env, bundle)
+		if err != nil {
+			log.Trace("Skipping bundle", "hash", bundle.Hash(), "err", err)
+			bundleFailedMeter.Mark(1)
+			continue
+		}
+		if sim.price.Cmp(tip) < 0 {
+			log.Trace("Skipping underpriced bundle", "hash", bundle.Hash(), "price", sim.price, "tip", tip)
+			continue
+		}
+		simulated = append(simulated, sim)
+	}
+	sort.SliceStable(simulated, func(i, j int) bool {
+		return simulated[i].price.Cmp(simulated[j].price) > 0
+	})
+
+	var txs mapset.Set[common.Hash]
+	for _, sim := range simulated {
+		if stop, err := interrupted(); stop {
+			return txs, err
+		}
+		bundle := sim.bundle
+		if env.gasPool.Gas() < sim.gasUsed {
+			log.Trace("Not enough gas left for bundle", "hash", bundle.Hash(), "left", env.gasPool.Gas(), "needed", sim.gasUsed)
+			continue
+		}
+		// The state changed with the bundles merged before, the bundle is reverted
+		// as a whole if any of its transactions fails now.
+		var (
+			snap = w.snapshotBundle(env)
+			err  error
+		)
+		for _, tx := range bundle.Txs {
+			env.state.SetTxContext(tx.Hash(), env.tcount)
+			if _, err = w.commitTransaction(env, tx, core.NewReceiptBloomGenerator()); err != nil {
+				break
+			}
+			env.tcount++
+			if env.receipts[len(env.receipts)-1].Status == types.ReceiptStatusFailed && !bundle.AllowRevert(tx.Hash()) {
+				err = fmt.Errorf("transaction %s reverted", tx.Hash())
+				break
+			}
+		}
+		if err != nil {
+			log.Trace("Skipping conflicting bundle", "hash", bundle.Hash(), "err", err)
+			bundleFailedMeter.Mark(1)
+			w.revertBundle(env, snap)
+			continue
+		}
+		if txs == nil {
+			txs = mapset.NewThreadUnsafeSet[common.Hash]()
+		}
+		for _, tx := range bundle.Txs {
+			txs.Add(tx.Hash())
+		}
+		bundleMergedMeter.Mark(1)
+		bundleIncludedMeter.Mark(1)
+		log.Debug("Merged bundle", "hash", bundle.Hash(), "txs", len(bundle.Txs), "gasUsed", sim.gasUsed, "profit", sim.profit)
+	}
+	return txs, nil
+}
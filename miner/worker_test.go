@@ -17,6 +17,8 @@
 package miner // TOFIX
 
 import (
+	"bytes"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -412,3 +414,306 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 		}
 	}
 }
+
+func TestCommitBundles(t *testing.T) {
+	var (
+		engine   = ethash.NewFaker()
+		signer   = types.LatestSigner(ethashChainConfig)
+		gasPrice = big.NewInt(10 * params.InitialBaseFee)
+	)
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1),
+			Gas:      params.TxGas,
+			GasPrice: gasPrice,
+		})
+	}
+	// The bundle overrides the pending transaction with the same nonce, while the
+	// one with the nonce gap can't be merged.
+	bundle := &types.Bundle{Txs: types.Transactions{newTx(0), newTx(1)}}
+	invalid := &types.Bundle{Txs: types.Transactions{newTx(5)}}
+	for _, bundle := range []*types.Bundle{bundle, invalid} {
+		if err := w.bundles.add(bundle, 0); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	if err := w.bundles.add(bundle, 0); err == nil {
+		t.Fatal("expected duplicated bundle failure")
+	}
+
+	r := w.getSealingBlock(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   common.HexToAddress("0xdeadbeef"),
+	})
+	if r.err != nil {
+		t.Fatalf("failed to generate block: %v", r.err)
+	}
+	txs := r.block.Transactions()
+	if len(txs) != len(bundle.Txs) {
+		t.Fatalf("transaction count mismatch, want %d, have %d", len(bundle.Txs), len(txs))
+	}
+	for i, tx := range txs {
+		if tx.Hash() != bundle.Txs[i].Hash() {
+			t.Errorf("transaction %d mismatch, want %x, have %x", i, bundle.Txs[i].Hash(), tx.Hash())
+		}
+	}
+
+	w.bundles.prune(1)
+	if size := w.bundles.size(); size != 2 {
+		t.Fatalf("bundles without max block number should not expire, have %d", size)
+	}
+}
+
+func TestPendingBundles(t *testing.T) {
+	var (
+		signer = types.LatestSigner(ethashChainConfig)
+		pool   = newBundlePool()
+		count  = maxBundlesPerBlock + 32
+	)
+	// The bundles are ordered by the tip they offer, then by hash, whatever
+	// the order they are added in, so the same ones are simulated when capped.
+	for i := 0; i < count; i++ {
+		tx := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &testUserAddress,
+			Value:    big.NewInt(1),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(int64(params.InitialBaseFee + i%(count/2))),
+		})
+		if err := pool.add(&types.Bundle{Txs: types.Transactions{tx}}, 0); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	baseFee := big.NewInt(params.InitialBaseFee)
+	nonce := func(common.Address) uint64 { return 0 }
+	bundles := pool.pending(1, 0, baseFee, signer, nonce)
+	if len(bundles) != count {
+		t.Fatalf("pending bundle count mismatch, want %d, have %d", count, len(bundles))
+	}
+	for i := 1; i < len(bundles); i++ {
+		prev, cur := bundleTip(bundles[i-1], baseFee), bundleTip(bundles[i], baseFee)
+		if cmp := prev.Cmp(cur); cmp < 0 || (cmp == 0 && bytes.Compare(bundles[i-1].Hash().Bytes(), bundles[i].Hash().Bytes()) > 0) {
+			t.Fatalf("bundle %d out of order: tip %v before %v", i, prev, cur)
+		}
+	}
+	if tip := bundleTip(bundles[maxBundlesPerBlock-1], baseFee); tip.Cmp(big.NewInt(int64(count/2-1-(maxBundlesPerBlock-1)/2))) != 0 {
+		t.Fatalf("capped bundles tip mismatch, have %v", tip)
+	}
+	for i := 0; i < 8; i++ {
+		again := pool.pending(1, 0, baseFee, signer, nonce)
+		for j := range bundles[:maxBundlesPerBlock] {
+			if again[j].Hash() != bundles[j].Hash() {
+				t.Fatalf("bundle %d differs between calls", j)
+			}
+		}
+	}
+	// The bundles whose nonce is used in the state are included, they're dropped
+	// instead of taking the place of the new bundles
+	included := func(common.Address) uint64 { return uint64(count - 8) }
+	if bundles := pool.pending(1, 0, baseFee, signer, included); len(bundles) != 8 {
+		t.Fatalf("pending bundle count mismatch after inclusion, want %d, have %d", 8, len(bundles))
+	}
+	if size := pool.size(); size != 8 {
+		t.Fatalf("included bundles not dropped, have %d", size)
+	}
+}
+
+func TestRevertBundle(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(ethashChainConfig)
+	)
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	env, err := w.prepareWork(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   common.HexToAddress("0xdeadbeef"),
+	}, false)
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.discard()
+	w.initGasPool(env)
+
+	// A transaction merged before the failure of its bundle is reverted along
+	// with the rest of the bundle.
+	snap := w.snapshotBundle(env)
+	tx := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		To:       &testUserAddress,
+		Value:    big.NewInt(1),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(10 * params.InitialBaseFee),
+	})
+	env.state.SetTxContext(tx.Hash(), env.tcount)
+	if _, err := w.commitTransaction(env, tx, core.NewReceiptBloomGenerator()); err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+	env.tcount++
+	w.revertBundle(env, snap)
+
+	if len(env.txs) != 0 || len(env.receipts) != 0 || env.tcount != 0 || env.header.GasUsed != 0 {
+		t.Fatalf("bundle not reverted: txs %d, receipts %d, tcount %d, gas used %d", len(env.txs), len(env.receipts), env.tcount, env.header.GasUsed)
+	}
+	if env.gasPool.Gas() != snap.gas {
+		t.Fatalf("gas pool not reverted: have %d, want %d", env.gasPool.Gas(), snap.gas)
+	}
+	if nonce := env.state.GetNonce(testBankAddress); nonce != 0 {
+		t.Fatalf("state not reverted: nonce %d", nonce)
+	}
+	// The reverted environment keeps working
+	env.state.SetTxContext(tx.Hash(), env.tcount)
+	if _, err := w.commitTransaction(env, tx, core.NewReceiptBloomGenerator()); err != nil {
+		t.Fatalf("failed to commit transaction after revert: %v", err)
+	}
+}
+
+func TestCommitPrivateTransactions(t *testing.T) {
+	var (
+		engine   = ethash.NewFaker()
+		signer   = types.LatestSigner(ethashChainConfig)
+		gasPrice = big.NewInt(10 * params.InitialBaseFee)
+	)
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(2),
+			Gas:      params.TxGas,
+			GasPrice: gasPrice,
+		})
+	}
+	// The handler announces the transactions added to the pool to the peers
+	announced := make(chan core.NewTxsEvent, 16)
+	sub := b.txPool.SubscribeTransactions(announced, false)
+	defer sub.Unsubscribe()
+
+	// The private transactions override the pending transaction with the same
+	// nonce, while the expired one is left out.
+	private := types.Transactions{newTx(1), newTx(0)}
+	for _, tx := range private {
+		if err := w.privateTxs.add(tx, testBankAddress, 1, 0); err != nil {
+			t.Fatalf("failed to add private transaction: %v", err)
+		}
+	}
+	if err := w.privateTxs.add(private[0], testBankAddress, 1, 0); err == nil {
+		t.Fatal("expected duplicated private transaction failure")
+	}
+	if err := w.privateTxs.add(newTx(2), testBankAddress, 0, 0); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+
+	r := w.getSealingBlock(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   common.HexToAddress("0xdeadbeef"),
+	})
+	if r.err != nil {
+		t.Fatalf("failed to generate block: %v", r.err)
+	}
+	txs := r.block.Transactions()
+	if len(txs) != len(private) {
+		t.Fatalf("transaction count mismatch, want %d, have %d", len(private), len(txs))
+	}
+	for i, tx := range txs {
+		if want := private[len(private)-1-i]; tx.Hash() != want.Hash() {
+			t.Errorf("transaction %d mismatch, want %x, have %x", i, want.Hash(), tx.Hash())
+		}
+	}
+
+	// The private transactions are kept out of the pool, so never announced
+	for _, tx := range private {
+		if b.txPool.Has(tx.Hash()) {
+			t.Errorf("private transaction %x added to the pool", tx.Hash())
+		}
+	}
+	select {
+	case event := <-announced:
+		t.Fatalf("private transactions announced: %d", len(event.Txs))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	w.privateTxs.prune(0)
+	if size := w.privateTxs.size(); size != 2 {
+		t.Fatalf("private transaction count mismatch after pruning, want 2, have %d", size)
+	}
+	// The included transactions are dropped once their nonce is used.
+	if pending := w.privateTxs.pending(1, func(common.Address) uint64 { return 2 }); len(pending) != 0 {
+		t.Fatalf("included private transactions should not be pending, have %d accounts", len(pending))
+	}
+	if size := w.privateTxs.size(); size != 0 {
+		t.Fatalf("included private transactions should be dropped, have %d", size)
+	}
+}
+
+func TestSendPrivateTransaction(t *testing.T) {
+	var (
+		engine   = ethash.NewFaker()
+		signer   = types.LatestSigner(ethashChainConfig)
+		gasPrice = big.NewInt(10 * params.InitialBaseFee)
+	)
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	w.setGasTip(big.NewInt(2 * params.InitialBaseFee))
+	miner := &Miner{worker: w}
+
+	newTx := func(nonce uint64, value, gasPrice *big.Int) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    value,
+			Gas:      params.TxGas,
+			GasPrice: gasPrice,
+		})
+	}
+	tests := []struct {
+		tx  *types.Transaction
+		err error
+	}{
+		{newTx(maxPrivateTxNonceGap, big.NewInt(1), gasPrice), core.ErrNonceTooHigh},
+		{newTx(0, big.NewInt(1), big.NewInt(params.InitialBaseFee)), txpool.ErrUnderpriced},
+		{newTx(0, testBankFunds, gasPrice), core.ErrInsufficientFunds},
+		{newTx(0, big.NewInt(1), gasPrice), nil},
+	}
+	for i, test := range tests {
+		if err := miner.SendPrivateTransaction(test.tx, 1); !errors.Is(err, test.err) {
+			t.Errorf("test %d: error mismatch, want %v, have %v", i, test.err, err)
+		}
+	}
+	// The max block number must be within the alive window
+	for _, number := range []uint64{0, MaxPrivateTxAliveBlock + 1} {
+		if err := miner.SendPrivateTransaction(newTx(1, big.NewInt(1), gasPrice), number); !errors.Is(err, errPrivateTxBlockNumber) {
+			t.Errorf("max block number %d: error mismatch, want %v, have %v", number, errPrivateTxBlockNumber, err)
+		}
+	}
+	// A sender can't fill the pool beyond its own limit
+	for nonce := uint64(1); nonce < maxPrivateTxsPerAccount; nonce++ {
+		if err := miner.SendPrivateTransaction(newTx(nonce, big.NewInt(1), gasPrice), 1); err != nil {
+			t.Fatalf("failed to add private transaction %d: %v", nonce, err)
+		}
+	}
+	if err := miner.SendPrivateTransaction(newTx(1, big.NewInt(2), gasPrice), 1); !errors.Is(err, errPrivateTxAccountFull) {
+		t.Fatalf("error mismatch, want %v, have %v", errPrivateTxAccountFull, err)
+	}
+	if size := miner.PendingPrivateTransactions(); size != maxPrivateTxsPerAccount {
+		t.Fatalf("private transaction count mismatch, want %d, have %d", maxPrivateTxsPerAccount, size)
+	}
+}
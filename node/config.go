@@ -224,6 +224,10 @@ type Config struct {
 	// EnableDoubleSignMonitor is a flag that whether to enable the double signature checker
 	EnableDoubleSignMonitor bool `toml:",omitempty"`
 
+	// DoubleSignEvidenceSubmitKey is the file containing the private key used to
+	// submit the found double sign evidences to the SlashIndicator contract.
+	DoubleSignEvidenceSubmitKey string `toml:",omitempty"`
+
 	// EnableMaliciousVoteMonitor is a flag that whether to enable the malicious vote checker
 	EnableMaliciousVoteMonitor bool `toml:",omitempty"`
 
@@ -235,6 +239,20 @@ type Config struct {
 	// current directory.
 	BLSWalletDir string `toml:",omitempty"`
 
+	// BLSRemoteSigner is the URL of a Web3Signer compatible signing service keeping
+	// the BLS key. The local BLS wallet is not used if it is set.
+	BLSRemoteSigner string `toml:",omitempty"`
+
+	// BLSRemoteSignerPubKey is the BLS public key to sign with in the remote signing
+	// service, the first key of the service is used if empty.
+	BLSRemoteSignerPubKey string `toml:",omitempty"`
+
+	// BLSRemoteSignerCACert, BLSRemoteSignerClientCert and BLSRemoteSignerClientKey
+	// are the TLS files used to connect to the remote signing service.
+	BLSRemoteSignerCACert     string `toml:",omitempty"`
+	BLSRemoteSignerClientCert string `toml:",omitempty"`
+	BLSRemoteSignerClientKey  string `toml:",omitempty"`
+
 	// VoteJournalDir is the directory to store votes in the fast finality feature.
 	VoteJournalDir string `toml:",omitempty"`
 
@@ -4,240 +4,517 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/Ezkerrox/bsc/common"
 	"github.com/Ezkerrox/bsc/core/rawdb"
 	"github.com/Ezkerrox/bsc/core/types"
-	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/ethdb"
 	"github.com/Ezkerrox/bsc/log"
 	"github.com/Ezkerrox/bsc/rlp"
-	"github.com/Ezkerrox/bsc/trie/trienode"
 )
 
-type Hbss2Pbss struct {
-	trie            *Trie // traverse trie
-	db              Database
-	blocknum        uint64
-	root            node // root of triedb
-	stateRootHash   common.Hash
-	concurrentQueue chan struct{}
-	totalNum        uint64
-	wg              sync.WaitGroup
-}
-
 const (
 	DEFAULT_TRIEDBCACHE_SIZE = 1024 * 1024 * 1024
+
+	// hbss2pbssSplitDepth is the depth down to which the account trie is split
+	// into subtries converted concurrently.
+	hbss2pbssSplitDepth = 2
 )
 
-// NewHbss2Pbss return a hash2Path obj
-func NewHbss2Pbss(tr *Trie, db Database, stateRootHash common.Hash, blocknum uint64, jobnum uint64) (*Hbss2Pbss, error) {
-	if tr == nil {
-		return nil, errors.New("trie is nil")
-	}
+// ErrHbss2PbssAborted is returned if the conversion is aborted, it resumes from
+// the persisted progress on the next run.
+var ErrHbss2PbssAborted = errors.New("hbss2pbss conversion aborted")
 
-	if tr.root == nil {
-		return nil, errors.New("trie root is nil")
-	}
+// hbss2pbssRange is the conversion progress of a subtrie of the account trie.
+type hbss2pbssRange struct {
+	Path    []byte // Path of the subtrie in the account trie
+	Account []byte // Path of the last account trie node persisted, or of the account whose storage is converted
+	Storage []byte // Path of the last storage trie node persisted, of the account above
+	Done    bool   // Flag whether the subtrie is fully converted
+}
 
-	ins := &Hbss2Pbss{
-		trie:            tr,
-		blocknum:        blocknum,
-		db:              db,
-		stateRootHash:   stateRootHash,
-		root:            tr.root,
-		concurrentQueue: make(chan struct{}, jobnum),
-		wg:              sync.WaitGroup{},
-	}
+// hbss2pbssStatus is the persisted progress of the conversion.
+type hbss2pbssStatus struct {
+	Root   common.Hash       // Root of the state converted
+	Number uint64            // Number of the block of the state converted
+	Paused bool              // Flag whether the conversion was paused by the user
+	Ranges []*hbss2pbssRange // Subtries converted concurrently, empty if not split yet
+}
 
-	return ins, nil
+// Hbss2Pbss converts a hash-based state into the path-based scheme. The trie
+// nodes are written under their paths in a deterministic order, along with the
+// progress of the conversion, so an interrupted conversion resumes where it
+// stopped. The hash-based nodes are left untouched, the conversion can run on
+// a live node.
+//
+// The account trie root is written last, along with the state id of the root:
+// it switches the database to the path-based scheme once everything else is
+// in place.
+type Hbss2Pbss struct {
+	db     Database
+	disk   ethdb.Database // Store of the state, where the path-based nodes go
+	jobnum uint64         // Number of subtries converted concurrently
+	nodes  atomic.Uint64  // Number of nodes converted in this run
+
+	status *hbss2pbssStatus
+	lock   sync.Mutex // Lock protecting the status and serializing its persistence
 }
 
-func (t *Trie) resloveWithoutTrack(n node, prefix []byte) (node, error) {
-	if n, ok := n.(hashNode); ok {
-		blob, err := t.reader.node(prefix, common.BytesToHash(n))
-		if err != nil {
-			return nil, err
-		}
-		return mustDecodeNode(n, blob), nil
+// stateStore returns the store holding the state in the given database.
+func stateStore(db ethdb.Database) ethdb.Database {
+	if store := db.StateStore(); store != nil {
+		return store
 	}
-	return n, nil
+	return db
 }
 
-func (h2p *Hbss2Pbss) writeNode(pathKey []byte, n *trienode.Node, owner common.Hash) {
-	if owner == (common.Hash{}) {
-		rawdb.WriteAccountTrieNode(h2p.db.Disk(), pathKey, n.Blob)
-		log.Debug("WriteNodes account node, ", "path: ", common.Bytes2Hex(pathKey), "Hash: ", n.Hash, "BlobHash: ", crypto.Keccak256Hash(n.Blob))
-	} else {
-		rawdb.WriteStorageTrieNode(h2p.db.Disk(), owner, pathKey, n.Blob)
-		log.Debug("WriteNodes storage node, ", "path: ", common.Bytes2Hex(pathKey), "owner: ", owner.String(), "Hash: ", n.Hash, "BlobHash: ", crypto.Keccak256Hash(n.Blob))
+// NewHbss2Pbss creates a conversion of the state with the given root, the state
+// of the block with the given number. A conversion in progress is discarded.
+func NewHbss2Pbss(db Database, root common.Hash, number uint64, jobnum uint64) (*Hbss2Pbss, error) {
+	reader, err := newTrieReader(root, common.Hash{}, db)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := reader.node(nil, root); err != nil {
+		return nil, err
+	}
+	h2p := &Hbss2Pbss{
+		db:     db,
+		disk:   stateStore(db.Disk()),
+		jobnum: max(jobnum, 1),
+		status: &hbss2pbssStatus{Root: root, Number: number},
+	}
+	if err := h2p.persist(h2p.disk.NewBatch()); err != nil {
+		return nil, err
+	}
+	return h2p, nil
+}
+
+// ResumeHbss2Pbss loads the conversion in progress, nil is returned if there
+// is none.
+func ResumeHbss2Pbss(db Database, jobnum uint64) (*Hbss2Pbss, error) {
+	disk := stateStore(db.Disk())
+	blob := rawdb.ReadHbss2PbssStatus(disk)
+	if len(blob) == 0 {
+		return nil, nil
 	}
+	status := new(hbss2pbssStatus)
+	if err := rlp.DecodeBytes(blob, status); err != nil {
+		return nil, fmt.Errorf("invalid hbss2pbss status: %w", err)
+	}
+	return &Hbss2Pbss{
+		db:     db,
+		disk:   disk,
+		jobnum: max(jobnum, 1),
+		status: status,
+	}, nil
 }
 
-// Run statistics, external call
-func (h2p *Hbss2Pbss) Run() {
-	log.Debug("Find Account Trie Tree, rootHash: ", h2p.trie.Hash().String(), "BlockNum: ", h2p.blocknum)
+// Root returns the root of the state converted.
+func (h2p *Hbss2Pbss) Root() common.Hash {
+	return h2p.status.Root
+}
 
-	h2p.ConcurrentTraversal(h2p.trie, h2p.root, []byte{})
-	h2p.wg.Wait()
+// Number returns the number of the block of the state converted.
+func (h2p *Hbss2Pbss) Number() uint64 {
+	return h2p.status.Number
+}
 
-	log.Info("Total", "complete", h2p.totalNum, "go routines Num", runtime.NumGoroutine, "h2p concurrentQueue", len(h2p.concurrentQueue))
+// Paused reports whether the conversion was paused by the user.
+func (h2p *Hbss2Pbss) Paused() bool {
+	h2p.lock.Lock()
+	defer h2p.lock.Unlock()
 
-	rawdb.WritePersistentStateID(h2p.db.Disk(), h2p.blocknum)
-	rawdb.WriteStateID(h2p.db.Disk(), h2p.stateRootHash, h2p.blocknum)
+	return h2p.status.Paused
 }
 
-func (h2p *Hbss2Pbss) SubConcurrentTraversal(theTrie *Trie, theNode node, path []byte) {
-	h2p.concurrentQueue <- struct{}{}
-	h2p.ConcurrentTraversal(theTrie, theNode, path)
-	<-h2p.concurrentQueue
-	h2p.wg.Done()
+// Pause marks the conversion as paused by the user. It must not be running.
+func (h2p *Hbss2Pbss) Pause() error {
+	h2p.lock.Lock()
+	defer h2p.lock.Unlock()
+
+	h2p.status.Paused = true
+	return h2p.persist(h2p.disk.NewBatch())
 }
 
-func (h2p *Hbss2Pbss) ConcurrentTraversal(theTrie *Trie, theNode node, path []byte) {
-	total_num := uint64(0)
-	// nil node
-	if theNode == nil {
-		return
+// persist writes the status in the batch and flushes it. The lock is held by
+// the caller, or the conversion isn't running.
+func (h2p *Hbss2Pbss) persist(batch ethdb.Batch) error {
+	blob, err := rlp.EncodeToBytes(h2p.status)
+	if err != nil {
+		return err
 	}
+	rawdb.WriteHbss2PbssStatus(batch, blob)
+	return batch.Write()
+}
 
-	switch current := (theNode).(type) {
-	case *shortNode:
-		collapsed := current.copy()
-		collapsed.Key = hexToCompact(current.Key)
-		var hash, _ = current.cache()
-		h2p.writeNode(path, trienode.New(common.BytesToHash(hash), nodeToBytes(collapsed)), theTrie.owner)
+// progress returns the fraction of the account trie key space converted.
+func (h2p *Hbss2Pbss) progress() float64 {
+	h2p.lock.Lock()
+	defer h2p.lock.Unlock()
 
-		h2p.ConcurrentTraversal(theTrie, current.Val, append(path, current.Key...))
+	var done float64
+	for _, r := range h2p.status.Ranges {
+		switch {
+		case r.Done:
+			done += keyspace(r.Path)
+		case len(r.Account) > 0:
+			done += position(r.Account) - position(r.Path)
+		}
+	}
+	return done
+}
 
-	case *fullNode:
-		// copy from trie/Committer (*committer).commit
-		collapsed := current.copy()
-		var hash, _ = collapsed.cache()
-		collapsed.Children = h2p.commitChildren(path, current)
+// position returns the position of the path in the key space, within [0, 1).
+func position(path []byte) float64 {
+	var pos, unit = 0.0, 1.0
+	for i := 0; i < len(path) && i < 16; i++ {
+		unit /= 16
+		if path[i] < 16 {
+			pos += float64(path[i]) * unit
+		}
+	}
+	return pos
+}
+
+// keyspace returns the fraction of the key space below the path.
+func keyspace(path []byte) float64 {
+	size := 1.0
+	for range path {
+		size /= 16
+	}
+	return size
+}
+
+// before reports whether the subtrie at the path precedes the given one in the
+// traversal order, in which case it's entirely converted already.
+func before(path, from []byte) bool {
+	n := min(len(path), len(from))
+	return bytes.Compare(path[:n], from[:n]) < 0
+}
+
+// Run converts the state, resuming the persisted progress. The conversion stops
+// with ErrHbss2PbssAborted once quit is closed.
+func (h2p *Hbss2Pbss) Run(quit <-chan struct{}) error {
+	select {
+	case <-quit:
+		return ErrHbss2PbssAborted
+	default:
+	}
+	root := h2p.status.Root
+	reader, err := newTrieReader(root, common.Hash{}, h2p.db)
+	if err != nil {
+		return err
+	}
+	rootBlob, err := reader.node(nil, root)
+	if err != nil {
+		return err
+	}
+	rootNode := mustDecodeNode(root.Bytes(), rootBlob)
 
-		nodebytes := nodeToBytes(collapsed)
-		if common.BytesToHash(hash) != common.BytesToHash(crypto.Keccak256(nodebytes)) {
-			log.Error("Hash is inconsistent, hash: ", common.BytesToHash(hash), "node hash: ", common.BytesToHash(crypto.Keccak256(nodebytes)), "node: ", collapsed.fstring(""))
-			panic("hash inconsistent.")
+	// Split the account trie into the subtries converted concurrently, the
+	// nodes above them are converted right away.
+	batch := h2p.disk.NewBatch()
+	if len(h2p.status.Ranges) == 0 {
+		if err := h2p.split(reader, rootNode, nil, batch); err != nil {
+			return err
 		}
+	}
+	h2p.status.Paused = false
+	if err := h2p.persist(batch); err != nil {
+		return err
+	}
+	log.Info("Converting hash-based state to path-based", "root", root, "number", h2p.status.Number, "subtries", len(h2p.status.Ranges))
 
-		h2p.writeNode(path, trienode.New(common.BytesToHash(hash), nodeToBytes(collapsed)), theTrie.owner)
+	var (
+		start   = time.Now()
+		initial = h2p.progress()
+		abort   = make(chan struct{})
+		once    sync.Once
+		done    = make(chan struct{})
+		errc    = make(chan error)
+		jobs    = make(chan struct{}, h2p.jobnum)
+		running int
+	)
+	stop := func() { once.Do(func() { close(abort) }) }
+	go func() {
+		ticker := time.NewTicker(8 * time.Second)
+		defer ticker.Stop()
 
-		for idx, child := range current.Children {
-			if child == nil {
-				continue
+		for {
+			select {
+			case <-quit:
+				stop()
+				quit = nil
+			case <-ticker.C:
+				h2p.report(start, initial)
+			case <-done:
+				return
 			}
-			childPath := append(path, byte(idx))
-			if len(h2p.concurrentQueue)*2 < cap(h2p.concurrentQueue) {
-				h2p.wg.Add(1)
-				dst := make([]byte, len(childPath))
-				copy(dst, childPath)
-				go h2p.SubConcurrentTraversal(theTrie, child, dst)
-			} else {
-				h2p.ConcurrentTraversal(theTrie, child, childPath)
-			}
-		}
-	case hashNode:
-		n, err := theTrie.resloveWithoutTrack(current, path)
-		if err != nil {
-			log.Error("Resolve HashNode", "error", err, "TrieRoot", theTrie.Hash(), "Path", path)
-			return
 		}
-		h2p.ConcurrentTraversal(theTrie, n, path)
-		total_num = atomic.AddUint64(&h2p.totalNum, 1)
-		if total_num%100000 == 0 {
-			log.Info("Converting ", "Complete progress", total_num, "go routines Num", runtime.NumGoroutine(), "h2p concurrentQueue", len(h2p.concurrentQueue))
-		}
-		return
-	case valueNode:
-		if !hasTerm(path) {
-			log.Info("ValueNode miss path term", "path", common.Bytes2Hex(path))
-			break
+	}()
+	for _, r := range h2p.status.Ranges {
+		if r.Done {
+			continue
 		}
-		var account types.StateAccount
-		if err := rlp.Decode(bytes.NewReader(current), &account); err != nil {
-			// log.Info("Rlp decode account failed.", "err", err)
-			break
+		running++
+		go func(r *hbss2pbssRange) {
+			jobs <- struct{}{}
+			defer func() { <-jobs }()
+
+			errc <- h2p.convert(reader, rootNode, r, abort)
+		}(r)
+	}
+	var failure error
+	for ; running > 0; running-- {
+		if err := <-errc; err != nil && failure == nil {
+			failure = err
+			stop()
 		}
-		if account.Root == (common.Hash{}) || account.Root == types.EmptyRootHash {
-			// log.Info("Not a storage trie.", "account", common.BytesToHash(path).String())
-			break
+	}
+	close(done)
+	if failure != nil {
+		if failure != ErrHbss2PbssAborted {
+			log.Error("Failed to convert hash-based state", "root", root, "err", failure)
 		}
+		return failure
+	}
+	// Write the root along with the state id, switching the database to the
+	// path-based scheme. The hash-based nodes left are pruned next.
+	batch = h2p.disk.NewBatch()
+	rawdb.WriteAccountTrieNode(batch, nil, rootBlob)
+	rawdb.WritePersistentStateID(batch, h2p.status.Number)
+	rawdb.WriteStateID(batch, root, h2p.status.Number)
+	rawdb.DeleteHbss2PbssStatus(batch)
+	rawdb.WriteHashTriePruning(batch, nil)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Converted hash-based state to path-based", "root", root, "number", h2p.status.Number, "nodes", h2p.nodes.Load(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
 
-		ownerAddress := common.BytesToHash(hexToCompact(path))
-		tr, err := New(StorageTrieID(h2p.stateRootHash, ownerAddress, account.Root), h2p.db)
-		if err != nil {
-			log.Error("New Storage trie error", "err", err, "root", account.Root.String(), "owner", ownerAddress.String())
-			break
+// report logs the progress of the conversion, along with the estimated time
+// left based on the speed of this run.
+func (h2p *Hbss2Pbss) report(start time.Time, initial float64) {
+	var (
+		done    = h2p.progress()
+		elapsed = time.Since(start)
+		ctx     = []interface{}{
+			"root", h2p.status.Root, "nodes", h2p.nodes.Load(),
+			"progress", fmt.Sprintf("%.2f%%", done*100), "elapsed", common.PrettyDuration(elapsed),
 		}
-		log.Debug("Find Contract Trie Tree", "rootHash: ", tr.Hash().String(), "")
-		h2p.wg.Add(1)
-		go h2p.SubConcurrentTraversal(tr, tr.root, []byte{})
-	default:
-		panic(errors.New("Invalid node type to traverse."))
+	)
+	if speed := (done - initial) / elapsed.Seconds(); speed > 0 {
+		ctx = append(ctx, "eta", common.PrettyDuration(time.Duration((1-done)/speed*float64(time.Second))))
 	}
+	log.Info("Converting hash-based state to path-based", ctx...)
 }
 
-// copy from trie/Committer (*committer).commit
-func (h2p *Hbss2Pbss) commitChildren(path []byte, n *fullNode) [17]node {
-	var children [17]node
-	for i := 0; i < 16; i++ {
-		child := n.Children[i]
+// split collects the subtries of the account trie to convert concurrently,
+// splitting the full nodes above hbss2pbssSplitDepth. The nodes split, except
+// the root, are written into the batch.
+func (h2p *Hbss2Pbss) split(reader *trieReader, n node, path []byte, batch ethdb.Batch) error {
+	var blob []byte
+	if hash, ok := n.(hashNode); ok {
+		var err error
+		if blob, err = reader.node(path, common.BytesToHash(hash)); err != nil {
+			return err
+		}
+		n = mustDecodeNode(hash, blob)
+	}
+	full, ok := n.(*fullNode)
+	if !ok || len(path) >= hbss2pbssSplitDepth {
+		h2p.status.Ranges = append(h2p.status.Ranges, &hbss2pbssRange{Path: path})
+		return nil
+	}
+	if blob != nil {
+		rawdb.WriteAccountTrieNode(batch, path, blob)
+	}
+	for i, child := range full.Children[:16] {
 		if child == nil {
 			continue
 		}
-		// If it's the hashed child, save the hash value directly.
-		// Note: it's impossible that the child in range [0, 15]
-		// is a valueNode.
-		if hn, ok := child.(hashNode); ok {
-			children[i] = hn
-			continue
+		if err := h2p.split(reader, child, concat(path, byte(i)), batch); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		children[i] = h2p.commit(append(path, byte(i)), child)
+// descend returns the node at the path below the split full nodes, as it's
+// referenced by its parent.
+func descend(reader *trieReader, n node, path []byte) (node, error) {
+	for i, nibble := range path {
+		full, ok := n.(*fullNode)
+		if !ok {
+			return nil, fmt.Errorf("unexpected node %T at path %x", n, path[:i])
+		}
+		n = full.Children[nibble]
+		if hash, ok := n.(hashNode); ok && i < len(path)-1 {
+			blob, err := reader.node(path[:i+1], common.BytesToHash(hash))
+			if err != nil {
+				return nil, err
+			}
+			n = mustDecodeNode(hash, blob)
+		}
+	}
+	return n, nil
+}
+
+// convert converts the subtrie of the given range, resuming its progress.
+func (h2p *Hbss2Pbss) convert(reader *trieReader, root node, r *hbss2pbssRange, abort chan struct{}) error {
+	select {
+	case <-abort:
+		return ErrHbss2PbssAborted
+	default:
+	}
+	n, err := descend(reader, root, r.Path)
+	if err != nil {
+		return err
 	}
-	// For the 17th child, it's possible the type is valuenode.
-	if n.Children[16] != nil {
-		children[16] = n.Children[16]
+	h2p.lock.Lock()
+	w := &h2pWorker{
+		h2p:     h2p,
+		rng:     r,
+		batch:   h2p.disk.NewBatch(),
+		abort:   abort,
+		storage: r.Storage,
 	}
-	return children
+	from := r.Account
+	h2p.lock.Unlock()
+
+	if err := w.walk(reader, n, r.Path, from); err != nil {
+		return err
+	}
+	return w.commit(nil, nil, true)
+}
+
+// h2pWorker converts the subtrie of a range, persisting the converted nodes in
+// batches along with the progress.
+type h2pWorker struct {
+	h2p   *Hbss2Pbss
+	rng   *hbss2pbssRange
+	batch ethdb.Batch
+	abort chan struct{}
+
+	account []byte // Path of the account whose storage is converted
+	storage []byte // Path of the storage trie node to resume from, of the account resumed
 }
 
-// commit collapses a node down into a hash node and returns it.
-func (h2p *Hbss2Pbss) commit(path []byte, n node) node {
-	// if this path is clean, use available cached data
-	hash, dirty := n.cache()
-	if hash != nil && !dirty {
-		return hash
+// walk converts the trie nodes of the subtrie at the path, skipping the ones
+// preceding the path to resume from. The hashed nodes are written as they are,
+// the embedded ones are part of their parents.
+func (w *h2pWorker) walk(reader *trieReader, n node, path, from []byte) error {
+	if n == nil || before(path, from) {
+		return nil
 	}
-	// Commit children, then parent, and remove the dirty flag.
-	switch cn := n.(type) {
+	switch n := n.(type) {
+	case hashNode:
+		select {
+		case <-w.abort:
+			return ErrHbss2PbssAborted
+		default:
+		}
+		blob, err := reader.node(path, common.BytesToHash(n))
+		if err != nil {
+			return err
+		}
+		if err := w.write(reader.owner, path, blob); err != nil {
+			return err
+		}
+		return w.walk(reader, mustDecodeNode(n, blob), path, from)
 	case *shortNode:
-		// Commit child
-		collapsed := cn.copy()
-
-		// If the child is fullNode, recursively commit,
-		// otherwise it can only be hashNode or valueNode.
-		if _, ok := cn.Val.(*fullNode); ok {
-			collapsed.Val = h2p.commit(append(path, cn.Key...), cn.Val)
-		}
-		// The key needs to be copied, since we're adding it to the
-		// modified nodeset.
-		collapsed.Key = hexToCompact(cn.Key)
-		return collapsed
+		return w.walk(reader, n.Val, concat(path, n.Key...), from)
 	case *fullNode:
-		hashedKids := h2p.commitChildren(path, cn)
-		collapsed := cn.copy()
-		collapsed.Children = hashedKids
-
-		return collapsed
-	case hashNode:
-		return cn
+		for i, child := range n.Children {
+			if err := w.walk(reader, child, concat(path, byte(i)), from); err != nil {
+				return err
+			}
+		}
+		return nil
+	case valueNode:
+		if reader.owner != (common.Hash{}) {
+			return nil
+		}
+		return w.storageTrie(path, n, bytes.Equal(path, from))
 	default:
-		// nil, valuenode shouldn't be committed
-		panic(fmt.Sprintf("%T: invalid node: %v", n, n))
+		return fmt.Errorf("invalid node type %T", n)
 	}
 }
+
+// storageTrie converts the storage trie of the account at the path, resuming
+// the persisted progress if the account was being converted.
+func (w *h2pWorker) storageTrie(path []byte, blob []byte, resume bool) error {
+	if !hasTerm(path) || len(path) != 2*common.HashLength+1 {
+		return fmt.Errorf("invalid account path %x", path)
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(blob, &account); err != nil {
+		return fmt.Errorf("invalid account at path %x: %w", path, err)
+	}
+	if account.Root == (common.Hash{}) || account.Root == types.EmptyRootHash {
+		return nil
+	}
+	reader, err := newTrieReader(w.h2p.status.Root, common.BytesToHash(hexToKeybytes(path)), w.h2p.db)
+	if err != nil {
+		return err
+	}
+	var from []byte
+	if resume {
+		from = w.storage
+	}
+	w.account = path
+	defer func() { w.account = nil }()
+
+	return w.walk(reader, hashNode(account.Root.Bytes()), nil, from)
+}
+
+// write writes the trie node into the batch, flushing it along with the
+// progress once it's large enough.
+func (w *h2pWorker) write(owner common.Hash, path []byte, blob []byte) error {
+	if owner == (common.Hash{}) {
+		rawdb.WriteAccountTrieNode(w.batch, path, blob)
+	} else {
+		rawdb.WriteStorageTrieNode(w.batch, owner, path, blob)
+	}
+	w.h2p.nodes.Add(1)
+
+	if w.batch.ValueSize() < ethdb.IdealBatchSize {
+		return nil
+	}
+	if owner == (common.Hash{}) {
+		return w.commit(path, nil, false)
+	}
+	return w.commit(w.account, path, false)
+}
+
+// commit flushes the batch along with the progress of the range. The nodes
+// preceding the given paths, and the nodes at them, are all persisted.
+func (w *h2pWorker) commit(account, storage []byte, done bool) error {
+	w.h2p.lock.Lock()
+	defer w.h2p.lock.Unlock()
+
+	w.rng.Account = common.CopyBytes(account)
+	w.rng.Storage = common.CopyBytes(storage)
+	w.rng.Done = done
+	if err := w.h2p.persist(w.batch); err != nil {
+		return err
+	}
+	w.batch.Reset()
+	return nil
+}
+
+func (t *Trie) resloveWithoutTrack(n node, prefix []byte) (node, error) {
+	if n, ok := n.(hashNode); ok {
+		blob, err := t.reader.node(prefix, common.BytesToHash(n))
+		if err != nil {
+			return nil, err
+		}
+		return mustDecodeNode(n, blob), nil
+	}
+	return n, nil
+}
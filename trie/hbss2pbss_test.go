@@ -0,0 +1,217 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/crypto"
+	"github.com/Ezkerrox/bsc/ethdb"
+	"github.com/Ezkerrox/bsc/rlp"
+	"github.com/Ezkerrox/bsc/trie/trienode"
+	"github.com/holiman/uint256"
+)
+
+// h2pTestDb extends the test database with the methods required by the
+// conversion.
+type h2pTestDb struct {
+	*testDb
+}
+
+func (db *h2pTestDb) Cap(limit common.StorageSize) error { return nil }
+func (db *h2pTestDb) Disk() ethdb.Database               { return db.disk }
+
+// makeH2PState creates a state with the given scheme, in which the first
+// account has a large storage trie and some others small ones.
+func makeH2PState(t *testing.T, scheme string) (*h2pTestDb, common.Hash, common.Hash) {
+	var (
+		db    = &h2pTestDb{newTestDatabase(rawdb.NewMemoryDatabase(), scheme)}
+		nodes = trienode.NewMergedNodeSet()
+		state = NewEmpty(db.testDb)
+		large common.Hash
+	)
+	for i := 0; i < 300; i++ {
+		var (
+			key   = crypto.Keccak256Hash(binary.BigEndian.AppendUint64(nil, uint64(i)))
+			slots = 0
+		)
+		switch {
+		case i == 0:
+			slots, large = 5000, key
+		case i%30 == 0:
+			slots = 20
+		}
+		account := types.StateAccount{
+			Nonce:    uint64(i),
+			Balance:  uint256.NewInt(uint64(i)),
+			Root:     types.EmptyRootHash,
+			CodeHash: types.EmptyCodeHash.Bytes(),
+		}
+		if slots > 0 {
+			storage, _ := New(StorageTrieID(types.EmptyRootHash, key, types.EmptyRootHash), db.testDb)
+			for j := 0; j < slots; j++ {
+				value, _ := rlp.EncodeToBytes(uint64(j + 1))
+				storage.MustUpdate(crypto.Keccak256(key[:], binary.BigEndian.AppendUint64(nil, uint64(j))), value)
+			}
+			root, set := storage.Commit(false)
+			if err := nodes.Merge(set); err != nil {
+				t.Fatalf("Failed to merge storage nodes: %v", err)
+			}
+			account.Root = root
+		}
+		blob, _ := rlp.EncodeToBytes(&account)
+		state.MustUpdate(key[:], blob)
+	}
+	root, set := state.Commit(false)
+	if err := nodes.Merge(set); err != nil {
+		t.Fatalf("Failed to merge account nodes: %v", err)
+	}
+	db.Update(root, types.EmptyRootHash, nodes)
+	db.Commit(root)
+	return db, root, large
+}
+
+// checkH2PState checks the path-based trie nodes of the converted database
+// against the ones of a database created in the path-based scheme, returning
+// their number.
+func checkH2PState(t *testing.T, have, want ethdb.Database) int {
+	collect := func(db ethdb.Database) map[string][]byte {
+		nodes := make(map[string][]byte)
+		it := db.NewIterator(nil, nil)
+		defer it.Release()
+
+		for it.Next() {
+			key := it.Key()
+			if rawdb.IsLegacyTrieNode(key, it.Value()) {
+				continue
+			}
+			if rawdb.IsAccountTrieNode(key) || rawdb.IsStorageTrieNode(key) {
+				nodes[string(key)] = common.CopyBytes(it.Value())
+			}
+		}
+		return nodes
+	}
+	haveNodes, wantNodes := collect(have), collect(want)
+	if len(haveNodes) != len(wantNodes) {
+		t.Fatalf("Node count mismatch: have %d, want %d", len(haveNodes), len(wantNodes))
+	}
+	for key, blob := range wantNodes {
+		if !bytes.Equal(haveNodes[key], blob) {
+			t.Fatalf("Node %x mismatch: have %x, want %x", key, haveNodes[key], blob)
+		}
+	}
+	return len(wantNodes)
+}
+
+func TestHbss2Pbss(t *testing.T) {
+	db, root, _ := makeH2PState(t, rawdb.HashScheme)
+	ref, _, _ := makeH2PState(t, rawdb.PathScheme)
+
+	h2p, err := NewHbss2Pbss(db, root, 10, 4)
+	if err != nil {
+		t.Fatalf("Failed to create conversion: %v", err)
+	}
+	if err := h2p.Run(nil); err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	checkH2PState(t, db.disk, ref.disk)
+
+	if scheme := rawdb.ReadStateScheme(db.disk); scheme != rawdb.PathScheme {
+		t.Fatalf("State scheme mismatch: have %s, want %s", scheme, rawdb.PathScheme)
+	}
+	if id := rawdb.ReadStateID(db.disk, root); id == nil || *id != 10 {
+		t.Fatalf("State id mismatch: have %v, want 10", id)
+	}
+	if _, pending := rawdb.ReadHashTriePruning(db.disk); !pending {
+		t.Fatal("Hash trie node pruning not scheduled")
+	}
+	if len(rawdb.ReadHbss2PbssStatus(db.disk)) != 0 {
+		t.Fatal("Conversion status not cleaned up")
+	}
+	// The hash-based nodes are removed afterwards
+	if err := rawdb.PruneHashTrieNodeInDataBase(db.disk, nil); err != nil {
+		t.Fatalf("Failed to prune hash trie nodes: %v", err)
+	}
+	if rawdb.HasLegacyTrieNode(db.disk, root) {
+		t.Fatal("Hash trie nodes not pruned")
+	}
+	if _, pending := rawdb.ReadHashTriePruning(db.disk); pending {
+		t.Fatal("Hash trie node pruning still pending")
+	}
+	checkH2PState(t, db.disk, ref.disk)
+}
+
+func TestHbss2PbssResume(t *testing.T) {
+	db, root, large := makeH2PState(t, rawdb.HashScheme)
+	ref, _, _ := makeH2PState(t, rawdb.PathScheme)
+
+	// Interrupt the conversion late in the large storage trie, by removing one
+	// of its nodes.
+	var missing []byte
+	it := ref.disk.NewIterator(append(rawdb.TrieNodeStoragePrefix, large.Bytes()...), []byte{0x0f})
+	for it.Next() {
+		missing = common.CopyBytes(it.Value())
+	}
+	it.Release()
+	hash := crypto.Keccak256Hash(missing)
+	rawdb.DeleteLegacyTrieNode(db.disk, hash)
+
+	h2p, err := NewHbss2Pbss(db, root, 10, 4)
+	if err != nil {
+		t.Fatalf("Failed to create conversion: %v", err)
+	}
+	var merr *MissingNodeError
+	if err := h2p.Run(nil); !errors.As(err, &merr) {
+		t.Fatalf("Unexpected conversion error: have %v, want missing node", err)
+	}
+	if rawdb.ReadStateScheme(db.disk) == rawdb.PathScheme {
+		t.Fatal("Incomplete conversion switched the state scheme")
+	}
+	// Restore the node and resume from the persisted progress
+	rawdb.WriteLegacyTrieNode(db.disk, hash, missing)
+	if h2p, err = ResumeHbss2Pbss(db, 4); err != nil || h2p == nil {
+		t.Fatalf("Failed to resume conversion: %v", err)
+	}
+	if h2p.Root() != root || h2p.Number() != 10 {
+		t.Fatalf("Resumed conversion mismatch: have %x/%d, want %x/10", h2p.Root(), h2p.Number(), root)
+	}
+	if err := h2p.Run(nil); err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	total := checkH2PState(t, db.disk, ref.disk)
+	if converted := h2p.nodes.Load(); converted >= uint64(total) {
+		t.Fatalf("Conversion not resumed: converted %d of %d nodes", converted, total)
+	}
+	if h2p, _ := ResumeHbss2Pbss(db, 4); h2p != nil {
+		t.Fatal("Conversion still in progress")
+	}
+}
+
+func TestHbss2PbssAbort(t *testing.T) {
+	db, root, _ := makeH2PState(t, rawdb.HashScheme)
+	ref, _, _ := makeH2PState(t, rawdb.PathScheme)
+
+	h2p, err := NewHbss2Pbss(db, root, 10, 4)
+	if err != nil {
+		t.Fatalf("Failed to create conversion: %v", err)
+	}
+	quit := make(chan struct{})
+	close(quit)
+	if err := h2p.Run(quit); err != ErrHbss2PbssAborted {
+		t.Fatalf("Unexpected conversion error: have %v, want %v", err, ErrHbss2PbssAborted)
+	}
+	if err := h2p.Pause(); err != nil {
+		t.Fatalf("Failed to pause conversion: %v", err)
+	}
+	if h2p, err = ResumeHbss2Pbss(db, 4); err != nil || h2p == nil || !h2p.Paused() {
+		t.Fatalf("Paused conversion not resumed: %v", err)
+	}
+	if err := h2p.Run(nil); err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	checkH2PState(t, db.disk, ref.disk)
+}
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/lru"
 	"github.com/Ezkerrox/bsc/core/rawdb"
 	"github.com/Ezkerrox/bsc/core/types"
 	"github.com/Ezkerrox/bsc/crypto"
@@ -215,6 +216,8 @@ type Database struct {
 	tree    *layerTree                   // The group for all known layers
 	freezer ethdb.ResettableAncientStore // Freezer for storing trie histories, nil possible in tests
 	lock    sync.RWMutex                 // Lock to prevent mutations from happening at the same time
+
+	historyIndexes *lru.Cache[uint64, *historyIndex] // Decoded state history indexes, purged once histories are truncated
 }
 
 // New attempts to load an already existing layer from a persistent key-value
@@ -232,6 +235,8 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 		config:   config,
 		diskdb:   diskdb,
 		hasher:   merkleNodeHasher,
+
+		historyIndexes: newHistoryIndexCache(),
 	}
 	// Establish a dedicated database namespace tailored for verkle-specific
 	// data, ensuring the isolation of both verkle and merkle tree data. It's
@@ -423,6 +428,7 @@ func (db *Database) Enable(root common.Hash) error {
 		if err := db.freezer.Reset(); err != nil {
 			return err
 		}
+		db.historyIndexes.Purge()
 	}
 	// Re-construct a new disk layer backed by persistent state
 	// with **empty clean cache and node buffer**.
@@ -488,6 +494,7 @@ func (db *Database) Recover(root common.Hash) error {
 	if err != nil {
 		return err
 	}
+	db.historyIndexes.Purge()
 	log.Debug("Recovered state", "root", root, "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
@@ -0,0 +1,285 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/common/lru"
+	"github.com/Ezkerrox/bsc/core/rawdb"
+	"github.com/Ezkerrox/bsc/core/types"
+	"github.com/Ezkerrox/bsc/rlp"
+	"github.com/Ezkerrox/bsc/trie"
+)
+
+// historyIndexCacheItems is the number of state histories whose indexes are
+// cached for the historical state reads.
+const historyIndexCacheItems = 128
+
+// maxHistoricalScan is the maximum number of state histories a historical state
+// read may look up, i.e. the maximum distance from the requested state to the
+// disk layer. Older states are refused even if their histories are retained.
+var maxHistoricalScan = uint64(16384)
+
+// historyIndex is the decoded index of a state history, shared by the readers
+// through the cache of the database. It must not be modified.
+type historyIndex struct {
+	version  uint8  // Version of the state history
+	accounts []byte // Account index, sorted by address
+	storages []byte // Storage index, sorted by slot key within each account
+}
+
+// newHistoryIndexCache creates the cache of the decoded state history indexes.
+func newHistoryIndexCache() *lru.Cache[uint64, *historyIndex] {
+	return lru.NewCache[uint64, *historyIndex](historyIndexCacheItems)
+}
+
+// HistoricalStateReader provides access to a historical state below the disk
+// layer, within the range covered by the state histories.
+//
+// The value of a state entry at the requested state is the original value
+// recorded by the first state history after it which mutated the entry. If
+// none did, the entry is unchanged since and the value is read from the disk
+// layer. The cost of a read therefore grows with the distance to the disk
+// layer, which is capped by maxHistoricalScan.
+type HistoricalStateReader struct {
+	db *Database
+	id uint64 // State id of the requested state
+}
+
+// HistoricReader constructs a reader for accessing the requested historic state.
+func (db *Database) HistoricReader(root common.Hash) (*HistoricalStateReader, error) {
+	if db.freezer == nil {
+		return nil, errors.New("state history is not available")
+	}
+	if db.isVerkle {
+		return nil, errors.New("historical state of verkle tree is not supported")
+	}
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	tail, err := db.freezer.Tail()
+	if err != nil {
+		return nil, err
+	}
+	// The histories after the requested state, up to the disk layer, must be
+	// retained.
+	if *id < tail {
+		return nil, fmt.Errorf("state %#x is not covered by the state histories", root)
+	}
+	dl := db.tree.bottom()
+	if *id > dl.stateID() {
+		return nil, fmt.Errorf("state %#x is not below the disk layer", root)
+	}
+	if dl.stateID()-*id > maxHistoricalScan {
+		return nil, fmt.Errorf("state %#x is too far below the disk layer (%d > %d)", root, dl.stateID()-*id, maxHistoricalScan)
+	}
+	return &HistoricalStateReader{db: db, id: *id}, nil
+}
+
+// Account retrieves the account associated with the given address. Nil is
+// returned if the account is not existent.
+func (r *HistoricalStateReader) Account(address common.Address) (*types.StateAccount, error) {
+	blob, err := r.read(func(id uint64) ([]byte, bool, error) {
+		return r.historyAccount(id, address)
+	}, func(root common.Hash, tr *trie.Trie) ([]byte, error) {
+		return diskAccount(tr, address)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	return types.FullAccount(blob)
+}
+
+// Storage retrieves the RLP-encoded storage slot associated with the given
+// account address and raw slot key. Nil is returned if the slot is not existent.
+func (r *HistoricalStateReader) Storage(address common.Address, key common.Hash) ([]byte, error) {
+	return r.read(func(id uint64) ([]byte, bool, error) {
+		return r.historyStorage(id, address, key)
+	}, func(root common.Hash, tr *trie.Trie) ([]byte, error) {
+		blob, err := diskAccount(tr, address)
+		if err != nil || len(blob) == 0 {
+			return nil, err
+		}
+		account, err := types.FullAccount(blob)
+		if err != nil {
+			return nil, err
+		}
+		h := newHasher()
+		defer h.release()
+
+		st, err := trie.New(trie.StorageTrieID(root, h.hash(address.Bytes()), account.Root), r.db)
+		if err != nil {
+			return nil, err
+		}
+		return st.Get(h.hash(key.Bytes()).Bytes())
+	})
+}
+
+// read resolves a state entry, looking it up in the state histories after the
+// requested state and falling back to the disk layer. If the disk layer turns
+// stale during the read, the histories it was flushed into are looked up too.
+func (r *HistoricalStateReader) read(history func(id uint64) ([]byte, bool, error), disk func(root common.Hash, tr *trie.Trie) ([]byte, error)) ([]byte, error) {
+	next := r.id + 1
+	for {
+		dl := r.db.tree.bottom()
+		if dl.stateID() < r.id {
+			return nil, errors.New("state was reverted")
+		}
+		for ; next <= dl.stateID(); next++ {
+			blob, found, err := history(next)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return blob, nil
+			}
+		}
+		root := dl.rootHash()
+		tr, err := trie.New(trie.StateTrieID(root), r.db)
+		if err == nil {
+			var blob []byte
+			if blob, err = disk(root, tr); err == nil {
+				return blob, nil
+			}
+		}
+		if !dl.isStale() {
+			return nil, err
+		}
+	}
+}
+
+// diskAccount retrieves the account associated with the given address from the
+// account trie, in the slim data format.
+func diskAccount(tr *trie.Trie, address common.Address) ([]byte, error) {
+	h := newHasher()
+	defer h.release()
+
+	blob, err := tr.Get(h.hash(address.Bytes()).Bytes())
+	if err != nil || len(blob) == 0 {
+		return nil, err
+	}
+	account := new(types.StateAccount)
+	if err := rlp.DecodeBytes(blob, account); err != nil {
+		return nil, err
+	}
+	return types.SlimAccountRLP(*account), nil
+}
+
+// historyIndex retrieves the decoded index of the state history with the given
+// id, from the cache if possible.
+func (r *HistoricalStateReader) historyIndex(id uint64) (*historyIndex, error) {
+	if index, ok := r.db.historyIndexes.Get(id); ok {
+		return index, nil
+	}
+	var m meta
+	if err := m.decode(rawdb.ReadStateHistoryMeta(r.db.freezer, id)); err != nil {
+		return nil, fmt.Errorf("state history %d is not available: %v", id, err)
+	}
+	index := &historyIndex{
+		version:  m.version,
+		accounts: rawdb.ReadStateAccountIndex(r.db.freezer, id),
+		storages: rawdb.ReadStateStorageIndex(r.db.freezer, id),
+	}
+	if len(index.accounts) == 0 || len(index.accounts)%accountIndexSize != 0 {
+		return nil, fmt.Errorf("state history %d is not available", id)
+	}
+	if len(index.storages)%slotIndexSize != 0 {
+		return nil, fmt.Errorf("storage index of state history %d is corrupted", id)
+	}
+	r.db.historyIndexes.Add(id, index)
+	return index, nil
+}
+
+// historyAccountIndex locates the account in the index of the state history
+// with the given id, reporting whether it was mutated.
+func (r *HistoricalStateReader) historyAccountIndex(id uint64, address common.Address) (*historyIndex, accountIndex, bool, error) {
+	history, err := r.historyIndex(id)
+	if err != nil {
+		return nil, accountIndex{}, false, err
+	}
+	indexes := history.accounts
+	n := len(indexes) / accountIndexSize
+	pos := sort.Search(n, func(i int) bool {
+		return bytes.Compare(indexes[i*accountIndexSize:i*accountIndexSize+common.AddressLength], address.Bytes()) >= 0
+	})
+	if pos == n {
+		return history, accountIndex{}, false, nil
+	}
+	var index accountIndex
+	index.decode(indexes[pos*accountIndexSize : (pos+1)*accountIndexSize])
+	return history, index, index.address == address, nil
+}
+
+// historyAccount retrieves the original value of the account recorded by the
+// state history with the given id, reporting whether it was mutated.
+func (r *HistoricalStateReader) historyAccount(id uint64, address common.Address) ([]byte, bool, error) {
+	_, index, found, err := r.historyAccountIndex(id, address)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	data := rawdb.ReadStateAccountHistory(r.db.freezer, id)
+	end := index.offset + uint32(index.length)
+	if uint32(len(data)) < end {
+		return nil, false, fmt.Errorf("account data of state history %d is corrupted", id)
+	}
+	return data[index.offset:end], true, nil
+}
+
+// historyStorage retrieves the original value of the storage slot recorded by
+// the state history with the given id, reporting whether it was mutated.
+func (r *HistoricalStateReader) historyStorage(id uint64, address common.Address, key common.Hash) ([]byte, bool, error) {
+	history, index, found, err := r.historyAccountIndex(id, address)
+	if err != nil || !found || index.storageSlots == 0 {
+		return nil, false, err
+	}
+	if history.version == stateHistoryV0 {
+		h := newHasher()
+		key = h.hash(key.Bytes())
+		h.release()
+	}
+	indexes := history.storages
+	if uint32(len(indexes)) < (index.storageOffset+index.storageSlots)*uint32(slotIndexSize) {
+		return nil, false, fmt.Errorf("storage index of state history %d is corrupted", id)
+	}
+	indexes = indexes[index.storageOffset*uint32(slotIndexSize) : (index.storageOffset+index.storageSlots)*uint32(slotIndexSize)]
+	pos := sort.Search(int(index.storageSlots), func(i int) bool {
+		return bytes.Compare(indexes[i*slotIndexSize:i*slotIndexSize+common.HashLength], key.Bytes()) >= 0
+	})
+	if pos == int(index.storageSlots) {
+		return nil, false, nil
+	}
+	var slot slotIndex
+	slot.decode(indexes[pos*slotIndexSize : (pos+1)*slotIndexSize])
+	if slot.id != key {
+		return nil, false, nil
+	}
+	data := rawdb.ReadStateStorageHistory(r.db.freezer, id)
+	end := slot.offset + uint32(slot.length)
+	if uint32(len(data)) < end {
+		return nil, false, fmt.Errorf("storage data of state history %d is corrupted", id)
+	}
+	return data[slot.offset:end], true, nil
+}
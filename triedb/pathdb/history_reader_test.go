@@ -0,0 +1,170 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Ezkerrox/bsc/common"
+	"github.com/Ezkerrox/bsc/core/types"
+)
+
+func (t *tester) verifyHistoricState(root common.Hash) error {
+	reader, err := t.db.HistoricReader(root)
+	if err != nil {
+		return err
+	}
+	for addrHash, blob := range t.snapAccounts[root] {
+		account, err := reader.Account(t.accountPreimage(addrHash))
+		if err != nil {
+			return err
+		}
+		want, _ := types.FullAccount(blob)
+		if account == nil || account.Root != want.Root || !bytes.Equal(account.CodeHash, want.CodeHash) || account.Nonce != want.Nonce {
+			return fmt.Errorf("account %x is mismatched", addrHash)
+		}
+	}
+	// The accounts created afterwards must not be existent
+	for addrHash := range t.accounts {
+		if _, ok := t.snapAccounts[root][addrHash]; ok {
+			continue
+		}
+		account, err := reader.Account(t.accountPreimage(addrHash))
+		if err != nil {
+			return err
+		}
+		if account != nil {
+			return fmt.Errorf("account %x is unexpectedly existent", addrHash)
+		}
+	}
+	for addrHash, slots := range t.snapStorages[root] {
+		for hash, slot := range slots {
+			blob, err := reader.Storage(t.accountPreimage(addrHash), t.hashPreimage(hash))
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(blob, slot) {
+				return fmt.Errorf("slot %x of account %x is mismatched", hash, addrHash)
+			}
+		}
+	}
+	return nil
+}
+
+func TestHistoricReader(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0, false, 12)
+	defer tester.release()
+
+	// The states above the disk layer are served by the live readers
+	bottom := tester.bottomIndex()
+	for i := bottom + 1; i < len(tester.roots); i++ {
+		if _, err := tester.db.HistoricReader(tester.roots[i]); err == nil {
+			t.Fatalf("Unexpected historic reader of the state %d above the disk layer", i)
+		}
+	}
+	for i := 0; i < bottom; i++ {
+		if err := tester.verifyHistoricState(tester.roots[i]); err != nil {
+			t.Fatalf("Historic state %d is invalid, err: %v", i, err)
+		}
+	}
+	// Flush all the layers into the disk, the histories must be looked up over
+	// the new disk layer
+	if err := tester.db.Commit(tester.lastHash(), false); err != nil {
+		t.Fatalf("Failed to cap database, err: %v", err)
+	}
+	for i := 0; i < len(tester.roots)-1; i++ {
+		if err := tester.verifyHistoricState(tester.roots[i]); err != nil {
+			t.Fatalf("Historic state %d is invalid, err: %v", i, err)
+		}
+	}
+}
+
+func TestHistoricReaderTruncated(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 4, false, 12)
+	defer tester.release()
+
+	tail, err := tester.db.freezer.Tail()
+	if err != nil {
+		t.Fatalf("Failed to obtain freezer tail, err: %v", err)
+	}
+	bottom := tester.bottomIndex()
+	for i := 0; i < bottom; i++ {
+		// The state ids are pruned along with the histories, the state of
+		// the oldest retained history is not reachable either.
+		_, err := tester.db.HistoricReader(tester.roots[i])
+		if uint64(i+1) <= tail && err == nil {
+			t.Fatalf("Unexpected historic reader of the pruned state %d", i)
+		}
+		if uint64(i+1) > tail {
+			if err := tester.verifyHistoricState(tester.roots[i]); err != nil {
+				t.Fatalf("Historic state %d is invalid, err: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestHistoricReaderScanLimit(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+	maxHistoricalScan = 3
+	defer func() {
+		maxHistoricalScan = 16384
+	}()
+
+	tester := newTester(t, 0, false, 12)
+	defer tester.release()
+
+	bottom := tester.bottomIndex()
+	for i := 0; i < bottom; i++ {
+		_, err := tester.db.HistoricReader(tester.roots[i])
+		if distance := uint64(bottom - i); distance > maxHistoricalScan {
+			if err == nil {
+				t.Fatalf("Unexpected historic reader of the state %d, %d below the disk layer", i, distance)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to open historic reader of the state %d, err: %v", i, err)
+		}
+		// The state is read twice, served by the cached indexes the second time
+		for j := 0; j < 2; j++ {
+			if err := tester.verifyHistoricState(tester.roots[i]); err != nil {
+				t.Fatalf("Historic state %d is invalid, err: %v", i, err)
+			}
+		}
+	}
+	if tester.db.historyIndexes.Len() == 0 {
+		t.Fatal("State history indexes are not cached")
+	}
+}